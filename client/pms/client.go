@@ -61,7 +61,7 @@ func (pc pmsClient) CreatePolicy(request *models.PolicyRequest) (*models.PolicyR
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyContentType, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -93,7 +93,7 @@ func (pc pmsClient) DeletePolicy(policyID uuid.UUID) error {
 		return errors.Wrap(err, " Error forming request")
 	}
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	_, err = client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -119,7 +119,7 @@ func (pc pmsClient) GetPolicy(policyID uuid.UUID) (*models.PolicyResponse, error
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -151,7 +151,7 @@ func (pc pmsClient) SearchPolicy() ([]models.PolicyResponse, error) {
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -190,7 +190,7 @@ func (pc pmsClient) UpdatePolicy(request *models.PolicyUpdateRequest) (*models.P
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyContentType, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -85,7 +85,7 @@ func (pc tmsClient) CreateApiClient(request *models.CreateApiClient) (*models.Ap
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyContentType, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -123,7 +123,7 @@ func (pc tmsClient) UpdateApiClient(request *models.UpdateApiClient, apiClientId
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyContentType, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -155,7 +155,7 @@ func (pc tmsClient) GetApiClient(serviceId uuid.UUID) ([]models.ApiClient, error
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -187,7 +187,7 @@ func (pc tmsClient) RetrieveApiClient(serviceId uuid.UUID, apiClientId uuid.UUID
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -219,7 +219,7 @@ func (pc tmsClient) GetApiClientPolicies(serviceId, apiClientId uuid.UUID) (*mod
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -251,7 +251,7 @@ func (pc tmsClient) GetApiClientTagValues(serviceId, apiClientId uuid.UUID) (*mo
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -283,7 +283,7 @@ func (pc tmsClient) DeleteApiClient(serviceId, apiClientId uuid.UUID) error {
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	_, err = client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -312,7 +312,7 @@ func (pc tmsClient) CreateUser(user *models.CreateTenantUser) (*models.TenantUse
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyContentType, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -350,7 +350,7 @@ func (pc tmsClient) UpdateTenantUserRole(request *models.UpdateTenantUserRoles)
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyContentType, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -381,7 +381,7 @@ func (pc tmsClient) GetUsers() ([]models.TenantUser, error) {
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -411,7 +411,7 @@ func (pc tmsClient) DeleteUser(userId uuid.UUID) error {
 		return errors.Wrap(err, "Error forming request")
 	}
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	_, err = client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -434,7 +434,7 @@ func (pc tmsClient) GetServices() ([]models.Service, error) {
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -465,7 +465,7 @@ func (pc tmsClient) RetrieveService(id uuid.UUID) (*models.ServiceDetail, error)
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -496,7 +496,7 @@ func (pc tmsClient) GetProducts(serviceOfferId uuid.UUID) ([]models.Product, err
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -527,7 +527,7 @@ func (pc tmsClient) GetServiceOffers() ([]models.ServiceOffer, error) {
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -564,7 +564,7 @@ func (pc tmsClient) CreateTenantTag(request *models.TagCreate) (*models.Tag, err
 	req.Header.Add(constants.HTTPHeaderKeyContentType, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -595,7 +595,7 @@ func (pc tmsClient) GetTenantTags() (*models.Tags, error) {
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -625,7 +625,7 @@ func (pc tmsClient) DeleteTenantTag(tagId uuid.UUID) error {
 		return errors.Wrap(err, "Error forming request")
 	}
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	_, err = client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -649,7 +649,7 @@ func (pc tmsClient) GetPlans(serviceOfferId uuid.UUID) ([]models.Plan, error) {
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -681,7 +681,7 @@ func (pc tmsClient) RetrievePlan(serviceOfferId, planId uuid.UUID) (*models.Plan
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -718,7 +718,7 @@ func (pc tmsClient) UpdateTenantSettings(request *models.AttestationFailureEmail
 	req.Header.Add(constants.HTTPHeaderKeyContentType, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -749,7 +749,7 @@ func (pc tmsClient) GetTenantSettings() (*models.AttestationFailureEmail, error)
 	}
 	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
 	req.Header.Add(constants.HTTPHeaderKeyApiKey, pc.ApiKey)
-	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.RequestId)
+	req.Header.Add(constants.HTTPHeaderKeyRequestId, models2.RespHeaderFields.GetRequestId())
 
 	response, err := client.SendRequest(pc.Client, req)
 	if err != nil {
@@ -22,18 +22,39 @@ import (
 
 var (
 	retryableStatusCode = map[int]bool{
+		// the backoff of retryablehttp waits for the Retry-After header of 429 responses, if any
+		429: true,
 		500: true,
 		503: true,
 		504: true,
 	}
 )
 
+// ResponseError is returned when Trust Authority does not accept a request, its status code tells apart missing
+// resources from other failures
+type ResponseError struct {
+	URL        string
+	Status     string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("The call to %q returned %q. Error: %s", e.URL, e.Status, e.Body)
+}
+
+// IsNotFound tells whether the error, or the error it wraps, reports a resource which does not exist
+func IsNotFound(err error) bool {
+	responseError, ok := errors.Cause(err).(*ResponseError)
+	return ok && responseError.StatusCode == http.StatusNotFound
+}
+
 func SendRequest(client *http.Client, req *http.Request) ([]byte, error) {
 	var resp *http.Response
 	var err error
 
 	// set the request Id to the provided in case there is an error while sending and receiving request
-	models.RespHeaderFields.RequestId = req.Header.Get(constants.HTTPHeaderKeyRequestId)
+	models.RespHeaderFields.SetRequestId(req.Header.Get(constants.HTTPHeaderKeyRequestId))
 
 	var retryClient = rClient.NewClient()
 	retryClient.HTTPClient = client
@@ -55,8 +76,8 @@ func SendRequest(client *http.Client, req *http.Request) ([]byte, error) {
 			}
 		}()
 		//Get the request and trace ID from response header
-		models.RespHeaderFields.RequestId = resp.Header.Get(constants.HTTPHeaderKeyRequestId)
-		models.RespHeaderFields.TraceId = resp.Header.Get(constants.HTTPHeaderKeyTraceId)
+		models.RespHeaderFields.SetResponseIds(resp.Header.Get(constants.HTTPHeaderKeyRequestId),
+			resp.Header.Get(constants.HTTPHeaderKeyTraceId))
 		//create byte array of HTTP response body
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
@@ -64,7 +85,7 @@ func SendRequest(client *http.Client, req *http.Request) ([]byte, error) {
 		}
 
 		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
-			return nil, errors.Errorf("The call to %q returned %q. Error: %s", req.URL, resp.Status, body)
+			return nil, errors.WithStack(&ResponseError{URL: req.URL.String(), Status: resp.Status, StatusCode: resp.StatusCode, Body: body})
 		}
 		return body, nil
 	} else {
@@ -95,7 +116,8 @@ func retryPolicy(ctx context.Context, resp *http.Response, err error) (bool, err
 
 	// Check the response code. We retry on 500, 503 and 504 responses to allow
 	// the server time to recover, as these are typically not permanent
-	// errors and may relate to outages on the server side.
+	// errors and may relate to outages on the server side, and on 429
+	// responses once the rate limit allows it.
 	if ok := retryableStatusCode[resp.StatusCode]; ok {
 		return true, fmt.Errorf("unexpected HTTP status %s", resp.Status)
 	}
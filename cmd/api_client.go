@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/keysink"
+	"intel/tac/v1/models"
+
+	"github.com/spf13/cobra"
+)
+
+// apiClientCmd groups the API client workflows that go beyond plain CRUD
+var apiClientCmd = &cobra.Command{
+	Use:   constants.ApiClientCmd,
+	Short: "Manage the lifecycle of api clients",
+	Long:  ``,
+}
+
+func init() {
+	tenantCmd.AddCommand(apiClientCmd)
+}
+
+// apiClientTagIdValues converts the tags returned with an API client to the tags of a create or update request
+func apiClientTagIdValues(tags []models.ApiClientTagValue) []models.ApiClientTagIdValue {
+	var tagIdValues []models.ApiClientTagIdValue
+	for _, tag := range tags {
+		tagIdValues = append(tagIdValues, models.ApiClientTagIdValue{Key: tag.Name, Value: tag.Value})
+	}
+	return tagIdValues
+}
+
+// addKeyOutputFlags adds the flags selecting where the attestation API keys of an api client are written to
+func addKeyOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().String(constants.KeyOutputParamName, "", "Where the attestation API keys are written to instead of being printed: "+
+		"\"file:<path>\" or a path for a new file only readable by the current user, \"dotenv:<path>\", \"k8s-secret:<path>\" "+
+		"for a Kubernetes Secret manifest, or \"exec:<command>\" for a command receiving the keys on stdin")
+	cmd.Flags().Bool(constants.ShowKeysParamName, false, "Print the attestation API keys in full instead of masked")
+}
+
+// apiClientKeyOutput returns the sink of the --key-output flag, nil when the flag is not provided
+func apiClientKeyOutput(cmd *cobra.Command) (keysink.Sink, error) {
+	keyOutput, err := cmd.Flags().GetString(constants.KeyOutputParamName)
+	if err != nil || keyOutput == "" {
+		return nil, err
+	}
+	return keysink.Parse(keyOutput)
+}
+
+// deliverApiClientKeys writes the attestation API keys of the api client to the sink, when provided, and then masks
+// them in the api client unless --show-keys is set
+func deliverApiClientKeys(cmd *cobra.Command, sink keysink.Sink, apiClient *models.ApiClientDetail) error {
+	showKeys, err := cmd.Flags().GetBool(constants.ShowKeysParamName)
+	if err != nil {
+		return err
+	}
+	if sink != nil {
+		if err = sink.Write(apiClient); err != nil {
+			return err
+		}
+		fmt.Printf("Attestation API keys of api client %s written to %s\n", apiClient.ID, sink)
+	}
+	if !showKeys {
+		apiClient.Keys = keysink.MaskKeys(apiClient.Keys)
+	}
+	return nil
+}
+
+// fetchApiClientKeysCommand returns the command fetching the attestation API keys of an api client again, for the
+// errors of the commands which created it but could not write its keys
+func fetchApiClientKeysCommand(serviceId, apiClientId uuid.UUID) string {
+	return fmt.Sprintf("list apiClient -r %s -c %s --key-output <key output>", serviceId, apiClientId)
+}
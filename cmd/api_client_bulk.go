@@ -0,0 +1,481 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/bulk"
+	"intel/tac/v1/internal/keysink"
+	"intel/tac/v1/internal/lookup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// apiClientBulkCreateCmd represents the apiClient bulk-create command
+var apiClientBulkCreateCmd = &cobra.Command{
+	Use:   constants.BulkCreateCmd,
+	Short: "Create the api clients listed in a CSV or YAML file",
+	Long: `Create the api clients listed in a CSV file, with a header row naming the name, service, product, status,
+policies and tags columns, or in a YAML file holding a list of api clients with the same fields. Services, products
+and policies are referenced by id or name, multiple policies or tags of a CSV row are separated by semicolons, and
+tags are key:value pairs. Api clients are created concurrently, the failure of one not stopping the others, and api
+clients which already exist in their service are left unchanged so that the file can be submitted again once the
+failures are fixed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apiClient bulk-create called")
+		response, err := bulkCreateApiClients(cmd)
+		utils.PrintRequestAndTraceId()
+		if response != "" {
+			fmt.Println("ApiClients: \n\n", response)
+		}
+		return err
+	},
+}
+
+func init() {
+	apiClientCmd.AddCommand(apiClientBulkCreateCmd)
+
+	apiClientBulkCreateCmd.Flags().StringP(constants.InputFileParamName, "f", "", "Path of the CSV (.csv) or YAML file listing the api clients to be created")
+	apiClientBulkCreateCmd.Flags().String(constants.KeyOutputParamName, "", "Where the attestation API keys of every api client "+
+		"are written to, with "+constants.KeyOutputNamePlaceholder+" replaced by the name of the api client: \"file:<path>\" "+
+		"or a path for a new file only readable by the current user, \"dotenv:<path>\", \"k8s-secret:<path>\" for a "+
+		"Kubernetes Secret manifest, or \"exec:<command>\" for a command receiving the keys on stdin. The keys are not "+
+		"printed otherwise")
+	addBulkFlags(apiClientBulkCreateCmd)
+	apiClientBulkCreateCmd.MarkFlagRequired(constants.InputFileParamName)
+}
+
+// addBulkFlags adds the flags shared by the bulk operations on api clients
+func addBulkFlags(cmd *cobra.Command) {
+	cmd.Flags().Int(constants.ConcurrencyParamName, constants.DefaultBulkConcurrency, fmt.Sprintf("Number of api "+
+		"clients processed concurrently, at most %d", constants.MaxBulkConcurrency))
+	cmd.Flags().Float64(constants.RateLimitParamName, constants.DefaultBulkRateLimit, "Maximum number of requests sent "+
+		"to Trust Authority per second, 0 for no limit. Requests rejected by Trust Authority rate limits are retried")
+	cmd.Flags().StringP(constants.OutFileParamName, "o", "", "Path of the result file mapping every api client to its id "+
+		"or error, written as CSV when the path ends with .csv and as JSON otherwise")
+	cmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+}
+
+// bulkHttpClient returns the http client of a bulk operation, whose requests are rate limited, along with the number
+// of api clients to be processed concurrently
+func bulkHttpClient(cmd *cobra.Command, configValues *config.Configuration) (*http.Client, int, error) {
+	concurrency, err := cmd.Flags().GetInt(constants.ConcurrencyParamName)
+	if err != nil {
+		return nil, 0, err
+	}
+	if concurrency < 1 || concurrency > constants.MaxBulkConcurrency {
+		return nil, 0, errors.Errorf("--%s should be between 1 and %d", constants.ConcurrencyParamName, constants.MaxBulkConcurrency)
+	}
+	rateLimit, err := cmd.Flags().GetFloat64(constants.RateLimitParamName)
+	if err != nil {
+		return nil, 0, err
+	}
+	if rateLimit < 0 {
+		return nil, 0, errors.Errorf("--%s cannot be negative", constants.RateLimitParamName)
+	}
+	requestId, err := cmd.Flags().GetString(constants.RequestIdParamName)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &http.Client{
+		Timeout:   time.Duration(configValues.HTTPClientTimeout) * time.Second,
+		Transport: bulk.RequestIdTransport(bulk.RateLimitTransport(http.DefaultTransport, rateLimit), requestId),
+	}, concurrency, nil
+}
+
+// bulkItemClient returns a copy of the http client of a bulk operation recording the request and trace ids of the
+// responses in the result of an api client
+func bulkItemClient(client *http.Client, result *models2.ApiClientBulkResult) *http.Client {
+	itemClient := *client
+	itemClient.Transport = bulk.ResponseIdsTransport(client.Transport, func(requestId, traceId string) {
+		result.RequestId = requestId
+		result.TraceId = traceId
+	})
+	return &itemClient
+}
+
+func bulkCreateApiClients(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client, concurrency, err := bulkHttpClient(cmd, configValues)
+	if err != nil {
+		return "", err
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	inputFile, err := cmd.Flags().GetString(constants.InputFileParamName)
+	if err != nil {
+		return "", err
+	}
+	outFile, err := cmd.Flags().GetString(constants.OutFileParamName)
+	if err != nil {
+		return "", err
+	}
+	keyOutput, err := cmd.Flags().GetString(constants.KeyOutputParamName)
+	if err != nil {
+		return "", err
+	}
+	if keyOutput != "" {
+		if err = keysink.ValidateShared(keyOutput); err != nil {
+			return "", err
+		}
+	}
+
+	entries, err := bulk.Load(inputFile)
+	if err != nil {
+		return "", err
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	resolver, err := newBulkResolver(tmsClient, pms.NewPmsClient(client, pmsUrl, apiKey), entries)
+	if err != nil {
+		return "", err
+	}
+
+	// references are resolved before any api client is created, the api clients which cannot be created being
+	// reported along with the others
+	results := make([]models2.ApiClientBulkResult, len(entries))
+	requests := make([]*models.CreateApiClient, len(entries))
+	for i := range entries {
+		entry := &entries[i]
+		results[i] = models2.ApiClientBulkResult{Row: entry.Row, Name: entry.Name}
+		request, existing, err := resolver.resolve(entry)
+		switch {
+		case err != nil:
+			results[i].Action = constants.BulkActionFailed
+			results[i].Error = err.Error()
+		case existing != nil:
+			results[i].ServiceId = &existing.ServiceId
+			results[i].ApiClientId = &existing.ID
+			results[i].Action = constants.BulkActionExists
+		default:
+			results[i].ServiceId = &request.ServiceId
+			requests[i] = request
+		}
+	}
+
+	bulk.Run(len(entries), concurrency, func(i int) {
+		if requests[i] == nil {
+			return
+		}
+		result := &results[i]
+		itemTmsClient := tms.NewTmsClient(bulkItemClient(client, result), tmsUrl, apiKey)
+		response, err := itemTmsClient.CreateApiClient(requests[i])
+		if err != nil {
+			log.WithError(err).Errorf("Error creating api client %s of row %d", result.Name, result.Row)
+			result.Action = constants.BulkActionFailed
+			result.Error = err.Error()
+			return
+		}
+		result.ApiClientId = &response.ID
+		result.Action = constants.BulkActionCreated
+		if keyOutput == "" {
+			return
+		}
+		sink, err := keysink.ParseFor(keyOutput, response.Name)
+		if err == nil {
+			err = sink.Write(response)
+		}
+		if err != nil {
+			result.Action = constants.BulkActionFailed
+			result.Error = fmt.Sprintf("Api client was created but its attestation API keys could not be written, they "+
+				"can be fetched again with \"%s\": %s", fetchApiClientKeysCommand(*result.ServiceId, response.ID), err.Error())
+			return
+		}
+		result.KeyOutput = sink.String()
+	})
+
+	return reportBulkResults(&models2.ApiClientBulk{Results: results}, outFile)
+}
+
+// bulkResolver resolves the services, products and policies referenced by the api clients to be created
+type bulkResolver struct {
+	tmsClient  tms.TmsClient
+	services   []models.Service
+	policies   []models.PolicyResponse
+	products   map[uuid.UUID][]models.Product
+	apiClients map[uuid.UUID][]models.ApiClient
+	seen       map[string]int
+}
+
+func newBulkResolver(tmsClient tms.TmsClient, pmsClient pms.PmsClient, entries []bulk.Entry) (*bulkResolver, error) {
+	services, err := tmsClient.GetServices()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the services of the tenant")
+	}
+	resolver := &bulkResolver{
+		tmsClient:  tmsClient,
+		services:   services,
+		products:   map[uuid.UUID][]models.Product{},
+		apiClients: map[uuid.UUID][]models.ApiClient{},
+		seen:       map[string]int{},
+	}
+	for _, entry := range entries {
+		if len(entry.Policies) > 0 {
+			if resolver.policies, err = pmsClient.SearchPolicy(); err != nil {
+				return nil, errors.Wrap(err, "Error fetching the policies of the tenant")
+			}
+			break
+		}
+	}
+	return resolver, nil
+}
+
+// resolve returns the request creating the api client, or the api client of the same name when it already exists in
+// the service
+func (r *bulkResolver) resolve(entry *bulk.Entry) (*models.CreateApiClient, *models.ApiClient, error) {
+	if err := entry.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	var service *models.Service
+	matches := 0
+	for i := range r.services {
+		if lookup.Matches(entry.Service, r.services[i].ID, r.services[i].Name) {
+			service = &r.services[i]
+			matches++
+		}
+	}
+	if err := lookup.CheckMatches(constants.ServiceCmd, entry.Service, matches); err != nil {
+		return nil, nil, err
+	}
+
+	key := service.ID.String() + "/" + entry.Name
+	if row, ok := r.seen[key]; ok {
+		return nil, nil, errors.Errorf("Api client %s of service %s is already listed in row %d", entry.Name, service.Name, row)
+	}
+	r.seen[key] = entry.Row
+
+	apiClients, ok := r.apiClients[service.ID]
+	if !ok {
+		var err error
+		if apiClients, err = r.tmsClient.GetApiClient(service.ID); err != nil {
+			return nil, nil, errors.Wrapf(err, "Error fetching the api clients of service %s", service.ID)
+		}
+		r.apiClients[service.ID] = apiClients
+	}
+	for i := range apiClients {
+		if apiClients[i].Name == entry.Name {
+			existing := apiClients[i]
+			existing.ServiceId = service.ID
+			return nil, &existing, nil
+		}
+	}
+
+	products, ok := r.products[service.ServiceOfferId]
+	if !ok {
+		var err error
+		if products, err = r.tmsClient.GetProducts(service.ServiceOfferId); err != nil {
+			return nil, nil, errors.Wrapf(err, "Error fetching the products of service offer %s", service.ServiceOfferId)
+		}
+		r.products[service.ServiceOfferId] = products
+	}
+	var productId uuid.UUID
+	matches = 0
+	for _, product := range products {
+		if lookup.Matches(entry.Product, product.ID, product.Name) {
+			productId = product.ID
+			matches++
+		}
+	}
+	if err := lookup.CheckMatches(constants.ProductCmd, entry.Product, matches); err != nil {
+		return nil, nil, err
+	}
+
+	var policyIds []uuid.UUID
+	for _, reference := range entry.Policies {
+		var policyId uuid.UUID
+		matches = 0
+		for _, policy := range r.policies {
+			if lookup.Matches(reference, policy.PolicyId, policy.PolicyName) {
+				policyId = policy.PolicyId
+				matches++
+			}
+		}
+		if err := lookup.CheckMatches(constants.PolicyCmd, reference, matches); err != nil {
+			return nil, nil, err
+		}
+		policyIds = append(policyIds, policyId)
+	}
+
+	var tags []models.ApiClientTagIdValue
+	for _, tag := range entry.Tags {
+		key, value, _ := bulk.ParseTag(tag)
+		tags = append(tags, models.ApiClientTagIdValue{Key: key, Value: value})
+	}
+
+	return &models.CreateApiClient{
+		ProductId:    productId,
+		ServiceId:    service.ID,
+		Name:         entry.Name,
+		PolicyIds:    policyIds,
+		TagIdsValues: tags,
+		Status:       models.ApiClientStatus(entry.Status),
+	}, nil, nil
+}
+
+// reportBulkResults counts the api clients which failed, writes the result file when requested and formats the
+// report. An error is returned along with the report when any api client failed.
+func reportBulkResults(report *models2.ApiClientBulk, outFile string) (string, error) {
+	for _, result := range report.Results {
+		if result.Action == constants.BulkActionFailed {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+	}
+	if outFile != "" {
+		if err := writeBulkResults(report, outFile); err != nil {
+			return "", err
+		}
+		report.ResultFile = outFile
+	}
+
+	responseBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if report.Failed > 0 {
+		return string(responseBytes), errors.Errorf("%d of %d api clients failed", report.Failed, len(report.Results))
+	}
+	return string(responseBytes), nil
+}
+
+// writeBulkResults writes the results as CSV, when the path ends with .csv, or as JSON
+func writeBulkResults(report *models2.ApiClientBulk, outFile string) error {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(outFile), constants.BulkCsvFileExtension) {
+		var buffer bytes.Buffer
+		writer := csv.NewWriter(&buffer)
+		records := [][]string{{"row", "name", "service_id", "api_client_id", "action", "key_output", "error", "request_id",
+			"trace_id"}}
+		for _, result := range report.Results {
+			record := []string{"", result.Name, "", "", result.Action, result.KeyOutput, result.Error, result.RequestId,
+				result.TraceId}
+			if result.Row > 0 {
+				record[0] = strconv.Itoa(result.Row)
+			}
+			if result.ServiceId != nil {
+				record[2] = result.ServiceId.String()
+			}
+			if result.ApiClientId != nil {
+				record[3] = result.ApiClientId.String()
+			}
+			records = append(records, record)
+		}
+		if err = writer.WriteAll(records); err != nil {
+			return err
+		}
+		content = buffer.Bytes()
+	}
+	if err = os.WriteFile(filepath.Clean(outFile), content, constants.DefaultFilePermission); err != nil {
+		return errors.Wrap(err, "Error writing the result file")
+	}
+	return nil
+}
+
+// printBulkMatches lists the api clients matching the selector of a bulk operation, along with the ones which could
+// not be checked and are left as they are
+func printBulkMatches(out io.Writer, selector, done string, results []models2.ApiClientBulkResult, services []models.Service) {
+	serviceNames := map[uuid.UUID]string{}
+	for _, service := range services {
+		serviceNames[service.ID] = service.Name
+	}
+	matched, failed := 0, 0
+	for _, result := range results {
+		if result.Action == constants.BulkActionFailed {
+			failed++
+		} else {
+			matched++
+		}
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%d api clients match %s:\n\n", matched, selector)
+	fmt.Fprintln(w, "SERVICE\tAPI CLIENT\tID")
+	for _, result := range results {
+		if result.Action != constants.BulkActionFailed {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", serviceNames[*result.ServiceId], result.Name, result.ApiClientId)
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(w, "\n%d api clients could not be checked and are not %s:\n\n", failed, done)
+		for _, result := range results {
+			if result.Action == constants.BulkActionFailed {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", serviceNames[*result.ServiceId], result.Name, result.Error)
+			}
+		}
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+}
+
+// confirmBulkAction checks that --confirm matches the number of api clients of a bulk operation, or prompts for
+// "<confirmation> <number of api clients>" to be typed, e.g. "revoke 12"
+func confirmBulkAction(cmd *cobra.Command, confirmation string, count int, change, done string) error {
+	if cmd.Flags().Changed(constants.ConfirmParamName) {
+		confirm, err := cmd.Flags().GetInt(constants.ConfirmParamName)
+		if err != nil {
+			return err
+		}
+		if confirm != count {
+			return errors.Errorf("%d api clients match while --%s is %d, nothing was %s", count,
+				constants.ConfirmParamName, confirm, done)
+		}
+		return nil
+	}
+
+	expected := fmt.Sprintf("%s %d", confirmation, count)
+	fmt.Fprintf(cmd.ErrOrStderr(), "Type %q to %s: ", expected, change)
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return errors.Wrap(err, "Error reading the confirmation")
+	}
+	if strings.TrimSpace(answer) != expected {
+		return errors.Errorf("Changes were not confirmed, nothing was %s", done)
+	}
+	return nil
+}
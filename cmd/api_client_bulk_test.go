@@ -0,0 +1,240 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/bulk"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const bulkCsvForTests = `name,service,product,policies,tags,status
+ai-workload-1,Production,Enterprise,payments-policy;shared-policy,Workload:Inference;Region:Europe,
+ai-workload-2,Production,Enterprise,,Workload:Inference,Inactive
+payments-workload,Production,Enterprise,,,
+ai-workload-3,Production,Premium,,,
+ai-workload-1,Production,Enterprise,,,
+ai-workload-4,Production,Enterprise,missing-policy,,
+ai-workload-5,Production,Enterprise,,Workload,
+`
+
+const bulkYamlForTests = `- name: ai-workload-6
+  service: Production
+  product: Enterprise
+  policies:
+    - shared-policy
+  tags:
+    - Workload:Inference
+`
+
+func TestApiClientBulkCreateCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tenant := exportTenantForTests()
+	server := test.TenantMockServer(t, tenant)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "clients.csv")
+	assert.NoError(t, os.WriteFile(csvPath, []byte(bulkCsvForTests), 0600))
+	yamlPath := filepath.Join(dir, "clients.yaml")
+	assert.NoError(t, os.WriteFile(yamlPath, []byte(bulkYamlForTests), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.csv"), []byte("name,service,unknown\na,b,c\n"), 0600))
+
+	create := func(args ...string) error {
+		resetFlagsForTests(t, apiClientBulkCreateCmd)
+		defer resetFlagsForTests(t, apiClientBulkCreateCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.ApiClientCmd, constants.BulkCreateCmd}, args...))
+		return err
+	}
+
+	tt := []struct {
+		args        []string
+		description string
+	}{
+		{
+			args:        []string{},
+			description: "Test bulk create without input file",
+		},
+		{
+			args:        []string{"-f", filepath.Join(dir, "invalid.csv")},
+			description: "Test bulk create from a CSV file with an unknown column",
+		},
+		{
+			args:        []string{"-f", csvPath, "--concurrency", "0"},
+			description: "Test bulk create with an invalid concurrency",
+		},
+		{
+			args:        []string{"-f", csvPath, "--key-output", filepath.Join(dir, "keys.txt")},
+			description: "Test bulk create with a key output shared by every api client",
+		},
+	}
+	for _, tc := range tt {
+		assert.Error(t, create(tc.args...), tc.description)
+	}
+	assert.Len(t, tenant.ApiClients, 1)
+
+	// the api clients which can be created are created, the others are reported as failed
+	resultPath := filepath.Join(dir, "results.json")
+	keyOutput := "file:" + filepath.Join(dir, constants.KeyOutputNamePlaceholder+".key")
+	assert.ErrorContains(t, create("-f", csvPath, "--key-output", keyOutput, "-o", resultPath, "--concurrency", "3"),
+		"4 of 7 api clients failed")
+	assert.Len(t, tenant.ApiClients, 3)
+
+	resultBytes, err := os.ReadFile(resultPath)
+	assert.NoError(t, err)
+	var report models2.ApiClientBulk
+	assert.NoError(t, json.Unmarshal(resultBytes, &report))
+	assert.Equal(t, 3, report.Succeeded)
+	assert.Equal(t, 4, report.Failed)
+	actions := []string{constants.BulkActionCreated, constants.BulkActionCreated, constants.BulkActionExists, constants.BulkActionFailed,
+		constants.BulkActionFailed, constants.BulkActionFailed, constants.BulkActionFailed}
+	for i, result := range report.Results {
+		assert.Equal(t, i+1, result.Row)
+		assert.Equal(t, actions[i], result.Action, result.Name)
+	}
+	assert.Equal(t, tenant.ApiClients[0].ID, *report.Results[2].ApiClientId)
+	assert.Contains(t, report.Results[3].Error, "Premium")
+	assert.Contains(t, report.Results[4].Error, "already listed in row 1")
+	assert.Contains(t, report.Results[5].Error, "missing-policy")
+
+	created := tenant.ApiClients[1]
+	if created.Name != "ai-workload-1" {
+		created = tenant.ApiClients[2]
+	}
+	assert.Equal(t, *report.Results[0].ApiClientId, created.ID)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), created.Status)
+	assert.ElementsMatch(t, []uuid.UUID{tenant.Policies[0].PolicyId, tenant.Policies[1].PolicyId}, created.PolicyIds)
+	assert.Equal(t, []models.ApiClientTagValue{{Name: "Workload", Value: "Inference"}, {Name: "Region", Value: "Europe"}}, created.TagsValues)
+	keys, err := os.ReadFile(filepath.Join(dir, "ai-workload-1.key"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(keys), created.Keys[0])
+	assert.NotContains(t, string(resultBytes), created.Keys[0])
+
+	// submitting the file again creates nothing more, the result file is written as CSV
+	csvResultPath := filepath.Join(dir, "results.csv")
+	assert.Error(t, create("-f", csvPath, "-o", csvResultPath))
+	assert.Len(t, tenant.ApiClients, 3)
+	f, err := os.Open(csvResultPath)
+	assert.NoError(t, err)
+	records, err := csv.NewReader(f).ReadAll()
+	assert.NoError(t, f.Close())
+	assert.NoError(t, err)
+	assert.Len(t, records, 8)
+	assert.Equal(t, []string{"row", "name", "service_id", "api_client_id", "action", "key_output", "error", "request_id",
+		"trace_id"}, records[0])
+	assert.Equal(t, constants.BulkActionExists, records[1][4])
+
+	assert.NoError(t, create("-f", yamlPath, "--rate-limit", "0"))
+	assert.Len(t, tenant.ApiClients, 4)
+	assert.Equal(t, "ai-workload-6", tenant.ApiClients[3].Name)
+}
+
+func TestApiClientBulkCreateRateLimited(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tenant := exportTenantForTests()
+	handler := test.TenantMockServer(t, tenant).Config.Handler
+
+	// the first request of every api client is rejected by the rate limits of the server
+	var mutex sync.Mutex
+	rejected := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		key := r.Method + r.URL.Path
+		reject := r.Method == http.MethodPost && !rejected[key]
+		rejected[key] = true
+		mutex.Unlock()
+		if reject {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	inputPath := filepath.Join(t.TempDir(), "clients.yaml")
+	assert.NoError(t, os.WriteFile(inputPath, []byte(bulkYamlForTests), 0600))
+	resetFlagsForTests(t, apiClientBulkCreateCmd)
+	_, err := execute(t, tenantCmd, []string{constants.ApiClientCmd, constants.BulkCreateCmd, "-f", inputPath})
+	resetFlagsForTests(t, apiClientBulkCreateCmd)
+	assert.NoError(t, err)
+	assert.Len(t, tenant.ApiClients, 2)
+}
+
+func TestBulkRun(t *testing.T) {
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	done := make([]bool, 20)
+	bulk.Run(len(done), 3, func(i int) {
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+		time.Sleep(time.Millisecond)
+		mutex.Lock()
+		running--
+		done[i] = true
+		mutex.Unlock()
+	})
+	assert.LessOrEqual(t, maxRunning, 3)
+	for _, d := range done {
+		assert.True(t, d)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	client := &http.Client{Transport: bulk.RateLimitTransport(http.DefaultTransport, 50)}
+	start := time.Now()
+	bulk.Run(6, 3, func(i int) {
+		resp, err := client.Get(server.URL)
+		if assert.NoError(t, err) {
+			assert.NoError(t, resp.Body.Close())
+		}
+	})
+	// 6 requests at 50 requests per second are spread over at least 100ms
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	// the concurrent requests carry the request id provided, whatever the request id of the request sent
+	idServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(constants.HTTPHeaderKeyRequestId, r.Header.Get(constants.HTTPHeaderKeyRequestId))
+		w.Header().Set(constants.HTTPHeaderKeyTraceId, r.URL.Query().Get("trace"))
+	}))
+	defer idServer.Close()
+	client = &http.Client{Transport: bulk.RequestIdTransport(http.DefaultTransport, "bulk-request")}
+	traceIds := make([]string, 6)
+	bulk.Run(len(traceIds), 3, func(i int) {
+		itemClient := &http.Client{Transport: bulk.ResponseIdsTransport(client.Transport, func(requestId, traceId string) {
+			assert.Equal(t, "bulk-request", requestId)
+			traceIds[i] = traceId
+		})}
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?trace=%d", idServer.URL, i), nil)
+		assert.NoError(t, err)
+		req.Header.Set(constants.HTTPHeaderKeyRequestId, "shared-request")
+		resp, err := itemClient.Do(req)
+		if assert.NoError(t, err) {
+			assert.NoError(t, resp.Body.Close())
+		}
+	})
+	assert.Equal(t, []string{"0", "1", "2", "3", "4", "5"}, traceIds)
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/bulk"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+// apiClientBulkUpdateCmd represents the apiClient bulk-update command
+var apiClientBulkUpdateCmd = &cobra.Command{
+	Use:   constants.BulkUpdateCmd,
+	Short: "Set the status or tags of every api client matching a selector",
+	Long: `Set the status, or the values of tags, of every api client of the tenant matching the selector. The
+selector lists comma separated requirements, all of which need to match, in the key=value or key!=value format: name,
+service (id or name), product (id or name), policy (id), status, and tag, whose value is either a tag, matching the
+api clients with a value of the tag, or a tag and value pair, e.g. "tag=Workload:AI,status=Active". The matching api
+clients are listed first and nothing is changed until the update is confirmed by typing "update <number of api
+clients>", or with --confirm set to the number of api clients. Api clients are updated concurrently, the failure of
+one not stopping the others.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apiClient bulk-update called")
+		response, err := bulkUpdateApiClients(cmd)
+		utils.PrintRequestAndTraceId()
+		if response != "" {
+			fmt.Println("ApiClients: \n\n", response)
+		}
+		return err
+	},
+}
+
+func init() {
+	apiClientCmd.AddCommand(apiClientBulkUpdateCmd)
+
+	apiClientBulkUpdateCmd.Flags().String(constants.SelectorParamName, "", "Requirements the api clients to be updated "+
+		"match, e.g. tag=Workload:AI,service=Production")
+	apiClientBulkUpdateCmd.Flags().StringP(constants.ActivationStatus, "s", "", "Status the api clients are set to, "+
+		"should be one of \"Active\", \"Inactive\" or \"Cancelled\"")
+	apiClientBulkUpdateCmd.Flags().StringSliceP(constants.TagKeyAndValuesParamName, "v", []string{}, "List of the comma "+
+		"separated tag Id and value pairs in the following format:\n Workload:WorkloadAI,Workload:WorkloadEXE etc. The "+
+		"values replace the current values of the tags")
+	apiClientBulkUpdateCmd.Flags().Int(constants.ConfirmParamName, 0, "Number of api clients expected to be updated, "+
+		"confirming the update without prompting. Nothing is updated when another number of api clients matches")
+	apiClientBulkUpdateCmd.Flags().Bool(constants.DryRunParamName, false, "List the api clients matching the selector without updating them")
+	addBulkFlags(apiClientBulkUpdateCmd)
+	apiClientBulkUpdateCmd.MarkFlagRequired(constants.SelectorParamName)
+}
+
+func bulkUpdateApiClients(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client, concurrency, err := bulkHttpClient(cmd, configValues)
+	if err != nil {
+		return "", err
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	selectorString, err := cmd.Flags().GetString(constants.SelectorParamName)
+	if err != nil {
+		return "", err
+	}
+	selector, err := bulk.ParseSelector(selectorString)
+	if err != nil {
+		return "", err
+	}
+
+	status, err := cmd.Flags().GetString(constants.ActivationStatus)
+	if err != nil {
+		return "", err
+	}
+	if status != "" && status != constants.ApiClientStatusActive && status != constants.ApiClientStatusInactive &&
+		status != constants.ApiClientStatusCancelled {
+		return "", errors.Errorf("Activation status should be one of %s, %s or %s", constants.ApiClientStatusActive,
+			constants.ApiClientStatusInactive, constants.ApiClientStatusCancelled)
+	}
+	tagKeyValuesString, err := cmd.Flags().GetStringSlice(constants.TagKeyAndValuesParamName)
+	if err != nil {
+		return "", err
+	}
+	keys, values, err := parseTagValues(tagKeyValuesString)
+	if err != nil {
+		return "", err
+	}
+	if status == "" && len(keys) == 0 {
+		return "", errors.Errorf("Either --%s or --%s should be provided", constants.ActivationStatus,
+			constants.TagKeyAndValuesParamName)
+	}
+
+	dryRun, err := cmd.Flags().GetBool(constants.DryRunParamName)
+	if err != nil {
+		return "", err
+	}
+	outFile, err := cmd.Flags().GetString(constants.OutFileParamName)
+	if err != nil {
+		return "", err
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	results, err := selectApiClients(tmsClient, selector, concurrency)
+	if err != nil {
+		return "", err
+	}
+	if dryRun {
+		return reportBulkResults(&models2.ApiClientBulk{Results: results, DryRun: true}, "")
+	}
+
+	matched := 0
+	for _, result := range results {
+		if result.Action != constants.BulkActionFailed {
+			matched++
+		}
+	}
+	if matched > 0 {
+		services, err := tmsClient.GetServices()
+		if err != nil {
+			return "", errors.Wrap(err, "Error fetching the services of the tenant")
+		}
+		printBulkMatches(cmd.ErrOrStderr(), selectorString, constants.BulkActionUpdated, results, services)
+		change := "update these api clients"
+		if status != "" {
+			change = "set these api clients to " + status
+		}
+		if err = confirmBulkAction(cmd, constants.BulkUpdateConfirmation, matched, change, constants.BulkActionUpdated); err != nil {
+			return "", err
+		}
+	}
+
+	setTags := setTagValues(keys, values)
+	edit := func(apiClient *models.ApiClientDetail) (bool, error) {
+		changed := false
+		if status != "" && string(apiClient.Status) != status {
+			apiClient.Status = models.ApiClientStatus(status)
+			changed = true
+		}
+		if len(keys) > 0 {
+			tagsChanged, err := setTags(apiClient)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || tagsChanged
+		}
+		return changed, nil
+	}
+
+	bulk.Run(len(results), concurrency, func(i int) {
+		result := &results[i]
+		if result.Action == constants.BulkActionFailed {
+			return
+		}
+		itemTmsClient := tms.NewTmsClient(bulkItemClient(client, result), tmsUrl, apiKey)
+		edited, err := editApiClient(itemTmsClient, *result.ServiceId, *result.ApiClientId, edit)
+		if err != nil {
+			log.WithError(err).Errorf("Error updating api client %s", result.ApiClientId)
+			result.Action = constants.BulkActionFailed
+			result.Error = err.Error()
+			return
+		}
+		result.Action = constants.BulkActionUnchanged
+		if edited.Changed {
+			result.Action = constants.BulkActionUpdated
+		}
+	})
+
+	return reportBulkResults(&models2.ApiClientBulk{Results: results}, outFile)
+}
+
+// selectApiClients returns the api clients of the tenant matching the selector, as results of a bulk operation. The
+// detail of the api clients is fetched concurrently when the selector matches tags or policies, an api client whose
+// detail cannot be fetched being reported as failed.
+func selectApiClients(tmsClient tms.TmsClient, selector bulk.Selector, concurrency int) ([]models2.ApiClientBulkResult, error) {
+	services, err := tmsClient.GetServices()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the services of the tenant")
+	}
+
+	var candidates []models.ApiClientDetail
+	var candidateServices []*models.Service
+	for i := range services {
+		apiClients, err := tmsClient.GetApiClient(services[i].ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error fetching the api clients of service %s", services[i].ID)
+		}
+		for _, apiClient := range apiClients {
+			candidates = append(candidates, models.ApiClientDetail{ID: apiClient.ID, ServiceId: services[i].ID,
+				ProductId: apiClient.ProductId, ProductName: apiClient.ProductName, Name: apiClient.Name, Status: apiClient.Status})
+			candidateServices = append(candidateServices, &services[i])
+		}
+	}
+
+	errs := make([]error, len(candidates))
+	if selector.NeedsDetail() {
+		bulk.Run(len(candidates), concurrency, func(i int) {
+			detail, err := tmsClient.RetrieveApiClient(candidates[i].ServiceId, candidates[i].ID)
+			if err != nil {
+				errs[i] = errors.Wrapf(err, "Error fetching api client %s", candidates[i].ID)
+				return
+			}
+			detail.ServiceId = candidates[i].ServiceId
+			candidates[i] = *detail
+		})
+	}
+
+	results := []models2.ApiClientBulkResult{}
+	for i := range candidates {
+		candidate := &candidates[i]
+		result := models2.ApiClientBulkResult{Name: candidate.Name, ServiceId: &candidate.ServiceId,
+			ApiClientId: &candidate.ID, Action: constants.BulkActionMatched}
+		if errs[i] != nil {
+			result.Action = constants.BulkActionFailed
+			result.Error = errs[i].Error()
+		} else if !selector.Matches(candidate, candidateServices[i]) {
+			continue
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
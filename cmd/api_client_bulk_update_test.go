@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/bulk"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApiClientBulkUpdateCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tenant := exportTenantForTests()
+	service := tenant.Services[0]
+	for _, name := range []string{"inference-1", "inference-2", test.FailingApiClientName} {
+		tenant.ApiClients = append(tenant.ApiClients, models.ApiClientDetail{ID: uuid.New(), ServiceId: service.ID,
+			ProductId: tenant.Products[0].ID, Status: constants.ApiClientStatusActive, Name: name,
+			TagsValues: []models.ApiClientTagValue{{Name: "Workload", Value: "Inference"}, {Name: "Region", Value: "Europe"}}})
+	}
+	server := test.TenantMockServer(t, tenant)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	update := func(args ...string) error {
+		resetFlagsForTests(t, apiClientBulkUpdateCmd)
+		defer resetFlagsForTests(t, apiClientBulkUpdateCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.ApiClientCmd, constants.BulkUpdateCmd}, args...))
+		return err
+	}
+
+	tt := []struct {
+		args        []string
+		description string
+	}{
+		{
+			args:        []string{"--status", constants.ApiClientStatusInactive},
+			description: "Test bulk update without selector",
+		},
+		{
+			args:        []string{"--selector", "tag=Workload:Inference"},
+			description: "Test bulk update without status nor tags",
+		},
+		{
+			args:        []string{"--selector", "owner=payments", "--status", constants.ApiClientStatusInactive},
+			description: "Test bulk update with an unknown selector key",
+		},
+		{
+			args:        []string{"--selector", "tag=Workload:Inference", "--status", "Suspended"},
+			description: "Test bulk update with an invalid status",
+		},
+	}
+	for _, tc := range tt {
+		assert.Error(t, update(tc.args...), tc.description)
+	}
+
+	// nothing is changed on a dry run
+	assert.NoError(t, update("--selector", "tag=Workload:Inference,name!="+test.FailingApiClientName, "--status",
+		constants.ApiClientStatusInactive, "--dry-run"))
+	for _, apiClient := range tenant.ApiClients {
+		assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), apiClient.Status)
+	}
+
+	// nothing is changed unless the number of api clients matching is confirmed
+	assert.ErrorContains(t, update("--selector", "tag=Workload:Inference", "--status", constants.ApiClientStatusCancelled,
+		"--confirm", "2"), "3 api clients match while --confirm is 2")
+	tenantCmd.SetIn(strings.NewReader("update 2\n"))
+	assert.ErrorContains(t, update("--selector", "tag=Workload:Inference", "--status", constants.ApiClientStatusCancelled),
+		"Changes were not confirmed")
+	tenantCmd.SetIn(strings.NewReader("y\n"))
+	assert.ErrorContains(t, update("--selector", "tag=Workload:Inference", "--status", constants.ApiClientStatusCancelled),
+		"Changes were not confirmed")
+	for _, apiClient := range tenant.ApiClients {
+		assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), apiClient.Status)
+	}
+
+	// the api clients which can be updated are updated, the others are reported as failed
+	tenantCmd.SetIn(strings.NewReader("update 3\n"))
+	resultPath := filepath.Join(t.TempDir(), "results.json")
+	assert.ErrorContains(t, update("--selector", "tag=Workload:Inference,service=Production", "--status",
+		constants.ApiClientStatusInactive, "-v", "Region:Asia", "-o", resultPath, "-q", "bulk-update"), "1 of 3 api clients failed")
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), tenant.ApiClients[0].Status)
+	for _, apiClient := range tenant.ApiClients[1:3] {
+		assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusInactive), apiClient.Status)
+		assert.Equal(t, []models.ApiClientTagValue{{Name: "Workload", Value: "Inference"}, {Name: "Region", Value: "Asia"}},
+			apiClient.TagsValues)
+	}
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), tenant.ApiClients[3].Status)
+
+	resultBytes, err := os.ReadFile(resultPath)
+	assert.NoError(t, err)
+	var report models2.ApiClientBulk
+	assert.NoError(t, json.Unmarshal(resultBytes, &report))
+	assert.Equal(t, 2, report.Succeeded)
+	assert.Equal(t, 1, report.Failed)
+	traceIds := map[string]bool{}
+	for _, result := range report.Results {
+		if result.Name == test.FailingApiClientName {
+			assert.Equal(t, constants.BulkActionFailed, result.Action)
+			assert.NotEmpty(t, result.Error)
+		} else {
+			assert.Equal(t, constants.BulkActionUpdated, result.Action)
+		}
+		// every api client is reported with the ids of its own requests
+		assert.Equal(t, "bulk-update", result.RequestId)
+		assert.NotEmpty(t, result.TraceId)
+		traceIds[result.TraceId] = true
+	}
+	assert.Len(t, traceIds, len(report.Results))
+
+	// the api clients already matching the update are left unchanged
+	assert.NoError(t, update("--selector", "status=Inactive", "--status", constants.ApiClientStatusInactive,
+		"--confirm", "2"))
+}
+
+func TestApiClientSelector(t *testing.T) {
+	service := &models.Service{ID: uuid.New(), Name: "Production"}
+	policyId := uuid.New()
+	apiClient := &models.ApiClientDetail{Name: "inference-1", Status: constants.ApiClientStatusActive,
+		ProductId: uuid.New(), ProductName: "Enterprise", PolicyIds: []uuid.UUID{policyId},
+		TagsValues: []models.ApiClientTagValue{{Name: "Workload", Value: "Inference"}}}
+
+	tt := []struct {
+		selector    string
+		matches     bool
+		wantErr     bool
+		description string
+	}{
+		{selector: "tag=Workload", matches: true, description: "Test select the api clients with a tag"},
+		{selector: "tag=Workload:Inference,status=Active", matches: true, description: "Test select by tag value and status"},
+		{selector: "tag=Workload:Payments", matches: false, description: "Test select by another tag value"},
+		{selector: "tag!=Region", matches: true, description: "Test select the api clients without a tag"},
+		{selector: "service=" + service.ID.String() + ",name=inference-1", matches: true, description: "Test select by service id and name"},
+		{selector: "service!=Production", matches: false, description: "Test select the api clients of other services"},
+		{selector: "product=Enterprise,policy=" + policyId.String(), matches: true, description: "Test select by product and policy"},
+		{selector: "policy!=" + policyId.String(), matches: false, description: "Test select the api clients without a policy"},
+		{selector: "policy=shared-policy", wantErr: true, description: "Test select by policy name"},
+		{selector: "status=Paused", wantErr: true, description: "Test select by an invalid status"},
+		{selector: "tag", wantErr: true, description: "Test select without value"},
+		{selector: "tag=Workload,", wantErr: true, description: "Test select with an empty requirement"},
+	}
+	for _, tc := range tt {
+		selector, err := bulk.ParseSelector(tc.selector)
+		if tc.wantErr {
+			assert.Error(t, err, tc.description)
+			continue
+		}
+		assert.NoError(t, err, tc.description)
+		assert.Equal(t, tc.matches, selector.Matches(apiClient, service), tc.description)
+	}
+}
@@ -0,0 +1,452 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// apiClientCloneCmd represents the apiClient clone command
+var apiClientCloneCmd = &cobra.Command{
+	Use:   constants.CloneCmd,
+	Short: "Create a copy of an api client, with the same product, policies and tags, in another service or tenant",
+	Long: `Create a copy of an api client in the same service, in another service or in the tenant of another profile.
+The policies and tags of the api client are mapped by name in the destination tenant, and can be copied when missing.
+What could not be mapped is reported in the output.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apiClient clone called")
+		response, err := cloneApiClient(cmd)
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("ApiClient clone: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	apiClientCmd.AddCommand(apiClientCloneCmd)
+
+	apiClientCloneCmd.Flags().StringP(constants.ServiceIdParamName, "r", "", "Id of the Trust Authority service of the api client to be cloned")
+	apiClientCloneCmd.Flags().StringP(constants.ApiClientIdParamName, "c", "", "Id of the api client to be cloned")
+	apiClientCloneCmd.Flags().String(constants.ToServiceParamName, "", "Id of the service the clone is created in, "+
+		"the service of the same name in the destination tenant otherwise")
+	apiClientCloneCmd.Flags().String(constants.ToProfileParamName, "", "Profile of the tenant the clone is created in, "+
+		"configured in ~/.config/trustauthorityctl/profiles/<profile>.yaml, the current tenant otherwise")
+	apiClientCloneCmd.Flags().StringP(constants.ApiClientNameParamName, "n", "", "Name of the clone, the name of the cloned api client otherwise")
+	apiClientCloneCmd.Flags().Bool(constants.CopyMissingParamName, false, "Copy the policies and tags which do not exist in the destination tenant")
+	apiClientCloneCmd.Flags().Bool(constants.DryRunParamName, false, "Report how the api client would be cloned without creating anything")
+	apiClientCloneCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	addKeyOutputFlags(apiClientCloneCmd)
+	apiClientCloneCmd.MarkFlagRequired(constants.ServiceIdParamName)
+	apiClientCloneCmd.MarkFlagRequired(constants.ApiClientIdParamName)
+}
+
+func cloneApiClient(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	serviceIdString, err := cmd.Flags().GetString(constants.ServiceIdParamName)
+	if err != nil {
+		return "", err
+	}
+	serviceId, err := uuid.Parse(serviceIdString)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid service id provided")
+	}
+
+	apiClientIdString, err := cmd.Flags().GetString(constants.ApiClientIdParamName)
+	if err != nil {
+		return "", err
+	}
+	apiClientId, err := uuid.Parse(apiClientIdString)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid api client Id provided")
+	}
+
+	toServiceString, err := cmd.Flags().GetString(constants.ToServiceParamName)
+	if err != nil {
+		return "", err
+	}
+	var toServiceId uuid.UUID
+	if toServiceString != "" {
+		if toServiceId, err = uuid.Parse(toServiceString); err != nil {
+			return "", errors.Wrap(err, "Invalid destination service id provided")
+		}
+	}
+
+	name, err := cmd.Flags().GetString(constants.ApiClientNameParamName)
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		if err = validation.ValidateApiClientName(name); err != nil {
+			return "", err
+		}
+	}
+
+	copyMissing, err := cmd.Flags().GetBool(constants.CopyMissingParamName)
+	if err != nil {
+		return "", err
+	}
+	dryRun, err := cmd.Flags().GetBool(constants.DryRunParamName)
+	if err != nil {
+		return "", err
+	}
+
+	keyOutput, err := apiClientKeyOutput(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	pmsClient := pms.NewPmsClient(client, pmsUrl, apiKey)
+
+	// the clone is created in the current tenant unless another profile is provided
+	profile, err := cmd.Flags().GetString(constants.ToProfileParamName)
+	if err != nil {
+		return "", err
+	}
+	destTmsClient, destPmsClient := tmsClient, pmsClient
+	if profile != "" {
+		if destTmsClient, destPmsClient, err = profileClients(profile); err != nil {
+			return "", err
+		}
+	}
+	sameTenant := profile == ""
+
+	source, err := tmsClient.RetrieveApiClient(serviceId, apiClientId)
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the api client to be cloned")
+	}
+	sourceService, err := tmsClient.RetrieveService(serviceId)
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the service of the api client to be cloned")
+	}
+	destService, err := cloneDestinationService(destTmsClient, sourceService, toServiceString != "", toServiceId, sameTenant)
+	if err != nil {
+		return "", err
+	}
+
+	if name == "" {
+		name = source.Name
+	}
+	destApiClients, err := destTmsClient.GetApiClient(destService.ID)
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the api clients of the destination service")
+	}
+	for _, apiClient := range destApiClients {
+		if apiClient.Name == name {
+			return "", errors.Errorf("An api client named %q already exists in service %s, another name should be provided "+
+				"with --%s", name, destService.ID, constants.ApiClientNameParamName)
+		}
+	}
+
+	productId, err := cloneDestinationProduct(destTmsClient, source, sourceService, destService, sameTenant)
+	if err != nil {
+		return "", err
+	}
+
+	status := constants.ApiClientStatusActive
+	if source.Status != constants.ApiClientStatusActive {
+		status = constants.ApiClientStatusInactive
+	}
+	clone := &models2.ApiClientClone{
+		SourceServiceId:   serviceId,
+		SourceApiClientId: apiClientId,
+		Profile:           profile,
+		ServiceId:         destService.ID,
+		Name:              name,
+		ProductId:         productId,
+		Status:            status,
+		DryRun:            dryRun,
+	}
+
+	// the policies and tags copied with --copy-missing are deleted when the clone cannot be created, so that a failed
+	// clone leaves the destination tenant unchanged
+	var copiedTags []*models.Tag
+	rollback := func(cause error) error {
+		var left []string
+		for _, policy := range clone.Policies {
+			if policy.Copied && policy.PolicyId != nil {
+				if err := destPmsClient.DeletePolicy(*policy.PolicyId); err != nil {
+					left = append(left, fmt.Sprintf("policy %s %s (%s)", policy.PolicyName, policy.PolicyId, err.Error()))
+				}
+			}
+		}
+		for _, tag := range copiedTags {
+			if err := destTmsClient.DeleteTenantTag(*tag.ID); err != nil {
+				left = append(left, fmt.Sprintf("tag %s %s (%s)", tag.Name, tag.ID, err.Error()))
+			}
+		}
+		if len(left) > 0 {
+			return errors.Wrapf(cause, "Clone failed and the copies could not all be deleted, they should be deleted "+
+				"manually: %s", strings.Join(left, ", "))
+		}
+		return cause
+	}
+
+	policyIds, err := cloneApiClientPolicies(pmsClient, destPmsClient, source.PolicyIds, sameTenant, copyMissing, dryRun, clone)
+	if err != nil {
+		return "", rollback(err)
+	}
+	if copiedTags, err = cloneApiClientTags(destTmsClient, source.TagsValues, sameTenant, copyMissing, dryRun, clone); err != nil {
+		return "", rollback(err)
+	}
+	for _, unmapped := range clone.Unmapped {
+		log.Warnf("Unable to map %s in the destination tenant, the clone is created without it", unmapped)
+	}
+
+	if !dryRun {
+		response, err := destTmsClient.CreateApiClient(&models.CreateApiClient{
+			ProductId:    productId,
+			ServiceId:    destService.ID,
+			PolicyIds:    policyIds,
+			TagIdsValues: apiClientTagIdValues(clone.Tags),
+			Name:         name,
+			Status:       models.ApiClientStatus(status),
+		})
+		if err != nil {
+			return "", rollback(errors.Wrap(err, "Error creating the clone of the api client"))
+		}
+		clone.ApiClientId = &response.ID
+		if err = deliverApiClientKeys(cmd, keyOutput, response); err != nil {
+			return "", errors.Wrapf(err, "Api client %s was created but its attestation API keys could not be written, "+
+				"they can be fetched again with \"%s\"", response.ID, fetchApiClientKeysCommand(destService.ID, response.ID))
+		}
+		clone.Keys = response.Keys
+		if keyOutput != nil {
+			clone.KeyOutput = keyOutput.String()
+		}
+	}
+
+	responseBytes, err := json.MarshalIndent(clone, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes) + deprecatedPolicyWarnings(policyIds), nil
+}
+
+// cloneDestinationService returns the service the clone is created in: the one provided, the service of the cloned api
+// client in the same tenant or the service of the same name in another tenant
+func cloneDestinationService(destTmsClient tms.TmsClient, sourceService *models.ServiceDetail, toServiceProvided bool,
+	toServiceId uuid.UUID, sameTenant bool) (*models.ServiceDetail, error) {
+	if toServiceProvided {
+		service, err := destTmsClient.RetrieveService(toServiceId)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error fetching the destination service")
+		}
+		return service, nil
+	}
+	if sameTenant {
+		return sourceService, nil
+	}
+
+	services, err := destTmsClient.GetServices()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the services of the destination tenant")
+	}
+	var matches []models.Service
+	for _, service := range services {
+		if service.Name == sourceService.Name {
+			matches = append(matches, service)
+		}
+	}
+	if len(matches) != 1 {
+		return nil, errors.Errorf("%d services of the destination tenant are named %q, the destination service should be "+
+			"provided with --%s", len(matches), sourceService.Name, constants.ToServiceParamName)
+	}
+	return destTmsClient.RetrieveService(matches[0].ID)
+}
+
+// cloneDestinationProduct maps the product of the cloned api client to the product of the same id, or else name, of the
+// service offer of the destination service
+func cloneDestinationProduct(destTmsClient tms.TmsClient, source *models.ApiClientDetail, sourceService,
+	destService *models.ServiceDetail, sameTenant bool) (uuid.UUID, error) {
+	if sameTenant && sourceService.ServiceOfferId == destService.ServiceOfferId {
+		return source.ProductId, nil
+	}
+
+	products, err := destTmsClient.GetProducts(destService.ServiceOfferId)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, "Error fetching the products of the destination service")
+	}
+	for _, product := range products {
+		if product.ID == source.ProductId {
+			return product.ID, nil
+		}
+	}
+	for _, product := range products {
+		if source.ProductName != "" && product.Name == source.ProductName {
+			return product.ID, nil
+		}
+	}
+	return uuid.Nil, errors.Errorf("Product %s (%s) of the api client is not available to the destination service %s",
+		source.ProductName, source.ProductId, destService.ID)
+}
+
+// cloneApiClientPolicies maps the policies of the cloned api client to the policies of the same name in the
+// destination tenant, copying the missing ones when requested
+func cloneApiClientPolicies(pmsClient, destPmsClient pms.PmsClient, sourcePolicyIds []uuid.UUID, sameTenant, copyMissing,
+	dryRun bool, clone *models2.ApiClientClone) ([]uuid.UUID, error) {
+	clone.Policies = []models2.ClonedPolicy{}
+	if len(sourcePolicyIds) == 0 {
+		return nil, nil
+	}
+
+	sourcePolicies, err := pmsClient.SearchPolicy()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the policies of the tenant")
+	}
+	sourceById := map[uuid.UUID]models.PolicyResponse{}
+	for _, policy := range sourcePolicies {
+		sourceById[policy.PolicyId] = policy
+	}
+
+	destByName := map[string]uuid.UUID{}
+	if !sameTenant {
+		destPolicies, err := destPmsClient.SearchPolicy()
+		if err != nil {
+			return nil, errors.Wrap(err, "Error fetching the policies of the destination tenant")
+		}
+		for _, policy := range destPolicies {
+			destByName[policy.PolicyName] = policy.PolicyId
+		}
+	}
+
+	var policyIds []uuid.UUID
+	for _, sourcePolicyId := range sourcePolicyIds {
+		cloned := models2.ClonedPolicy{SourcePolicyId: sourcePolicyId}
+		sourcePolicy, ok := sourceById[sourcePolicyId]
+		if ok {
+			cloned.PolicyName = sourcePolicy.PolicyName
+		}
+		switch {
+		case sameTenant:
+			policyId := sourcePolicyId
+			cloned.PolicyId = &policyId
+		case !ok:
+			clone.Unmapped = append(clone.Unmapped, fmt.Sprintf("policy %s, which no longer exists", sourcePolicyId))
+		default:
+			if policyId, found := destByName[sourcePolicy.PolicyName]; found {
+				cloned.PolicyId = &policyId
+			} else if copyMissing {
+				cloned.Copied = true
+				if !dryRun {
+					copied, err := copyPolicy(destPmsClient, &sourcePolicy)
+					if err != nil {
+						return nil, err
+					}
+					cloned.PolicyId = &copied.PolicyId
+				}
+			} else {
+				clone.Unmapped = append(clone.Unmapped, fmt.Sprintf("policy %s (%s)", sourcePolicy.PolicyName, sourcePolicyId))
+			}
+		}
+		if cloned.PolicyId != nil {
+			policyIds = append(policyIds, *cloned.PolicyId)
+		}
+		clone.Policies = append(clone.Policies, cloned)
+	}
+	return policyIds, nil
+}
+
+// copyPolicy creates the policy in the destination tenant, a policy signed by the tenant is copied along with its
+// signature
+func copyPolicy(destPmsClient pms.PmsClient, policy *models.PolicyResponse) (*models.PolicyResponse, error) {
+	commonPolicy := policy.CommonPolicy
+	commonPolicy.PolicyId = uuid.Nil
+	if policy.SignedByTenant && policy.PolicyJWT != "" {
+		commonPolicy.Policy = policy.PolicyJWT
+	}
+	copied, err := destPmsClient.CreatePolicy(&models.PolicyRequest{CommonPolicy: commonPolicy})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error copying policy %s to the destination tenant", policy.PolicyName)
+	}
+	return copied, nil
+}
+
+// cloneApiClientTags keeps the tags of the cloned api client which exist in the destination tenant, creating the
+// missing ones when requested. The tags created are returned, even when it fails, so that they can be deleted again.
+func cloneApiClientTags(destTmsClient tms.TmsClient, sourceTags []models.ApiClientTagValue, sameTenant, copyMissing, dryRun bool,
+	clone *models2.ApiClientClone) ([]*models.Tag, error) {
+	clone.Tags = []models.ApiClientTagValue{}
+	if sameTenant {
+		clone.Tags = append(clone.Tags, sourceTags...)
+		return nil, nil
+	}
+	if len(sourceTags) == 0 {
+		return nil, nil
+	}
+
+	destTags, err := destTmsClient.GetTenantTags()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the tags of the destination tenant")
+	}
+	tagNames := map[string]bool{}
+	for _, tag := range destTags.Tags {
+		tagNames[tag.Name] = true
+	}
+
+	var created []*models.Tag
+	for _, tag := range sourceTags {
+		if !tagNames[tag.Name] {
+			if !copyMissing {
+				clone.Unmapped = append(clone.Unmapped, fmt.Sprintf("tag %s:%s", tag.Name, tag.Value))
+				continue
+			}
+			if !dryRun {
+				createdTag, err := destTmsClient.CreateTenantTag(&models.TagCreate{Name: tag.Name})
+				if err != nil {
+					return created, errors.Wrapf(err, "Error copying tag %s to the destination tenant", tag.Name)
+				}
+				if createdTag.ID != nil {
+					created = append(created, createdTag)
+				}
+			}
+			tagNames[tag.Name] = true
+		}
+		clone.Tags = append(clone.Tags, tag)
+	}
+	return created, nil
+}
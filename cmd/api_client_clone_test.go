@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupProfileForTests writes the configuration of the profile, pointing at the server. The server should be a TLS
+// server, whose certificate is trusted for the duration of the test.
+func setupProfileForTests(t *testing.T, profile string, server *httptest.Server) {
+	userHomeDir, err := os.UserHomeDir()
+	assert.NoError(t, err)
+	profileDir := filepath.Join(userHomeDir, constants.ProfileConfigDir)
+	assert.NoError(t, os.MkdirAll(profileDir, 0750))
+	profileBytes, err := yaml.Marshal(&config.Configuration{TrustAuthorityBaseUrl: server.URL,
+		TrustAuthorityApiKey: "dGVzdC1hcGkta2V5LWZvci10aGUtc3RhZ2luZy1wcm9maWxl", HTTPClientTimeout: 10})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(profileDir, profile+".yaml"), profileBytes, 0600))
+
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	t.Cleanup(func() {
+		http.DefaultTransport = defaultTransport
+	})
+}
+
+func TestApiClientCloneCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	serviceOfferId := uuid.New()
+	workloadTagId, regionTagId, destWorkloadTagId := uuid.New(), uuid.New(), uuid.New()
+	source := &test.Tenant{
+		Services:      []models.ServiceDetail{{ID: uuid.New(), ServiceOfferId: serviceOfferId, Name: "Production", Active: true}},
+		ServiceOffers: []models.ServiceOffer{{ID: serviceOfferId, Name: "TDX Attestation"}},
+		Products:      []models.Product{{ID: uuid.New(), ServiceOfferId: serviceOfferId, Name: "Enterprise"}},
+		Policies: []models.PolicyResponse{
+			{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "payments-policy", Policy: "default allow = true",
+				PolicyType: constants.DefaultPolicyType, ServiceOfferId: serviceOfferId, AttestationType: "TDX"}},
+			{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "shared-policy", Policy: "default allow = false"}},
+		},
+		Tags: []models.Tag{{ID: &workloadTagId, Name: "Workload"}, {ID: &regionTagId, Name: "Region"}},
+	}
+	serviceId := source.Services[0].ID
+	source.ApiClients = []models.ApiClientDetail{
+		{ID: uuid.New(), ServiceId: serviceId, ProductId: source.Products[0].ID, ProductName: "Enterprise",
+			Status: constants.ApiClientStatusActive, Name: "payments-workload",
+			PolicyIds:  []uuid.UUID{source.Policies[0].PolicyId, source.Policies[1].PolicyId, uuid.New()},
+			TagsValues: []models.ApiClientTagValue{{Name: "Workload", Value: "Payments"}, {Name: "Region", Value: "Europe"}}},
+	}
+	apiClientId := source.ApiClients[0].ID
+	destination := &test.Tenant{
+		Services: []models.ServiceDetail{{ID: uuid.New(), ServiceOfferId: serviceOfferId, Name: "Staging", Active: true},
+			{ID: uuid.New(), ServiceOfferId: serviceOfferId, Name: "Production", Active: true}},
+		Products: []models.Product{{ID: uuid.New(), ServiceOfferId: serviceOfferId, Name: "Enterprise"}},
+		Policies: []models.PolicyResponse{{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "shared-policy"}}},
+		Tags:     []models.Tag{{ID: &destWorkloadTagId, Name: "Workload"}},
+	}
+
+	sourceServer := test.TenantMockServer(t, source)
+	defer sourceServer.Close()
+	useServerForTests(t, sourceServer.URL)
+	destinationServer := httptest.NewUnstartedServer(test.TenantMockServer(t, destination).Config.Handler)
+	destinationServer.StartTLS()
+	defer destinationServer.Close()
+	setupProfileForTests(t, "staging", destinationServer)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{"--to-profile", "staging", "--dry-run"},
+			wantErr:     false,
+			description: "Test clone an api client to another tenant without creating it",
+		},
+		{
+			args:        []string{"--to-profile", "staging", "--copy-missing", "--key-output", filepath.Join(t.TempDir(), "clone.key")},
+			wantErr:     false,
+			description: "Test clone an api client to another tenant copying the missing policies and tags",
+		},
+		{
+			args:        []string{"--to-profile", "staging"},
+			wantErr:     true,
+			description: "Test clone an api client to a service which has an api client of the same name",
+		},
+		{
+			args:        []string{"--to-profile", "staging", "--to-service", destination.Services[0].ID.String(), "-n", "payments-staging"},
+			wantErr:     false,
+			description: "Test clone an api client to a service of another tenant",
+		},
+		{
+			args:        []string{},
+			wantErr:     true,
+			description: "Test clone an api client to its own service without another name",
+		},
+		{
+			args:        []string{"-n", "payments-workload-copy"},
+			wantErr:     false,
+			description: "Test clone an api client to its own service",
+		},
+		{
+			args:        []string{"--to-profile", "unknown"},
+			wantErr:     true,
+			description: "Test clone an api client to an unknown profile",
+		},
+		{
+			args:        []string{"--to-service", "invalid-id"},
+			wantErr:     true,
+			description: "Test clone an api client to an invalid service id",
+		},
+		{
+			args:        []string{"--to-service", uuid.NewString(), "-n", "payments-missing"},
+			wantErr:     true,
+			description: "Test clone an api client to a missing service",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, apiClientCloneCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.ApiClientCmd, constants.CloneCmd, "-r", serviceId.String(),
+			"-c", apiClientId.String()}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+
+	// the missing policy and tag are copied and the other ones mapped by name
+	if assert.Len(t, destination.ApiClients, 2) {
+		clone := destination.ApiClients[0]
+		assert.Equal(t, "payments-workload", clone.Name)
+		assert.Equal(t, destination.Services[1].ID, clone.ServiceId)
+		assert.Equal(t, destination.Products[0].ID, clone.ProductId)
+		if assert.Len(t, destination.Policies, 2) {
+			assert.Equal(t, "payments-policy", destination.Policies[1].PolicyName)
+			assert.Equal(t, "default allow = true", destination.Policies[1].Policy)
+			assert.Equal(t, []uuid.UUID{destination.Policies[1].PolicyId, destination.Policies[0].PolicyId}, clone.PolicyIds)
+		}
+		assert.Equal(t, source.ApiClients[0].TagsValues, clone.TagsValues)
+		assert.Len(t, destination.Tags, 2)
+		assert.Equal(t, destination.Services[0].ID, destination.ApiClients[1].ServiceId)
+	}
+	if assert.Len(t, source.ApiClients, 2) {
+		assert.Equal(t, "payments-workload-copy", source.ApiClients[1].Name)
+		assert.Equal(t, source.ApiClients[0].PolicyIds, source.ApiClients[1].PolicyIds)
+		assert.Equal(t, source.ApiClients[0].TagsValues, source.ApiClients[1].TagsValues)
+	}
+
+	// what cannot be mapped is reported
+	resetFlagsForTests(t, apiClientCloneCmd)
+	defer resetFlagsForTests(t, apiClientCloneCmd)
+	for name, value := range map[string]string{
+		constants.ServiceIdParamName:     serviceId.String(),
+		constants.ApiClientIdParamName:   apiClientId.String(),
+		constants.ToProfileParamName:     "staging",
+		constants.ApiClientNameParamName: "payments-unmapped",
+	} {
+		assert.NoError(t, apiClientCloneCmd.Flags().Set(name, value))
+	}
+	destination.Tags = destination.Tags[:1]
+	response, err := cloneApiClient(apiClientCloneCmd)
+	assert.NoError(t, err)
+	var clone models2.ApiClientClone
+	assert.NoError(t, json.Unmarshal([]byte(response), &clone))
+	assert.Len(t, clone.Unmapped, 2)
+	assert.Contains(t, clone.Unmapped, "tag Region:Europe")
+	assert.Equal(t, []models.ApiClientTagValue{{Name: "Workload", Value: "Payments"}}, clone.Tags)
+	if assert.Len(t, clone.Policies, 3) {
+		assert.Equal(t, destination.Policies[1].PolicyId, *clone.Policies[0].PolicyId)
+		assert.Nil(t, clone.Policies[2].PolicyId)
+	}
+	assert.Len(t, clone.Keys, 2)
+
+	// the policies and tags copied are deleted when the clone cannot be created
+	destination.Policies = destination.Policies[:1]
+	assert.NoError(t, apiClientCloneCmd.Flags().Set(constants.ApiClientNameParamName, test.FailingApiClientName))
+	assert.NoError(t, apiClientCloneCmd.Flags().Set(constants.CopyMissingParamName, "true"))
+	_, err = cloneApiClient(apiClientCloneCmd)
+	assert.ErrorContains(t, err, "Error creating the clone of the api client")
+	assert.Len(t, destination.Policies, 1)
+	assert.Len(t, destination.Tags, 1)
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// apiClientEdit applies an incremental edit to the api client and tells whether the api client was changed. It is
+// applied again to the latest state of the api client when the api client changed while being edited.
+type apiClientEdit func(apiClient *models.ApiClientDetail) (bool, error)
+
+// addApiClientEditFlags adds the flags selecting the api client to be edited
+func addApiClientEditFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP(constants.ServiceIdParamName, "r", "", "Id of the Trust Authority service of the api client")
+	cmd.Flags().StringP(constants.ApiClientIdParamName, "c", "", "Id of the api client to be edited")
+	cmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	cmd.MarkFlagRequired(constants.ServiceIdParamName)
+	cmd.MarkFlagRequired(constants.ApiClientIdParamName)
+}
+
+// runApiClientEdit applies the edit to the api client of the command flags
+func runApiClientEdit(cmd *cobra.Command, edit apiClientEdit) (*models2.ApiClientEdit, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return nil, err
+	}
+
+	serviceIdString, err := cmd.Flags().GetString(constants.ServiceIdParamName)
+	if err != nil {
+		return nil, err
+	}
+	serviceId, err := uuid.Parse(serviceIdString)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid service id provided")
+	}
+
+	apiClientIdString, err := cmd.Flags().GetString(constants.ApiClientIdParamName)
+	if err != nil {
+		return nil, err
+	}
+	apiClientId, err := uuid.Parse(apiClientIdString)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid api client Id provided")
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	return editApiClient(tmsClient, serviceId, apiClientId, edit)
+}
+
+// editApiClient reads the api client, applies the edit and sends the whole api client back, as Trust Authority only
+// accepts full updates. Trust Authority does not version api clients, so the api client is read again right before
+// the update and the edit is applied afresh when another change was made in the meantime, instead of overwriting it.
+func editApiClient(tmsClient tms.TmsClient, serviceId, apiClientId uuid.UUID, edit apiClientEdit) (*models2.ApiClientEdit, error) {
+	apiClient, err := tmsClient.RetrieveApiClient(serviceId, apiClientId)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the api client to be edited")
+	}
+
+	for attempt := 1; attempt <= constants.MaxApiClientEditAttempts; attempt++ {
+		edited := copyApiClient(apiClient)
+		changed, err := edit(edited)
+		if err != nil {
+			return nil, err
+		}
+		if !changed {
+			return apiClientEditResult(apiClient, false), nil
+		}
+
+		latest, err := tmsClient.RetrieveApiClient(serviceId, apiClientId)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error fetching the api client to be edited")
+		}
+		if !sameApiClientState(apiClient, latest) {
+			log.Warnf("Api client %s was changed while being edited, applying the edit again (attempt %d of %d)", apiClientId,
+				attempt, constants.MaxApiClientEditAttempts)
+			apiClient = latest
+			continue
+		}
+
+		status := edited.Status
+		request := &models.UpdateApiClient{
+			ProductId:    edited.ProductId,
+			ServiceId:    serviceId,
+			Name:         &edited.Name,
+			PolicyIds:    edited.PolicyIds,
+			TagIdsValues: apiClientTagIdValues(edited.TagsValues),
+			Status:       &status,
+		}
+		response, err := tmsClient.UpdateApiClient(request, apiClientId)
+		if err != nil {
+			return nil, err
+		}
+		result := apiClientEditResult(edited, true)
+		result.ApiClient = *response
+		return result, nil
+	}
+	return nil, errors.Errorf("Api client %s kept changing while being edited, no change was made", apiClientId)
+}
+
+// copyApiClient copies the api client so that an edit does not alter the state it is compared against
+func copyApiClient(apiClient *models.ApiClientDetail) *models.ApiClientDetail {
+	edited := *apiClient
+	edited.PolicyIds = append([]uuid.UUID(nil), apiClient.PolicyIds...)
+	edited.TagsValues = append([]models.ApiClientTagValue(nil), apiClient.TagsValues...)
+	return &edited
+}
+
+// sameApiClientState tells whether the editable state of the api client is unchanged, regardless of the order of its
+// policies and tags
+func sameApiClientState(a, b *models.ApiClientDetail) bool {
+	if a.Name != b.Name || a.Status != b.Status || a.ProductId != b.ProductId ||
+		len(a.PolicyIds) != len(b.PolicyIds) || len(a.TagsValues) != len(b.TagsValues) {
+		return false
+	}
+	policyIds := map[uuid.UUID]int{}
+	for _, policyId := range a.PolicyIds {
+		policyIds[policyId]++
+	}
+	for _, policyId := range b.PolicyIds {
+		if policyIds[policyId] == 0 {
+			return false
+		}
+		policyIds[policyId]--
+	}
+	tags := map[models.ApiClientTagValue]int{}
+	for _, tag := range a.TagsValues {
+		tags[tag]++
+	}
+	for _, tag := range b.TagsValues {
+		if tags[tag] == 0 {
+			return false
+		}
+		tags[tag]--
+	}
+	return true
+}
+
+func apiClientEditResult(apiClient *models.ApiClientDetail, changed bool) *models2.ApiClientEdit {
+	return &models2.ApiClientEdit{
+		ApiClient: models.ApiClient{
+			ID:          apiClient.ID,
+			ServiceId:   apiClient.ServiceId,
+			ProductId:   apiClient.ProductId,
+			ProductName: apiClient.ProductName,
+			Status:      apiClient.Status,
+			Name:        apiClient.Name,
+			CreatedAt:   apiClient.CreatedAt,
+			ProductType: apiClient.ProductType,
+		},
+		PolicyIds:  append([]uuid.UUID{}, apiClient.PolicyIds...),
+		TagsValues: append([]models.ApiClientTagValue{}, apiClient.TagsValues...),
+		Changed:    changed,
+	}
+}
+
+func formatApiClientEdit(result *models2.ApiClientEdit) (string, error) {
+	responseBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes), nil
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// concurrentTmsClient simulates another edit of the api client each time it is read, up to the number of changes
+type concurrentTmsClient struct {
+	tms.TmsClient
+	change  func()
+	changes int
+}
+
+func (c *concurrentTmsClient) RetrieveApiClient(serviceId, apiClientId uuid.UUID) (*models.ApiClientDetail, error) {
+	apiClient, err := c.TmsClient.RetrieveApiClient(serviceId, apiClientId)
+	if c.changes > 0 {
+		c.changes--
+		c.change()
+	}
+	return apiClient, err
+}
+
+func TestEditApiClient(t *testing.T) {
+	serviceId := uuid.New()
+	policyId, concurrentPolicyId := uuid.New(), uuid.New()
+	apiClients := []models.ApiClientDetail{
+		{ID: uuid.New(), ServiceId: serviceId, ProductId: uuid.New(), Status: constants.ApiClientStatusActive, Name: "payments-workload",
+			TagsValues: []models.ApiClientTagValue{{Name: "Workload", Value: "Payments"}}},
+	}
+	server := test.ApiClientMockServer(t, &apiClients)
+	defer server.Close()
+
+	tmsUrl, err := url.Parse(server.URL + constants.TmsBaseUrl)
+	assert.NoError(t, err)
+	tmsClient := tms.NewTmsClient(&http.Client{Timeout: time.Second}, tmsUrl, "")
+	attach := func(apiClient *models.ApiClientDetail) (bool, error) {
+		apiClient.PolicyIds = append(apiClient.PolicyIds, policyId)
+		return true, nil
+	}
+
+	// a change made while the api client is edited is kept and the edit applied on top of it
+	concurrent := &concurrentTmsClient{TmsClient: tmsClient, changes: 1, change: func() {
+		apiClients[0].PolicyIds = append(apiClients[0].PolicyIds, concurrentPolicyId)
+	}}
+	result, err := editApiClient(concurrent, serviceId, apiClients[0].ID, attach)
+	assert.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.Equal(t, []uuid.UUID{concurrentPolicyId, policyId}, apiClients[0].PolicyIds)
+	assert.Equal(t, []uuid.UUID{concurrentPolicyId, policyId}, result.PolicyIds)
+	assert.Equal(t, []models.ApiClientTagValue{{Name: "Workload", Value: "Payments"}}, apiClients[0].TagsValues)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), apiClients[0].Status)
+
+	// the edit is given up when the api client keeps changing
+	concurrent = &concurrentTmsClient{TmsClient: tmsClient, changes: 2 * constants.MaxApiClientEditAttempts, change: func() {
+		apiClients[0].Name += "-renamed"
+	}}
+	_, err = editApiClient(concurrent, serviceId, apiClients[0].ID, attach)
+	assert.Error(t, err)
+	assert.Equal(t, []uuid.UUID{concurrentPolicyId, policyId}, apiClients[0].PolicyIds)
+
+	// an api client already matching the edit is not updated
+	result, err = editApiClient(tmsClient, serviceId, apiClients[0].ID, func(apiClient *models.ApiClientDetail) (bool, error) {
+		return false, nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, result.Changed)
+
+	_, err = editApiClient(tmsClient, serviceId, uuid.New(), attach)
+	assert.Error(t, err)
+}
+
+func TestSameApiClientState(t *testing.T) {
+	policyIds := []uuid.UUID{uuid.New(), uuid.New()}
+	tags := []models.ApiClientTagValue{{Name: "Workload", Value: "Payments"}, {Name: "Workload", Value: "Billing"}}
+	apiClient := &models.ApiClientDetail{Name: "payments", Status: constants.ApiClientStatusActive, PolicyIds: policyIds, TagsValues: tags}
+
+	reordered := copyApiClient(apiClient)
+	reordered.PolicyIds = []uuid.UUID{policyIds[1], policyIds[0]}
+	reordered.TagsValues = []models.ApiClientTagValue{tags[1], tags[0]}
+	reordered.Keys = []string{"key"}
+	assert.True(t, sameApiClientState(apiClient, reordered))
+
+	for _, edit := range []func(apiClient *models.ApiClientDetail){
+		func(apiClient *models.ApiClientDetail) { apiClient.Name = "billing" },
+		func(apiClient *models.ApiClientDetail) { apiClient.Status = constants.ApiClientStatusInactive },
+		func(apiClient *models.ApiClientDetail) { apiClient.ProductId = uuid.New() },
+		func(apiClient *models.ApiClientDetail) { apiClient.PolicyIds[0] = uuid.New() },
+		func(apiClient *models.ApiClientDetail) { apiClient.PolicyIds = apiClient.PolicyIds[1:] },
+		func(apiClient *models.ApiClientDetail) { apiClient.TagsValues[0].Value = "Billing" },
+	} {
+		edited := copyApiClient(apiClient)
+		edit(edited)
+		assert.False(t, sameApiClientState(apiClient, edited))
+	}
+	// the copy does not share the policies and tags of the api client
+	assert.Equal(t, policyIds, apiClient.PolicyIds)
+	assert.Equal(t, tags, apiClient.TagsValues)
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// apiClientAttachPolicyCmd represents the apiClient attach-policy command
+var apiClientAttachPolicyCmd = &cobra.Command{
+	Use:   constants.AttachPolicyCmd,
+	Short: "Link policies to an api client, keeping the policies already linked",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apiClient attach-policy called")
+		response, err := attachApiClientPolicies(cmd)
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("ApiClient: \n\n", response)
+		return nil
+	},
+}
+
+// apiClientDetachPolicyCmd represents the apiClient detach-policy command
+var apiClientDetachPolicyCmd = &cobra.Command{
+	Use:   constants.DetachPolicyCmd,
+	Short: "Unlink policies from an api client, keeping the other policies linked",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apiClient detach-policy called")
+		response, err := detachApiClientPolicies(cmd)
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("ApiClient: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	apiClientCmd.AddCommand(apiClientAttachPolicyCmd)
+	apiClientCmd.AddCommand(apiClientDetachPolicyCmd)
+
+	for _, cmd := range []*cobra.Command{apiClientAttachPolicyCmd, apiClientDetachPolicyCmd} {
+		addApiClientEditFlags(cmd)
+		cmd.Flags().StringSliceP(constants.PolicyIdsParamName, "i", []string{}, "List of comma separated policy IDs")
+		cmd.MarkFlagRequired(constants.PolicyIdsParamName)
+	}
+}
+
+func attachApiClientPolicies(cmd *cobra.Command) (string, error) {
+	policyIds, err := apiClientEditPolicyIds(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := runApiClientEdit(cmd, func(apiClient *models.ApiClientDetail) (bool, error) {
+		changed := false
+		for _, policyId := range policyIds {
+			if !containsPolicyId(apiClient.PolicyIds, policyId) {
+				apiClient.PolicyIds = append(apiClient.PolicyIds, policyId)
+				changed = true
+			}
+		}
+		return changed, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	response, err := formatApiClientEdit(result)
+	if err != nil {
+		return "", err
+	}
+	return response + deprecatedPolicyWarnings(policyIds), nil
+}
+
+func detachApiClientPolicies(cmd *cobra.Command) (string, error) {
+	policyIds, err := apiClientEditPolicyIds(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := runApiClientEdit(cmd, func(apiClient *models.ApiClientDetail) (bool, error) {
+		var remaining []uuid.UUID
+		for _, policyId := range apiClient.PolicyIds {
+			if !containsPolicyId(policyIds, policyId) {
+				remaining = append(remaining, policyId)
+			}
+		}
+		changed := len(remaining) != len(apiClient.PolicyIds)
+		apiClient.PolicyIds = remaining
+		return changed, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return formatApiClientEdit(result)
+}
+
+func apiClientEditPolicyIds(cmd *cobra.Command) ([]uuid.UUID, error) {
+	policyIdsString, err := cmd.Flags().GetStringSlice(constants.PolicyIdsParamName)
+	if err != nil {
+		return nil, err
+	}
+	if len(policyIdsString) == 0 {
+		return nil, errors.New("At least one policy ID should be provided")
+	}
+
+	var policyIds []uuid.UUID
+	for _, policyId := range policyIdsString {
+		policyUUID, err := uuid.Parse(policyId)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid policy ID found "+policyId+". Should be UUID.")
+		}
+		policyIds = append(policyIds, policyUUID)
+	}
+	return policyIds, nil
+}
+
+func containsPolicyId(policyIds []uuid.UUID, policyId uuid.UUID) bool {
+	for _, id := range policyIds {
+		if id == policyId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"testing"
+)
+
+func TestApiClientPolicyEditCmd(t *testing.T) {
+	serviceId := uuid.New()
+	linkedPolicyId, otherPolicyId, newPolicyId := uuid.New(), uuid.New(), uuid.New()
+	tags := []models.ApiClientTagValue{{Name: "Workload", Value: "Payments"}}
+	apiClients := []models.ApiClientDetail{
+		{ID: uuid.New(), ServiceId: serviceId, ProductId: uuid.New(), Status: constants.ApiClientStatusInactive, Name: "payments-workload",
+			PolicyIds: []uuid.UUID{linkedPolicyId, otherPolicyId}, TagsValues: tags},
+	}
+	apiClientId := apiClients[0].ID
+	server := test.ApiClientMockServer(t, &apiClients)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+	t.Setenv("HOME", t.TempDir())
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		policyIds   []uuid.UUID
+		description string
+	}{
+		{
+			args:        []string{constants.AttachPolicyCmd, "-r", serviceId.String(), "-c", apiClientId.String(), "-i", newPolicyId.String() + "," + linkedPolicyId.String()},
+			wantErr:     false,
+			policyIds:   []uuid.UUID{linkedPolicyId, otherPolicyId, newPolicyId},
+			description: "Test attach a policy to an api client",
+		},
+		{
+			args:        []string{constants.AttachPolicyCmd, "-r", serviceId.String(), "-c", apiClientId.String(), "-i", newPolicyId.String()},
+			wantErr:     false,
+			policyIds:   []uuid.UUID{linkedPolicyId, otherPolicyId, newPolicyId},
+			description: "Test attach a policy already linked to the api client",
+		},
+		{
+			args:        []string{constants.DetachPolicyCmd, "-r", serviceId.String(), "-c", apiClientId.String(), "-i", linkedPolicyId.String()},
+			wantErr:     false,
+			policyIds:   []uuid.UUID{otherPolicyId, newPolicyId},
+			description: "Test detach a policy from an api client",
+		},
+		{
+			args:        []string{constants.DetachPolicyCmd, "-r", serviceId.String(), "-c", apiClientId.String(), "-i", uuid.NewString()},
+			wantErr:     false,
+			policyIds:   []uuid.UUID{otherPolicyId, newPolicyId},
+			description: "Test detach a policy not linked to the api client",
+		},
+		{
+			args:        []string{constants.AttachPolicyCmd, "-r", serviceId.String(), "-c", apiClientId.String(), "-i", "invalid-id"},
+			wantErr:     true,
+			policyIds:   []uuid.UUID{otherPolicyId, newPolicyId},
+			description: "Test attach an invalid policy id",
+		},
+		{
+			args:        []string{constants.AttachPolicyCmd, "-r", serviceId.String(), "-c", apiClientId.String()},
+			wantErr:     true,
+			policyIds:   []uuid.UUID{otherPolicyId, newPolicyId},
+			description: "Test attach without policy ids",
+		},
+		{
+			args:        []string{constants.DetachPolicyCmd, "-r", serviceId.String(), "-c", uuid.NewString(), "-i", otherPolicyId.String()},
+			wantErr:     true,
+			policyIds:   []uuid.UUID{otherPolicyId, newPolicyId},
+			description: "Test detach a policy from a missing api client",
+		},
+		{
+			args:        []string{constants.DetachPolicyCmd, "-r", "invalid-id", "-c", apiClientId.String(), "-i", otherPolicyId.String()},
+			wantErr:     true,
+			policyIds:   []uuid.UUID{otherPolicyId, newPolicyId},
+			description: "Test detach a policy with an invalid service id",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, apiClientAttachPolicyCmd)
+		resetFlagsForTests(t, apiClientDetachPolicyCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.ApiClientCmd}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+		// the other fields of the api client are kept
+		assert.Equal(t, tc.policyIds, apiClients[0].PolicyIds, tc.description)
+		assert.Equal(t, tags, apiClients[0].TagsValues, tc.description)
+		assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusInactive), apiClients[0].Status, tc.description)
+	}
+	resetFlagsForTests(t, apiClientDetachPolicyCmd)
+	defer resetFlagsForTests(t, apiClientDetachPolicyCmd)
+
+	for name, value := range map[string]string{
+		constants.ServiceIdParamName:   serviceId.String(),
+		constants.ApiClientIdParamName: apiClientId.String(),
+		constants.PolicyIdsParamName:   newPolicyId.String(),
+	} {
+		assert.NoError(t, apiClientDetachPolicyCmd.Flags().Set(name, value))
+	}
+	response, err := detachApiClientPolicies(apiClientDetachPolicyCmd)
+	assert.NoError(t, err)
+	var result models2.ApiClientEdit
+	assert.NoError(t, json.Unmarshal([]byte(response), &result))
+	assert.True(t, result.Changed)
+	assert.Equal(t, apiClientId, result.ID)
+	assert.Equal(t, []uuid.UUID{otherPolicyId}, result.PolicyIds)
+}
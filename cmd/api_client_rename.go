@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+
+	"github.com/spf13/cobra"
+)
+
+// apiClientRenameCmd represents the apiClient rename command
+var apiClientRenameCmd = &cobra.Command{
+	Use:   constants.RenameCmd,
+	Short: "Rename an api client, keeping its product, policies, tags and status",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apiClient rename called")
+		response, err := renameApiClient(cmd)
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("ApiClient: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	apiClientCmd.AddCommand(apiClientRenameCmd)
+
+	addApiClientEditFlags(apiClientRenameCmd)
+	apiClientRenameCmd.Flags().StringP(constants.ApiClientNameParamName, "n", "", "New name of the api client")
+	apiClientRenameCmd.MarkFlagRequired(constants.ApiClientNameParamName)
+}
+
+func renameApiClient(cmd *cobra.Command) (string, error) {
+	name, err := cmd.Flags().GetString(constants.ApiClientNameParamName)
+	if err != nil {
+		return "", err
+	}
+	if err = validation.ValidateApiClientName(name); err != nil {
+		return "", err
+	}
+
+	result, err := runApiClientEdit(cmd, func(apiClient *models.ApiClientDetail) (bool, error) {
+		changed := apiClient.Name != name
+		apiClient.Name = name
+		return changed, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return formatApiClientEdit(result)
+}
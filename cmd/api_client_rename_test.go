@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"testing"
+)
+
+func TestApiClientRenameCmd(t *testing.T) {
+	serviceId := uuid.New()
+	policyIds := []uuid.UUID{uuid.New()}
+	tags := []models.ApiClientTagValue{{Name: "Workload", Value: "Payments"}}
+	apiClients := []models.ApiClientDetail{
+		{ID: uuid.New(), ServiceId: serviceId, ProductId: uuid.New(), Status: constants.ApiClientStatusInactive, Name: "payments-workload",
+			PolicyIds: policyIds, TagsValues: tags},
+		{ID: uuid.New(), ServiceId: serviceId, Status: constants.ApiClientStatusActive, Name: test.FailingApiClientName},
+	}
+	apiClientId := apiClients[0].ID
+	server := test.ApiClientMockServer(t, &apiClients)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{"-r", serviceId.String(), "-c", apiClientId.String(), "-n", "billing-workload"},
+			wantErr:     false,
+			description: "Test rename an api client",
+		},
+		{
+			args:        []string{"-r", serviceId.String(), "-c", apiClientId.String(), "-n", "billing-workload"},
+			wantErr:     false,
+			description: "Test rename an api client to its current name",
+		},
+		{
+			args:        []string{"-r", serviceId.String(), "-c", apiClients[1].ID.String(), "-n", "other-workload"},
+			wantErr:     true,
+			description: "Test rename an api client which cannot be updated",
+		},
+		{
+			args:        []string{"-r", serviceId.String(), "-c", apiClientId.String(), "-n", "@@@"},
+			wantErr:     true,
+			description: "Test rename an api client with an invalid name",
+		},
+		{
+			args:        []string{"-r", serviceId.String(), "-c", "invalid-id", "-n", "other-workload"},
+			wantErr:     true,
+			description: "Test rename an api client with an invalid api client id",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, apiClientRenameCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.ApiClientCmd, constants.RenameCmd}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, apiClientRenameCmd)
+
+	// only the name is changed
+	assert.Equal(t, "billing-workload", apiClients[0].Name)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusInactive), apiClients[0].Status)
+	assert.Equal(t, policyIds, apiClients[0].PolicyIds)
+	assert.Equal(t, tags, apiClients[0].TagsValues)
+	assert.Equal(t, test.FailingApiClientName, apiClients[1].Name)
+}
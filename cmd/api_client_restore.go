@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/bulk"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// apiClientRestoreCmd represents the apiClient restore command
+var apiClientRestoreCmd = &cobra.Command{
+	Use:   constants.RestoreCmd,
+	Short: "Reverse a revocation of api clients from its undo file",
+	Long: `Set the api clients listed in the undo file written by "apiClient revoke" back to the status they had before
+the revocation. Once the revocation is completed, the undo file only lists the api clients it revoked. Only the api
+clients which are still Inactive are changed, so that api clients cancelled or reactivated since the revocation are
+left as they are.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apiClient restore called")
+		response, err := restoreApiClients(cmd)
+		utils.PrintRequestAndTraceId()
+		if response != "" {
+			fmt.Println("ApiClients: \n\n", response)
+		}
+		return err
+	},
+}
+
+func init() {
+	apiClientCmd.AddCommand(apiClientRestoreCmd)
+
+	apiClientRestoreCmd.Flags().StringP(constants.InputFileParamName, "f", "", "Path of the undo file written by \"apiClient revoke\"")
+	addBulkFlags(apiClientRestoreCmd)
+	apiClientRestoreCmd.MarkFlagRequired(constants.InputFileParamName)
+}
+
+func restoreApiClients(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client, concurrency, err := bulkHttpClient(cmd, configValues)
+	if err != nil {
+		return "", err
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	undoFile, err := cmd.Flags().GetString(constants.InputFileParamName)
+	if err != nil {
+		return "", err
+	}
+	outFile, err := cmd.Flags().GetString(constants.OutFileParamName)
+	if err != nil {
+		return "", err
+	}
+	revocation, err := readUndoFile(undoFile)
+	if err != nil {
+		return "", err
+	}
+	if !revocation.Completed {
+		fmt.Fprintf(cmd.ErrOrStderr(), "The revocation was interrupted, every api client it was about to revoke which is "+
+			"still %s is restored\n", constants.ApiClientStatusInactive)
+	}
+
+	results := make([]models2.ApiClientBulkResult, len(revocation.ApiClients))
+	bulk.Run(len(results), concurrency, func(i int) {
+		revoked := &revocation.ApiClients[i]
+		result := &results[i]
+		*result = models2.ApiClientBulkResult{Name: revoked.Name, ServiceId: &revoked.ServiceId,
+			ApiClientId: &revoked.ApiClientId}
+		restore := func(apiClient *models.ApiClientDetail) (bool, error) {
+			if apiClient.Status != constants.ApiClientStatusInactive {
+				return false, nil
+			}
+			apiClient.Status = models.ApiClientStatus(revoked.Status)
+			return true, nil
+		}
+		tmsClient := tms.NewTmsClient(bulkItemClient(client, result), tmsUrl, apiKey)
+		edited, err := editApiClient(tmsClient, revoked.ServiceId, revoked.ApiClientId, restore)
+		if err != nil {
+			log.WithError(err).Errorf("Error restoring api client %s", revoked.ApiClientId)
+			result.Action = constants.BulkActionFailed
+			result.Error = err.Error()
+			return
+		}
+		result.Action = constants.BulkActionUnchanged
+		if edited.Changed {
+			result.Action = constants.BulkActionRestored
+		}
+	})
+
+	return reportBulkResults(&models2.ApiClientBulk{Results: results}, outFile)
+}
+
+// readUndoFile reads the undo file of a revocation
+func readUndoFile(undoFile string) (*models2.ApiClientRevocation, error) {
+	path, err := validation.ValidatePath(undoFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid undo file path provided")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading the undo file")
+	}
+	var revocation models2.ApiClientRevocation
+	if err = json.Unmarshal(content, &revocation); err != nil {
+		return nil, errors.Wrap(err, "Error parsing the undo file")
+	}
+	for _, revoked := range revocation.ApiClients {
+		if revoked.Status != constants.ApiClientStatusActive && revoked.Status != constants.ApiClientStatusInactive {
+			return nil, errors.Errorf("Invalid status %q of api client %s in the undo file", revoked.Status, revoked.ApiClientId)
+		}
+	}
+	return &revocation, nil
+}
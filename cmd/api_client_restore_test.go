@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApiClientRestoreCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tenant := revokeTenantForTests()
+	server := test.TenantMockServer(t, tenant)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	restore := func(args ...string) error {
+		resetFlagsForTests(t, apiClientRestoreCmd)
+		defer resetFlagsForTests(t, apiClientRestoreCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.ApiClientCmd, constants.RestoreCmd}, args...))
+		return err
+	}
+
+	dir := t.TempDir()
+	undoPath := filepath.Join(dir, "undo.json")
+	assert.ErrorContains(t, revokeForTests(t, "revoke 4\n", "--by-product", tenant.Products[0].ID.String(), "--undo-file",
+		undoPath), "1 of 4 api clients failed")
+	for _, apiClient := range []models.ApiClientDetail{tenant.ApiClients[0], tenant.ApiClients[1], tenant.ApiClients[2]} {
+		assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusInactive), apiClient.Status)
+	}
+
+	invalidPath := filepath.Join(dir, "invalid.json")
+	revocation := models2.ApiClientRevocation{ApiClients: []models2.RevokedApiClient{{ServiceId: tenant.Services[0].ID,
+		ApiClientId: tenant.ApiClients[0].ID, Name: tenant.ApiClients[0].Name, Status: constants.ApiClientStatusCancelled}}}
+	invalidBytes, err := json.Marshal(revocation)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(invalidPath, invalidBytes, 0600))
+
+	tt := []struct {
+		args        []string
+		description string
+	}{
+		{
+			args:        []string{},
+			description: "Test restore without undo file",
+		},
+		{
+			args:        []string{"-f", filepath.Join(dir, "missing.json")},
+			description: "Test restore from a missing undo file",
+		},
+		{
+			args:        []string{"-f", invalidPath},
+			description: "Test restore to a status other than Active or Inactive",
+		},
+	}
+	for _, tc := range tt {
+		assert.Error(t, restore(tc.args...), tc.description)
+	}
+
+	// the api clients cancelled since the revocation are left as they are
+	tenant.ApiClients[1].Status = constants.ApiClientStatusCancelled
+	resultPath := filepath.Join(dir, "results.json")
+	assert.NoError(t, restore("-f", undoPath, "-o", resultPath))
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), tenant.ApiClients[0].Status)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusCancelled), tenant.ApiClients[1].Status)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), tenant.ApiClients[2].Status)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), tenant.ApiClients[3].Status)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusInactive), tenant.ApiClients[4].Status)
+
+	resultBytes, err := os.ReadFile(resultPath)
+	assert.NoError(t, err)
+	var report models2.ApiClientBulk
+	assert.NoError(t, json.Unmarshal(resultBytes, &report))
+	actions := map[string]string{}
+	for _, result := range report.Results {
+		actions[result.Name] = result.Action
+	}
+	assert.Equal(t, map[string]string{"payments-workload": constants.BulkActionRestored,
+		"inference-1": constants.BulkActionUnchanged, "inference-2": constants.BulkActionRestored}, actions)
+
+	// the api clients listed in the undo file of an interrupted revocation are restored as well
+	interruptedPath := filepath.Join(dir, "interrupted.json")
+	revocation = models2.ApiClientRevocation{ApiClients: []models2.RevokedApiClient{{ServiceId: tenant.Services[0].ID,
+		ApiClientId: tenant.ApiClients[4].ID, Name: tenant.ApiClients[4].Name, Status: constants.ApiClientStatusActive}}}
+	interruptedBytes, err := json.Marshal(revocation)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(interruptedPath, interruptedBytes, 0600))
+	assert.NoError(t, restore("-f", interruptedPath))
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), tenant.ApiClients[4].Status)
+}
@@ -0,0 +1,300 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/bulk"
+	"intel/tac/v1/internal/lookup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// apiClientRevokeCmd represents the apiClient revoke command
+var apiClientRevokeCmd = &cobra.Command{
+	Use:   constants.RevokeCmd,
+	Short: "Set every active api client linked to a policy, tag or product to Inactive",
+	Long: `Set to Inactive, across all the services of the tenant, every active api client linked to a policy, having a
+tag, or subscribed to a product, or every active api client with --all. The matching api clients are listed first, and
+nothing is changed until the revocation is confirmed by typing "revoke <number of api clients>", or with --confirm
+set to the number of api clients. Before any api client is changed, the api clients are written to an undo file which
+"apiClient restore" takes to set them back to their previous status.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apiClient revoke called")
+		response, err := revokeApiClients(cmd)
+		utils.PrintRequestAndTraceId()
+		if response != "" {
+			fmt.Println("ApiClients: \n\n", response)
+		}
+		return err
+	},
+}
+
+func init() {
+	apiClientCmd.AddCommand(apiClientRevokeCmd)
+
+	apiClientRevokeCmd.Flags().String(constants.ByPolicyParamName, "", "Id or name of the policy whose api clients are revoked")
+	apiClientRevokeCmd.Flags().String(constants.ByTagParamName, "", "Tag of the api clients to be revoked, in the "+
+		"key:value format, or a tag key to revoke the api clients with any value of the tag")
+	apiClientRevokeCmd.Flags().String(constants.ByProductParamName, "", "Id or name of the product whose api clients are revoked")
+	apiClientRevokeCmd.Flags().Bool(constants.AllParamName, false, "Revoke every active api client of the tenant")
+	apiClientRevokeCmd.Flags().Int(constants.ConfirmParamName, 0, "Number of api clients expected to be revoked, "+
+		"confirming the revocation without prompting. Nothing is revoked when another number of api clients matches")
+	apiClientRevokeCmd.Flags().String(constants.UndoFileParamName, "", "Path of the undo file, which should not exist. "+
+		"Defaults to "+fmt.Sprintf(constants.RevokeUndoFileFormat, "<time>")+" in the current directory")
+	apiClientRevokeCmd.Flags().Bool(constants.DryRunParamName, false, "List the api clients which would be revoked without revoking them")
+	addBulkFlags(apiClientRevokeCmd)
+}
+
+func revokeApiClients(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client, concurrency, err := bulkHttpClient(cmd, configValues)
+	if err != nil {
+		return "", err
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	dryRun, err := cmd.Flags().GetBool(constants.DryRunParamName)
+	if err != nil {
+		return "", err
+	}
+	outFile, err := cmd.Flags().GetString(constants.OutFileParamName)
+	if err != nil {
+		return "", err
+	}
+	undoFile, err := cmd.Flags().GetString(constants.UndoFileParamName)
+	if err != nil {
+		return "", err
+	}
+	if undoFile == "" {
+		undoFile = fmt.Sprintf(constants.RevokeUndoFileFormat, time.Now().UTC().Format(constants.RevokeUndoFileTimeFormat))
+	}
+	undoFile = filepath.Clean(undoFile)
+	if _, err = os.Stat(undoFile); err == nil {
+		return "", errors.Errorf("Undo file %s already exists", undoFile)
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	selectorString, err := revokeSelector(cmd, pms.NewPmsClient(client, pmsUrl, apiKey))
+	if err != nil {
+		return "", err
+	}
+	selector, err := bulk.ParseSelector(selectorString)
+	if err != nil {
+		return "", err
+	}
+
+	results, err := selectApiClients(tmsClient, selector, concurrency)
+	if err != nil {
+		return "", err
+	}
+	revocation := models2.ApiClientRevocation{Selector: selectorString, ApiClients: []models2.RevokedApiClient{}}
+	for _, result := range results {
+		if result.Action != constants.BulkActionFailed {
+			revocation.ApiClients = append(revocation.ApiClients, models2.RevokedApiClient{ServiceId: *result.ServiceId,
+				ApiClientId: *result.ApiClientId, Name: result.Name, Status: constants.ApiClientStatusActive})
+		}
+	}
+	if len(results) == 0 {
+		return fmt.Sprintf("No active api client matches %s", selectorString), nil
+	}
+
+	services, err := tmsClient.GetServices()
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the services of the tenant")
+	}
+	printBulkMatches(cmd.ErrOrStderr(), selectorString, constants.BulkActionRevoked, results, services)
+
+	if dryRun {
+		return reportBulkResults(&models2.ApiClientBulk{Results: results, DryRun: true}, outFile)
+	}
+	if len(revocation.ApiClients) == 0 {
+		return reportBulkResults(&models2.ApiClientBulk{Results: results}, outFile)
+	}
+	if err = confirmBulkAction(cmd, constants.RevokeConfirmation, len(revocation.ApiClients),
+		"set these api clients to "+constants.ApiClientStatusInactive, constants.BulkActionRevoked); err != nil {
+		return "", err
+	}
+
+	// the undo file is written before any api client is changed, so that an interrupted revocation can be reversed
+	revocation.RevokedAt = time.Now().UTC()
+	if err = writeUndoFile(undoFile, &revocation); err != nil {
+		return "", err
+	}
+
+	revoke := func(apiClient *models.ApiClientDetail) (bool, error) {
+		if apiClient.Status != constants.ApiClientStatusActive {
+			return false, nil
+		}
+		apiClient.Status = constants.ApiClientStatusInactive
+		return true, nil
+	}
+	bulk.Run(len(results), concurrency, func(i int) {
+		result := &results[i]
+		if result.Action == constants.BulkActionFailed {
+			return
+		}
+		itemTmsClient := tms.NewTmsClient(bulkItemClient(client, result), tmsUrl, apiKey)
+		edited, err := editApiClient(itemTmsClient, *result.ServiceId, *result.ApiClientId, revoke)
+		if err != nil {
+			log.WithError(err).Errorf("Error revoking api client %s", result.ApiClientId)
+			result.Action = constants.BulkActionFailed
+			result.Error = err.Error()
+			return
+		}
+		result.Action = constants.BulkActionUnchanged
+		if edited.Changed {
+			result.Action = constants.BulkActionRevoked
+		}
+	})
+
+	// the undo file is rewritten with only the api clients which were revoked, so that restoring it does not
+	// reactivate api clients that were already set to Inactive by someone else
+	revocation.Completed = true
+	revocation.ApiClients = []models2.RevokedApiClient{}
+	for _, result := range results {
+		if result.Action == constants.BulkActionRevoked {
+			revocation.ApiClients = append(revocation.ApiClients, models2.RevokedApiClient{ServiceId: *result.ServiceId,
+				ApiClientId: *result.ApiClientId, Name: result.Name, Status: constants.ApiClientStatusActive})
+		}
+	}
+	if err = rewriteUndoFile(undoFile, &revocation); err != nil {
+		return "", err
+	}
+
+	return reportBulkResults(&models2.ApiClientBulk{UndoFile: undoFile, Results: results}, outFile)
+}
+
+// revokeSelector returns the selector of the active api clients matching the one of --by-policy, --by-tag,
+// --by-product and --all provided, the policy being resolved to its id
+func revokeSelector(cmd *cobra.Command, pmsClient pms.PmsClient) (string, error) {
+	var provided []string
+	for _, flag := range []string{constants.ByPolicyParamName, constants.ByTagParamName, constants.ByProductParamName,
+		constants.AllParamName} {
+		if cmd.Flags().Changed(flag) {
+			provided = append(provided, "--"+flag)
+		}
+	}
+	if len(provided) != 1 {
+		return "", errors.Errorf("Exactly one of --%s, --%s, --%s or --%s should be provided", constants.ByPolicyParamName,
+			constants.ByTagParamName, constants.ByProductParamName, constants.AllParamName)
+	}
+
+	selector := "status=" + constants.ApiClientStatusActive
+	switch provided[0] {
+	case "--" + constants.ByPolicyParamName:
+		reference, err := cmd.Flags().GetString(constants.ByPolicyParamName)
+		if err != nil {
+			return "", err
+		}
+		policies, err := pmsClient.SearchPolicy()
+		if err != nil {
+			return "", errors.Wrap(err, "Error fetching the policies of the tenant")
+		}
+		var policyId uuid.UUID
+		matches := 0
+		for _, policy := range policies {
+			if lookup.Matches(reference, policy.PolicyId, policy.PolicyName) {
+				policyId = policy.PolicyId
+				matches++
+			}
+		}
+		if err = lookup.CheckMatches(constants.PolicyCmd, reference, matches); err != nil {
+			return "", err
+		}
+		return selector + ",policy=" + policyId.String(), nil
+	case "--" + constants.ByTagParamName:
+		tag, err := cmd.Flags().GetString(constants.ByTagParamName)
+		if err != nil {
+			return "", err
+		}
+		if tag == "" || strings.Contains(tag, ",") {
+			return "", errors.Errorf("--%s should be a single tag key or key:value pair", constants.ByTagParamName)
+		}
+		return selector + ",tag=" + tag, nil
+	case "--" + constants.ByProductParamName:
+		product, err := cmd.Flags().GetString(constants.ByProductParamName)
+		if err != nil {
+			return "", err
+		}
+		if product == "" || strings.Contains(product, ",") {
+			return "", errors.Errorf("--%s should be the id or name of a product", constants.ByProductParamName)
+		}
+		return selector + ",product=" + product, nil
+	default:
+		all, err := cmd.Flags().GetBool(constants.AllParamName)
+		if err != nil {
+			return "", err
+		}
+		if !all {
+			return "", errors.Errorf("--%s=false does not select any api client", constants.AllParamName)
+		}
+		return selector, nil
+	}
+}
+
+// rewriteUndoFile replaces the undo file with the api clients which were revoked
+func rewriteUndoFile(undoFile string, revocation *models2.ApiClientRevocation) error {
+	content, err := json.MarshalIndent(revocation, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = utils.WriteFileAtomic(undoFile, content, constants.DefaultFilePermission); err != nil {
+		return errors.Wrap(err, "Error rewriting the undo file")
+	}
+	return nil
+}
+
+// writeUndoFile writes the api clients to be revoked to a new undo file
+func writeUndoFile(undoFile string, revocation *models2.ApiClientRevocation) error {
+	content, err := json.MarshalIndent(revocation, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(undoFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, constants.DefaultFilePermission)
+	if err != nil {
+		return errors.Wrap(err, "Error creating the undo file")
+	}
+	if _, err = f.Write(content); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "Error writing the undo file")
+	}
+	if err = f.Close(); err != nil {
+		return errors.Wrap(err, "Error writing the undo file")
+	}
+	return nil
+}
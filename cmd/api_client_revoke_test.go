@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// revokeTenantForTests returns the tenant of the export tests with three active api clients tagged Workload:Inference,
+// one of which cannot be updated, and an inactive one
+func revokeTenantForTests() *test.Tenant {
+	tenant := exportTenantForTests()
+	service := tenant.Services[0]
+	for _, name := range []string{"inference-1", "inference-2", test.FailingApiClientName, "inference-3"} {
+		tenant.ApiClients = append(tenant.ApiClients, models.ApiClientDetail{ID: uuid.New(), ServiceId: service.ID,
+			ProductId: tenant.Products[0].ID, ProductName: "Enterprise", Status: constants.ApiClientStatusActive, Name: name,
+			TagsValues: []models.ApiClientTagValue{{Name: "Workload", Value: "Inference"}}})
+	}
+	tenant.ApiClients[4].Status = constants.ApiClientStatusInactive
+	return tenant
+}
+
+// revokeForTests runs apiClient revoke with the input typed at the confirmation prompt
+func revokeForTests(t *testing.T, input string, args ...string) error {
+	resetFlagsForTests(t, apiClientRevokeCmd)
+	defer resetFlagsForTests(t, apiClientRevokeCmd)
+	tenantCmd.SetIn(strings.NewReader(input))
+	defer tenantCmd.SetIn(nil)
+	_, err := execute(t, tenantCmd, append([]string{constants.ApiClientCmd, constants.RevokeCmd}, args...))
+	return err
+}
+
+func TestApiClientRevokeCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tenant := revokeTenantForTests()
+	server := test.TenantMockServer(t, tenant)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	dir := t.TempDir()
+	undoPath := filepath.Join(dir, "undo.json")
+	existingPath := filepath.Join(dir, "existing.json")
+	assert.NoError(t, os.WriteFile(existingPath, []byte("{}"), 0600))
+
+	tt := []struct {
+		input       string
+		args        []string
+		description string
+	}{
+		{
+			args:        []string{"--undo-file", undoPath},
+			description: "Test revoke without selector",
+		},
+		{
+			args:        []string{"--by-tag", "Workload:Inference", "--all", "--undo-file", undoPath},
+			description: "Test revoke with two selectors",
+		},
+		{
+			args:        []string{"--by-policy", "missing-policy", "--undo-file", undoPath},
+			description: "Test revoke by an unknown policy",
+		},
+		{
+			args:        []string{"--by-tag", "Workload:Inference,Region", "--undo-file", undoPath},
+			description: "Test revoke by several tags",
+		},
+		{
+			args:        []string{"--by-tag", "Workload:Inference", "--undo-file", existingPath},
+			description: "Test revoke with an existing undo file",
+		},
+		{
+			args:        []string{"--by-tag", "Workload:Inference", "--confirm", "2", "--undo-file", undoPath},
+			description: "Test revoke confirming another number of api clients",
+		},
+		{
+			input:       "yes\n",
+			args:        []string{"--by-tag", "Workload:Inference", "--undo-file", undoPath},
+			description: "Test revoke without typing the confirmation",
+		},
+		{
+			args:        []string{"--by-tag", "Workload:Inference", "--undo-file", undoPath},
+			description: "Test revoke without input",
+		},
+	}
+	for _, tc := range tt {
+		assert.Error(t, revokeForTests(t, tc.input, tc.args...), tc.description)
+	}
+	assert.NoFileExists(t, undoPath)
+
+	// nothing is changed on a dry run
+	assert.NoError(t, revokeForTests(t, "", "--by-policy", "shared-policy", "--dry-run", "--undo-file", undoPath))
+	assert.NoFileExists(t, undoPath)
+	for _, apiClient := range tenant.ApiClients[:4] {
+		assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), apiClient.Status)
+	}
+
+	// the active api clients with the tag are revoked once confirmed, the others are reported as failed
+	resultPath := filepath.Join(dir, "results.json")
+	assert.ErrorContains(t, revokeForTests(t, "revoke 3\n", "--by-tag", "Workload:Inference", "--undo-file", undoPath,
+		"-o", resultPath), "1 of 3 api clients failed")
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), tenant.ApiClients[0].Status)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusInactive), tenant.ApiClients[1].Status)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusInactive), tenant.ApiClients[2].Status)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), tenant.ApiClients[3].Status)
+
+	undoBytes, err := os.ReadFile(undoPath)
+	assert.NoError(t, err)
+	var revocation models2.ApiClientRevocation
+	assert.NoError(t, json.Unmarshal(undoBytes, &revocation))
+	assert.Equal(t, "status=Active,tag=Workload:Inference", revocation.Selector)
+	assert.False(t, revocation.RevokedAt.IsZero())
+	// only the api clients which were revoked are kept in the undo file
+	assert.True(t, revocation.Completed)
+	assert.Len(t, revocation.ApiClients, 2)
+	for i, revoked := range revocation.ApiClients {
+		assert.Equal(t, tenant.ApiClients[i+1].ID, revoked.ApiClientId)
+		assert.Equal(t, tenant.ApiClients[i+1].ServiceId, revoked.ServiceId)
+		assert.Equal(t, constants.ApiClientStatusActive, revoked.Status)
+	}
+
+	resultBytes, err := os.ReadFile(resultPath)
+	assert.NoError(t, err)
+	var report models2.ApiClientBulk
+	assert.NoError(t, json.Unmarshal(resultBytes, &report))
+	assert.Equal(t, 2, report.Succeeded)
+	assert.Equal(t, 1, report.Failed)
+	for _, result := range report.Results {
+		if result.Name == test.FailingApiClientName {
+			assert.Equal(t, constants.BulkActionFailed, result.Action)
+		} else {
+			assert.Equal(t, constants.BulkActionRevoked, result.Action)
+		}
+	}
+
+	// the api clients of a policy are revoked with --confirm
+	assert.NoError(t, revokeForTests(t, "", "--by-policy", "payments-policy", "--confirm", "1", "--undo-file",
+		filepath.Join(dir, "policy-undo.json")))
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusInactive), tenant.ApiClients[0].Status)
+
+	// nothing is revoked when no active api client matches
+	assert.NoError(t, revokeForTests(t, "", "--by-product", "Premium", "--undo-file", filepath.Join(dir, "product-undo.json")))
+	assert.NoFileExists(t, filepath.Join(dir, "product-undo.json"))
+}
@@ -0,0 +1,321 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// rotatedNameSuffixRegex matches the timestamp added to the name of a replacement API client
+var rotatedNameSuffixRegex = regexp.MustCompile(`-[0-9]{14}$`)
+
+// apiClientRotateCmd represents the apiClient rotate command
+var apiClientRotateCmd = &cobra.Command{
+	Use:   constants.RotateCmd,
+	Short: "Replace an api client, and so its attestation API keys, by a new api client with the same product, policies and tags",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apiClient rotate called")
+		response, err := rotateApiClient(cmd)
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("ApiClient rotation: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	apiClientCmd.AddCommand(apiClientRotateCmd)
+
+	apiClientRotateCmd.Flags().StringP(constants.ServiceIdParamName, "r", "", "Id of the Trust Authority service of the api client")
+	apiClientRotateCmd.Flags().StringP(constants.ApiClientIdParamName, "c", "", "Id of the api client to be replaced")
+	apiClientRotateCmd.Flags().StringP(constants.ApiClientNameParamName, "n", "", "Name of the replacement api client, defaults to "+
+		"the name of the api client followed by the current time")
+	apiClientRotateCmd.Flags().String(constants.OldStatusParamName, constants.ApiClientStatusInactive, "Status the replaced api client "+
+		"is set to, should be one of \"Inactive\" or \"Cancelled\"")
+	apiClientRotateCmd.Flags().String(constants.AttestationUrlParamName, "", "Trust Authority attestation API URL, example "+
+		"https://api.trustauthority.intel.com. When provided the new attestation API key is polled until it is accepted, "+
+		"instead of waiting for the activation delay")
+	apiClientRotateCmd.Flags().Duration(constants.ActivationDelayParamName, constants.DefaultActivationDelay*time.Second,
+		"Time to wait for the new attestation API key to take effect when no attestation API URL is provided")
+	apiClientRotateCmd.Flags().Duration(constants.ActivationTimeoutParamName, constants.DefaultActivationTimeout*time.Second,
+		"Maximum time to wait for the replacement api client to be ready")
+	apiClientRotateCmd.Flags().Duration(constants.PollIntervalParamName, constants.DefaultActivationPollInterval*time.Second,
+		"Time between two readiness checks of the replacement api client")
+	apiClientRotateCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	addKeyOutputFlags(apiClientRotateCmd)
+	apiClientRotateCmd.MarkFlagRequired(constants.ServiceIdParamName)
+	apiClientRotateCmd.MarkFlagRequired(constants.ApiClientIdParamName)
+}
+
+func rotateApiClient(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	serviceIdString, err := cmd.Flags().GetString(constants.ServiceIdParamName)
+	if err != nil {
+		return "", err
+	}
+	serviceId, err := uuid.Parse(serviceIdString)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid service id provided")
+	}
+
+	apiClientIdString, err := cmd.Flags().GetString(constants.ApiClientIdParamName)
+	if err != nil {
+		return "", err
+	}
+	apiClientId, err := uuid.Parse(apiClientIdString)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid api client Id provided")
+	}
+
+	name, err := cmd.Flags().GetString(constants.ApiClientNameParamName)
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		if err = validation.ValidateApiClientName(name); err != nil {
+			return "", err
+		}
+	}
+
+	oldStatus, err := cmd.Flags().GetString(constants.OldStatusParamName)
+	if err != nil {
+		return "", err
+	}
+	if oldStatus != constants.ApiClientStatusInactive && oldStatus != constants.ApiClientStatusCancelled {
+		return "", errors.Errorf("Status of the replaced api client should be one of %s or %s", constants.ApiClientStatusInactive,
+			constants.ApiClientStatusCancelled)
+	}
+
+	keyOutput, err := apiClientKeyOutput(cmd)
+	if err != nil {
+		return "", err
+	}
+	showKeys, err := cmd.Flags().GetBool(constants.ShowKeysParamName)
+	if err != nil {
+		return "", err
+	}
+	// the replaced api client stops working, the new keys should not only be printed masked
+	if keyOutput == nil && !showKeys {
+		return "", errors.Errorf("Either --%s or --%s should be provided, the replaced api client is deactivated "+
+			"so that the new attestation API keys are needed", constants.KeyOutputParamName, constants.ShowKeysParamName)
+	}
+
+	attestationUrl, err := cmd.Flags().GetString(constants.AttestationUrlParamName)
+	if err != nil {
+		return "", err
+	}
+	if attestationUrl != "" {
+		if err = validation.ValidateURL(attestationUrl); err != nil {
+			return "", err
+		}
+	}
+
+	activationDelay, err := cmd.Flags().GetDuration(constants.ActivationDelayParamName)
+	if err != nil {
+		return "", err
+	}
+	activationTimeout, err := cmd.Flags().GetDuration(constants.ActivationTimeoutParamName)
+	if err != nil {
+		return "", err
+	}
+	pollInterval, err := cmd.Flags().GetDuration(constants.PollIntervalParamName)
+	if err != nil {
+		return "", err
+	}
+	if activationDelay < 0 || activationTimeout <= 0 || pollInterval <= 0 {
+		return "", errors.Errorf("--%s should not be negative, --%s and --%s should be positive", constants.ActivationDelayParamName,
+			constants.ActivationTimeoutParamName, constants.PollIntervalParamName)
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	oldApiClient, err := tmsClient.RetrieveApiClient(serviceId, apiClientId)
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the api client to be replaced")
+	}
+	if oldApiClient.Status == constants.ApiClientStatusCancelled {
+		return "", errors.Errorf("Api client %s is cancelled and cannot be rotated", apiClientId)
+	}
+	if name == "" {
+		name = rotatedApiClientName(oldApiClient.Name, time.Now())
+	}
+
+	newApiClient, err := tmsClient.CreateApiClient(&models.CreateApiClient{
+		ProductId:    oldApiClient.ProductId,
+		ServiceId:    serviceId,
+		PolicyIds:    oldApiClient.PolicyIds,
+		TagIdsValues: apiClientTagIdValues(oldApiClient.TagsValues),
+		Name:         name,
+		Status:       constants.ApiClientStatusActive,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating the replacement api client")
+	}
+
+	// the replacement is deleted when a later step fails, so that the replaced api client remains the only one in use
+	keysDelivered := false
+	rollback := func(cause error) error {
+		if keysDelivered {
+			if err := keyOutput.Revert(); err != nil {
+				log.WithError(err).Warn("Unable to revert the key output")
+			}
+		}
+		if err := tmsClient.DeleteApiClient(serviceId, newApiClient.ID); err != nil {
+			return errors.Wrapf(cause, "Rotation failed and the replacement api client %s could not be deleted, "+
+				"it should be deleted manually (%s)", newApiClient.ID, err.Error())
+		}
+		return errors.Wrapf(cause, "Rotation failed, the replacement api client %s was deleted", newApiClient.ID)
+	}
+
+	if len(newApiClient.Keys) == 0 {
+		return "", rollback(errors.New("No attestation API key was returned for the replacement api client"))
+	}
+
+	rotation := models2.ApiClientRotation{
+		ServiceId:        serviceId,
+		OldApiClientId:   oldApiClient.ID,
+		OldApiClientName: oldApiClient.Name,
+		OldStatus:        oldStatus,
+		NewApiClientId:   newApiClient.ID,
+		NewApiClientName: newApiClient.Name,
+	}
+	// the key is kept for the readiness check as the keys are masked once delivered
+	key := newApiClient.Keys[0]
+	if err = deliverApiClientKeys(cmd, keyOutput, newApiClient); err != nil {
+		return "", rollback(err)
+	}
+	if keyOutput != nil {
+		keysDelivered = true
+		rotation.KeyOutput = keyOutput.String()
+	}
+	rotation.Keys = newApiClient.Keys
+
+	fmt.Printf("Waiting for the attestation API key of api client %s to take effect...\n", newApiClient.ID)
+	err = waitForApiClientActivation(tmsClient, client, serviceId, newApiClient.ID, key, attestationUrl,
+		activationDelay, activationTimeout, pollInterval)
+	if err != nil {
+		return "", rollback(err)
+	}
+	rotation.ActivatedAt = time.Now().UTC()
+
+	status := models.ApiClientStatus(oldStatus)
+	_, err = tmsClient.UpdateApiClient(&models.UpdateApiClient{
+		ProductId:    oldApiClient.ProductId,
+		ServiceId:    serviceId,
+		PolicyIds:    oldApiClient.PolicyIds,
+		TagIdsValues: apiClientTagIdValues(oldApiClient.TagsValues),
+		Status:       &status,
+	}, oldApiClient.ID)
+	if err != nil {
+		return "", rollback(errors.Wrapf(err, "Error setting the status of api client %s to %s", oldApiClient.ID, oldStatus))
+	}
+
+	responseBytes, err := json.MarshalIndent(rotation, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes), nil
+}
+
+// rotatedApiClientName names the replacement of an API client after it, replacing the time of a previous rotation
+func rotatedApiClientName(name string, now time.Time) string {
+	suffix := "-" + now.UTC().Format(constants.TimeLayout)
+	name = rotatedNameSuffixRegex.ReplaceAllString(name, "")
+	if len(name)+len(suffix) > constants.MaxApiClientNameLength {
+		name = strings.TrimRight(name[:constants.MaxApiClientNameLength-len(suffix)], "-_")
+	}
+	return name + suffix
+}
+
+// waitForApiClientActivation polls the API client until it is active. The attestation API key is then polled until it
+// is accepted by the attestation API when its URL is known, otherwise the activation delay is waited out.
+func waitForApiClientActivation(tmsClient tms.TmsClient, client *http.Client, serviceId, apiClientId uuid.UUID, key,
+	attestationUrl string, activationDelay, activationTimeout, pollInterval time.Duration) error {
+	deadline := time.Now().Add(activationTimeout)
+	for {
+		apiClient, err := tmsClient.RetrieveApiClient(serviceId, apiClientId)
+		if err != nil {
+			log.WithError(err).Debugf("Unable to fetch api client %s", apiClientId)
+		} else if apiClient.Status == constants.ApiClientStatusActive {
+			break
+		}
+		if time.Now().Add(pollInterval).After(deadline) {
+			return errors.Errorf("Api client %s is not active after %s", apiClientId, activationTimeout)
+		}
+		time.Sleep(pollInterval)
+	}
+
+	if attestationUrl == "" {
+		time.Sleep(activationDelay)
+		return nil
+	}
+	for {
+		accepted, err := attestationKeyAccepted(client, attestationUrl, key)
+		if err != nil {
+			log.WithError(err).Debug("Unable to reach the attestation API")
+		} else if accepted {
+			return nil
+		}
+		if time.Now().Add(pollInterval).After(deadline) {
+			return errors.Errorf("The attestation API key of api client %s is not accepted after %s", apiClientId, activationTimeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// attestationKeyAccepted requests a nonce from the attestation API with the key, which only succeeds once the key is
+// in effect
+func attestationKeyAccepted(client *http.Client, attestationUrl, key string) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(attestationUrl, "/")+constants.AttestationNonceEndpoint, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "Error forming request")
+	}
+	req.Header.Add(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
+	req.Header.Add(constants.HTTPHeaderKeyApiKey, key)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK, nil
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApiClientRotateCmd(t *testing.T) {
+	serviceId := uuid.New()
+	productId := uuid.New()
+	policyIds := []uuid.UUID{uuid.New(), uuid.New()}
+	tags := []models.ApiClientTagValue{{Name: "Workload", Value: "Payments"}}
+	apiClients := []models.ApiClientDetail{
+		{ID: uuid.New(), ServiceId: serviceId, ProductId: productId, Status: constants.ApiClientStatusActive, Name: "payments-workload",
+			Keys: []string{"payments-key"}, PolicyIds: policyIds, TagsValues: tags},
+		{ID: uuid.New(), ServiceId: serviceId, ProductId: productId, Status: constants.ApiClientStatusCancelled, Name: "cancelled-workload"},
+		{ID: uuid.New(), ServiceId: serviceId, ProductId: productId, Status: constants.ApiClientStatusActive, Name: test.FailingApiClientName},
+	}
+	oldApiClientId, cancelledApiClientId, failingApiClientId := apiClients[0].ID, apiClients[1].ID, apiClients[2].ID
+
+	server := test.ApiClientMockServer(t, &apiClients)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	keyDir := t.TempDir()
+	existingKeyFile := filepath.Join(keyDir, "existing.key")
+	assert.NoError(t, os.WriteFile(existingKeyFile, nil, 0600))
+	waitArgs := []string{"--activation-delay", "0s", "--poll-interval", "10ms"}
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        append([]string{"-r", serviceId.String(), "-c", oldApiClientId.String(), "--key-output", filepath.Join(keyDir, "payments.key")}, waitArgs...),
+			wantErr:     false,
+			description: "Test rotate an api client",
+		},
+		{
+			args:        append([]string{"-r", serviceId.String(), "-c", failingApiClientId.String(), "--key-output", filepath.Join(keyDir, "failing.key")}, waitArgs...),
+			wantErr:     true,
+			description: "Test rotate an api client which cannot be disabled",
+		},
+		{
+			args:        append([]string{"-r", serviceId.String(), "-c", cancelledApiClientId.String(), "--show-keys"}, waitArgs...),
+			wantErr:     true,
+			description: "Test rotate a cancelled api client",
+		},
+		{
+			args:        append([]string{"-r", serviceId.String(), "-c", uuid.NewString(), "--show-keys"}, waitArgs...),
+			wantErr:     true,
+			description: "Test rotate a missing api client",
+		},
+		{
+			args:        append([]string{"-r", serviceId.String(), "-c", oldApiClientId.String()}, waitArgs...),
+			wantErr:     true,
+			description: "Test rotate without key output nor --show-keys",
+		},
+		{
+			args:        append([]string{"-r", serviceId.String(), "-c", oldApiClientId.String(), "--key-output", existingKeyFile}, waitArgs...),
+			wantErr:     true,
+			description: "Test rotate with an existing key output file",
+		},
+		{
+			args:        []string{"-r", serviceId.String(), "-c", oldApiClientId.String(), "--old-status", constants.ApiClientStatusActive},
+			wantErr:     true,
+			description: "Test rotate with an invalid status for the replaced api client",
+		},
+		{
+			args:        []string{"-r", serviceId.String(), "-c", oldApiClientId.String(), "-n", "@@@"},
+			wantErr:     true,
+			description: "Test rotate with an invalid name",
+		},
+		{
+			args:        []string{"-r", serviceId.String(), "-c", oldApiClientId.String(), "--attestation-url", "http://api.example.com"},
+			wantErr:     true,
+			description: "Test rotate with an attestation URL which is not https",
+		},
+		{
+			args:        []string{"-r", "invalid-id", "-c", oldApiClientId.String()},
+			wantErr:     true,
+			description: "Test rotate with an invalid service id",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, apiClientRotateCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.ApiClientCmd, constants.RotateCmd}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, apiClientRotateCmd)
+
+	// the replacement has the same product, policies and tags and the replaced api client is disabled
+	if assert.Len(t, apiClients, 4) {
+		assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusInactive), apiClients[0].Status)
+		assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusActive), apiClients[2].Status)
+		replacement := apiClients[3]
+		assert.True(t, strings.HasPrefix(replacement.Name, "payments-workload-"))
+		assert.Equal(t, productId, replacement.ProductId)
+		assert.Equal(t, policyIds, replacement.PolicyIds)
+		assert.Equal(t, tags, replacement.TagsValues)
+		keys, err := os.ReadFile(filepath.Join(keyDir, "payments.key"))
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Join(replacement.Keys, "\n")+"\n", string(keys))
+		info, err := os.Stat(filepath.Join(keyDir, "payments.key"))
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	// a failed rotation deletes the replacement and its keys
+	assert.NoFileExists(t, filepath.Join(keyDir, "failing.key"))
+
+	// keys are printed in full with --show-keys
+	resetFlagsForTests(t, apiClientRotateCmd)
+	defer resetFlagsForTests(t, apiClientRotateCmd)
+	for name, value := range map[string]string{
+		constants.ServiceIdParamName:       serviceId.String(),
+		constants.ApiClientIdParamName:     apiClients[3].ID.String(),
+		constants.ApiClientNameParamName:   "payments-workload-v3",
+		constants.OldStatusParamName:       constants.ApiClientStatusCancelled,
+		constants.ActivationDelayParamName: "0s",
+		constants.PollIntervalParamName:    "10ms",
+		constants.ShowKeysParamName:        "true",
+	} {
+		assert.NoError(t, apiClientRotateCmd.Flags().Set(name, value))
+	}
+	response, err := rotateApiClient(apiClientRotateCmd)
+	assert.NoError(t, err)
+	var rotation models2.ApiClientRotation
+	assert.NoError(t, json.Unmarshal([]byte(response), &rotation))
+	assert.Equal(t, apiClients[3].ID, rotation.OldApiClientId)
+	assert.Equal(t, constants.ApiClientStatusCancelled, rotation.OldStatus)
+	if assert.Len(t, rotation.Keys, 2) {
+		assert.Equal(t, apiClients[4].Keys[0], rotation.Keys[0])
+	}
+	assert.Equal(t, "payments-workload-v3", rotation.NewApiClientName)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusCancelled), apiClients[3].Status)
+}
+
+func TestWaitForApiClientActivation(t *testing.T) {
+	serviceId := uuid.New()
+	apiClients := []models.ApiClientDetail{
+		{ID: uuid.New(), ServiceId: serviceId, Status: constants.ApiClientStatusActive, Name: "active", Keys: []string{"active-key"}},
+		{ID: uuid.New(), ServiceId: serviceId, Status: constants.ApiClientStatusInactive, Name: "inactive", Keys: []string{"inactive-key"}},
+	}
+	server := test.ApiClientMockServer(t, &apiClients)
+	defer server.Close()
+
+	tmsUrl, err := url.Parse(server.URL + constants.TmsBaseUrl)
+	assert.NoError(t, err)
+	client := &http.Client{Timeout: time.Second}
+	tmsClient := tms.NewTmsClient(client, tmsUrl, "")
+
+	// the key is polled on the attestation API until accepted
+	assert.NoError(t, waitForApiClientActivation(tmsClient, client, serviceId, apiClients[0].ID, "active-key", server.URL,
+		time.Hour, time.Second, 10*time.Millisecond))
+	assert.Error(t, waitForApiClientActivation(tmsClient, client, serviceId, apiClients[0].ID, "unknown-key", server.URL,
+		0, 50*time.Millisecond, 10*time.Millisecond))
+	assert.Error(t, waitForApiClientActivation(tmsClient, client, serviceId, apiClients[1].ID, "inactive-key", "",
+		0, 50*time.Millisecond, 10*time.Millisecond))
+
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	assert.Equal(t, "payments-20240501103000", rotatedApiClientName("payments", now))
+	assert.Equal(t, "payments-20240501103000", rotatedApiClientName("payments-20240101000000", now))
+	assert.Len(t, rotatedApiClientName(strings.Repeat("a", 64), now), constants.MaxApiClientNameLength)
+}
+
+// useServerForTests points the CLI at the server for the duration of the test. The tests sharing the configuration file
+// expect it to name a mock server which is left running, so such a server is started when the file is not written yet.
+func useServerForTests(t *testing.T, serverUrl string) {
+	if fileInfo, err := tempConfigFile.Stat(); err == nil && fileInfo.Size() == 0 {
+		test.SetupMockConfiguration(test.MockServer(t).URL, tempConfigFile)
+	}
+	load, err := config.LoadConfiguration()
+	assert.NoError(t, err)
+	viper.Set("trustauthority-url", serverUrl)
+	t.Cleanup(func() {
+		viper.Set("trustauthority-url", load.TrustAuthorityBaseUrl)
+	})
+}
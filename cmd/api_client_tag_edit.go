@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// apiClientSetTagCmd represents the apiClient set-tag command
+var apiClientSetTagCmd = &cobra.Command{
+	Use:   constants.SetTagCmd,
+	Short: "Set the values of tags of an api client, keeping its other tags",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apiClient set-tag called")
+		response, err := setApiClientTags(cmd)
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("ApiClient: \n\n", response)
+		return nil
+	},
+}
+
+// apiClientUnsetTagCmd represents the apiClient unset-tag command
+var apiClientUnsetTagCmd = &cobra.Command{
+	Use:   constants.UnsetTagCmd,
+	Short: "Remove tags from an api client, keeping its other tags",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apiClient unset-tag called")
+		response, err := unsetApiClientTags(cmd)
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("ApiClient: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	apiClientCmd.AddCommand(apiClientSetTagCmd)
+	apiClientCmd.AddCommand(apiClientUnsetTagCmd)
+
+	addApiClientEditFlags(apiClientSetTagCmd)
+	apiClientSetTagCmd.Flags().StringSliceP(constants.TagKeyAndValuesParamName, "v", []string{}, "List of the comma separated tag Id and value pairs "+
+		"in the following format:\n Workload:WorkloadAI,Workload:WorkloadEXE etc. The values replace the current values of the tags")
+	apiClientSetTagCmd.MarkFlagRequired(constants.TagKeyAndValuesParamName)
+
+	addApiClientEditFlags(apiClientUnsetTagCmd)
+	apiClientUnsetTagCmd.Flags().StringSliceP(constants.TagKeyAndValuesParamName, "v", []string{}, "List of the comma separated tag Ids, "+
+		"removing every value of the tag, or tag Id and value pairs, removing a single value, e.g. Workload,Power:High")
+	apiClientUnsetTagCmd.MarkFlagRequired(constants.TagKeyAndValuesParamName)
+}
+
+func setApiClientTags(cmd *cobra.Command) (string, error) {
+	tagKeyValuesString, err := cmd.Flags().GetStringSlice(constants.TagKeyAndValuesParamName)
+	if err != nil {
+		return "", err
+	}
+	if len(tagKeyValuesString) == 0 {
+		return "", errors.New("At least one tag Id value pair should be provided")
+	}
+
+	keys, values, err := parseTagValues(tagKeyValuesString)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := runApiClientEdit(cmd, setTagValues(keys, values))
+	if err != nil {
+		return "", err
+	}
+	return formatApiClientEdit(result)
+}
+
+func unsetApiClientTags(cmd *cobra.Command) (string, error) {
+	tagKeyValuesString, err := cmd.Flags().GetStringSlice(constants.TagKeyAndValuesParamName)
+	if err != nil {
+		return "", err
+	}
+	if len(tagKeyValuesString) == 0 {
+		return "", errors.New("At least one tag Id should be provided")
+	}
+
+	var removed []models.ApiClientTagValue
+	for _, tagIdValue := range tagKeyValuesString {
+		key, value, hasValue := strings.Cut(tagIdValue, ":")
+		if err = validation.ValidateTagName(key); err != nil {
+			return "", err
+		}
+		if hasValue {
+			if err = validation.ValidateTagValue(value); err != nil {
+				return "", err
+			}
+		}
+		removed = append(removed, models.ApiClientTagValue{Name: key, Value: value})
+	}
+
+	result, err := runApiClientEdit(cmd, func(apiClient *models.ApiClientDetail) (bool, error) {
+		var tags []models.ApiClientTagValue
+		for _, tag := range apiClient.TagsValues {
+			keep := true
+			for _, r := range removed {
+				if tag.Name == r.Name && (r.Value == "" || tag.Value == r.Value) {
+					keep = false
+				}
+			}
+			if keep {
+				tags = append(tags, tag)
+			}
+		}
+		changed := len(tags) != len(apiClient.TagsValues)
+		apiClient.TagsValues = tags
+		return changed, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return formatApiClientEdit(result)
+}
+
+// parseTagValues parses tag Id and value pairs into the values of every tag, keys listing the tags in order
+func parseTagValues(tagKeyValuesString []string) ([]string, map[string][]string, error) {
+	values := map[string][]string{}
+	var keys []string
+	for _, tagIdValue := range tagKeyValuesString {
+		splitTag := strings.Split(tagIdValue, ":")
+		if len(splitTag) != 2 {
+			return nil, nil, errors.New("Tag Id value pairs are not provided in proper format, please check help section for more details")
+		}
+		if err := validation.ValidateTagName(splitTag[0]); err != nil {
+			return nil, nil, err
+		}
+		if err := validation.ValidateTagValue(splitTag[1]); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := values[splitTag[0]]; !ok {
+			keys = append(keys, splitTag[0])
+		}
+		values[splitTag[0]] = append(values[splitTag[0]], splitTag[1])
+	}
+	return keys, values, nil
+}
+
+// setTagValues returns the edit replacing the values of the tags of an api client, keeping its other tags
+func setTagValues(keys []string, values map[string][]string) apiClientEdit {
+	return func(apiClient *models.ApiClientDetail) (bool, error) {
+		var tags []models.ApiClientTagValue
+		current := map[string][]string{}
+		for _, tag := range apiClient.TagsValues {
+			if _, ok := values[tag.Name]; ok {
+				current[tag.Name] = append(current[tag.Name], tag.Value)
+			} else {
+				tags = append(tags, tag)
+			}
+		}
+		changed := false
+		for _, key := range keys {
+			if strings.Join(current[key], ",") != strings.Join(values[key], ",") {
+				changed = true
+			}
+			for _, value := range values[key] {
+				tags = append(tags, models.ApiClientTagValue{Name: key, Value: value})
+			}
+		}
+		apiClient.TagsValues = tags
+		return changed, nil
+	}
+}
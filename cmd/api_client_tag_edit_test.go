@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"testing"
+)
+
+func TestApiClientTagEditCmd(t *testing.T) {
+	serviceId := uuid.New()
+	policyIds := []uuid.UUID{uuid.New()}
+	apiClients := []models.ApiClientDetail{
+		{ID: uuid.New(), ServiceId: serviceId, ProductId: uuid.New(), Status: constants.ApiClientStatusActive, Name: "payments-workload",
+			PolicyIds: policyIds, TagsValues: []models.ApiClientTagValue{{Name: "Workload", Value: "Payments"}, {Name: "Power", Value: "High"}}},
+	}
+	apiClientId := apiClients[0].ID
+	server := test.ApiClientMockServer(t, &apiClients)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		tags        []models.ApiClientTagValue
+		description string
+	}{
+		{
+			args:        []string{constants.SetTagCmd, "-v", "Workload:Billing,Workload:Invoicing,Region:Europe"},
+			wantErr:     false,
+			tags:        []models.ApiClientTagValue{{Name: "Power", Value: "High"}, {Name: "Workload", Value: "Billing"}, {Name: "Workload", Value: "Invoicing"}, {Name: "Region", Value: "Europe"}},
+			description: "Test set tags of an api client",
+		},
+		{
+			args:        []string{constants.UnsetTagCmd, "-v", "Workload:Billing,Power"},
+			wantErr:     false,
+			tags:        []models.ApiClientTagValue{{Name: "Workload", Value: "Invoicing"}, {Name: "Region", Value: "Europe"}},
+			description: "Test unset a tag and a single value of a tag",
+		},
+		{
+			args:        []string{constants.UnsetTagCmd, "-v", "Unknown"},
+			wantErr:     false,
+			tags:        []models.ApiClientTagValue{{Name: "Workload", Value: "Invoicing"}, {Name: "Region", Value: "Europe"}},
+			description: "Test unset a tag the api client does not have",
+		},
+		{
+			args:        []string{constants.SetTagCmd, "-v", "Workload"},
+			wantErr:     true,
+			tags:        []models.ApiClientTagValue{{Name: "Workload", Value: "Invoicing"}, {Name: "Region", Value: "Europe"}},
+			description: "Test set a tag without a value",
+		},
+		{
+			args:        []string{constants.SetTagCmd, "-v", "Work load:Payments"},
+			wantErr:     true,
+			tags:        []models.ApiClientTagValue{{Name: "Workload", Value: "Invoicing"}, {Name: "Region", Value: "Europe"}},
+			description: "Test set a tag with an invalid name",
+		},
+		{
+			args:        []string{constants.UnsetTagCmd},
+			wantErr:     true,
+			tags:        []models.ApiClientTagValue{{Name: "Workload", Value: "Invoicing"}, {Name: "Region", Value: "Europe"}},
+			description: "Test unset without tags",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, apiClientSetTagCmd)
+		resetFlagsForTests(t, apiClientUnsetTagCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.ApiClientCmd}, append(tc.args, "-r", serviceId.String(), "-c", apiClientId.String())...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+		assert.Equal(t, tc.tags, apiClients[0].TagsValues, tc.description)
+		assert.Equal(t, policyIds, apiClients[0].PolicyIds, tc.description)
+	}
+	resetFlagsForTests(t, apiClientSetTagCmd)
+	resetFlagsForTests(t, apiClientUnsetTagCmd)
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/keysink"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApiClientKeySinks(t *testing.T) {
+	dir := t.TempDir()
+	apiClient := &models.ApiClientDetail{ID: uuid.New(), Name: "Payments_Workload", Keys: []string{"9dca50986c414304", "996a9a6e67814f17"}}
+
+	// plain paths and file sinks write the keys one per line to a new file only readable by the current user
+	for _, spec := range []string{filepath.Join(dir, "plain.key"), "file:" + filepath.Join(dir, "file.key")} {
+		sink, err := keysink.Parse(spec)
+		assert.NoError(t, err)
+		assert.NoError(t, sink.Write(apiClient))
+		path := strings.TrimPrefix(spec, "file:")
+		keys, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "9dca50986c414304\n996a9a6e67814f17\n", string(keys))
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		assert.NoError(t, sink.Revert())
+		assert.NoFileExists(t, path)
+	}
+	existing := filepath.Join(dir, "existing.key")
+	assert.NoError(t, os.WriteFile(existing, nil, 0600))
+	_, err := keysink.Parse(existing)
+	assert.Error(t, err)
+	_, err = keysink.Parse("k8s-secret:" + existing)
+	assert.Error(t, err)
+	_, err = keysink.Parse("exec:  ")
+	assert.Error(t, err)
+	_, err = keysink.Parse("dotenv:")
+	assert.Error(t, err)
+
+	// the Kubernetes Secret is named after the api client
+	sink, err := keysink.Parse("k8s-secret:" + filepath.Join(dir, "secret.yaml"))
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Write(apiClient))
+	manifest, err := os.ReadFile(filepath.Join(dir, "secret.yaml"))
+	assert.NoError(t, err)
+	var secret struct {
+		Kind     string `yaml:"kind"`
+		Metadata struct {
+			Name        string            `yaml:"name"`
+			Annotations map[string]string `yaml:"annotations"`
+		} `yaml:"metadata"`
+		Data map[string]string `yaml:"data"`
+	}
+	assert.NoError(t, yaml.Unmarshal(manifest, &secret))
+	assert.Equal(t, "Secret", secret.Kind)
+	assert.Equal(t, "trustauthority-payments-workload", secret.Metadata.Name)
+	assert.Equal(t, apiClient.ID.String(), secret.Metadata.Annotations[constants.KubernetesApiClientIdAnnotation])
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("996a9a6e67814f17")), secret.Data["api-key-2"])
+
+	// the keys replace the variables of an existing dotenv file, which is restored on revert
+	dotenv := filepath.Join(dir, "workload.env")
+	previous := "LOG_LEVEL=debug\nTRUSTAUTHORITY_API_KEY=\"old\"\n"
+	assert.NoError(t, os.WriteFile(dotenv, []byte(previous), 0600))
+	sink, err = keysink.Parse("dotenv:" + dotenv)
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Write(apiClient))
+	content, err := os.ReadFile(dotenv)
+	assert.NoError(t, err)
+	assert.Equal(t, "LOG_LEVEL=debug\nTRUSTAUTHORITY_API_KEY=\"9dca50986c414304\"\nTRUSTAUTHORITY_API_KEY_2=\"996a9a6e67814f17\"\n", string(content))
+	assert.NoError(t, sink.Revert())
+	content, err = os.ReadFile(dotenv)
+	assert.NoError(t, err)
+	assert.Equal(t, previous, string(content))
+
+	// commands receive the keys on stdin along with the api client in the environment
+	script := filepath.Join(dir, "store-keys")
+	assert.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n{ echo \"$TRUSTAUTHORITY_API_CLIENT_NAME\"; cat; } > \"$1\"\n"), 0700))
+	sink, err = keysink.Parse("exec:" + script + " " + filepath.Join(dir, "stored.txt"))
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Write(apiClient))
+	content, err = os.ReadFile(filepath.Join(dir, "stored.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Payments_Workload\n9dca50986c414304\n996a9a6e67814f17\n", string(content))
+	assert.Error(t, sink.Revert())
+	sink, err = keysink.Parse("exec:false")
+	assert.NoError(t, err)
+	assert.Error(t, sink.Write(apiClient))
+
+	assert.Equal(t, "9dca************", keysink.Mask("9dca50986c414304"))
+	assert.Equal(t, "***", keysink.Mask("abc"))
+}
+
+func TestApiClientKeyOutput(t *testing.T) {
+	server := test.MockServer(t)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	dir := t.TempDir()
+	createArgs := []string{constants.CreateCmd, constants.ApiClientCmd, "-n", "Test_Subs", "-p", "e169d34f-58ce-4717-9b3a-5c66abd33417",
+		"-r", "5cfb6af4-59ac-4a14-8b83-bd65b1e11777"}
+	listArgs := []string{constants.ListCmd, constants.ApiClientCmd, "-r", "5cfb6af4-59ac-4a14-8b83-bd65b1e11777"}
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        append(createArgs, "--key-output", filepath.Join(dir, "created.key")),
+			wantErr:     false,
+			description: "Test create an api client with the keys written to a file",
+		},
+		{
+			args:        append(createArgs, "--key-output", filepath.Join(dir, "created.key")),
+			wantErr:     true,
+			description: "Test create an api client with an existing key file",
+		},
+		{
+			args:        append(listArgs, "-c", "3780cc39-cce2-4ec2-a47f-03e55b12e259", "--key-output", "dotenv:"+filepath.Join(dir, "workload.env")),
+			wantErr:     false,
+			description: "Test get an api client with the keys written to a dotenv file",
+		},
+		{
+			args:        append(listArgs, "--key-output", filepath.Join(dir, "listed.key")),
+			wantErr:     true,
+			description: "Test list api clients with a key output",
+		},
+	}
+
+	createCmd.AddCommand(createApiClientCmd)
+	listCmd.AddCommand(getApiClientsCmd)
+	tenantCmd.AddCommand(createCmd)
+	tenantCmd.AddCommand(listCmd)
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, createApiClientCmd)
+		resetFlagsForTests(t, getApiClientsCmd)
+		_, err := execute(t, tenantCmd, tc.args)
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	// the error of an api client created without its keys written tells how to fetch them again
+	resetFlagsForTests(t, createApiClientCmd)
+	_, err := execute(t, tenantCmd, append(createArgs, "--key-output", "exec:false"))
+	assert.ErrorContains(t, err, "\"list apiClient -r 5cfb6af4-59ac-4a14-8b83-bd65b1e11777 -c ")
+	assert.ErrorContains(t, err, " --key-output <key output>\"")
+	resetFlagsForTests(t, createApiClientCmd)
+	resetFlagsForTests(t, getApiClientsCmd)
+	assert.FileExists(t, filepath.Join(dir, "created.key"))
+	assert.FileExists(t, filepath.Join(dir, "workload.env"))
+	assert.NoFileExists(t, filepath.Join(dir, "listed.key"))
+
+	// keys are masked unless requested in full
+	getApiClient := func(showKeys bool) models.ApiClientDetail {
+		resetFlagsForTests(t, getApiClientsCmd)
+		defer resetFlagsForTests(t, getApiClientsCmd)
+		assert.NoError(t, getApiClientsCmd.Flags().Set(constants.ServiceIdParamName, "5cfb6af4-59ac-4a14-8b83-bd65b1e11777"))
+		assert.NoError(t, getApiClientsCmd.Flags().Set(constants.ApiClientIdParamName, "3780cc39-cce2-4ec2-a47f-03e55b12e259"))
+		if showKeys {
+			assert.NoError(t, getApiClientsCmd.Flags().Set(constants.ShowKeysParamName, "true"))
+		}
+		response, err := getApiClients(getApiClientsCmd)
+		assert.NoError(t, err)
+		var apiClient models.ApiClientDetail
+		assert.NoError(t, json.Unmarshal([]byte(response), &apiClient))
+		return apiClient
+	}
+	assert.Equal(t, []string{"9dca****************************", "996a****************************"}, getApiClient(false).Keys)
+	assert.Equal(t, []string{"9dca50986c414304a4b1ffe202dcf2b0", "996a9a6e67814f1784eadb5405bdabf3"}, getApiClient(true).Keys)
+}
@@ -0,0 +1,426 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/manifest"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   constants.ApplyCmd,
+	Short: "Make the tenant match YAML manifests of tags, policies, users, tenant settings and api clients",
+	Long: `Compare YAML manifests of tags, policies, users, tenant settings and api clients with the tenant, print the
+create, update and delete actions making the tenant match the manifests and execute them. Tags and policies are
+created before the api clients referencing them by name, and deleted after them.
+With --prune, the resources of the kinds described by the manifests which are not in the manifests are deleted,
+except for policies and tags still referenced by api clients of other services. A plan deleting resources is only executed
+once confirmed, or with --yes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("apply called")
+		response, err := applyManifests(cmd)
+		utils.PrintRequestAndTraceId()
+		if response != "" {
+			fmt.Println("Apply: \n\n", response)
+		}
+		return err
+	},
+}
+
+func init() {
+	tenantCmd.AddCommand(applyCmd)
+
+	addManifestFlags(applyCmd)
+	applyCmd.Flags().Bool(constants.DryRunParamName, false, "Print the plan without executing it")
+	applyCmd.Flags().Bool(constants.YesParamName, false, "Execute a plan deleting resources without asking for confirmation")
+}
+
+// addManifestFlags adds the flags selecting the manifests and how they are compared with the tenant
+func addManifestFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceP(constants.ManifestParamName, "f", nil, "Manifest file, or directory of .yaml and .yml "+
+		"manifest files, describing the tenant. Can be repeated.")
+	cmd.Flags().Bool(constants.PruneParamName, false, "Delete the resources of the kinds described by the manifests "+
+		"which are not in the manifests")
+	cmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	cmd.MarkFlagRequired(constants.ManifestParamName)
+}
+
+func applyManifests(cmd *cobra.Command) (string, error) {
+	plan, tmsClient, pmsClient, err := planManifests(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	dryRun, err := cmd.Flags().GetBool(constants.DryRunParamName)
+	if err != nil {
+		return "", err
+	}
+	if dryRun || len(plan.Actions) == 0 {
+		return plan.String(), nil
+	}
+
+	deletes := 0
+	for _, action := range plan.Actions {
+		if action.Operation == constants.PlanOperationDelete {
+			deletes++
+		}
+	}
+	if deletes > 0 {
+		if err = confirmChanges(cmd, constants.YesParamName, plan.String(),
+			fmt.Sprintf("Execute the plan, deleting %d resources?", deletes)); err != nil {
+			return "", err
+		}
+	}
+
+	policyIds, err := manifestPolicyIds(pmsClient)
+	if err != nil {
+		return "", err
+	}
+	for i := range plan.Actions {
+		action := &plan.Actions[i]
+		if err = applyAction(tmsClient, pmsClient, action, policyIds); err != nil {
+			return createdApiClients(plan.Actions[:i]), errors.Wrapf(err, "Error applying the %s of %s %s, %d of %d actions were applied",
+				action.Operation, action.Kind, action.Name, i, len(plan.Actions))
+		}
+		log.Infof("Applied the %s of %s %s", action.Operation, action.Kind, action.Name)
+	}
+	return plan.String() + fmt.Sprintf("\n\n%d actions applied", len(plan.Actions)) + createdApiClients(plan.Actions), nil
+}
+
+// createdApiClients lists the api clients created by the actions applied along with the command fetching their
+// attestation API keys, which are not printed
+func createdApiClients(actions []manifest.Action) string {
+	var builder strings.Builder
+	for _, action := range actions {
+		if action.Kind == constants.ManifestKindApiClient && action.Operation == constants.PlanOperationCreate {
+			fmt.Fprintf(&builder, "\n%s/%s: %s", action.ApiClient.Service, action.Name,
+				fetchApiClientKeysCommand(action.ApiClient.ServiceId, action.Id))
+		}
+	}
+	if builder.Len() == 0 {
+		return ""
+	}
+	return "\n\nApi clients created, their attestation API keys can be fetched with:\n" + builder.String()
+}
+
+// planManifests loads the manifests of the command flags and compares them with the tenant
+func planManifests(cmd *cobra.Command) (*manifest.Plan, tms.TmsClient, pms.PmsClient, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return nil, nil, nil, err
+	}
+
+	paths, err := cmd.Flags().GetStringSlice(constants.ManifestParamName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	prune, err := cmd.Flags().GetBool(constants.PruneParamName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	m, err := manifest.Load(paths)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	pmsClient := pms.NewPmsClient(client, pmsUrl, apiKey)
+	state, err := fetchManifestState(tmsClient, pmsClient, m, prune)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	plan, err := manifest.NewPlan(m, state, prune)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return plan, tmsClient, pmsClient, nil
+}
+
+// fetchManifestState fetches the live state of the tenant the manifests are compared against. Users and tenant
+// settings are only fetched when described by the manifests, api clients and products only for the services the
+// manifests reference, and the api clients of every service referencing the policies or tags only when policies or
+// tags are pruned.
+func fetchManifestState(tmsClient tms.TmsClient, pmsClient pms.PmsClient, m *manifest.Manifest, prune bool) (*manifest.State, error) {
+	state := &manifest.State{Products: map[uuid.UUID][]models.Product{}}
+
+	tags, err := tmsClient.GetTenantTags()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the tags")
+	}
+	state.Tags = tags.Tags
+
+	if state.Policies, err = pmsClient.SearchPolicy(); err != nil {
+		return nil, errors.Wrap(err, "Error fetching the policies")
+	}
+	if state.ServiceOffers, err = tmsClient.GetServiceOffers(); err != nil {
+		return nil, errors.Wrap(err, "Error fetching the service offers")
+	}
+
+	// the policies referenced by api clients are not pruned, as done by "delete policy"
+	if prune && len(m.Policies) > 0 {
+		if state.PolicyUsage, err = findPoliciesUsage(tmsClient); err != nil {
+			return nil, errors.Wrap(err, "Error checking whether the policies are referenced by API clients")
+		}
+	}
+	if prune && len(m.Tags) > 0 {
+		if state.TagUsage, err = findTagsUsage(tmsClient); err != nil {
+			return nil, errors.Wrap(err, "Error checking whether the tags are carried by API clients")
+		}
+	}
+	if len(m.Users) > 0 {
+		if state.Users, err = tmsClient.GetUsers(); err != nil {
+			return nil, errors.Wrap(err, "Error fetching the users")
+		}
+	}
+	if m.TenantSettings != nil {
+		if state.Settings, err = tmsClient.GetTenantSettings(); err != nil {
+			return nil, errors.Wrap(err, "Error fetching the tenant settings")
+		}
+	}
+
+	if len(m.ApiClients) == 0 {
+		return state, nil
+	}
+	if state.Services, err = tmsClient.GetServices(); err != nil {
+		return nil, errors.Wrap(err, "Error fetching the services")
+	}
+	for _, service := range m.ServiceReferences(state.Services) {
+		if _, ok := state.Products[service.ServiceOfferId]; !ok {
+			products, err := tmsClient.GetProducts(service.ServiceOfferId)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error fetching the products of service %s", service.Name)
+			}
+			state.Products[service.ServiceOfferId] = products
+		}
+
+		apiClients, err := tmsClient.GetApiClient(service.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error fetching the api clients of service %s", service.Name)
+		}
+		for _, apiClient := range apiClients {
+			detail, err := tmsClient.RetrieveApiClient(service.ID, apiClient.ID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error fetching api client %s", apiClient.ID)
+			}
+			detail.ServiceId = service.ID
+			state.ApiClients = append(state.ApiClients, *detail)
+		}
+	}
+	return state, nil
+}
+
+// findTagsUsage lists the api clients of every service carrying each tag, by tag name
+func findTagsUsage(tmsClient tms.TmsClient) (map[string][]models2.PolicyUsage, error) {
+	services, err := tmsClient.GetServices()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the services of the tenant")
+	}
+
+	usage := map[string][]models2.PolicyUsage{}
+	for _, service := range services {
+		apiClients, err := tmsClient.GetApiClient(service.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error fetching the API clients of service %s", service.ID)
+		}
+		for _, apiClient := range apiClients {
+			tags, err := tmsClient.GetApiClientTagValues(service.ID, apiClient.ID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error fetching the tags of API client %s", apiClient.ID)
+			}
+			for _, tag := range tags.TagsValues {
+				usage[tag.Name] = append(usage[tag.Name], models2.PolicyUsage{
+					ServiceId:     service.ID,
+					ServiceName:   service.Name,
+					ApiClientId:   apiClient.ID,
+					ApiClientName: apiClient.Name,
+					Status:        string(apiClient.Status),
+				})
+			}
+		}
+	}
+	return usage, nil
+}
+
+// manifestPolicyIds maps the names of the policies of the tenant to their ids, so that api clients can reference
+// policies by name
+func manifestPolicyIds(pmsClient pms.PmsClient) (map[string]uuid.UUID, error) {
+	policies, err := pmsClient.SearchPolicy()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the policies")
+	}
+	policyIds := map[string]uuid.UUID{}
+	for _, policy := range policies {
+		policyIds[policy.PolicyName] = policy.PolicyId
+	}
+	return policyIds, nil
+}
+
+// applyAction executes an action of the plan. policyIds is updated with the policies created so that the api clients
+// applied afterwards can reference them.
+func applyAction(tmsClient tms.TmsClient, pmsClient pms.PmsClient, action *manifest.Action, policyIds map[string]uuid.UUID) error {
+	switch action.Kind {
+	case constants.ManifestKindTag:
+		if action.Operation == constants.PlanOperationDelete {
+			return tmsClient.DeleteTenantTag(action.Id)
+		}
+		_, err := tmsClient.CreateTenantTag(&models.TagCreate{Name: action.Tag})
+		return err
+
+	case constants.ManifestKindPolicy:
+		return applyPolicyAction(pmsClient, action, policyIds)
+
+	case constants.ManifestKindUser:
+		switch action.Operation {
+		case constants.PlanOperationCreate:
+			_, err := tmsClient.CreateUser(&models.CreateTenantUser{Email: action.User.Email, Role: action.User.Role})
+			return err
+		case constants.PlanOperationUpdate:
+			_, err := tmsClient.UpdateTenantUserRole(&models.UpdateTenantUserRoles{UserId: action.Id, Role: action.User.Role})
+			return err
+		}
+		return tmsClient.DeleteUser(action.Id)
+
+	case constants.ManifestKindSettings:
+		_, err := tmsClient.UpdateTenantSettings(&models.AttestationFailureEmail{
+			AttestationFailureEmail: action.Settings.AttestationFailureEmail})
+		return err
+
+	case constants.ManifestKindApiClient:
+		return applyApiClientAction(tmsClient, action, policyIds)
+	}
+	return errors.Errorf("Unknown kind %s", action.Kind)
+}
+
+func applyPolicyAction(pmsClient pms.PmsClient, action *manifest.Action, policyIds map[string]uuid.UUID) error {
+	switch action.Operation {
+	case constants.PlanOperationCreate:
+		policy := action.Policy
+		response, err := pmsClient.CreatePolicy(&models.PolicyRequest{CommonPolicy: models.CommonPolicy{
+			Policy:          policy.Policy.Policy,
+			PolicyName:      policy.Name,
+			PolicyType:      policy.PolicyType,
+			ServiceOfferId:  policy.ServiceOfferId,
+			AttestationType: policy.AttestationType,
+		}})
+		if err != nil {
+			return err
+		}
+		recordPolicyHistory(response, constants.HistoryOperationCreate)
+		policyIds[response.PolicyName] = response.PolicyId
+		return nil
+
+	case constants.PlanOperationUpdate:
+		// the previous policy is kept in the local history so that the update can be rolled back
+		if action.Policy.Live != nil {
+			recordPolicyHistory(action.Policy.Live, constants.HistoryOperationPrevious)
+		}
+		response, err := pmsClient.UpdatePolicy(&models.PolicyUpdateRequest{PolicyId: action.Id,
+			Policy: action.Policy.Policy.Policy, PolicyName: action.Name})
+		if err != nil {
+			return err
+		}
+		recordPolicyHistory(response, constants.HistoryOperationUpdate)
+		return nil
+	}
+	if err := pmsClient.DeletePolicy(action.Id); err != nil {
+		return err
+	}
+	delete(policyIds, action.Name)
+	return nil
+}
+
+func applyApiClientAction(tmsClient tms.TmsClient, action *manifest.Action, policyIds map[string]uuid.UUID) error {
+	apiClient := action.ApiClient
+	if action.Operation == constants.PlanOperationDelete {
+		return tmsClient.DeleteApiClient(apiClient.ServiceId, action.Id)
+	}
+
+	var ids []uuid.UUID
+	for _, name := range apiClient.Policies {
+		policyId, ok := policyIds[name]
+		if !ok {
+			return errors.Errorf("Policy %s not found", name)
+		}
+		ids = append(ids, policyId)
+	}
+	var tags []models.ApiClientTagValue
+	for _, tag := range apiClient.Tags {
+		tags = append(tags, models.ApiClientTagValue{Name: tag.Key, Value: tag.Value})
+	}
+
+	if action.Operation == constants.PlanOperationCreate {
+		// the attestation API keys are not printed, the id of the api client is kept to report how to fetch them
+		created, err := tmsClient.CreateApiClient(&models.CreateApiClient{
+			ProductId:    apiClient.ProductId,
+			ServiceId:    apiClient.ServiceId,
+			PolicyIds:    ids,
+			TagIdsValues: apiClientTagIdValues(tags),
+			Name:         apiClient.Name,
+			Status:       models.ApiClientStatus(apiClient.Status),
+		})
+		if err != nil {
+			return err
+		}
+		action.Id = created.ID
+		return nil
+	}
+
+	_, err := editApiClient(tmsClient, apiClient.ServiceId, action.Id, func(live *models.ApiClientDetail) (bool, error) {
+		// the predefined tags of the api client are kept as they are not described by the manifests
+		var desiredTags []models.ApiClientTagValue
+		for _, tag := range live.TagsValues {
+			if tag.Predefined {
+				desiredTags = append(desiredTags, tag)
+			}
+		}
+		desiredTags = append(desiredTags, tags...)
+		desired := copyApiClient(live)
+		desired.ProductId = apiClient.ProductId
+		desired.Status = models.ApiClientStatus(apiClient.Status)
+		desired.PolicyIds = ids
+		desired.TagsValues = desiredTags
+		if sameApiClientState(live, desired) {
+			return false, nil
+		}
+		*live = *desired
+		return true, nil
+	})
+	return err
+}
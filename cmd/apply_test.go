@@ -0,0 +1,281 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const manifestTagsForTests = `apiVersion: trustauthority.intel.com/v1
+kind: Tag
+metadata:
+  name: Workload
+---
+apiVersion: trustauthority.intel.com/v1
+kind: Tag
+metadata:
+  name: Region
+`
+
+const manifestPoliciesForTests = `apiVersion: trustauthority.intel.com/v1
+kind: Policy
+metadata:
+  name: payments-policy
+spec:
+  attestationType: TDX
+  serviceOffer: TDX Attestation
+  policyFile: payments.rego
+`
+
+const manifestApiClientsForTests = `apiVersion: trustauthority.intel.com/v1
+kind: ApiClient
+metadata:
+  name: payments-workload
+spec:
+  service: Production
+  product: Enterprise
+  policies:
+    - payments-policy
+  tags:
+    - key: Workload
+      value: Payments
+---
+apiVersion: trustauthority.intel.com/v1
+kind: ApiClient
+metadata:
+  name: reporting-workload
+spec:
+  service: Production
+  product: Enterprise
+  status: Inactive
+  policies:
+    - shared-policy
+`
+
+const manifestUsersForTests = `apiVersion: trustauthority.intel.com/v1
+kind: User
+metadata:
+  name: alice@example.com
+spec:
+  role: Tenant Admin
+---
+apiVersion: trustauthority.intel.com/v1
+kind: TenantSettings
+spec:
+  attestationFailureEmail: security@example.com
+`
+
+// manifestTenantForTests returns a tenant with a service, a policy and an api client, and the directory of the
+// manifests describing its desired state
+func manifestTenantForTests(t *testing.T) (*test.Tenant, string) {
+	serviceOfferId := uuid.New()
+	predefinedTagId, legacyTagId := uuid.New(), uuid.New()
+	tenant := &test.Tenant{
+		Services:      []models.ServiceDetail{{ID: uuid.New(), ServiceOfferId: serviceOfferId, Name: "Production", Active: true}},
+		ServiceOffers: []models.ServiceOffer{{ID: serviceOfferId, Name: "TDX Attestation"}},
+		Products:      []models.Product{{ID: uuid.New(), ServiceOfferId: serviceOfferId, Name: "Enterprise"}},
+		Policies: []models.PolicyResponse{
+			{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "shared-policy", Policy: "default allow = false",
+				PolicyType: constants.DefaultPolicyType, ServiceOfferId: serviceOfferId, AttestationType: "TDX"}},
+			{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "legacy-policy", Policy: "default allow = false",
+				PolicyType: constants.DefaultPolicyType, ServiceOfferId: serviceOfferId, AttestationType: "TDX"}},
+		},
+		Tags:  []models.Tag{{ID: &predefinedTagId, Name: "Predefined", Predefined: true}, {ID: &legacyTagId, Name: "Legacy"}},
+		Users: []models.TenantUser{{ID: uuid.New(), Email: "bob@example.com", Role: models.Role{Name: constants.UserRole}}},
+	}
+	tenant.ApiClients = []models.ApiClientDetail{
+		{ID: uuid.New(), ServiceId: tenant.Services[0].ID, ProductId: tenant.Products[0].ID, ProductName: "Enterprise",
+			Status: constants.ApiClientStatusActive, Name: "reporting-workload",
+			PolicyIds: []uuid.UUID{tenant.Policies[0].PolicyId}},
+		{ID: uuid.New(), ServiceId: tenant.Services[0].ID, ProductId: tenant.Products[0].ID, ProductName: "Enterprise",
+			Status: constants.ApiClientStatusActive, Name: "legacy-workload",
+			PolicyIds: []uuid.UUID{tenant.Policies[1].PolicyId}},
+	}
+
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "policies"), 0750))
+	for name, content := range map[string]string{
+		"tags.yaml":              manifestTagsForTests,
+		"policies/policies.yaml": manifestPoliciesForTests,
+		"policies/payments.rego": "default allow = true",
+		"api-clients.yml":        manifestApiClientsForTests,
+		"users.yaml":             manifestUsersForTests,
+		"README.md":              "not a manifest",
+	} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	return tenant, dir
+}
+
+func TestApplyCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tenant, dir := manifestTenantForTests(t)
+	server := test.TenantMockServer(t, tenant)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	apply := func(args ...string) error {
+		resetFlagsForTests(t, applyCmd)
+		defer resetFlagsForTests(t, applyCmd)
+		tenantCmd.SetIn(strings.NewReader(""))
+		defer tenantCmd.SetIn(nil)
+		_, err := execute(t, tenantCmd, append([]string{constants.ApplyCmd}, args...))
+		return err
+	}
+
+	// nothing is changed on a dry run
+	assert.NoError(t, apply("-f", dir, "--dry-run"))
+	assert.Len(t, tenant.Policies, 2)
+	assert.Len(t, tenant.ApiClients, 2)
+
+	// the api clients created are listed with the command fetching their keys
+	resetFlagsForTests(t, applyCmd)
+	assert.NoError(t, applyCmd.Flags().Set(constants.ManifestParamName, dir))
+	response, err := applyManifests(applyCmd)
+	assert.NoError(t, err)
+	resetFlagsForTests(t, applyCmd)
+	assert.Len(t, tenant.Tags, 4)
+	assert.Len(t, tenant.Policies, 3)
+	assert.Equal(t, "payments-policy", tenant.Policies[2].PolicyName)
+	assert.Equal(t, "default allow = true", tenant.Policies[2].Policy)
+	assert.Len(t, tenant.Users, 2)
+	assert.Equal(t, "security@example.com", tenant.Settings.AttestationFailureEmail)
+	assert.Len(t, tenant.ApiClients, 3)
+	assert.Equal(t, models.ApiClientStatus(constants.ApiClientStatusInactive), tenant.ApiClients[0].Status)
+	payments := tenant.ApiClients[2]
+	assert.Equal(t, "payments-workload", payments.Name)
+	assert.Equal(t, []uuid.UUID{tenant.Policies[2].PolicyId}, payments.PolicyIds)
+	assert.Equal(t, "Workload", payments.TagsValues[0].Name)
+	assert.Equal(t, "Payments", payments.TagsValues[0].Value)
+	assert.Contains(t, response, "Production/payments-workload: "+fetchApiClientKeysCommand(payments.ServiceId, payments.ID))
+
+	// applying the manifests again changes nothing
+	assert.NoError(t, apply("-f", dir))
+	assert.Len(t, tenant.Policies, 3)
+	assert.Len(t, tenant.ApiClients, 3)
+
+	// a plan deleting resources is only executed once confirmed
+	assert.Error(t, apply("-f", dir, "--prune"))
+	assert.Len(t, tenant.ApiClients, 3)
+	assert.Len(t, tenant.Policies, 3)
+
+	// a policy referenced by the api clients of another service is not deleted
+	otherService := models.ServiceDetail{ID: uuid.New(), ServiceOfferId: tenant.Services[0].ServiceOfferId, Name: "Staging", Active: true}
+	tenant.Services = append(tenant.Services, otherService)
+	tenant.ApiClients = append(tenant.ApiClients, models.ApiClientDetail{ID: uuid.New(), ServiceId: otherService.ID,
+		ProductId: tenant.Products[0].ID, ProductName: "Enterprise", Status: constants.ApiClientStatusActive,
+		Name: "staging-workload", PolicyIds: []uuid.UUID{tenant.Policies[1].PolicyId}})
+	assert.ErrorContains(t, apply("-f", dir, "--prune", "--yes"), "staging-workload (service Staging)")
+	assert.Len(t, tenant.Policies, 3)
+
+	// as is a tag carried by the api clients of another service
+	tenant.ApiClients[3].PolicyIds = nil
+	tenant.ApiClients[3].TagsValues = []models.ApiClientTagValue{{Name: "Legacy", Value: "Staging"}}
+	assert.ErrorContains(t, apply("-f", dir, "--prune", "--yes"), "Tag Legacy is not in the manifests but cannot be pruned")
+	assert.Len(t, tenant.Tags, 4)
+	tenant.Services = tenant.Services[:1]
+	tenant.ApiClients = tenant.ApiClients[:3]
+
+	// the resources which are not in the manifests are deleted, except for predefined tags
+	assert.NoError(t, apply("-f", dir, "--prune", "--yes"))
+	assert.Len(t, tenant.ApiClients, 2)
+	assert.Len(t, tenant.Policies, 2)
+	assert.Len(t, tenant.Users, 1)
+	assert.Equal(t, "alice@example.com", tenant.Users[0].Email)
+	assert.Len(t, tenant.Tags, 3)
+	assert.Equal(t, "Predefined", tenant.Tags[0].Name)
+
+	// the policy is updated when its rego policy changes
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "policies", "payments.rego"), []byte("default allow = false"), 0600))
+	assert.NoError(t, apply("-f", filepath.Join(dir, "policies")))
+	assert.Equal(t, "default allow = false", tenant.Policies[1].Policy)
+	assert.Equal(t, "v2", tenant.Policies[1].Version)
+}
+
+func TestApplyCmdInvalidManifests(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tenant, dir := manifestTenantForTests(t)
+	server := test.TenantMockServer(t, tenant)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	tt := []struct {
+		manifest    string
+		description string
+	}{
+		{
+			manifest:    "apiVersion: v1\nkind: Tag\nmetadata:\n  name: Workload\n",
+			description: "Test apply a manifest of an unsupported api version",
+		},
+		{
+			manifest:    "apiVersion: trustauthority.intel.com/v1\nkind: Service\nmetadata:\n  name: Production\n",
+			description: "Test apply a manifest of an unknown kind",
+		},
+		{
+			manifest:    "apiVersion: trustauthority.intel.com/v1\nkind: User\nmetadata:\n  name: carol@example.com\nspec:\n  role: Admin\n",
+			description: "Test apply a manifest of a user with an invalid role",
+		},
+		{
+			manifest: "apiVersion: trustauthority.intel.com/v1\nkind: User\nmetadata:\n  name: carol@example.com\nspec:\n" +
+				"  role: User\n  group: Finance\n",
+			description: "Test apply a manifest with an unknown field",
+		},
+		{
+			manifest: "apiVersion: trustauthority.intel.com/v1\nkind: ApiClient\nmetadata:\n  name: payments-workload\nspec:\n" +
+				"  service: Production\n  product: Enterprise\n  policies:\n    - missing-policy\n",
+			description: "Test apply a manifest of an api client referencing a missing policy",
+		},
+		{
+			manifest: "apiVersion: trustauthority.intel.com/v1\nkind: ApiClient\nmetadata:\n  name: payments-workload\nspec:\n" +
+				"  service: Staging\n  product: Enterprise\n",
+			description: "Test apply a manifest of an api client of a missing service",
+		},
+		{
+			manifest: "apiVersion: trustauthority.intel.com/v1\nkind: Policy\nmetadata:\n  name: shared-policy\nspec:\n" +
+				"  attestationType: SGX\n  serviceOffer: TDX Attestation\n  policy: default allow = true\n",
+			description: "Test apply a manifest changing the attestation type of a policy",
+		},
+		{
+			manifest:    manifestTagsForTests + "---\n" + manifestTagsForTests,
+			description: "Test apply a manifest describing a tag twice",
+		},
+	}
+
+	for _, tc := range tt {
+		manifestFile := filepath.Join(dir, "invalid.yaml")
+		assert.NoError(t, os.WriteFile(manifestFile, []byte(tc.manifest), 0600))
+		resetFlagsForTests(t, applyCmd)
+		_, err := execute(t, tenantCmd, []string{constants.ApplyCmd, "-f", manifestFile})
+		assert.Error(t, err, tc.description)
+		resetFlagsForTests(t, applyCmd)
+	}
+	assert.Len(t, tenant.Tags, 2)
+	assert.Len(t, tenant.Policies, 2)
+
+	resetFlagsForTests(t, applyCmd)
+	_, err := execute(t, tenantCmd, []string{constants.ApplyCmd, "-f", filepath.Join(dir, "missing.yaml")})
+	assert.Error(t, err, "Test apply a missing manifest")
+	resetFlagsForTests(t, applyCmd)
+
+	// a missing service is reported as not found, as for the get commands
+	manifestFile := filepath.Join(dir, "invalid.yaml")
+	assert.NoError(t, os.WriteFile(manifestFile, []byte("apiVersion: trustauthority.intel.com/v1\nkind: ApiClient\n"+
+		"metadata:\n  name: payments-workload\nspec:\n  service: Staging\n  product: Enterprise\n"), 0600))
+	_, err = execute(t, tenantCmd, []string{constants.ApplyCmd, "-f", manifestFile})
+	var notFound *lookup.NotFoundError
+	assert.True(t, errors.As(err, &notFound))
+	resetFlagsForTests(t, applyCmd)
+}
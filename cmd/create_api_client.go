@@ -55,6 +55,7 @@ func init() {
 	createApiClientCmd.Flags().StringSliceP(constants.TagKeyAndValuesParamName, "v", []string{}, "List of the comma separated tad Id and value pairs in the "+
 		"following format:\n Workload:WorkloadAI,Workload:WorkloadEXE etc.")
 	createApiClientCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	addKeyOutputFlags(createApiClientCmd)
 	createApiClientCmd.MarkFlagRequired(constants.ServiceIdParamName)
 	createApiClientCmd.MarkFlagRequired(constants.ProductIdParamName)
 	createApiClientCmd.MarkFlagRequired(constants.ApiClientNameParamName)
@@ -142,6 +143,11 @@ func createApiClient(cmd *cobra.Command) (string, error) {
 		tagKeyValues = append(tagKeyValues, models.ApiClientTagIdValue{Key: splitTag[0], Value: splitTag[1]})
 	}
 
+	keyOutput, err := apiClientKeyOutput(cmd)
+	if err != nil {
+		return "", err
+	}
+
 	var apiClientInfo = models.CreateApiClient{
 		ProductId:    productId,
 		Name:         apiClientName,
@@ -156,13 +162,17 @@ func createApiClient(cmd *cobra.Command) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if err = deliverApiClientKeys(cmd, keyOutput, response); err != nil {
+		return "", errors.Wrapf(err, "Api client %s was created but its attestation API keys could not be written, "+
+			"they can be fetched again with \"%s\"", response.ID, fetchApiClientKeysCommand(serviceId, response.ID))
+	}
 
 	responseBytes, err := json.MarshalIndent(response, "", "  ")
 	if err != nil {
 		return "", err
 	}
 
-	return string(responseBytes), nil
+	return string(responseBytes) + deprecatedPolicyWarnings(policyIds), nil
 }
 
 func setRequestId(cmd *cobra.Command) error {
@@ -7,7 +7,6 @@ package cmd
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -15,7 +14,6 @@ import (
 	"intel/tac/v1/config"
 	"intel/tac/v1/constants"
 	"intel/tac/v1/models"
-	"intel/tac/v1/utils"
 	"intel/tac/v1/validation"
 	"net/http"
 	"net/url"
@@ -33,12 +31,7 @@ var createPolicyCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log.Info("create policy called")
 		response, err := createPolicy(cmd)
-		utils.PrintRequestAndTraceId()
-		if err != nil {
-			return err
-		}
-		fmt.Println("Policy: \n\n", response)
-		return nil
+		return printUploadedPolicy("Policy: \n\n ", response, err)
 	},
 }
 
@@ -51,6 +44,7 @@ func init() {
 	createPolicyCmd.Flags().StringP(constants.AttestationTypeParamName, "a", "", "Attestation type of policy to be uploaded, example \"SGX Attestation\".")
 	createPolicyCmd.Flags().StringP(constants.PolicyFileParamName, "f", "", "Path of the file containing the rego policy to be uploaded. The file size should be <= 10 KB")
 	createPolicyCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	addPolicySigningFlags(createPolicyCmd)
 	createPolicyCmd.MarkFlagRequired(constants.PolicyNameParamName)
 	createPolicyCmd.MarkFlagRequired(constants.ServiceOfferIdParamName)
 	createPolicyCmd.MarkFlagRequired(constants.AttestationTypeParamName)
@@ -58,20 +52,11 @@ func init() {
 }
 
 func createPolicy(cmd *cobra.Command) (string, error) {
-	configValues, err := config.LoadConfiguration()
-	if err != nil {
+	if _, err := config.LoadConfiguration(); err != nil {
 		return "", err
 	}
-	client := &http.Client{
-		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
-	}
 
-	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
-	if err != nil {
-		return "", err
-	}
-
-	if err = setRequestId(cmd); err != nil {
+	if err := setRequestId(cmd); err != nil {
 		return "", err
 	}
 
@@ -125,24 +110,58 @@ func createPolicy(cmd *cobra.Command) (string, error) {
 		return "", errors.Wrap(err, "Error reading policy file")
 	}
 
-	var policyCreateReq = models.PolicyRequest{models.CommonPolicy{
+	return uploadPolicy(cmd, models.CommonPolicy{
 		Policy:          string(policyBytes),
 		PolicyName:      policyName,
 		PolicyType:      policyType,
 		ServiceOfferId:  soId,
 		AttestationType: attestationType,
-	}}
+	})
+}
+
+// uploadPolicy creates the policy, signing it first when requested by the policy signing flags of the command
+func uploadPolicy(cmd *cobra.Command, commonPolicy models.CommonPolicy) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	policy := commonPolicy.Policy
+	policyToken, algorithm, err := signPolicyForUpload(cmd, policy)
+	if err != nil {
+		return "", err
+	}
+	commonPolicy.Policy = policyToken
+
+	var policyCreateReq = models.PolicyRequest{CommonPolicy: commonPolicy}
 
 	pmsClient := pms.NewPmsClient(client, pmsUrl, apiKey)
 	response, err := pmsClient.CreatePolicy(&policyCreateReq)
 	if err != nil {
 		return "", err
 	}
+	recordPolicyHistory(response, constants.HistoryOperationCreate)
 
 	responseBytes, err := json.MarshalIndent(response, "", "  ")
 	if err != nil {
 		return "", err
 	}
+	if algorithm == "" {
+		return string(responseBytes), nil
+	}
 
-	return string(responseBytes), nil
+	check, checkErr := checkSignedPolicyResponse(policy, policyToken, algorithm, response)
+	checkBytes, err := json.MarshalIndent(check, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes) + "\n\nSignature check: \n\n" + string(checkBytes), checkErr
 }
@@ -7,13 +7,21 @@ package cmd
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
 	"fmt"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"intel/tac/v1/config"
 	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
 	"intel/tac/v1/test"
+	"intel/tac/v1/utils"
 	"os"
 	"strings"
 	"testing"
@@ -36,6 +44,7 @@ func init() {
 	viper.AddConfigPath(tempDir)
 }
 func TestCreatePolicyCommandWithInvalidUrl(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	test.SetupMockConfiguration("invalid url", tempConfigFile)
 	load, err := config.LoadConfiguration()
 	assert.NoError(t, err)
@@ -78,6 +87,7 @@ func TestCreatePolicyCommandWithInvalidUrl(t *testing.T) {
 }
 
 func TestCreatePolicyCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	server := test.MockServer(t)
 	test.SetupMockConfiguration(server.URL, tempConfigFile)
 	GenerateInvalidPolicyFile(t, tempPolicyFile)
@@ -153,6 +163,178 @@ func TestCreatePolicyCmd(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCreateSignedPolicyCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := test.MockServer(t)
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+
+	signingArgs := []string{"--" + constants.SignObjectParamName, "--" + constants.PrivateKeyFileParamName, "../test/resources/signing/leaf-encrypted.key",
+		"--" + constants.CertificateFileParamName, "../test/resources/signing/chain.pem", "--" + constants.PassphraseFileParamName, "../test/resources/signing/passphrase.txt"}
+	createArgs := func(policyName string, extraArgs ...string) []string {
+		return append([]string{constants.CreateCmd, constants.PolicyCmd, "-n", policyName, "-t", "Appraisal policy",
+			"-r", "e8a72b7e-c4b1-4bdc-bf40-68f23c68a2aa", "-a", "SGX Attestation", "-f", "../test/resources/rego-policy.txt"}, extraArgs...)
+	}
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        createArgs("Signed_Policy_SGX", signingArgs...),
+			description: "Test Create Policy signed by the tenant",
+		},
+		{
+			args:        createArgs(test.TamperedPolicyName, signingArgs...),
+			wantErr:     true,
+			description: "Test Create Policy with policy hash mismatch",
+		},
+		{
+			args: createArgs("Signed_Policy_SGX", "--"+constants.SignObjectParamName, "--"+constants.PrivateKeyFileParamName, "../test/resources/signing/leaf-encrypted.key",
+				"--"+constants.PassphraseFileParamName, "../test/resources/signing/passphrase.txt"),
+			wantErr:     true,
+			description: "Test Create Policy signing without certificate",
+		},
+		{
+			args:        createArgs("Signed_Policy_SGX", "--"+constants.SignObjectParamName),
+			wantErr:     true,
+			description: "Test Create Policy signing without signer",
+		},
+	}
+
+	createCmd.AddCommand(createPolicyCmd)
+	tenantCmd.AddCommand(createCmd)
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, createPolicyCmd)
+		_, err := execute(t, tenantCmd, tc.args)
+		if tc.wantErr {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, createPolicyCmd)
+}
+
+func TestCheckSignedPolicyResponse(t *testing.T) {
+	privKey, certChain, err := utils.CheckKeyFiles("../test/resources/signing/leaf.p12", "", utils.NewPassphraseSource("../test/resources/signing/passphrase.txt"))
+	assert.NoError(t, err)
+
+	policy := "default matches_sgx_policy = false"
+	signToken := func(policy string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodPS384, models.PolicyClaims{AttestationPolicy: policy})
+		token.Header[constants.KeyHeader] = certChain
+		tokenString, err := token.SignedString(privKey)
+		assert.NoError(t, err)
+		return tokenString
+	}
+	policyToken := signToken(policy)
+	policyHash := sha512.Sum384([]byte(policy))
+	tokenHash := sha512.Sum384([]byte(policyToken))
+	encodedHash := base64.StdEncoding.EncodeToString(policyHash[:])
+	tokenSignature := func(token string) string {
+		return token[strings.LastIndex(token, ".")+1:]
+	}
+	regoSignature, err := privKey.Sign(rand.Reader, policyHash[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA384})
+	assert.NoError(t, err)
+	rawSignature, err := base64.RawURLEncoding.DecodeString(tokenSignature(policyToken))
+	assert.NoError(t, err)
+
+	tt := []struct {
+		response        models.PolicyResponse
+		hashStatus      string
+		policyJwtStatus string
+		signatureStatus string
+		wantErr         bool
+		description     string
+	}{
+		{
+			response:        models.PolicyResponse{PolicyHash: base64.StdEncoding.EncodeToString(policyHash[:]), PolicyJWT: policyToken, SignedByTenant: true},
+			hashStatus:      constants.PolicyHashVerified,
+			policyJwtStatus: constants.PolicyHashVerified,
+			signatureStatus: constants.PolicyHashUnverifiable,
+			description:     "Test policy hash and policy JWT matching",
+		},
+		{
+			response:        models.PolicyResponse{PolicyHash: base64.StdEncoding.EncodeToString(tokenHash[:]), PolicyJWT: signToken(policy), SignedByTenant: true},
+			hashStatus:      constants.PolicyHashVerified,
+			policyJwtStatus: constants.PolicyHashVerified,
+			signatureStatus: constants.PolicyHashUnverifiable,
+			description:     "Test policy hash of the token and re-signed policy JWT",
+		},
+		{
+			response:        models.PolicyResponse{PolicyHash: base64.StdEncoding.EncodeToString(policyHash[:]), SignedByTenant: true},
+			hashStatus:      constants.PolicyHashVerified,
+			policyJwtStatus: constants.PolicyHashUnverifiable,
+			signatureStatus: constants.PolicyHashUnverifiable,
+			description:     "Test policy JWT not returned",
+		},
+		{
+			response:        models.PolicyResponse{PolicyHash: base64.StdEncoding.EncodeToString(policyHash[:]), PolicyJWT: signToken("default allow = true"), SignedByTenant: true},
+			hashStatus:      constants.PolicyHashVerified,
+			policyJwtStatus: constants.PolicyHashMismatch,
+			signatureStatus: constants.PolicyHashUnverifiable,
+			wantErr:         true,
+			description:     "Test policy JWT carrying another policy",
+		},
+		{
+			response:        models.PolicyResponse{PolicyHash: base64.StdEncoding.EncodeToString(policyHash[:]), PolicyJWT: policyToken},
+			hashStatus:      constants.PolicyHashVerified,
+			policyJwtStatus: constants.PolicyHashVerified,
+			signatureStatus: constants.PolicyHashUnverifiable,
+			wantErr:         true,
+			description:     "Test policy not signed by the tenant",
+		},
+		{
+			response: models.PolicyResponse{PolicyHash: encodedHash, PolicyJWT: policyToken, PolicySignature: tokenSignature(policyToken),
+				SignedByTenant: true},
+			hashStatus:      constants.PolicyHashVerified,
+			policyJwtStatus: constants.PolicyHashVerified,
+			signatureStatus: constants.PolicyHashVerified,
+			description:     "Test signature of the policy JWT",
+		},
+		{
+			response: models.PolicyResponse{PolicyHash: encodedHash, PolicyJWT: policyToken,
+				PolicySignature: base64.StdEncoding.EncodeToString(rawSignature), SignedByTenant: true},
+			hashStatus:      constants.PolicyHashVerified,
+			policyJwtStatus: constants.PolicyHashVerified,
+			signatureStatus: constants.PolicyHashVerified,
+			description:     "Test base64 encoded signature of the policy JWT",
+		},
+		{
+			response: models.PolicyResponse{PolicyHash: encodedHash, PolicyJWT: policyToken,
+				PolicySignature: base64.StdEncoding.EncodeToString(regoSignature), SignedByTenant: true},
+			hashStatus:      constants.PolicyHashVerified,
+			policyJwtStatus: constants.PolicyHashVerified,
+			signatureStatus: constants.PolicyHashVerified,
+			description:     "Test signature of the rego policy",
+		},
+		{
+			response: models.PolicyResponse{PolicyHash: encodedHash, PolicyJWT: policyToken,
+				PolicySignature: tokenSignature(signToken("default allow = true")), SignedByTenant: true},
+			hashStatus:      constants.PolicyHashVerified,
+			policyJwtStatus: constants.PolicyHashVerified,
+			signatureStatus: constants.PolicyHashMismatch,
+			wantErr:         true,
+			description:     "Test signature of another policy",
+		},
+	}
+
+	for _, tc := range tt {
+		check, err := checkSignedPolicyResponse(policy, policyToken, constants.PS384, &tc.response)
+		assert.Equal(t, tc.hashStatus, check.HashStatus, tc.description)
+		assert.Equal(t, tc.policyJwtStatus, check.PolicyJwtStatus, tc.description)
+		assert.Equal(t, tc.signatureStatus, check.SignatureStatus, tc.description)
+		if tc.wantErr {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+}
+
 func GenerateInvalidPolicyFile(t *testing.T, f string) {
 	f1, err := os.Create(f)
 	assert.NoError(t, err)
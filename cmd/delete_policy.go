@@ -11,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
 	"intel/tac/v1/config"
 	"intel/tac/v1/constants"
 	"intel/tac/v1/utils"
@@ -43,6 +44,7 @@ func init() {
 
 	deletePolicyCmd.Flags().StringP(constants.PolicyIdParamName, "p", "", "Id of the policy to be deleted")
 	deletePolicyCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	deletePolicyCmd.Flags().Bool(constants.ForceParamName, false, "Delete the policy even if it is referenced by API clients")
 	deletePolicyCmd.MarkFlagRequired(constants.PolicyIdParamName)
 }
 
@@ -74,12 +76,42 @@ func deletePolicy(cmd *cobra.Command) (string, error) {
 		return "", errors.Wrap(err, "Invalid policy id provided")
 	}
 
+	force, err := cmd.Flags().GetBool(constants.ForceParamName)
+	if err != nil {
+		return "", err
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+	usage, err := findPolicyUsage(tms.NewTmsClient(client, tmsUrl, apiKey), policyId)
+	if err != nil {
+		if !force {
+			return "", errors.Wrapf(err, "Unable to check whether the policy is referenced by API clients, use --%s to delete it anyway",
+				constants.ForceParamName)
+		}
+		log.WithError(err).Warn("Unable to check whether the policy is referenced by API clients")
+	}
+	if len(usage) > 0 {
+		usageString, err := formatPolicyUsage(usage)
+		if err != nil {
+			return "", err
+		}
+		if !force {
+			return "", errors.Errorf("Policy is referenced by %d API client(s), use --%s to delete it anyway:\n%s",
+				len(usage), constants.ForceParamName, usageString)
+		}
+		fmt.Printf("Deleting policy referenced by %d API client(s):\n%s\n", len(usage), usageString)
+	}
+
 	pmsClient := pms.NewPmsClient(client, pmsUrl, apiKey)
 
 	err = pmsClient.DeletePolicy(policyId)
 	if err != nil {
 		return "", err
 	}
+	forgetPolicyAnnotation(policyId)
 
 	return policyIdString, nil
 }
@@ -77,12 +77,23 @@ func TestDeletePolicyCmd(t *testing.T) {
 			wantErr:     true,
 			description: "Test Invalid request id provided",
 		},
+		{
+			args:        []string{constants.DeleteCmd, constants.PolicyCmd, "-p", "c855d8d6-744f-48c6-a06d-a97ef1811a61"},
+			wantErr:     true,
+			description: "Test delete policy referenced by API clients",
+		},
+		{
+			args:        []string{constants.DeleteCmd, constants.PolicyCmd, "-p", "c855d8d6-744f-48c6-a06d-a97ef1811a61", "--force"},
+			wantErr:     false,
+			description: "Test force delete policy referenced by API clients",
+		},
 	}
 
 	deleteCmd.AddCommand(deletePolicyCmd)
 	tenantCmd.AddCommand(deleteCmd)
 
 	for _, tc := range tt {
+		resetFlagsForTests(t, deletePolicyCmd)
 		_, err := execute(t, tenantCmd, tc.args)
 
 		if tc.wantErr == true {
@@ -91,4 +102,5 @@ func TestDeletePolicyCmd(t *testing.T) {
 			assert.NoError(t, err)
 		}
 	}
+	resetFlagsForTests(t, deletePolicyCmd)
 }
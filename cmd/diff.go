@@ -0,0 +1,47 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   constants.DiffCmd,
+	Short: "Show how the tenant differs from YAML manifests of tags, policies, users, tenant settings and api clients",
+	Long: `Compare YAML manifests of tags, policies, users, tenant settings and api clients with the tenant and print the
+create, update and delete actions apply would execute, without changing anything.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("diff called")
+		response, err := diffManifests(cmd)
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Diff: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	tenantCmd.AddCommand(diffCmd)
+
+	addManifestFlags(diffCmd)
+}
+
+func diffManifests(cmd *cobra.Command) (string, error) {
+	plan, _, _, err := planManifests(cmd)
+	if err != nil {
+		return "", err
+	}
+	return plan.String(), nil
+}
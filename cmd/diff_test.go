@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/manifest"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tenant, dir := manifestTenantForTests(t)
+	server := test.TenantMockServer(t, tenant)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{"-f", dir},
+			wantErr:     false,
+			description: "Test diff a directory of manifests",
+		},
+		{
+			args:        []string{"-f", filepath.Join(dir, "tags.yaml"), "-f", filepath.Join(dir, "users.yaml"), "--prune"},
+			wantErr:     false,
+			description: "Test diff manifest files with prune",
+		},
+		{
+			args:        []string{},
+			wantErr:     true,
+			description: "Test diff without manifests",
+		},
+		{
+			args:        []string{"-f", t.TempDir()},
+			wantErr:     true,
+			description: "Test diff a directory without manifests",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, diffCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.DiffCmd}, tc.args...))
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+		resetFlagsForTests(t, diffCmd)
+	}
+	// diff never changes the tenant
+	assert.Len(t, tenant.Tags, 2)
+	assert.Len(t, tenant.Users, 1)
+	assert.Empty(t, tenant.Settings.AttestationFailureEmail)
+}
+
+func TestManifestPlan(t *testing.T) {
+	tenant, dir := manifestTenantForTests(t)
+	m, err := manifest.Load([]string{dir})
+	assert.NoError(t, err)
+
+	state := &manifest.State{
+		Tags:          tenant.Tags,
+		Policies:      tenant.Policies,
+		ServiceOffers: tenant.ServiceOffers,
+		Services:      []models.Service{{ID: tenant.Services[0].ID, ServiceOfferId: tenant.Services[0].ServiceOfferId, Name: "Production"}},
+		Products:      map[uuid.UUID][]models.Product{tenant.Products[0].ServiceOfferId: tenant.Products},
+		ApiClients:    tenant.ApiClients,
+		Users:         tenant.Users,
+	}
+	plan, err := manifest.NewPlan(m, state, true)
+	assert.NoError(t, err)
+
+	// tags and policies are created before the api clients referencing them, and deleted after them
+	var actions []string
+	for _, action := range plan.Actions {
+		actions = append(actions, action.Operation+" "+action.Kind+" "+action.Name)
+	}
+	assert.Equal(t, []string{
+		"create Tag Workload",
+		"create Tag Region",
+		"create Policy payments-policy",
+		"create User alice@example.com",
+		"update TenantSettings TenantSettings",
+		"create ApiClient payments-workload",
+		"update ApiClient reporting-workload",
+		"delete ApiClient legacy-workload",
+		"delete User bob@example.com",
+		"delete Policy legacy-policy",
+		"delete Tag Legacy",
+	}, actions)
+	assert.True(t, strings.HasSuffix(plan.String(), "Plan: 5 to create, 2 to update, 4 to delete"))
+	assert.Contains(t, plan.String(), "    status: Active -> Inactive")
+
+	// a policy is pruned along with the api clients referencing it, but not when api clients of other services do
+	legacyPolicyId := tenant.Policies[1].PolicyId
+	state.PolicyUsage = map[uuid.UUID][]models2.PolicyUsage{legacyPolicyId: {{ServiceId: tenant.Services[0].ID,
+		ServiceName: "Production", ApiClientId: tenant.ApiClients[1].ID, ApiClientName: "legacy-workload"}}}
+	plan, err = manifest.NewPlan(m, state, true)
+	assert.NoError(t, err)
+	assert.Len(t, plan.Actions, 11)
+	state.PolicyUsage[legacyPolicyId] = append(state.PolicyUsage[legacyPolicyId], models2.PolicyUsage{ServiceId: uuid.New(),
+		ServiceName: "Staging", ApiClientId: uuid.New(), ApiClientName: "staging-workload"})
+	_, err = manifest.NewPlan(m, state, true)
+	assert.ErrorContains(t, err, "Policy legacy-policy is not in the manifests but cannot be pruned")
+	state.PolicyUsage = nil
+
+	// without prune, nothing is deleted
+	plan, err = manifest.NewPlan(m, state, false)
+	assert.NoError(t, err)
+	for _, action := range plan.Actions {
+		assert.NotEqual(t, constants.PlanOperationDelete, action.Operation)
+	}
+}
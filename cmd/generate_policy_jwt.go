@@ -6,6 +6,8 @@
 package cmd
 
 import (
+	"crypto"
+	"encoding/base64"
 	"fmt"
 	"github.com/fatih/set"
 	"github.com/golang-jwt/jwt/v5"
@@ -13,10 +15,18 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
 	"intel/tac/v1/models"
+	"intel/tac/v1/signer"
 	"intel/tac/v1/utils"
 	"intel/tac/v1/validation"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // createPolicyJwtCmd represents the createPolicyJwtCmd command
@@ -40,16 +50,30 @@ func init() {
 
 	createPolicyJwtCmd.Flags().StringP(constants.PolicyFileParamName, "f", "", "Path of the file containing the rego policy to be uploaded")
 	createPolicyJwtCmd.Flags().BoolP(constants.SignObjectParamName, "s", false, "Determines if the JWT needs to be signed. Generates a JWS when this parameter is set")
-	createPolicyJwtCmd.Flags().StringP(constants.PrivateKeyFileParamName, "p", "", "Path of the file containing the private key to be used to sign the policy. To be used only if -s (sign) parameter is set, else it is ignored")
-	createPolicyJwtCmd.Flags().StringP(constants.CertificateFileParamName, "c", "", "Path of the file containing the certificate to be added to the JWT. To be used only if -s (sign) parameter is set, else it is ignored")
-	createPolicyJwtCmd.Flags().StringP(constants.AlgorithmParamName, "a", constants.PS384, "Algorithm to be used to sign Trust Authority JWT policy (RS256|PS256|RS384|PS384). To be used only if -s (sign) parameter is set, else it is ignored")
+	createPolicyJwtCmd.Flags().StringP(constants.PrivateKeyFileParamName, "p", "", "Path of the file containing the private key to be used to sign the policy, either PEM encoded or a PKCS#12 (.p12/.pfx) bundle. To be used only if -s (sign) parameter is set, else it is ignored")
+	createPolicyJwtCmd.Flags().StringP(constants.CertificateFileParamName, "c", "", "Path of the file containing the certificate, or the certificate chain ordered leaf first, to be added to the JWT. Optional for PKCS#12 bundles. To be used only if -s (sign) parameter is set, else it is ignored")
+	createPolicyJwtCmd.Flags().StringP(constants.AlgorithmParamName, "a", constants.PS384, "Algorithm to be used to sign Trust Authority JWT policy (RS256|PS256|RS384|PS384|RS512|PS512|ES256|ES384|ES512|EdDSA). "+
+		"When not set, the algorithm is derived from the private key (PS256, PS384 or PS512 for RSA keys). To be used only if -s (sign) parameter is set, else it is ignored")
+	addExternalSignerFlags(createPolicyJwtCmd)
+	createPolicyJwtCmd.Flags().StringP(constants.OutFileParamName, "o", "", "Path of the file to which the policy token is written. "+
+		"Defaults to the policy file name suffixed with \".signed.<timestamp>.txt\"")
+	createPolicyJwtCmd.Flags().Bool(constants.ForceParamName, false, "Replace the output file when it already exists")
+	createPolicyJwtCmd.Flags().Bool(constants.StdoutOnlyParamName, false, "Print only the policy token to stdout without writing it to a file")
+	createPolicyJwtCmd.Flags().Bool(constants.NoEchoParamName, false, "Do not print the rego policy")
+	createPolicyJwtCmd.Flags().String(constants.IssuedAtParamName, "", "Issued at (iat) claim of the policy token, an RFC 3339 timestamp or \"now\"")
+	createPolicyJwtCmd.Flags().String(constants.NotBeforeParamName, "", "Not before (nbf) claim of the policy token, an RFC 3339 timestamp or \"now\"")
+	createPolicyJwtCmd.Flags().Duration(constants.ExpiresInParamName, 0, "Validity of the policy token, e.g. 720h. The expiration (exp) claim is set relative to the issued at time, or to now when not set")
+	createPolicyJwtCmd.Flags().String(constants.IssuerParamName, "", "Issuer (iss) claim of the policy token")
+	createPolicyJwtCmd.Flags().String(constants.JwtIdParamName, "", "JWT ID (jti) claim of the policy token")
+	createPolicyJwtCmd.Flags().String(constants.KeyIdParamName, "", "Key ID (kid) header of the policy token. To be used only if -s (sign) parameter is set, else it is ignored")
+	createPolicyJwtCmd.Flags().Bool(constants.DeterministicParamName, false, "Generate a reproducible policy token. \"now\" is read from the SOURCE_DATE_EPOCH environment variable, "+
+		"the output file name has no timestamp and a deterministic signing algorithm is preferred when none is provided")
 	createPolicyJwtCmd.MarkFlagRequired(constants.PolicyFileParamName)
+	createPolicyJwtCmd.MarkFlagsMutuallyExclusive(constants.OutFileParamName, constants.StdoutOnlyParamName)
 }
 
 func generatePolicyJwt(cmd *cobra.Command) error {
 	var tokenString, algorithm string
-	// Create permitted algorithm set
-	algorithms := set.New(set.NonThreadSafe)
 	policyFilePath, err := cmd.Flags().GetString(constants.PolicyFileParamName)
 	if err != nil {
 		return err
@@ -76,56 +100,46 @@ func generatePolicyJwt(cmd *cobra.Command) error {
 	if len(policyBytes) == 0 {
 		return errors.New("Policy file does not contain a rego policy")
 	}
+	registeredClaims, err := policyRegisteredClaims(cmd)
+	if err != nil {
+		return err
+	}
 	claims := models.PolicyClaims{
 		AttestationPolicy: string(policyBytes),
+		RegisteredClaims:  registeredClaims,
 	}
 
-	algorithms.Add(constants.RS256, constants.PS256, constants.RS384, constants.PS384)
-
-	signJwt, err := cmd.Flags().GetBool(constants.SignObjectParamName)
+	// the output file is checked before signing, so that a signature is not requested for a token that cannot be written
+	stdoutOnly, err := cmd.Flags().GetBool(constants.StdoutOnlyParamName)
 	if err != nil {
 		return err
 	}
-
-	if signJwt {
-		algorithm, err = cmd.Flags().GetString(constants.AlgorithmParamName)
-		if err != nil {
+	force, err := cmd.Flags().GetBool(constants.ForceParamName)
+	if err != nil {
+		return err
+	}
+	var outputFile string
+	if !stdoutOnly {
+		if outputFile, err = policyJwtOutputFile(cmd, path, force); err != nil {
 			return err
 		}
-		if !algorithms.Has(algorithm) {
-			return errors.New("Input algorithm is not supported")
-		}
+	}
 
-		privateKeyFilePath, err := cmd.Flags().GetString(constants.PrivateKeyFileParamName)
-		if err != nil {
-			return err
-		}
+	signJwt, err := cmd.Flags().GetBool(constants.SignObjectParamName)
+	if err != nil {
+		return err
+	}
 
-		certFilePath, err := cmd.Flags().GetString(constants.CertificateFileParamName)
+	if signJwt {
+		keyId, err := cmd.Flags().GetString(constants.KeyIdParamName)
 		if err != nil {
 			return err
 		}
-
-		privKeyFinal, certContents, err := utils.CheckKeyFiles(privateKeyFilePath, certFilePath)
+		deterministic, err := cmd.Flags().GetBool(constants.DeterministicParamName)
 		if err != nil {
 			return err
 		}
-
-		// Check if provided algorithm makes sense
-		signMethod := utils.CheckSigningAlgorithm(privKeyFinal, algorithm)
-		if signMethod == nil {
-			return errors.New("Signing algorithm provided as input is not compatible with the private key type")
-		}
-
-		signedToken := &jwt.Token{
-			Header: map[string]interface{}{
-				"alg": signMethod.Alg(),
-			},
-			Claims: claims,
-			Method: signMethod,
-		}
-		signedToken.Header[constants.KeyHeader] = []string{certContents}
-		tokenString, err = signedToken.SignedString(privKeyFinal)
+		tokenString, algorithm, err = signPolicyToken(cmd, claims, keyId, deterministic)
 		if err != nil {
 			return err
 		}
@@ -146,25 +160,455 @@ func generatePolicyJwt(cmd *cobra.Command) error {
 		tokenString = tokenString + "."
 	}
 
-	outputFile, err := utils.GenerateOutputFileName(policyFilePath)
+	if stdoutOnly {
+		fmt.Println(tokenString)
+		return nil
+	}
+
+	noEcho, err := cmd.Flags().GetBool(constants.NoEchoParamName)
 	if err != nil {
 		return err
 	}
 	// Output to screen
-	generateConsoleOutput(string(policyBytes), algorithm, outputFile, tokenString)
+	if noEcho {
+		generateConsoleOutput("", algorithm, outputFile, tokenString)
+	} else {
+		generateConsoleOutput(string(policyBytes), algorithm, outputFile, tokenString)
+	}
 
 	// Output to file
-	err = os.WriteFile(outputFile, []byte(tokenString), 0400)
+	return utils.WriteNewFile(outputFile, []byte(tokenString), 0400, force)
+}
+
+// policyJwtOutputFile returns the path the policy token is written to. The default file name has a timestamp
+// unless the token is generated in deterministic mode. The policy file is never replaced, and an existing file only
+// when force is set.
+func policyJwtOutputFile(cmd *cobra.Command, policyFilePath string, force bool) (string, error) {
+	outFile, err := cmd.Flags().GetString(constants.OutFileParamName)
 	if err != nil {
-		return err
+		return "", err
+	}
+	deterministic, err := cmd.Flags().GetBool(constants.DeterministicParamName)
+	if err != nil {
+		return "", err
 	}
-	return nil
+
+	var outputFile string
+	switch {
+	case outFile != "":
+		if outputFile, err = validation.ValidateOutputPath(outFile); err != nil {
+			return "", errors.Wrap(err, "Invalid output file path provided")
+		}
+	case !deterministic:
+		if outputFile, err = utils.GenerateOutputFileName(policyFilePath); err != nil {
+			return "", err
+		}
+	default:
+		outputFile = strings.TrimSuffix(policyFilePath, filepath.Ext(policyFilePath)) + ".signed.txt"
+	}
+
+	info, err := os.Stat(outputFile)
+	if err == nil {
+		policyInfo, err := os.Stat(policyFilePath)
+		if err == nil && os.SameFile(info, policyInfo) {
+			return "", errors.New("Output file cannot be the policy file")
+		}
+		if !force {
+			return "", errors.Errorf("%s already exists, use --%s to replace it", outputFile, constants.ForceParamName)
+		}
+	}
+	return outputFile, nil
+}
+
+// policyRegisteredClaims returns the registered claims of the policy token selected by the claim flags. In
+// deterministic mode the current time is never read from the clock but from SOURCE_DATE_EPOCH.
+func policyRegisteredClaims(cmd *cobra.Command) (jwt.RegisteredClaims, error) {
+	var claims jwt.RegisteredClaims
+	deterministic, err := cmd.Flags().GetBool(constants.DeterministicParamName)
+	if err != nil {
+		return claims, err
+	}
+	now := func() (time.Time, error) {
+		if !deterministic {
+			return time.Now(), nil
+		}
+		epoch := os.Getenv(constants.SourceDateEpochEnvVar)
+		if epoch == "" {
+			return time.Time{}, errors.Errorf("%s needs to be set to use the current time in deterministic mode, or explicit timestamps provided",
+				constants.SourceDateEpochEnvVar)
+		}
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, errors.Errorf("%s should be a number of seconds since the Unix epoch", constants.SourceDateEpochEnvVar)
+		}
+		return time.Unix(seconds, 0), nil
+	}
+	timestamp := func(name string) (*jwt.NumericDate, error) {
+		value, err := cmd.Flags().GetString(name)
+		if err != nil || value == "" {
+			return nil, err
+		}
+		if value == constants.TimestampNow {
+			t, err := now()
+			if err != nil {
+				return nil, err
+			}
+			return jwt.NewNumericDate(t), nil
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errors.Errorf("Invalid %s provided, should be an RFC 3339 timestamp or \"%s\"", name, constants.TimestampNow)
+		}
+		return jwt.NewNumericDate(t), nil
+	}
+
+	if claims.IssuedAt, err = timestamp(constants.IssuedAtParamName); err != nil {
+		return claims, err
+	}
+	if claims.NotBefore, err = timestamp(constants.NotBeforeParamName); err != nil {
+		return claims, err
+	}
+	expiresIn, err := cmd.Flags().GetDuration(constants.ExpiresInParamName)
+	if err != nil {
+		return claims, err
+	}
+	if expiresIn < 0 {
+		return claims, errors.New("Policy token validity cannot be negative")
+	}
+	if expiresIn > 0 {
+		issuedAt := time.Time{}
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		} else if issuedAt, err = now(); err != nil {
+			return claims, err
+		}
+		claims.ExpiresAt = jwt.NewNumericDate(issuedAt.Add(expiresIn))
+		if claims.NotBefore != nil && !claims.NotBefore.Before(claims.ExpiresAt.Time) {
+			return claims, errors.New("Policy token expires before it becomes valid")
+		}
+	}
+
+	if claims.Issuer, err = cmd.Flags().GetString(constants.IssuerParamName); err != nil {
+		return claims, err
+	}
+	if claims.ID, err = cmd.Flags().GetString(constants.JwtIdParamName); err != nil {
+		return claims, err
+	}
+	return claims, nil
+}
+
+// addExternalSignerFlags adds the flags selecting an encrypted key passphrase or a signer holding the key outside of the CLI
+func addExternalSignerFlags(cmd *cobra.Command) {
+	cmd.Flags().String(constants.PassphraseFileParamName, "", "Path of the file containing the passphrase of an encrypted private key or PKCS#12 bundle. "+
+		"When not set, the passphrase is prompted for if required")
+	cmd.Flags().String(constants.PKCS11URIParamName, "", "PKCS#11 URI of the signing key, e.g. pkcs11:token=<token>;object=<key label>?module-path=<module>&pin-source=<pin file>. "+
+		"Requires pkcs11-tool and the certificate file")
+	cmd.Flags().String(constants.SignCommandParamName, "", "Command run to sign the policy, it receives the signing input on stdin and writes the raw signature to stdout. "+
+		"Requires the certificate file")
+	cmd.Flags().String(constants.KMSUrlParamName, "", "URL of the remote KMS signing endpoint. Requires the KMS key ID and the certificate file")
+	cmd.Flags().String(constants.KMSKeyIdParamName, "", "ID of the signing key in the remote KMS")
+}
+
+// addPolicySigningFlags adds the long only signing flags used when uploading a tenant signed policy
+func addPolicySigningFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(constants.SignObjectParamName, false, "Sign the policy and upload it as a tenant signed policy JWT")
+	cmd.Flags().String(constants.PrivateKeyFileParamName, "", "Path of the file containing the private key to be used to sign the policy, either PEM encoded or a PKCS#12 (.p12/.pfx) bundle. "+
+		"To be used only if --sign parameter is set, else it is ignored")
+	cmd.Flags().String(constants.CertificateFileParamName, "", "Path of the file containing the certificate, or the certificate chain ordered leaf first, to be added to the policy JWT. "+
+		"Optional for PKCS#12 bundles. To be used only if --sign parameter is set, else it is ignored")
+	cmd.Flags().String(constants.AlgorithmParamName, constants.PS384, "Algorithm to be used to sign the policy (RS256|PS256|RS384|PS384|RS512|PS512|ES256|ES384|ES512|EdDSA). "+
+		"When not set, the algorithm is derived from the private key. To be used only if --sign parameter is set, else it is ignored")
+	addExternalSignerFlags(cmd)
+}
+
+// signPolicyClaims signs the policy claims with the key selected by the signing flags and returns the JWS
+// along with the algorithm used
+func signPolicyClaims(cmd *cobra.Command, claims models.PolicyClaims) (string, string, error) {
+	return signPolicyToken(cmd, claims, "", false)
+}
+
+// signPolicyToken signs the policy claims adding the key ID header when provided. In deterministic mode a signing
+// algorithm producing the same signature for the same input is preferred when the algorithm is not provided.
+func signPolicyToken(cmd *cobra.Command, claims models.PolicyClaims, keyId string, deterministic bool) (string, string, error) {
+	algorithm, err := cmd.Flags().GetString(constants.AlgorithmParamName)
+	if err != nil {
+		return "", "", err
+	}
+	// Create permitted algorithm set
+	algorithms := set.New(set.NonThreadSafe)
+	for _, supportedAlgorithm := range constants.SigningAlgorithms {
+		algorithms.Add(supportedAlgorithm)
+	}
+	if !algorithms.Has(algorithm) {
+		return "", "", errors.New("Input algorithm is not supported")
+	}
+
+	privKeyFinal, certChain, external, err := loadPolicySigner(cmd)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Pick the algorithm matching the key type and size when it was not explicitly requested
+	if !cmd.Flags().Changed(constants.AlgorithmParamName) {
+		if validAlgorithms := utils.SigningAlgorithmsForKey(privKeyFinal); len(validAlgorithms) > 0 {
+			algorithm = validAlgorithms[0]
+			// RSA-PSS signatures are salted, RSA PKCS#1 v1.5 ones are reproducible
+			if deterministic && len(validAlgorithms) > 1 && strings.HasPrefix(algorithm, "PS") {
+				algorithm = validAlgorithms[1]
+			}
+		}
+	}
+	if deterministic && (strings.HasPrefix(algorithm, "PS") || strings.HasPrefix(algorithm, "ES")) {
+		log.Warnf("%s signatures are randomized, the policy token claims are reproducible but its signature is not", algorithm)
+	}
+
+	// Check if provided algorithm makes sense
+	signMethod := utils.CheckSigningAlgorithm(privKeyFinal, algorithm)
+	if signMethod == nil {
+		return "", "", errors.New("Signing algorithm provided as input is not compatible with the private key type")
+	}
+	// External signers only produce the raw signature, the token is assembled here
+	if external {
+		if signMethod, err = signer.NewSigningMethod(algorithm); err != nil {
+			return "", "", err
+		}
+	}
+
+	signedToken := &jwt.Token{
+		Header: map[string]interface{}{
+			"alg": signMethod.Alg(),
+		},
+		Claims: claims,
+		Method: signMethod,
+	}
+	signedToken.Header[constants.KeyHeader] = certChain
+	if keyId != "" {
+		signedToken.Header[constants.KidHeader] = keyId
+	}
+	tokenString, err := signedToken.SignedString(privKeyFinal)
+	if err != nil {
+		return "", "", err
+	}
+	return tokenString, algorithm, nil
+}
+
+// signPolicyForUpload returns the tenant signed policy JWT of the rego policy along with the signing algorithm when
+// the --sign flag is set, and the unchanged policy otherwise
+func signPolicyForUpload(cmd *cobra.Command, policy string) (string, string, error) {
+	signPolicy, err := cmd.Flags().GetBool(constants.SignObjectParamName)
+	if err != nil {
+		return "", "", err
+	}
+	if !signPolicy {
+		return policy, "", nil
+	}
+	return signPolicyClaims(cmd, models.PolicyClaims{AttestationPolicy: policy})
+}
+
+// checkSignedPolicyResponse verifies the policy hash, the policy JWT and the policy signature returned by Trust
+// Authority for a tenant signed policy. An error is returned along with the report when the policy is not recorded as
+// signed as submitted.
+func checkSignedPolicyResponse(policy, policyToken, algorithm string, response *models.PolicyResponse) (*models2.PolicySignatureCheck, error) {
+	check := &models2.PolicySignatureCheck{
+		Algorithm:      algorithm,
+		SignedByTenant: response.SignedByTenant,
+		HashStatus:     utils.PolicyHashStatus(policy, response.PolicyHash),
+	}
+	// the hash may be computed over the signed policy JWT instead of the rego policy
+	if check.HashStatus == constants.PolicyHashMismatch && utils.PolicyHashStatus(policyToken, response.PolicyHash) == constants.PolicyHashVerified {
+		check.HashStatus = constants.PolicyHashVerified
+	}
+
+	switch response.PolicyJWT {
+	case "":
+		check.PolicyJwtStatus = constants.PolicyHashUnverifiable
+	case policyToken:
+		check.PolicyJwtStatus = constants.PolicyHashVerified
+	default:
+		check.PolicyJwtStatus = constants.PolicyHashMismatch
+		if returnedPolicy, err := verifyPolicyTokenWithSigner(response.PolicyJWT, policyToken, algorithm); err == nil && returnedPolicy == policy {
+			check.PolicyJwtStatus = constants.PolicyHashVerified
+		}
+	}
+
+	check.SignatureStatus = policySignatureStatus(policy, policyToken, algorithm, response)
+
+	switch {
+	case !check.SignedByTenant:
+		return check, errors.New("Policy was uploaded but is not recorded as signed by the tenant")
+	case check.HashStatus == constants.PolicyHashMismatch:
+		return check, errors.New("Policy was uploaded but the policy hash returned by Trust Authority does not match the signed policy")
+	case check.PolicyJwtStatus == constants.PolicyHashMismatch:
+		return check, errors.New("Policy was uploaded but the policy JWT returned by Trust Authority does not match the signed policy")
+	case check.SignatureStatus == constants.PolicyHashMismatch:
+		return check, errors.New("Policy was uploaded but the policy signature returned by Trust Authority was not made with the signing certificate")
+	}
+	return check, nil
+}
+
+// printUploadedPolicy prints the response of a command uploading a policy after its title and returns the error of
+// the command. The response is printed even with an error, as the report of checkSignedPolicyResponse comes with the
+// error of a policy which was uploaded but not recorded as signed.
+func printUploadedPolicy(title, response string, err error) error {
+	utils.PrintRequestAndTraceId()
+	if response != "" {
+		fmt.Println(title + response)
+	}
+	return err
+}
+
+// policySignatureStatus verifies the policy signature returned by Trust Authority with the signing certificate of the
+// submitted policy token. The signature is either the signature of the policy JWT, base64url or base64 encoded, or a
+// signature over the rego policy.
+func policySignatureStatus(policy, policyToken, algorithm string, response *models.PolicyResponse) string {
+	if response.PolicySignature == "" {
+		return constants.PolicyHashUnverifiable
+	}
+	submitted, _, err := utils.ParsePolicyToken(policyToken)
+	if err != nil {
+		return constants.PolicyHashUnverifiable
+	}
+	chain, err := utils.CertificateChainFromHeader(submitted)
+	if err != nil || len(chain) == 0 {
+		return constants.PolicyHashUnverifiable
+	}
+	publicKey := chain[0].PublicKey
+
+	signature, err := base64.RawURLEncoding.DecodeString(response.PolicySignature)
+	if err != nil {
+		signature, err = base64.StdEncoding.DecodeString(response.PolicySignature)
+	}
+	if method := jwt.GetSigningMethod(algorithm); err == nil && method != nil {
+		for _, token := range []string{policyToken, response.PolicyJWT} {
+			separator := strings.LastIndex(token, ".")
+			if separator > 0 && method.Verify(token[:separator], signature, publicKey) == nil {
+				return constants.PolicyHashVerified
+			}
+		}
+	}
+	if utils.VerifyPolicySignature(policy, response.PolicyHash, response.PolicySignature, []crypto.PublicKey{publicKey}) == nil {
+		return constants.PolicyHashVerified
+	}
+	return constants.PolicyHashMismatch
+}
+
+// verifyPolicyTokenWithSigner verifies the token with the signing certificate of the submitted policy token and
+// returns the policy it carries
+func verifyPolicyTokenWithSigner(tokenString, submittedToken, algorithm string) (string, error) {
+	submitted, _, err := utils.ParsePolicyToken(submittedToken)
+	if err != nil {
+		return "", err
+	}
+	chain, err := utils.CertificateChainFromHeader(submitted)
+	if err != nil || len(chain) == 0 {
+		return "", errors.New("Submitted policy token does not contain the signing certificate")
+	}
+
+	claims := &models.PolicyClaims{}
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return chain[0].PublicKey, nil
+	}, jwt.WithValidMethods([]string{algorithm}))
+	if err != nil {
+		return "", err
+	}
+	return claims.AttestationPolicy, nil
+}
+
+// loadPolicySigner returns the signer selected by the signing flags, the x5c certificate chain and whether the
+// private key is held outside of the CLI
+func loadPolicySigner(cmd *cobra.Command) (crypto.Signer, []string, bool, error) {
+	privateKeyFilePath, err := cmd.Flags().GetString(constants.PrivateKeyFileParamName)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	certFilePath, err := cmd.Flags().GetString(constants.CertificateFileParamName)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	pkcs11Uri, err := cmd.Flags().GetString(constants.PKCS11URIParamName)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	signCommand, err := cmd.Flags().GetString(constants.SignCommandParamName)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	kmsUrl, err := cmd.Flags().GetString(constants.KMSUrlParamName)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	signers := 0
+	for _, value := range []string{privateKeyFilePath, pkcs11Uri, signCommand, kmsUrl} {
+		if value != "" {
+			signers++
+		}
+	}
+	if signers != 1 {
+		return nil, nil, false, errors.New("Exactly one of the private key file, PKCS#11 URI, signing command or KMS URL needs to be provided for signing")
+	}
+
+	if privateKeyFilePath != "" {
+		passphraseFilePath, err := cmd.Flags().GetString(constants.PassphraseFileParamName)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		privKeyFinal, certChain, err := utils.CheckKeyFiles(privateKeyFilePath, certFilePath, utils.NewPassphraseSource(passphraseFilePath))
+		return privKeyFinal, certChain, false, err
+	}
+
+	// the public key of external signers is taken from the signing certificate
+	if certFilePath == "" {
+		return nil, nil, false, errors.New("Certificate file path cannot be empty")
+	}
+	chain, err := utils.ReadCertificateChain(certFilePath)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if err = utils.ValidateCertificateChain(chain, time.Now()); err != nil {
+		return nil, nil, false, err
+	}
+
+	var externalSigner crypto.Signer
+	switch {
+	case pkcs11Uri != "":
+		externalSigner, err = signer.NewPKCS11Signer(pkcs11Uri, chain[0].PublicKey)
+	case signCommand != "":
+		externalSigner, err = signer.NewCommandSigner(signCommand, chain[0].PublicKey)
+	default:
+		externalSigner, err = newKMSSigner(cmd, kmsUrl, chain[0].PublicKey)
+	}
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return externalSigner, utils.EncodeCertificateChain(chain), true, nil
+}
+
+func newKMSSigner(cmd *cobra.Command, kmsUrl string, publicKey crypto.PublicKey) (crypto.Signer, error) {
+	kmsKeyId, err := cmd.Flags().GetString(constants.KMSKeyIdParamName)
+	if err != nil {
+		return nil, err
+	}
+	parsedUrl, err := url.Parse(kmsUrl)
+	if err != nil || (parsedUrl.Scheme != constants.HTTPScheme && parsedUrl.Scheme != "http") || parsedUrl.Host == "" {
+		return nil, errors.New("Invalid KMS URL provided")
+	}
+	if parsedUrl.Scheme != constants.HTTPScheme {
+		log.Warn("KMS URL does not use https, the KMS token and signing requests are sent unencrypted")
+	}
+
+	client := &http.Client{
+		Timeout: time.Duration(constants.DefaultSignerTimeout) * time.Second,
+	}
+	return signer.NewKMSSigner(client, kmsUrl, kmsKeyId, publicKey)
 }
 
 // Print out the contents on console
 func generateConsoleOutput(policy, algorithm, outputFile, policyToken string) {
-	fmt.Println("Original policy:")
-	fmt.Println(policy)
+	if policy != "" {
+		fmt.Println("Original policy:")
+		fmt.Println(policy)
+	}
 	fmt.Println("Algorithm used during signing: ", algorithm)
 	if outputFile != "" {
 		fmt.Println("Policy token is stored in file ", outputFile)
@@ -6,16 +6,35 @@
 package cmd
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha512"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
+	"flag"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/signer"
 	"intel/tac/v1/test"
+	"intel/tac/v1/utils"
+	"io"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -130,6 +149,132 @@ func TestGeneratePolicyJwtCmd(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGeneratePolicyJwtOutput(t *testing.T) {
+	server := test.MockServer(t)
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+
+	dir := t.TempDir()
+	signingKeyFile := filepath.Join(dir, "signing.key")
+	signingCertFile := filepath.Join(dir, "signing.crt")
+	generateKeyPairForTests(t, signingKeyFile, signingCertFile)
+	policyFile := filepath.Join(dir, "policy.rego")
+	assert.NoError(t, os.WriteFile(policyFile, []byte("default allow = true\n"), 0600))
+
+	createCmd.AddCommand(createPolicyJwtCmd)
+	tenantCmd.AddCommand(createCmd)
+	t.Setenv(constants.SourceDateEpochEnvVar, "1700000000")
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{"--stdout-only", "--no-echo"},
+			wantErr:     false,
+			description: "Test print only the policy token",
+		},
+		{
+			args:        []string{"--stdout-only", "-o", filepath.Join(dir, "token.txt")},
+			wantErr:     true,
+			description: "Test output file along with stdout only",
+		},
+		{
+			args:        []string{"--issued-at", "yesterday"},
+			wantErr:     true,
+			description: "Test invalid issued at timestamp",
+		},
+		{
+			args:        []string{"--expires-in", "-1h"},
+			wantErr:     true,
+			description: "Test negative validity",
+		},
+		{
+			args:        []string{"--issued-at", "2024-01-01T00:00:00Z", "--not-before", "2024-02-01T00:00:00Z", "--expires-in", "24h"},
+			wantErr:     true,
+			description: "Test policy token expiring before it becomes valid",
+		},
+		{
+			args:        []string{"--deterministic", "--stdout-only", "--issued-at", "now", "--expires-in", "24h"},
+			wantErr:     false,
+			description: "Test deterministic policy token using SOURCE_DATE_EPOCH",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, createPolicyJwtCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.CreateCmd, constants.PolicyJwtCmd, "-f", policyFile}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+
+	// the registered claims and key ID are set from the flags, and signed tokens are reproducible in deterministic mode
+	var tokens []string
+	signArgs := func(out string) []string {
+		return []string{constants.CreateCmd, constants.PolicyJwtCmd, "-f", policyFile, "-p", signingKeyFile,
+			"-c", signingCertFile, "-s", "-o", out, "--deterministic", "--no-echo", "--issued-at", "now", "--not-before", "2023-11-14T00:00:00Z",
+			"--expires-in", "720h", "--issuer", "ci", "--jwt-id", "policy-1", "--key-id", "policy-key"}
+	}
+	for i, out := range []string{filepath.Join(dir, "first.txt"), filepath.Join(dir, "second.txt"), filepath.Join(dir, "second.txt")} {
+		resetFlagsForTests(t, createPolicyJwtCmd)
+		args := signArgs(out)
+		if i == 2 {
+			// an existing token is only replaced with --force
+			_, err := execute(t, tenantCmd, args)
+			assert.ErrorContains(t, err, "already exists")
+			resetFlagsForTests(t, createPolicyJwtCmd)
+			args = append(args, "--force")
+		}
+		_, err := execute(t, tenantCmd, args)
+		assert.NoError(t, err)
+		token, err := os.ReadFile(out)
+		assert.NoError(t, err)
+		tokens = append(tokens, string(token))
+	}
+	assert.Equal(t, tokens[0], tokens[1])
+	assert.Equal(t, tokens[0], tokens[2])
+
+	// the policy file is never replaced and the output directory should exist
+	for _, out := range []string{policyFile, filepath.Join(dir, ".", "policy.rego"), filepath.Join(dir, "missing", "token.txt"),
+		filepath.Join(dir, "token*.txt")} {
+		resetFlagsForTests(t, createPolicyJwtCmd)
+		_, err := execute(t, tenantCmd, append(signArgs(out), "--force"))
+		assert.Error(t, err, out)
+	}
+	policyBytes, err := os.ReadFile(policyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "default allow = true\n", string(policyBytes))
+
+	claims := &models.PolicyClaims{}
+	token, _, err := jwt.NewParser().ParseUnverified(tokens[0], claims)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.RS384, token.Header["alg"])
+	assert.Equal(t, "policy-key", token.Header[constants.KidHeader])
+	assert.Equal(t, "ci", claims.Issuer)
+	assert.Equal(t, "policy-1", claims.ID)
+	assert.Equal(t, int64(1700000000), claims.IssuedAt.Unix())
+	assert.Equal(t, int64(1700000000+720*3600), claims.ExpiresAt.Unix())
+	assert.Equal(t, time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC).Unix(), claims.NotBefore.Unix())
+
+	// the current time is not read from the clock in deterministic mode
+	t.Setenv(constants.SourceDateEpochEnvVar, "")
+	resetFlagsForTests(t, createPolicyJwtCmd)
+	_, err = execute(t, tenantCmd, []string{constants.CreateCmd, constants.PolicyJwtCmd, "-f", policyFile, "--deterministic", "--expires-in", "1h"})
+	assert.Error(t, err)
+
+	// without timestamps the default output file name has no timestamp in deterministic mode
+	resetFlagsForTests(t, createPolicyJwtCmd)
+	_, err = execute(t, tenantCmd, []string{constants.CreateCmd, constants.PolicyJwtCmd, "-f", policyFile, "--deterministic"})
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "policy.signed.txt"))
+	resetFlagsForTests(t, createPolicyJwtCmd)
+}
+
 func generateKeyPairForTests(t *testing.T, keyFile, certFile string) {
 	keyPair, err := rsa.GenerateKey(rand.Reader, 3072)
 	assert.NoError(t, err)
@@ -184,3 +329,426 @@ func generateKeyPairForTests(t *testing.T, keyFile, certFile string) {
 	err = privatePem.Close()
 	assert.NoError(t, err)
 }
+
+func TestGeneratePolicyJwtKeyTypes(t *testing.T) {
+	server := test.MockServer(t)
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+
+	keyDir := t.TempDir()
+	rsa4096, err := rsa.GenerateKey(rand.Reader, 4096)
+	assert.NoError(t, err)
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	p521, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	assert.NoError(t, err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	tt := []struct {
+		key         crypto.Signer
+		pemType     string
+		algorithm   string
+		wantErr     bool
+		description string
+	}{
+		{key: rsa4096, pemType: constants.RSAPrivateKeyType, algorithm: constants.PS512, description: "Test RSA-4096 PKCS#1 key with PS512"},
+		{key: rsa4096, pemType: constants.PKCS8PrivateKeyType, algorithm: constants.RS512, description: "Test RSA-4096 PKCS#8 key with RS512"},
+		{key: rsa4096, pemType: constants.RSAPrivateKeyType, algorithm: constants.PS384, wantErr: true, description: "Test RSA-4096 key with PS384"},
+		{key: p256, pemType: constants.ECPrivateKeyType, algorithm: constants.ES256, description: "Test P-256 SEC1 key with ES256"},
+		{key: p256, pemType: constants.PKCS8PrivateKeyType, algorithm: constants.ES384, wantErr: true, description: "Test P-256 PKCS#8 key with ES384"},
+		{key: p384, pemType: constants.PKCS8PrivateKeyType, algorithm: constants.ES384, description: "Test P-384 PKCS#8 key with ES384"},
+		{key: p384, pemType: constants.ECPrivateKeyType, algorithm: constants.PS384, wantErr: true, description: "Test P-384 key with PS384"},
+		{key: p521, pemType: constants.ECPrivateKeyType, algorithm: constants.ES512, description: "Test P-521 SEC1 key with ES512"},
+		{key: ed25519Key, pemType: constants.PKCS8PrivateKeyType, algorithm: constants.EdDSA, description: "Test Ed25519 PKCS#8 key with EdDSA"},
+		{key: ed25519Key, pemType: constants.PKCS8PrivateKeyType, algorithm: constants.ES256, wantErr: true, description: "Test Ed25519 key with ES256"},
+	}
+
+	for i, tc := range tt {
+		keyPath := filepath.Join(keyDir, fmt.Sprintf("key-%d.pem", i))
+		certPath := filepath.Join(keyDir, fmt.Sprintf("cert-%d.pem", i))
+		writeKeyAndCertForTests(t, tc.key, tc.pemType, keyPath, certPath)
+
+		privKey, _, err := utils.CheckKeyFiles(keyPath, certPath, nil)
+		assert.NoError(t, err, tc.description)
+
+		signMethod := utils.CheckSigningAlgorithm(privKey, tc.algorithm)
+		if tc.wantErr {
+			assert.Nil(t, signMethod, tc.description)
+			continue
+		}
+		assert.NotNil(t, signMethod, tc.description)
+
+		token := jwt.NewWithClaims(signMethod, models.PolicyClaims{AttestationPolicy: "default allow = true"})
+		tokenString, err := token.SignedString(privKey)
+		assert.NoError(t, err, tc.description)
+
+		_, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			return privKey.Public(), nil
+		}, jwt.WithValidMethods([]string{tc.algorithm}))
+		assert.NoError(t, err, tc.description)
+	}
+
+	// certificate of one key with the private key of another
+	mismatchKey := filepath.Join(keyDir, "mismatch-key.pem")
+	mismatchCert := filepath.Join(keyDir, "mismatch-cert.pem")
+	writeKeyAndCertForTests(t, p256, constants.ECPrivateKeyType, mismatchKey, mismatchCert)
+	writeKeyAndCertForTests(t, p384, constants.ECPrivateKeyType, mismatchKey, filepath.Join(keyDir, "unused-cert.pem"))
+	_, _, err = utils.CheckKeyFiles(mismatchKey, mismatchCert, nil)
+	assert.Error(t, err)
+
+	// the algorithm is derived from the key when it is not provided
+	ecKey := filepath.Join(keyDir, "ec-key.pem")
+	ecCert := filepath.Join(keyDir, "ec-cert.pem")
+	writeKeyAndCertForTests(t, p384, constants.ECPrivateKeyType, ecKey, ecCert)
+	tokenString := createPolicyJwtForTests(t, "-p", ecKey, "-c", ecCert)
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return p384.Public(), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ES384, token.Header["alg"])
+}
+
+func TestGeneratePolicyJwtCertificateChain(t *testing.T) {
+	const signingDir = "../test/resources/signing/"
+	encryptedKey := signingDir + "leaf-encrypted.key"
+	passphraseFile := signingDir + "passphrase.txt"
+	wrongPassphrase := func() ([]byte, error) { return []byte("wrong"), nil }
+
+	tt := []struct {
+		keyFile     string
+		certFile    string
+		passphrase  utils.PassphraseSource
+		chainLength int
+		wantErr     bool
+		description string
+	}{
+		{keyFile: encryptedKey, certFile: signingDir + "chain.pem", passphrase: utils.NewPassphraseSource(passphraseFile), chainLength: 2,
+			description: "Test encrypted PKCS#8 key with certificate chain"},
+		{keyFile: encryptedKey, certFile: signingDir + "leaf.pem", passphrase: utils.NewPassphraseSource(passphraseFile), chainLength: 1,
+			description: "Test encrypted PKCS#8 key with leaf certificate"},
+		{keyFile: encryptedKey, certFile: signingDir + "chain-unordered.pem", passphrase: utils.NewPassphraseSource(passphraseFile), wantErr: true,
+			description: "Test certificate chain not ordered leaf first"},
+		{keyFile: encryptedKey, certFile: signingDir + "chain.pem", passphrase: wrongPassphrase, wantErr: true,
+			description: "Test encrypted PKCS#8 key with wrong passphrase"},
+		{keyFile: encryptedKey, certFile: signingDir + "chain.pem", wantErr: true,
+			description: "Test encrypted PKCS#8 key without passphrase"},
+		{keyFile: signingDir + "leaf.p12", passphrase: utils.NewPassphraseSource(passphraseFile), chainLength: 2,
+			description: "Test PKCS#12 bundle"},
+		{keyFile: signingDir + "leaf.p12", certFile: signingDir + "leaf.pem", passphrase: utils.NewPassphraseSource(passphraseFile), chainLength: 1,
+			description: "Test PKCS#12 bundle with explicit certificate file"},
+		{keyFile: signingDir + "leaf.p12", passphrase: wrongPassphrase, wantErr: true,
+			description: "Test PKCS#12 bundle with wrong passphrase"},
+		{keyFile: encryptedKey, passphrase: utils.NewPassphraseSource(passphraseFile), wantErr: true,
+			description: "Test PEM key without certificate file"},
+		{keyFile: signingDir + "leaf-iterations.key", certFile: signingDir + "chain.pem", passphrase: utils.NewPassphraseSource(passphraseFile),
+			wantErr: true, description: "Test encrypted PKCS#8 key with too many key derivation iterations"},
+		{keyFile: signingDir + "leaf-iterations.p12", passphrase: utils.NewPassphraseSource(passphraseFile), wantErr: true,
+			description: "Test PKCS#12 bundle with too many key derivation iterations"},
+	}
+
+	roots, err := utils.LoadCertPool(signingDir + "root.pem")
+	assert.NoError(t, err)
+	for _, tc := range tt {
+		privKey, certChain, err := utils.CheckKeyFiles(tc.keyFile, tc.certFile, tc.passphrase)
+		if tc.wantErr {
+			assert.Error(t, err, tc.description)
+			continue
+		}
+		assert.NoError(t, err, tc.description)
+		assert.Len(t, certChain, tc.chainLength, tc.description)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodPS384, models.PolicyClaims{AttestationPolicy: "default allow = true"})
+		token.Header[constants.KeyHeader] = certChain
+		tokenString, err := token.SignedString(privKey)
+		assert.NoError(t, err, tc.description)
+
+		// a verifier trusting only the root needs the intermediate from the x5c header
+		_, _, err = utils.VerifyPolicyToken(tokenString, roots, constants.SigningAlgorithms)
+		if tc.chainLength > 1 {
+			assert.NoError(t, err, tc.description)
+		} else {
+			assert.Error(t, err, tc.description)
+		}
+	}
+
+	// expired signing certificate
+	keyDir := t.TempDir()
+	expiredKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(expiredKey)
+	assert.NoError(t, err)
+	keyPath := filepath.Join(keyDir, "expired-key.pem")
+	assert.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: constants.PKCS8PrivateKeyType, Bytes: keyBytes}), 0600))
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Expired Test Cert"},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     time.Now().Add(-24 * time.Hour),
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, expiredKey.Public(), expiredKey)
+	assert.NoError(t, err)
+	certPath := filepath.Join(keyDir, "expired-cert.pem")
+	assert.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: constants.CertType, Bytes: certBytes}), 0600))
+	_, _, err = utils.CheckKeyFiles(keyPath, certPath, nil)
+	assert.Error(t, err)
+
+	// the passphrase file flag is used to decrypt the signing key, the token carrying the chain of the bundle
+	tokenString := createPolicyJwtForTests(t, "-p", signingDir+"leaf.p12", "-c", "",
+		"--"+constants.PassphraseFileParamName, passphraseFile)
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &models.PolicyClaims{})
+	assert.NoError(t, err)
+	assert.Len(t, token.Header[constants.KeyHeader], 2)
+	_, _, err = utils.VerifyPolicyToken(tokenString, roots, constants.SigningAlgorithms)
+	assert.NoError(t, err)
+}
+
+func TestGeneratePolicyJwtExternalSigners(t *testing.T) {
+	keyDir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 3072)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	rsaKeyFile, rsaCertFile := filepath.Join(keyDir, "rsa-key.pem"), filepath.Join(keyDir, "rsa-cert.pem")
+	ecKeyFile, ecCertFile := filepath.Join(keyDir, "ec-key.pem"), filepath.Join(keyDir, "ec-cert.pem")
+	edKeyFile, edCertFile := filepath.Join(keyDir, "ed-key.pem"), filepath.Join(keyDir, "ed-cert.pem")
+	writeKeyAndCertForTests(t, rsaKey, constants.PKCS8PrivateKeyType, rsaKeyFile, rsaCertFile)
+	writeKeyAndCertForTests(t, ecKey, constants.PKCS8PrivateKeyType, ecKeyFile, ecCertFile)
+	writeKeyAndCertForTests(t, edKey, constants.PKCS8PrivateKeyType, edKeyFile, edCertFile)
+
+	// the test binary stands in for the signing command and pkcs11-tool, see TestSignerHelperProcess
+	signCommand := os.Args[0] + " -test.run=TestSignerHelperProcess --"
+	pkcs11Tool := filepath.Join(keyDir, "pkcs11-tool")
+	assert.NoError(t, os.WriteFile(pkcs11Tool, []byte("#!/bin/sh\nexec "+signCommand+" \"$@\"\n"), 0700))
+	t.Setenv(constants.PKCS11ToolEnvVar, pkcs11Tool)
+	t.Setenv(signerHelperEnv, "1")
+	// the helper process creates its own test configuration file in the temporary directory on start up
+	t.Setenv("TMPDIR", keyDir)
+	pkcs11Uri := "pkcs11:token=policy-signing;object=policy%20key?module-path=/usr/lib/softhsm/libsofthsm2.so&pin-value=1234"
+
+	kmsToken := "kms-test-token"
+	t.Setenv(constants.KMSTokenEnvVar, kmsToken)
+	kmsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var signRequest signer.KMSSignRequest
+		if r.Header.Get(constants.HTTPHeaderKeyAuthorization) != "Bearer "+kmsToken || json.NewDecoder(r.Body).Decode(&signRequest) != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		digest, _ := base64.StdEncoding.DecodeString(signRequest.Digest)
+		if signRequest.KeyId != "policy-key" || signRequest.Algorithm != constants.ES384 || len(digest) != 48 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		signature, _ := ecdsa.SignASN1(rand.Reader, ecKey, digest)
+		_ = json.NewEncoder(w).Encode(signer.KMSSignResponse{Signature: base64.StdEncoding.EncodeToString(signature)})
+	}))
+	defer kmsServer.Close()
+
+	tt := []struct {
+		signerKey   string
+		flags       map[string]string
+		wantErr     bool
+		description string
+	}{
+		{signerKey: rsaKeyFile, flags: map[string]string{constants.SignCommandParamName: signCommand, constants.CertificateFileParamName: rsaCertFile},
+			description: "Test signing command with RSA key"},
+		{signerKey: rsaKeyFile, flags: map[string]string{constants.SignCommandParamName: signCommand, constants.CertificateFileParamName: rsaCertFile, constants.AlgorithmParamName: constants.RS384},
+			description: "Test signing command with RSA key and RS384"},
+		{signerKey: ecKeyFile, flags: map[string]string{constants.SignCommandParamName: signCommand, constants.CertificateFileParamName: ecCertFile},
+			description: "Test signing command returning DER encoded ECDSA signature"},
+		{signerKey: edKeyFile, flags: map[string]string{constants.SignCommandParamName: signCommand, constants.CertificateFileParamName: edCertFile},
+			description: "Test signing command with Ed25519 key"},
+		{signerKey: ecKeyFile, flags: map[string]string{constants.SignCommandParamName: signCommand, constants.CertificateFileParamName: rsaCertFile},
+			wantErr: true, description: "Test signing command with key not matching the certificate"},
+		{signerKey: rsaKeyFile, flags: map[string]string{constants.SignCommandParamName: "false", constants.CertificateFileParamName: rsaCertFile},
+			wantErr: true, description: "Test failing signing command"},
+		{flags: map[string]string{constants.SignCommandParamName: signCommand}, wantErr: true,
+			description: "Test signing command without certificate"},
+		{signerKey: rsaKeyFile, flags: map[string]string{constants.PKCS11URIParamName: pkcs11Uri, constants.CertificateFileParamName: rsaCertFile},
+			description: "Test PKCS#11 signer with RSA key"},
+		{signerKey: ecKeyFile, flags: map[string]string{constants.PKCS11URIParamName: pkcs11Uri, constants.CertificateFileParamName: ecCertFile},
+			description: "Test PKCS#11 signer with EC key"},
+		{signerKey: rsaKeyFile, flags: map[string]string{constants.PKCS11URIParamName: "pkcs11:object=policy-key", constants.CertificateFileParamName: rsaCertFile},
+			wantErr: true, description: "Test PKCS#11 URI without module path"},
+		{flags: map[string]string{constants.KMSUrlParamName: kmsServer.URL, constants.KMSKeyIdParamName: "policy-key", constants.CertificateFileParamName: ecCertFile},
+			description: "Test remote KMS signer"},
+		{flags: map[string]string{constants.KMSUrlParamName: kmsServer.URL, constants.KMSKeyIdParamName: "other-key", constants.CertificateFileParamName: ecCertFile},
+			wantErr: true, description: "Test remote KMS signer with unknown key"},
+		{flags: map[string]string{constants.KMSUrlParamName: kmsServer.URL, constants.CertificateFileParamName: ecCertFile},
+			wantErr: true, description: "Test remote KMS signer without key ID"},
+		{flags: map[string]string{constants.KMSUrlParamName: kmsServer.URL, constants.SignCommandParamName: signCommand, constants.CertificateFileParamName: ecCertFile},
+			wantErr: true, description: "Test more than one signer"},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, createPolicyJwtCmd)
+		t.Setenv(signerHelperKeyEnv, tc.signerKey)
+		for name, value := range tc.flags {
+			assert.NoError(t, createPolicyJwtCmd.Flags().Set(name, value), tc.description)
+		}
+
+		tokenString, algorithm, err := signPolicyClaims(createPolicyJwtCmd, models.PolicyClaims{AttestationPolicy: "default allow = true"})
+		if tc.wantErr {
+			assert.Error(t, err, tc.description)
+			continue
+		}
+		assert.NoError(t, err, tc.description)
+
+		roots, err := utils.LoadCertPool(tc.flags[constants.CertificateFileParamName])
+		assert.NoError(t, err, tc.description)
+		_, _, err = utils.VerifyPolicyToken(tokenString, roots, []string{algorithm})
+		assert.NoError(t, err, tc.description)
+	}
+
+	// the external signer flags are wired to the command
+	t.Setenv(signerHelperKeyEnv, rsaKeyFile)
+	tokenString := createPolicyJwtForTests(t, "--"+constants.SignCommandParamName, signCommand, "-c", rsaCertFile)
+	roots, err := utils.LoadCertPool(rsaCertFile)
+	assert.NoError(t, err)
+	_, _, err = utils.VerifyPolicyToken(tokenString, roots, constants.SigningAlgorithms)
+	assert.NoError(t, err)
+}
+
+// createPolicyJwtForTests signs the test policy with the signing flags provided and returns the token, written to a
+// temporary file
+func createPolicyJwtForTests(t *testing.T, signingArgs ...string) string {
+	resetFlagsForTests(t, createPolicyJwtCmd)
+	defer resetFlagsForTests(t, createPolicyJwtCmd)
+	createCmd.AddCommand(createPolicyJwtCmd)
+	tenantCmd.AddCommand(createCmd)
+	out := filepath.Join(t.TempDir(), "policy.signed.txt")
+	args := append([]string{constants.CreateCmd, constants.PolicyJwtCmd, "-f", "../test/resources/rego-policy.txt", "-s",
+		"-o", out}, signingArgs...)
+	_, err := execute(t, tenantCmd, args)
+	assert.NoError(t, err)
+	token, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	return string(token)
+}
+
+const (
+	signerHelperEnv    = "TRUSTAUTHORITY_TEST_SIGNER_HELPER"
+	signerHelperKeyEnv = "TRUSTAUTHORITY_TEST_SIGNER_KEY"
+)
+
+// TestSignerHelperProcess is not a real test, it is run by the external signer tests as a signing command and
+// as a pkcs11-tool stand-in that signs with the key file from the environment
+func TestSignerHelperProcess(t *testing.T) {
+	if os.Getenv(signerHelperEnv) != "1" {
+		t.Skip("only run as signing command")
+	}
+
+	args := flag.Args()
+	algorithm := os.Getenv(constants.SigningAlgorithmEnvVar)
+	var inputFile, outputFile string
+	for i := 0; i+1 < len(args); i++ {
+		switch args[i] {
+		case "--input-file":
+			inputFile = args[i+1]
+		case "--output-file":
+			outputFile = args[i+1]
+		case "--mechanism":
+			for alg, mechanism := range map[string]string{constants.RS384: "SHA384-RSA-PKCS", constants.PS384: "SHA384-RSA-PKCS-PSS",
+				constants.ES384: "ECDSA-SHA384", constants.EdDSA: "EDDSA"} {
+				if mechanism == args[i+1] {
+					algorithm = alg
+				}
+			}
+		}
+	}
+
+	var message []byte
+	var err error
+	if inputFile != "" {
+		message, err = os.ReadFile(inputFile)
+	} else {
+		message, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
+	keyBytes, err := os.ReadFile(os.Getenv(signerHelperKeyEnv))
+	if err != nil {
+		os.Exit(2)
+	}
+	key, err := utils.ParsePrivateKey(keyBytes, nil)
+	if err != nil {
+		os.Exit(2)
+	}
+
+	var signature []byte
+	digest := sha512.Sum384(message)
+	switch algorithm {
+	case constants.PS384:
+		signature, err = rsa.SignPSS(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA384, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case constants.RS384:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA384, digest[:])
+	case constants.ES384:
+		signature, err = ecdsa.SignASN1(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+	case constants.EdDSA:
+		signature = ed25519.Sign(key.(ed25519.PrivateKey), message)
+	default:
+		os.Exit(3)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
+	if outputFile != "" {
+		err = os.WriteFile(outputFile, signature, 0600)
+	} else {
+		_, err = os.Stdout.Write(signature)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+	os.Exit(0)
+}
+
+// resetFlagsForTests restores the default value of every flag of the command, as flag values are kept between executions
+func resetFlagsForTests(t *testing.T, c *cobra.Command) {
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		if sliceValue, ok := f.Value.(pflag.SliceValue); ok {
+			assert.NoError(t, sliceValue.Replace(nil))
+		} else {
+			assert.NoError(t, f.Value.Set(f.DefValue))
+		}
+		f.Changed = false
+	})
+}
+
+// writeKeyAndCertForTests writes the private key in the requested PEM format along with a self-signed certificate
+func writeKeyAndCertForTests(t *testing.T, key crypto.Signer, pemType, keyPath, certPath string) {
+	var keyBytes []byte
+	var err error
+	switch pemType {
+	case constants.RSAPrivateKeyType:
+		keyBytes = x509.MarshalPKCS1PrivateKey(key.(*rsa.PrivateKey))
+	case constants.ECPrivateKeyType:
+		keyBytes, err = x509.MarshalECPrivateKey(key.(*ecdsa.PrivateKey))
+	default:
+		keyBytes, err = x509.MarshalPKCS8PrivateKey(key)
+	}
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: keyBytes}), 0600))
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject: pkix.Name{
+			Organization: []string{"Test Co"},
+		},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: constants.CertType, Bytes: certBytes}), 0600))
+}
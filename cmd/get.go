@@ -0,0 +1,23 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"intel/tac/v1/constants"
+
+	"github.com/spf13/cobra"
+)
+
+// getCmd represents the get command
+var getCmd = &cobra.Command{
+	Use:   constants.GetCmd,
+	Short: "Get a single resource, by id or name, with its full detail",
+	Long:  ``,
+}
+
+func init() {
+	tenantCmd.AddCommand(getCmd)
+}
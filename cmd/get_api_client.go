@@ -0,0 +1,183 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/internal/registry"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// getApiClientDetailCmd represents the get apiClient command
+var getApiClientDetailCmd = &cobra.Command{
+	Use:   constants.ApiClientCmd + " <api client id or name>",
+	Short: "Get an api client with the names of its service and policies and its tags",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("get apiClient called")
+		response, err := getApiClientDetail(cmd, args[0])
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("ApiClient: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	getCmd.AddCommand(getApiClientDetailCmd)
+
+	getApiClientDetailCmd.Flags().StringP(constants.ServiceIdParamName, "r", "", "Id of the Trust Authority service of the api client, "+
+		"every service is searched otherwise")
+	getApiClientDetailCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	addKeyOutputFlags(getApiClientDetailCmd)
+}
+
+func getApiClientDetail(cmd *cobra.Command, reference string) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	serviceIdString, err := cmd.Flags().GetString(constants.ServiceIdParamName)
+	if err != nil {
+		return "", err
+	}
+	var serviceId uuid.UUID
+	if serviceIdString != "" {
+		if serviceId, err = uuid.Parse(serviceIdString); err != nil {
+			return "", errors.Wrap(err, "Invalid service id provided")
+		}
+	}
+
+	keyOutput, err := apiClientKeyOutput(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	services, err := tmsClient.GetServices()
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the services of the tenant")
+	}
+
+	var matches []models2.ApiClientView
+	for _, service := range services {
+		if serviceIdString != "" && service.ID != serviceId {
+			continue
+		}
+		apiClients, err := tmsClient.GetApiClient(service.ID)
+		if err != nil {
+			return "", errors.Wrapf(err, "Error fetching the API clients of service %s", service.ID)
+		}
+		for _, apiClient := range apiClients {
+			if lookup.Matches(reference, apiClient.ID, apiClient.Name) {
+				matches = append(matches, models2.ApiClientView{
+					ApiClientDetail: models.ApiClientDetail{ID: apiClient.ID, ServiceId: service.ID},
+					ServiceName:     service.Name,
+				})
+			}
+		}
+	}
+	if err = lookup.CheckMatches(constants.ApiClientCmd, reference, len(matches)); err != nil {
+		return "", err
+	}
+
+	view := matches[0]
+	detail, err := tmsClient.RetrieveApiClient(view.ServiceId, view.ID)
+	if err != nil {
+		return "", err
+	}
+	policies, err := tmsClient.GetApiClientPolicies(view.ServiceId, view.ID)
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the policies of the api client")
+	}
+	tags, err := tmsClient.GetApiClientTagValues(view.ServiceId, view.ID)
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the tags of the api client")
+	}
+	detail.PolicyIds = policies.PolicyIds
+	detail.TagsValues = tags.TagsValues
+
+	if err = deliverApiClientKeys(cmd, keyOutput, detail); err != nil {
+		return "", err
+	}
+	view.ApiClientDetail = *detail
+	view.Policies = apiClientPolicies(pms.NewPmsClient(client, pmsUrl, apiKey), detail.PolicyIds)
+
+	responseBytes, err := json.MarshalIndent(view, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes), nil
+}
+
+// apiClientPolicies resolves the names and deprecation of the policies. Policies are listed by id alone when the
+// policies of the tenant cannot be fetched.
+func apiClientPolicies(pmsClient pms.PmsClient, policyIds []uuid.UUID) []models2.ApiClientPolicy {
+	policies := []models2.ApiClientPolicy{}
+	for _, policyId := range policyIds {
+		policies = append(policies, models2.ApiClientPolicy{PolicyId: policyId})
+	}
+
+	tenantPolicies, err := pmsClient.SearchPolicy()
+	if err != nil {
+		log.WithError(err).Warn("Unable to fetch the policies of the tenant, policies are listed without their names")
+		return policies
+	}
+	names := map[uuid.UUID]string{}
+	for _, policy := range tenantPolicies {
+		names[policy.PolicyId] = policy.PolicyName
+	}
+	r, err := registry.Load()
+	if err != nil {
+		log.WithError(err).Warn("Unable to read the policy registry, policies are listed without their deprecation")
+		r = &registry.Registry{}
+	}
+	for i := range policies {
+		name, ok := names[policies[i].PolicyId]
+		policies[i].PolicyName = name
+		policies[i].Missing = !ok
+		if annotation := r.Get(policies[i].PolicyId); annotation != nil {
+			policies[i].Deprecated = annotation.Deprecated
+		}
+	}
+	return policies
+}
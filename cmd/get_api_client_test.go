@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/keysink"
+	"intel/tac/v1/internal/lookup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/internal/registry"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetApiClientCmd(t *testing.T) {
+	serviceId := uuid.New()
+	otherServiceId := uuid.New()
+	policyIds := []uuid.UUID{uuid.New()}
+	tags := []models.ApiClientTagValue{{Name: "Workload", Value: "Payments"}}
+	apiClients := []models.ApiClientDetail{
+		{ID: uuid.New(), ServiceId: serviceId, Status: constants.ApiClientStatusActive, Name: "payments-workload",
+			Keys: []string{"payments-key-1", "payments-key-2"}, PolicyIds: policyIds, TagsValues: tags},
+		{ID: uuid.New(), ServiceId: serviceId, Status: constants.ApiClientStatusActive, Name: "shared-name"},
+		{ID: uuid.New(), ServiceId: otherServiceId, Status: constants.ApiClientStatusActive, Name: "shared-name"},
+	}
+	server := test.ApiClientMockServer(t, &apiClients)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+	t.Setenv("HOME", t.TempDir())
+	keyFile := filepath.Join(t.TempDir(), "payments.key")
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{"payments-workload"},
+			wantErr:     false,
+			description: "Test get an api client by name",
+		},
+		{
+			args:        []string{apiClients[0].ID.String(), "-r", serviceId.String(), "--key-output", keyFile},
+			wantErr:     false,
+			description: "Test get an api client by id with its keys written to a file",
+		},
+		{
+			args:        []string{apiClients[2].ID.String()},
+			wantErr:     false,
+			description: "Test get an api client by id when its name is shared",
+		},
+		{
+			args:        []string{"shared-name", "-r", otherServiceId.String()},
+			wantErr:     false,
+			description: "Test get an api client by a name shared with another service",
+		},
+		{
+			args:        []string{"shared-name"},
+			wantErr:     true,
+			description: "Test get an api client by a name shared by several api clients",
+		},
+		{
+			args:        []string{"payments-workload", "-r", otherServiceId.String()},
+			wantErr:     true,
+			description: "Test get an api client of another service",
+		},
+		{
+			args:        []string{"payments-workload", "-r", "invalid-id"},
+			wantErr:     true,
+			description: "Test get an api client with an invalid service id",
+		},
+		{
+			args:        []string{},
+			wantErr:     true,
+			description: "Test get an api client without a reference",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, getApiClientDetailCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.GetCmd, constants.ApiClientCmd}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, getApiClientDetailCmd)
+	defer resetFlagsForTests(t, getApiClientDetailCmd)
+
+	keys, err := os.ReadFile(keyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "payments-key-1\npayments-key-2\n", string(keys))
+
+	// the policies and tags are listed along with the masked keys
+	response, err := getApiClientDetail(getApiClientDetailCmd, "payments-workload")
+	assert.NoError(t, err)
+	var view models2.ApiClientView
+	assert.NoError(t, json.Unmarshal([]byte(response), &view))
+	assert.Equal(t, apiClients[0].ID, view.ID)
+	assert.Equal(t, tags, view.TagsValues)
+	assert.Equal(t, []string{keysink.Mask("payments-key-1"), keysink.Mask("payments-key-2")}, view.Keys)
+	if assert.Len(t, view.Policies, 1) {
+		// the policies of the tenant cannot be fetched from the mock server
+		assert.Equal(t, models2.ApiClientPolicy{PolicyId: policyIds[0]}, view.Policies[0])
+	}
+
+	_, err = getApiClientDetail(getApiClientDetailCmd, "unknown-workload")
+	var notFound *lookup.NotFoundError
+	assert.True(t, errors.As(err, &notFound))
+}
+
+func TestApiClientPolicies(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	deprecatedPolicy := models.PolicyResponse{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "deprecated-policy"}}
+	policy := models.PolicyResponse{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "policy"}}
+	missingPolicyId := uuid.New()
+	server := test.PolicyMockServer(t, []models.PolicyResponse{deprecatedPolicy, policy})
+	defer server.Close()
+
+	r, err := registry.Load()
+	assert.NoError(t, err)
+	r.Annotate(deprecatedPolicy.PolicyId).Deprecated = true
+	assert.NoError(t, r.Save())
+
+	pmsUrl, err := url.Parse(server.URL + constants.PmsBaseUrl)
+	assert.NoError(t, err)
+	pmsClient := pms.NewPmsClient(&http.Client{Timeout: time.Second}, pmsUrl, "")
+
+	assert.Equal(t, []models2.ApiClientPolicy{
+		{PolicyId: policy.PolicyId, PolicyName: "policy"},
+		{PolicyId: deprecatedPolicy.PolicyId, PolicyName: "deprecated-policy", Deprecated: true},
+		{PolicyId: missingPolicyId, Missing: true},
+	}, apiClientPolicies(pmsClient, []uuid.UUID{policy.PolicyId, deprecatedPolicy.PolicyId, missingPolicyId}))
+	assert.Equal(t, []models2.ApiClientPolicy{}, apiClientPolicies(pmsClient, nil))
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// getPlanDetailCmd represents the get plan command
+var getPlanDetailCmd = &cobra.Command{
+	Use:   constants.PlanCmd + " <plan id or name>",
+	Short: "Get a plan along with its products and the name of its service offer",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("get plan called")
+		response, err := getPlanDetail(cmd, args[0])
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Plan: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	getCmd.AddCommand(getPlanDetailCmd)
+
+	getPlanDetailCmd.Flags().StringP(constants.ServiceOfferIdParamName, "r", "", "Id of the Trust Authority service offer of the plan, "+
+		"every service offer is searched otherwise")
+	getPlanDetailCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+}
+
+func getPlanDetail(cmd *cobra.Command, reference string) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	serviceOffers, err := serviceOffersOfFlag(cmd, tmsClient)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []models2.PlanView
+	for _, serviceOffer := range serviceOffers {
+		plans, err := tmsClient.GetPlans(serviceOffer.ID)
+		if err != nil {
+			return "", errors.Wrapf(err, "Error fetching the plans of service offer %s", serviceOffer.ID)
+		}
+		for _, plan := range plans {
+			if lookup.Matches(reference, plan.ID, plan.Name) {
+				matches = append(matches, models2.PlanView{
+					PlanProducts:     models.PlanProducts{ID: plan.ID, ServiceOfferId: serviceOffer.ID},
+					ServiceOfferName: serviceOffer.Name,
+				})
+			}
+		}
+	}
+	if err = lookup.CheckMatches(constants.PlanCmd, reference, len(matches)); err != nil {
+		return "", err
+	}
+
+	view := matches[0]
+	plan, err := tmsClient.RetrievePlan(view.ServiceOfferId, view.ID)
+	if err != nil {
+		return "", err
+	}
+	view.PlanProducts = *plan
+
+	responseBytes, err := json.MarshalIndent(view, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes), nil
+}
+
+// serviceOffersOfFlag returns the service offer of the --service-offer-id flag, every service offer when the flag is
+// not provided
+func serviceOffersOfFlag(cmd *cobra.Command, tmsClient tms.TmsClient) ([]models.ServiceOffer, error) {
+	serviceOfferIdString, err := cmd.Flags().GetString(constants.ServiceOfferIdParamName)
+	if err != nil {
+		return nil, err
+	}
+	serviceOffers, err := tmsClient.GetServiceOffers()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the service offers")
+	}
+	if serviceOfferIdString == "" {
+		return serviceOffers, nil
+	}
+
+	serviceOfferId, err := uuid.Parse(serviceOfferIdString)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid service offer id provided")
+	}
+	for _, serviceOffer := range serviceOffers {
+		if serviceOffer.ID == serviceOfferId {
+			return []models.ServiceOffer{serviceOffer}, nil
+		}
+	}
+	return nil, &lookup.NotFoundError{Resource: "service offer", Reference: serviceOfferIdString}
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
+	"intel/tac/v1/internal/registry"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// getPolicyDetailCmd represents the get policy command
+var getPolicyDetailCmd = &cobra.Command{
+	Use:   constants.PolicyCmd + " <policy id or name>",
+	Short: "Get a policy along with its labels, description and deprecation",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("get policy called")
+		response, err := getPolicyDetail(cmd, args[0])
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Policy: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	getCmd.AddCommand(getPolicyDetailCmd)
+
+	getPolicyDetailCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+}
+
+func getPolicyDetail(cmd *cobra.Command, reference string) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	httpClient := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	r, err := registry.Load()
+	if err != nil {
+		return "", err
+	}
+
+	pmsClient := pms.NewPmsClient(httpClient, pmsUrl, apiKey)
+	var policy *models.PolicyResponse
+	if policyId, parseErr := uuid.Parse(reference); parseErr == nil {
+		policy, err = pmsClient.GetPolicy(policyId)
+		if client.IsNotFound(err) {
+			return "", &lookup.NotFoundError{Resource: constants.PolicyCmd, Reference: reference}
+		}
+		if err != nil {
+			return "", err
+		}
+	} else {
+		policies, err := pmsClient.SearchPolicy()
+		if err != nil {
+			return "", errors.Wrap(err, "Error fetching the policies of the tenant")
+		}
+		var matches []models.PolicyResponse
+		for _, p := range policies {
+			if p.PolicyName == reference {
+				matches = append(matches, p)
+			}
+		}
+		if err = lookup.CheckMatches(constants.PolicyCmd, reference, len(matches)); err != nil {
+			return "", err
+		}
+		policy = &matches[0]
+	}
+
+	responseBytes, err := json.MarshalIndent(annotatePolicies(r, []models.PolicyResponse{*policy})[0], "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes), nil
+}
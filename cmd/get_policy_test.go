@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/internal/registry"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"testing"
+)
+
+func TestGetPolicyCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	policies := []models.PolicyResponse{
+		{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "payments-policy"}},
+		{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "shared-policy"}},
+		{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "shared-policy"}},
+	}
+	server := test.PolicyMockServer(t, policies)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	r, err := registry.Load()
+	assert.NoError(t, err)
+	r.Annotate(policies[0].PolicyId).Labels = map[string]string{"team": "payments"}
+	assert.NoError(t, r.Save())
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		notFound    bool
+		description string
+	}{
+		{
+			args:        []string{"payments-policy"},
+			wantErr:     false,
+			description: "Test get a policy by name",
+		},
+		{
+			args:        []string{policies[1].PolicyId.String()},
+			wantErr:     false,
+			description: "Test get a policy by id when its name is shared",
+		},
+		{
+			args:        []string{"shared-policy"},
+			wantErr:     true,
+			description: "Test get a policy by a name shared by several policies",
+		},
+		{
+			args:        []string{"unknown-policy"},
+			wantErr:     true,
+			notFound:    true,
+			description: "Test get a policy by an unknown name",
+		},
+		{
+			args:        []string{uuid.NewString()},
+			wantErr:     true,
+			notFound:    true,
+			description: "Test get a policy by an unknown id",
+		},
+		{
+			args:        []string{"payments-policy", "-q", "@#$invalid-id"},
+			wantErr:     true,
+			description: "Test get a policy with an invalid request id",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, getPolicyDetailCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.GetCmd, constants.PolicyCmd}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+		var notFound *lookup.NotFoundError
+		assert.Equal(t, tc.notFound, errors.As(err, &notFound), tc.description)
+	}
+	resetFlagsForTests(t, getPolicyDetailCmd)
+
+	// the policy is returned with its local annotation
+	response, err := getPolicyDetail(getPolicyDetailCmd, "payments-policy")
+	assert.NoError(t, err)
+	var policy models2.AnnotatedPolicy
+	assert.NoError(t, json.Unmarshal([]byte(response), &policy))
+	assert.Equal(t, policies[0].PolicyId, policy.PolicyId)
+	assert.Equal(t, map[string]string{"team": "payments"}, policy.Labels)
+}
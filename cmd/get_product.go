@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// getProductDetailCmd represents the get product command
+var getProductDetailCmd = &cobra.Command{
+	Use:   constants.ProductCmd + " <product id or name>",
+	Short: "Get a product along with the name of its service offer",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("get product called")
+		response, err := getProductDetail(cmd, args[0])
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Product: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	getCmd.AddCommand(getProductDetailCmd)
+
+	getProductDetailCmd.Flags().StringP(constants.ServiceOfferIdParamName, "r", "", "Id of the Trust Authority service offer of the product, "+
+		"every service offer is searched otherwise")
+	getProductDetailCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+}
+
+func getProductDetail(cmd *cobra.Command, reference string) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	serviceOffers, err := serviceOffersOfFlag(cmd, tmsClient)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []models2.ProductView
+	for _, serviceOffer := range serviceOffers {
+		products, err := tmsClient.GetProducts(serviceOffer.ID)
+		if err != nil {
+			return "", errors.Wrapf(err, "Error fetching the products of service offer %s", serviceOffer.ID)
+		}
+		for _, product := range products {
+			if lookup.Matches(reference, product.ID, product.Name) {
+				matches = append(matches, models2.ProductView{Product: product, ServiceOfferName: serviceOffer.Name})
+			}
+		}
+	}
+	if err = lookup.CheckMatches(constants.ProductCmd, reference, len(matches)); err != nil {
+		return "", err
+	}
+
+	responseBytes, err := json.MarshalIndent(matches[0], "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes), nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// getServiceDetailCmd represents the get service command
+var getServiceDetailCmd = &cobra.Command{
+	Use:   constants.ServiceCmd + " <service id or name>",
+	Short: "Get a service of the tenant along with its api clients",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("get service called")
+		response, err := getServiceDetail(cmd, args[0])
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Service: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	getCmd.AddCommand(getServiceDetailCmd)
+
+	getServiceDetailCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+}
+
+func getServiceDetail(cmd *cobra.Command, reference string) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	services, err := tmsClient.GetServices()
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the services of the tenant")
+	}
+	var matches []models.Service
+	for _, service := range services {
+		if lookup.Matches(reference, service.ID, service.Name) {
+			matches = append(matches, service)
+		}
+	}
+	if err = lookup.CheckMatches(constants.ServiceCmd, reference, len(matches)); err != nil {
+		return "", err
+	}
+
+	service, err := tmsClient.RetrieveService(matches[0].ID)
+	if err != nil {
+		return "", err
+	}
+	apiClients, err := tmsClient.GetApiClient(service.ID)
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the api clients of the service")
+	}
+	if apiClients == nil {
+		apiClients = []models.ApiClient{}
+	}
+
+	responseBytes, err := json.MarshalIndent(models2.ServiceView{ServiceDetail: *service, ApiClients: apiClients}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes), nil
+}
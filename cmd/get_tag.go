@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// getTagDetailCmd represents the get tag command
+var getTagDetailCmd = &cobra.Command{
+	Use:   constants.TagCmd + " <tag id or name>",
+	Short: "Get a tag of the tenant",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("get tag called")
+		response, err := getTagDetail(cmd, args[0])
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Tag: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	getCmd.AddCommand(getTagDetailCmd)
+
+	getTagDetailCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+}
+
+func getTagDetail(cmd *cobra.Command, reference string) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	tags, err := tmsClient.GetTenantTags()
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the tags of the tenant")
+	}
+	var matches []models.Tag
+	for _, tag := range tags.Tags {
+		if (tag.ID != nil && lookup.Matches(reference, *tag.ID, tag.Name)) || (tag.ID == nil && reference == tag.Name) {
+			matches = append(matches, tag)
+		}
+	}
+	if err = lookup.CheckMatches(constants.TagCmd, reference, len(matches)); err != nil {
+		return "", err
+	}
+
+	responseBytes, err := json.MarshalIndent(matches[0], "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes), nil
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
+	"intel/tac/v1/test"
+	"strings"
+	"testing"
+)
+
+func TestGetCmd(t *testing.T) {
+	server := test.MockServer(t)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		notFound    bool
+		description string
+	}{
+		{
+			args:        []string{constants.ServiceCmd, "Test Service"},
+			wantErr:     false,
+			description: "Test get a service by name",
+		},
+		{
+			args:        []string{constants.ServiceCmd, "5cfb6af4-59ac-4a14-8b83-bd65b1e11777"},
+			wantErr:     false,
+			description: "Test get a service by id",
+		},
+		{
+			args:        []string{constants.ServiceCmd, "Unknown Service"},
+			wantErr:     true,
+			notFound:    true,
+			description: "Test get an unknown service",
+		},
+		{
+			args:        []string{constants.PlanCmd, "Basic"},
+			wantErr:     false,
+			description: "Test get a plan by name",
+		},
+		{
+			args:        []string{constants.PlanCmd, "8f2a20fa-b08d-48a8-b2b4-2ebd1feb6f74", "-r", "ae3d7720-08ab-421c-b8d4-1725c358f03e"},
+			wantErr:     false,
+			description: "Test get a plan by id of a service offer",
+		},
+		{
+			args:        []string{constants.PlanCmd, "Basic", "-r", uuid.NewString()},
+			wantErr:     true,
+			notFound:    true,
+			description: "Test get a plan of an unknown service offer",
+		},
+		{
+			args:        []string{constants.PlanCmd, "Basic", "-r", "invalid-id"},
+			wantErr:     true,
+			description: "Test get a plan with an invalid service offer id",
+		},
+		{
+			args:        []string{constants.ProductCmd, "Enterprise"},
+			wantErr:     false,
+			description: "Test get a product by name",
+		},
+		{
+			args:        []string{constants.ProductCmd, "Unknown"},
+			wantErr:     true,
+			notFound:    true,
+			description: "Test get an unknown product",
+		},
+		{
+			args:        []string{constants.UserCmd, "ArijitGh@gmail.com"},
+			wantErr:     false,
+			description: "Test get a user by email id",
+		},
+		{
+			args:        []string{constants.UserCmd, "23011406-6f3b-4431-9363-4e1af9af6b13"},
+			wantErr:     false,
+			description: "Test get a user by id",
+		},
+		{
+			args:        []string{constants.UserCmd, "unknown@example.com"},
+			wantErr:     true,
+			notFound:    true,
+			description: "Test get an unknown user",
+		},
+		{
+			args:        []string{constants.TagCmd, "Workload"},
+			wantErr:     false,
+			description: "Test get a tag by name",
+		},
+		{
+			args:        []string{constants.TagCmd, "c0b2d143-c9f5-4137-88db-1f2a8e666d0c"},
+			wantErr:     false,
+			description: "Test get a tag by id",
+		},
+		{
+			args:        []string{constants.TagCmd, "Unknown"},
+			wantErr:     true,
+			notFound:    true,
+			description: "Test get an unknown tag",
+		},
+		{
+			args:        []string{constants.TagCmd, "Workload", "-q", "@#$invalid-id"},
+			wantErr:     true,
+			description: "Test get a tag with an invalid request id",
+		},
+	}
+
+	for _, tc := range tt {
+		for _, c := range getCmd.Commands() {
+			resetFlagsForTests(t, c)
+		}
+		_, err := execute(t, tenantCmd, append([]string{constants.GetCmd}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+		var notFound *lookup.NotFoundError
+		assert.Equal(t, tc.notFound, errors.As(err, &notFound), tc.description)
+	}
+	for _, c := range getCmd.Commands() {
+		resetFlagsForTests(t, c)
+	}
+}
+
+func TestCheckReferenceMatches(t *testing.T) {
+	id := uuid.New()
+	assert.True(t, lookup.Matches(id.String(), id, "name"))
+	assert.True(t, lookup.Matches(strings.ToUpper(id.String()), id, "name"))
+	assert.True(t, lookup.Matches("name", id, "name"))
+	assert.False(t, lookup.Matches("Name", id, "name"))
+
+	assert.NoError(t, lookup.CheckMatches(constants.TagCmd, "name", 1))
+	err := lookup.CheckMatches(constants.TagCmd, "name", 0)
+	assert.EqualError(t, err, `tag "name" not found`)
+	_, ok := errors.Cause(err).(*lookup.NotFoundError)
+	assert.True(t, ok)
+	err = lookup.CheckMatches(constants.TagCmd, "name", 2)
+	assert.Error(t, err)
+	_, ok = errors.Cause(err).(*lookup.NotFoundError)
+	assert.False(t, ok)
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// getUserDetailCmd represents the get user command
+var getUserDetailCmd = &cobra.Command{
+	Use:   constants.UserCmd + " <user id or email id>",
+	Short: "Get a user of the tenant along with its role",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("get user called")
+		response, err := getUserDetail(cmd, args[0])
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("User: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	getCmd.AddCommand(getUserDetailCmd)
+
+	getUserDetailCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+}
+
+func getUserDetail(cmd *cobra.Command, reference string) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
+	users, err := tmsClient.GetUsers()
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching the users of the tenant")
+	}
+	var matches []models.TenantUser
+	for _, user := range users {
+		// email ids are case insensitive
+		if lookup.Matches(reference, user.ID, user.Email) || strings.EqualFold(reference, user.Email) {
+			matches = append(matches, user)
+		}
+	}
+	if err = lookup.CheckMatches(constants.UserCmd, reference, len(matches)); err != nil {
+		return "", err
+	}
+
+	responseBytes, err := json.MarshalIndent(matches[0], "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes), nil
+}
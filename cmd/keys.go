@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"crypto"
+	"crypto/x509"
+	"github.com/spf13/cobra"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/utils"
+	"time"
+)
+
+// keysCmd groups the commands managing the keys used to sign policies
+var keysCmd = &cobra.Command{
+	Use:   constants.KeysCmd,
+	Short: "Generate and inspect policy signing keys",
+	Long:  ``,
+}
+
+func init() {
+	tenantCmd.AddCommand(keysCmd)
+}
+
+// describeSigningKey reports the type and size of the key along with the signing algorithms it can be used with,
+// the first one being the algorithm derived when none is provided
+func describeSigningKey(key crypto.Signer) *models2.SigningKeyInfo {
+	info := &models2.SigningKeyInfo{Algorithms: utils.SigningAlgorithmsForKey(key)}
+	info.KeyType, info.KeySize = utils.DescribeKey(key.Public())
+	if info.Algorithms == nil {
+		info.Algorithms = []string{}
+	} else {
+		info.DefaultAlgorithm = info.Algorithms[0]
+	}
+	return info
+}
+
+// describeCertificate reports whether the leaf certificate of the chain belongs to the key and whether the chain
+// is currently valid
+func describeCertificate(key crypto.Signer, chain []*x509.Certificate) *models2.CertificateInfo {
+	cert := chain[0]
+	info := &models2.CertificateInfo{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		NotBefore: cert.NotBefore.UTC(),
+		NotAfter:  cert.NotAfter.UTC(),
+	}
+	if err := utils.CheckKeyMatchesCertificate(key, cert); err != nil {
+		info.Reason = err.Error()
+		return info
+	}
+	info.MatchesKey = true
+	if err := utils.ValidateCertificateChain(chain, time.Now()); err != nil {
+		info.Reason = err.Error()
+		return info
+	}
+	info.Valid = true
+	return info
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/utils"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// keysGenerateCmd represents the keys generate command
+var keysGenerateCmd = &cobra.Command{
+	Use:   constants.GenerateCmd,
+	Short: "Generate a policy signing key along with a self-signed certificate or a certificate signing request",
+	Long: `Generate a private key sized for the policy signing algorithms along with a matching self-signed certificate,
+to be used with "create policy-jwt -p <key> -c <certificate>", and/or a certificate signing request to be
+submitted to a certificate authority. The private key is written unencrypted and only readable by the current user.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("keys generate called")
+		response, err := generateSigningKey(cmd)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Policy signing key: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	keysCmd.AddCommand(keysGenerateCmd)
+
+	keysGenerateCmd.Flags().String(constants.KeyTypeParamName, constants.DefaultKeyType, "Type of the key ("+strings.Join(utils.SigningKeyTypes(), "|")+")")
+	keysGenerateCmd.Flags().String(constants.OutKeyParamName, "", "Path of the file to which the private key is written")
+	keysGenerateCmd.Flags().String(constants.OutCertParamName, "", "Path of the file to which the self-signed certificate is written")
+	keysGenerateCmd.Flags().String(constants.OutCsrParamName, "", "Path of the file to which the certificate signing request is written")
+	keysGenerateCmd.Flags().String(constants.SubjectParamName, "", "Subject of the certificate, example \"/C=US/O=Example/CN=Policy signer\" or \"CN=Policy signer,O=Example,C=US\"")
+	keysGenerateCmd.Flags().Int(constants.DaysParamName, constants.DefaultCertificateDays, "Number of days the self-signed certificate is valid")
+	keysGenerateCmd.Flags().Bool(constants.ForceParamName, false, "Replace the output files when they already exist")
+	keysGenerateCmd.MarkFlagRequired(constants.OutKeyParamName)
+	keysGenerateCmd.MarkFlagRequired(constants.SubjectParamName)
+}
+
+func generateSigningKey(cmd *cobra.Command) (string, error) {
+	keyType, err := cmd.Flags().GetString(constants.KeyTypeParamName)
+	if err != nil {
+		return "", err
+	}
+	keyFile, err := cmd.Flags().GetString(constants.OutKeyParamName)
+	if err != nil {
+		return "", err
+	}
+	certFile, err := cmd.Flags().GetString(constants.OutCertParamName)
+	if err != nil {
+		return "", err
+	}
+	csrFile, err := cmd.Flags().GetString(constants.OutCsrParamName)
+	if err != nil {
+		return "", err
+	}
+	subjectString, err := cmd.Flags().GetString(constants.SubjectParamName)
+	if err != nil {
+		return "", err
+	}
+	days, err := cmd.Flags().GetInt(constants.DaysParamName)
+	if err != nil {
+		return "", err
+	}
+	force, err := cmd.Flags().GetBool(constants.ForceParamName)
+	if err != nil {
+		return "", err
+	}
+
+	if keyFile == "" {
+		return "", errors.New("Private key file path cannot be empty")
+	}
+	if certFile == "" && csrFile == "" {
+		return "", errors.Errorf("Either --%s or --%s needs to be provided", constants.OutCertParamName, constants.OutCsrParamName)
+	}
+	if days <= 0 {
+		return "", errors.New("Number of days the certificate is valid should be greater than 0")
+	}
+	subject, err := utils.ParseSubject(subjectString)
+	if err != nil {
+		return "", err
+	}
+
+	// every output is checked before writing any of them, so that a failure does not leave a key without certificate
+	outputs := map[string]bool{}
+	for _, output := range []string{keyFile, certFile, csrFile} {
+		if output == "" {
+			continue
+		}
+		cleaned := filepath.Clean(output)
+		if outputs[cleaned] {
+			return "", errors.Errorf("%s is used for more than one output", output)
+		}
+		outputs[cleaned] = true
+		if _, err = os.Stat(cleaned); err == nil && !force {
+			return "", errors.Errorf("%s already exists, use --%s to replace it", output, constants.ForceParamName)
+		}
+	}
+
+	key, err := utils.GenerateSigningKey(keyType)
+	if err != nil {
+		return "", err
+	}
+	if err = utils.WritePrivateKey(keyFile, key, force); err != nil {
+		return "", err
+	}
+	info := describeSigningKey(key)
+	info.KeyFile = keyFile
+
+	if certFile != "" {
+		cert, err := utils.CreateSelfSignedCertificate(key, subject, days)
+		if err != nil {
+			return "", err
+		}
+		if err = utils.WritePEMFile(certFile, constants.CertType, cert.Raw, constants.CertificateFilePermission, force); err != nil {
+			return "", err
+		}
+		info.Certificate = describeCertificate(key, []*x509.Certificate{cert})
+		info.Certificate.CertificateFile = certFile
+	}
+	if csrFile != "" {
+		csr, err := utils.CreateCertificateRequest(key, subject)
+		if err != nil {
+			return "", err
+		}
+		if err = utils.WritePEMFile(csrFile, constants.CertificateRequestType, csr, constants.CertificateFilePermission, force); err != nil {
+			return "", err
+		}
+		info.CertificateRequest = csrFile
+	}
+	return formatSigningKeyInfo(info)
+}
+
+func formatSigningKeyInfo(info *models2.SigningKeyInfo) (string, error) {
+	infoBytes, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(infoBytes), nil
+}
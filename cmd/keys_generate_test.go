@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/utils"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeysGenerateCmd(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := filepath.Join(dir, "rsa.key")
+	rsaCert := filepath.Join(dir, "rsa.crt")
+	ecKey := filepath.Join(dir, "ec.key")
+	ecCert := filepath.Join(dir, "ec.crt")
+	ecCsr := filepath.Join(dir, "ec.csr")
+
+	tenantCmd.AddCommand(keysCmd)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{"--type", constants.KeyTypeRSA2048, "--out-key", rsaKey, "--out-cert", rsaCert, "--subject", "/C=US/O=Example/CN=Policy signer"},
+			wantErr:     false,
+			description: "Test generate an RSA key and self-signed certificate",
+		},
+		{
+			args:        []string{"--type", constants.KeyTypeECP384, "--out-key", ecKey, "--out-cert", ecCert, "--out-csr", ecCsr, "--subject", "CN=Policy signer,O=Example", "--days", "30"},
+			wantErr:     false,
+			description: "Test generate an EC key, self-signed certificate and certificate signing request",
+		},
+		{
+			args:        []string{"--type", constants.KeyTypeRSA2048, "--out-key", rsaKey, "--out-cert", rsaCert, "--subject", "CN=Policy signer"},
+			wantErr:     true,
+			description: "Test generate a key to an existing file",
+		},
+		{
+			args:        []string{"--type", constants.KeyTypeEd25519, "--out-key", rsaKey, "--out-cert", rsaCert, "--subject", "CN=Policy signer", "--force"},
+			wantErr:     false,
+			description: "Test replace an existing key",
+		},
+		{
+			args:        []string{"--out-key", filepath.Join(dir, "other.key"), "--subject", "CN=Policy signer"},
+			wantErr:     true,
+			description: "Test generate a key without certificate or certificate signing request",
+		},
+		{
+			args:        []string{"--type", "rsa1024", "--out-key", filepath.Join(dir, "other.key"), "--out-cert", filepath.Join(dir, "other.crt"), "--subject", "CN=Policy signer"},
+			wantErr:     true,
+			description: "Test generate a key of an unsupported type",
+		},
+		{
+			args:        []string{"--out-key", filepath.Join(dir, "other.key"), "--out-cert", filepath.Join(dir, "other.key"), "--subject", "CN=Policy signer"},
+			wantErr:     true,
+			description: "Test write the key and certificate to the same file",
+		},
+		{
+			args:        []string{"--out-key", filepath.Join(dir, "other.key"), "--out-cert", filepath.Join(dir, "other.crt"), "--subject", "O=Example"},
+			wantErr:     true,
+			description: "Test subject without common name",
+		},
+		{
+			args:        []string{"--out-key", filepath.Join(dir, "other.key"), "--out-cert", filepath.Join(dir, "other.crt"), "--subject", "CN=Policy signer", "--days", "0"},
+			wantErr:     true,
+			description: "Test certificate valid for no days",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, keysGenerateCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.KeysCmd, constants.GenerateCmd}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, keysGenerateCmd)
+	assert.NoFileExists(t, filepath.Join(dir, "other.key"))
+
+	// the generated keys are accepted for signing along with their certificate
+	for _, pair := range [][2]string{{rsaKey, rsaCert}, {ecKey, ecCert}} {
+		fi, err := os.Stat(pair[0])
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(constants.PrivateKeyFilePermission), fi.Mode().Perm())
+		_, x5c, err := utils.CheckKeyFiles(pair[0], pair[1], nil)
+		assert.NoError(t, err)
+		assert.Len(t, x5c, 1)
+	}
+
+	csrBytes, err := os.ReadFile(ecCsr)
+	assert.NoError(t, err)
+	block, _ := pem.Decode(csrBytes)
+	if assert.NotNil(t, block) {
+		assert.Equal(t, constants.CertificateRequestType, block.Type)
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		assert.NoError(t, err)
+		assert.NoError(t, csr.CheckSignature())
+		assert.Equal(t, "Policy signer", csr.Subject.CommonName)
+	}
+}
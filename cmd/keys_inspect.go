@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// keysInspectCmd represents the keys inspect command
+var keysInspectCmd = &cobra.Command{
+	Use:   constants.InspectCmd,
+	Short: "Report the signing algorithms a policy signing key can be used with",
+	Long: `Report the type and size of a private key, the values of "create policy-jwt --algorithm" that can be used
+with it and, when a certificate is provided, whether the certificate belongs to the key and is currently valid.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("keys inspect called")
+		response, err := inspectSigningKey(cmd)
+		if response != "" {
+			fmt.Println("Policy signing key: \n\n", response)
+		}
+		return err
+	},
+}
+
+func init() {
+	keysCmd.AddCommand(keysInspectCmd)
+
+	keysInspectCmd.Flags().StringP(constants.PrivateKeyFileParamName, "p", "", "Path of the file containing the private key, either PEM encoded or a PKCS#12 (.p12/.pfx) bundle")
+	keysInspectCmd.Flags().StringP(constants.CertificateFileParamName, "c", "", "Path of the file containing the certificate, or the certificate chain ordered leaf first. Optional for PKCS#12 bundles")
+	keysInspectCmd.Flags().String(constants.PassphraseFileParamName, "", "Path of the file containing the passphrase of an encrypted private key or PKCS#12 bundle. "+
+		"When not set, the passphrase is prompted for if required")
+	keysInspectCmd.MarkFlagRequired(constants.PrivateKeyFileParamName)
+}
+
+func inspectSigningKey(cmd *cobra.Command) (string, error) {
+	keyFile, err := cmd.Flags().GetString(constants.PrivateKeyFileParamName)
+	if err != nil {
+		return "", err
+	}
+	certFile, err := cmd.Flags().GetString(constants.CertificateFileParamName)
+	if err != nil {
+		return "", err
+	}
+	passphraseFile, err := cmd.Flags().GetString(constants.PassphraseFileParamName)
+	if err != nil {
+		return "", err
+	}
+	if keyFile == "" {
+		return "", errors.New("Private key file path cannot be empty")
+	}
+
+	var key crypto.Signer
+	var chain []*x509.Certificate
+	if utils.IsPKCS12File(keyFile) {
+		var x5c []string
+		if key, x5c, err = utils.CheckKeyFiles(keyFile, certFile, utils.NewPassphraseSource(passphraseFile)); err != nil {
+			return "", err
+		}
+		for _, encoded := range x5c {
+			der, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return "", err
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return "", errors.Wrap(err, "Error parsing certificate")
+			}
+			chain = append(chain, cert)
+		}
+	} else {
+		path, err := validation.ValidatePath(keyFile)
+		if err != nil {
+			return "", errors.Wrap(err, "Invalid private key file path provided")
+		}
+		keyBytes, err := os.ReadFile(path)
+		if err != nil {
+			return "", errors.Wrap(err, "Error reading private key file")
+		}
+		if key, err = utils.ParsePrivateKey(keyBytes, utils.NewPassphraseSource(passphraseFile)); err != nil {
+			return "", errors.Wrap(err, "Error parsing private key PEM file")
+		}
+		if certFile != "" {
+			if chain, err = utils.ReadCertificateChain(certFile); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	info := describeSigningKey(key)
+	info.KeyFile = keyFile
+	if len(chain) > 0 {
+		info.Certificate = describeCertificate(key, chain)
+		info.Certificate.CertificateFile = certFile
+	}
+	response, err := formatSigningKeyInfo(info)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case len(info.Algorithms) == 0:
+		return response, errors.New("Private key cannot be used to sign policies, RSA keys should be 2048, 3072 or 4096 bits long and EC keys should use the P-256, P-384 or P-521 curve")
+	case info.Certificate != nil && !info.Certificate.Valid:
+		return response, errors.Errorf("Certificate cannot be used to sign policies with the private key: %s", info.Certificate.Reason)
+	}
+	return response, nil
+}
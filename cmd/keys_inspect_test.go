@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/utils"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeysInspectCmd(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "signing.key")
+	certFile := filepath.Join(dir, "signing.crt")
+	otherKeyFile := filepath.Join(dir, "other.key")
+	otherCertFile := filepath.Join(dir, "other.crt")
+	for _, pair := range [][2]string{{keyFile, certFile}, {otherKeyFile, otherCertFile}} {
+		key, err := utils.GenerateSigningKey(constants.KeyTypeECP256)
+		assert.NoError(t, err)
+		assert.NoError(t, utils.WritePrivateKey(pair[0], key, false))
+		subject, err := utils.ParseSubject("CN=Policy signer")
+		assert.NoError(t, err)
+		cert, err := utils.CreateSelfSignedCertificate(key, subject, 1)
+		assert.NoError(t, err)
+		assert.NoError(t, utils.WritePEMFile(pair[1], constants.CertType, cert.Raw, constants.CertificateFilePermission, false))
+	}
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+	weakKeyFile := filepath.Join(dir, "weak.key")
+	assert.NoError(t, utils.WritePrivateKey(weakKeyFile, weakKey, false))
+
+	tenantCmd.AddCommand(keysCmd)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{"-p", keyFile},
+			wantErr:     false,
+			description: "Test inspect a private key",
+		},
+		{
+			args:        []string{"-p", keyFile, "-c", certFile},
+			wantErr:     false,
+			description: "Test inspect a private key and its certificate",
+		},
+		{
+			args:        []string{"-p", keyFile, "-c", otherCertFile},
+			wantErr:     true,
+			description: "Test inspect a private key with the certificate of another key",
+		},
+		{
+			args:        []string{"-p", weakKeyFile},
+			wantErr:     true,
+			description: "Test inspect a private key too short to sign policies",
+		},
+		{
+			args:        []string{"-p", filepath.Join(dir, "missing.key")},
+			wantErr:     true,
+			description: "Test inspect a missing private key",
+		},
+		{
+			args:        []string{"-p", certFile},
+			wantErr:     true,
+			description: "Test inspect a file which is not a private key",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, keysInspectCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.KeysCmd, constants.InspectCmd}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, keysInspectCmd)
+
+	key, err := utils.GenerateSigningKey(constants.KeyTypeRSA2048)
+	assert.NoError(t, err)
+	info := describeSigningKey(key)
+	assert.Equal(t, "RSA", info.KeyType)
+	assert.Equal(t, 2048, info.KeySize)
+	assert.Equal(t, []string{constants.PS256, constants.RS256}, info.Algorithms)
+	assert.Equal(t, constants.PS256, info.DefaultAlgorithm)
+	assert.Empty(t, describeSigningKey(weakKey).Algorithms)
+}
@@ -45,6 +45,7 @@ func init() {
 	getApiClientsCmd.Flags().StringP(constants.ServiceIdParamName, "r", "", "Id of the Trust Authority service for which the apiClient needs to be created")
 	getApiClientsCmd.Flags().StringP(constants.ApiClientIdParamName, "c", "", "Id of the apiClient which needs to be fetched (optional)")
 	getApiClientsCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	addKeyOutputFlags(getApiClientsCmd)
 	getApiClientsCmd.MarkFlagRequired(constants.ServiceIdParamName)
 }
 
@@ -81,6 +82,14 @@ func getApiClients(cmd *cobra.Command) (string, error) {
 		return "", err
 	}
 
+	keyOutput, err := apiClientKeyOutput(cmd)
+	if err != nil {
+		return "", err
+	}
+	if keyOutput != nil && apiClientIdString == "" {
+		return "", errors.Errorf("--%s can only be used along with an apiClient id", constants.KeyOutputParamName)
+	}
+
 	tmsClient := tms.NewTmsClient(client, tmsUrl, apiKey)
 
 	var responseBytes []byte
@@ -105,6 +114,9 @@ func getApiClients(cmd *cobra.Command) (string, error) {
 		if err != nil {
 			return "", err
 		}
+		if err = deliverApiClientKeys(cmd, keyOutput, response); err != nil {
+			return "", err
+		}
 
 		responseBytes, err = json.MarshalIndent(response, "", "  ")
 		if err != nil {
@@ -14,6 +14,9 @@ import (
 	"intel/tac/v1/client/pms"
 	"intel/tac/v1/config"
 	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/internal/registry"
+	"intel/tac/v1/models"
 	"intel/tac/v1/utils"
 	"net/http"
 	"net/url"
@@ -44,6 +47,8 @@ func init() {
 
 	getPoliciesCmd.Flags().StringP(constants.PolicyIdParamName, "p", "", "Path of the file containing the policy to be uploaded")
 	getPoliciesCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	getPoliciesCmd.Flags().String(constants.SelectorParamName, "", "Comma separated label requirements the listed policies should meet, "+
+		"example \"team=payments,env!=dev\". \"key\" and \"!key\" select policies with or without the label")
 }
 
 func getPolicies(cmd *cobra.Command) (string, error) {
@@ -70,6 +75,30 @@ func getPolicies(cmd *cobra.Command) (string, error) {
 		return "", err
 	}
 
+	selectorString, err := cmd.Flags().GetString(constants.SelectorParamName)
+	if err != nil {
+		return "", err
+	}
+	var selector registry.Selector
+	if selectorString != "" {
+		if policyIdString != "" {
+			return "", errors.Errorf("--%s cannot be used along with a policy id", constants.SelectorParamName)
+		}
+		if selector, err = registry.ParseSelector(selectorString); err != nil {
+			return "", err
+		}
+	}
+
+	// the labels and descriptions of the local policy registry are shown along with the policies
+	r, err := registry.Load()
+	if err != nil {
+		if selector != nil {
+			return "", err
+		}
+		log.WithError(err).Warn("Unable to read the policy registry, policies are listed without their labels")
+		r = &registry.Registry{}
+	}
+
 	pmsClient := pms.NewPmsClient(client, pmsUrl, apiKey)
 
 	var responseBytes []byte
@@ -78,7 +107,20 @@ func getPolicies(cmd *cobra.Command) (string, error) {
 		if err != nil {
 			return "", err
 		}
-		responseBytes, err = json.MarshalIndent(response, "", "  ")
+		policies := annotatePolicies(r, response)
+		if selector != nil {
+			var selected []models2.AnnotatedPolicy
+			for _, policy := range policies {
+				if selector.Matches(policy.Labels) {
+					selected = append(selected, policy)
+				}
+			}
+			if len(selected) == 0 {
+				return "No policies match the selector", nil
+			}
+			policies = selected
+		}
+		responseBytes, err = json.MarshalIndent(policies, "", "  ")
 		if err != nil {
 			return "", err
 		}
@@ -92,7 +134,7 @@ func getPolicies(cmd *cobra.Command) (string, error) {
 			return "", err
 		}
 
-		responseBytes, err = json.MarshalIndent(response, "", "  ")
+		responseBytes, err = json.MarshalIndent(annotatePolicies(r, []models.PolicyResponse{*response})[0], "", "  ")
 		if err != nil {
 			return "", err
 		}
@@ -0,0 +1,22 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"intel/tac/v1/constants"
+)
+
+// policyCmd groups the policy workflows that go beyond plain CRUD
+var policyCmd = &cobra.Command{
+	Use:   constants.PolicyCmd,
+	Short: "Manage and inspect policies",
+	Long:  ``,
+}
+
+func init() {
+	tenantCmd.AddCommand(policyCmd)
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/internal/registry"
+	"intel/tac/v1/models"
+	"intel/tac/v1/validation"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// policyAnnotateCmd represents the policy annotate command
+var policyAnnotateCmd = &cobra.Command{
+	Use:   constants.AnnotateCmd,
+	Short: "Set the labels and description of a policy",
+	Long: `Set the labels and description of a policy in the local policy registry. Trust Authority does not store
+labels or descriptions, they are kept by the CLI for the current profile and shown by "list policy", which can
+filter policies by label with --selector.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy annotate called")
+		response, err := annotatePolicy(cmd)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Policy annotation: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(policyAnnotateCmd)
+
+	policyAnnotateCmd.Flags().StringP(constants.PolicyIdParamName, "i", "", "Id of the policy")
+	policyAnnotateCmd.Flags().StringArrayP(constants.LabelParamName, "l", nil, "Label to be set in the key=value format, example \"team=payments\". Can be repeated")
+	policyAnnotateCmd.Flags().StringSlice(constants.RemoveLabelParamName, nil, "Comma separated keys of the labels to be removed")
+	policyAnnotateCmd.Flags().StringP(constants.DescriptionParamName, "d", "", "Description of the policy, an empty description removes it")
+	policyAnnotateCmd.MarkFlagRequired(constants.PolicyIdParamName)
+}
+
+func annotatePolicy(cmd *cobra.Command) (string, error) {
+	policyIdString, err := cmd.Flags().GetString(constants.PolicyIdParamName)
+	if err != nil {
+		return "", err
+	}
+	policyId, err := uuid.Parse(policyIdString)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid policy Id provided, should be in UUID format")
+	}
+	labels, err := cmd.Flags().GetStringArray(constants.LabelParamName)
+	if err != nil {
+		return "", err
+	}
+	removedLabels, err := cmd.Flags().GetStringSlice(constants.RemoveLabelParamName)
+	if err != nil {
+		return "", err
+	}
+	description, err := cmd.Flags().GetString(constants.DescriptionParamName)
+	if err != nil {
+		return "", err
+	}
+
+	newLabels := map[string]string{}
+	for _, label := range labels {
+		key, value, found := strings.Cut(label, "=")
+		if !found {
+			return "", errors.Errorf("Invalid label %q, should be in the key=value format", label)
+		}
+		if err = validation.ValidateLabelKey(key); err != nil {
+			return "", err
+		}
+		if err = validation.ValidateLabelValue(value); err != nil {
+			return "", err
+		}
+		newLabels[key] = value
+	}
+	for _, key := range removedLabels {
+		if _, ok := newLabels[key]; ok {
+			return "", errors.Errorf("Label %q cannot be both set and removed", key)
+		}
+	}
+	if err = validation.ValidateDescription(description); err != nil {
+		return "", err
+	}
+
+	r, err := registry.Load()
+	if err != nil {
+		return "", err
+	}
+	if len(newLabels) == 0 && len(removedLabels) == 0 && !cmd.Flags().Changed(constants.DescriptionParamName) {
+		// nothing to change, the current annotation is shown
+		annotation := r.Get(policyId)
+		if annotation == nil {
+			return "Policy is not annotated", nil
+		}
+		return formatPolicyAnnotation(annotation)
+	}
+
+	annotation := r.Annotate(policyId)
+	if annotation.Labels == nil {
+		annotation.Labels = map[string]string{}
+	}
+	for key, value := range newLabels {
+		annotation.Labels[key] = value
+	}
+	for _, key := range removedLabels {
+		delete(annotation.Labels, key)
+	}
+	if cmd.Flags().Changed(constants.DescriptionParamName) {
+		annotation.Description = description
+	}
+	if err = r.Save(); err != nil {
+		return "", err
+	}
+	return formatPolicyAnnotation(annotation)
+}
+
+func formatPolicyAnnotation(annotation *models2.PolicyAnnotation) (string, error) {
+	annotationBytes, err := json.MarshalIndent(annotation, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(annotationBytes), nil
+}
+
+// annotatePolicies adds the client side metadata of the local policy registry to the policies
+func annotatePolicies(r *registry.Registry, policies []models.PolicyResponse) []models2.AnnotatedPolicy {
+	annotated := make([]models2.AnnotatedPolicy, 0, len(policies))
+	for _, policy := range policies {
+		annotatedPolicy := models2.AnnotatedPolicy{PolicyResponse: policy}
+		if annotation := r.Get(policy.PolicyId); annotation != nil {
+			annotatedPolicy.Labels = annotation.Labels
+			annotatedPolicy.Description = annotation.Description
+			annotatedPolicy.Deprecated = annotation.Deprecated
+			annotatedPolicy.DeprecationMessage = annotation.DeprecationMessage
+		}
+		annotated = append(annotated, annotatedPolicy)
+	}
+	return annotated
+}
+
+// forgetPolicyAnnotation removes the annotation of a deleted policy. Failing to update the local policy registry
+// does not fail the command.
+func forgetPolicyAnnotation(policyId uuid.UUID) {
+	r, err := registry.Load()
+	if err == nil && r.Remove(policyId) {
+		err = r.Save()
+	}
+	if err != nil {
+		log.WithError(err).Warn("Unable to remove the policy from the policy registry")
+	}
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/internal/registry"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"testing"
+)
+
+func TestPolicyAnnotateCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	paymentsPolicyId := uuid.New()
+	identityPolicyId := uuid.New()
+	server := test.PolicyMockServer(t, []models.PolicyResponse{
+		{CommonPolicy: models.CommonPolicy{PolicyId: paymentsPolicyId, PolicyName: "payments-policy"}, Version: "v1"},
+		{CommonPolicy: models.CommonPolicy{PolicyId: identityPolicyId, PolicyName: "identity-policy"}, Version: "v1"},
+		{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "unlabelled-policy"}, Version: "v1"},
+	})
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+	load, err := config.LoadConfiguration()
+	assert.NoError(t, err)
+	viper.Set("trustauthority-url", server.URL)
+	defer viper.Set("trustauthority-url", load.TrustAuthorityBaseUrl)
+
+	policyCmd.AddCommand(policyAnnotateCmd)
+	tenantCmd.AddCommand(policyCmd)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{"-i", paymentsPolicyId.String(), "-l", "team=payments", "-l", "env=prod", "-d", "Payments enclave policy"},
+			wantErr:     false,
+			description: "Test set the labels and description of a policy",
+		},
+		{
+			args:        []string{"-i", identityPolicyId.String(), "-l", "team=identity", "-l", "env=prod"},
+			wantErr:     false,
+			description: "Test set the labels of another policy",
+		},
+		{
+			args:        []string{"-i", identityPolicyId.String(), "--remove-label", "env"},
+			wantErr:     false,
+			description: "Test remove a label",
+		},
+		{
+			args:        []string{"-i", identityPolicyId.String()},
+			wantErr:     false,
+			description: "Test show the annotation of a policy",
+		},
+		{
+			args:        []string{"-i", uuid.NewString()},
+			wantErr:     false,
+			description: "Test show the annotation of a policy which is not annotated",
+		},
+		{
+			args:        []string{"-i", paymentsPolicyId.String(), "-l", "team"},
+			wantErr:     true,
+			description: "Test label without value",
+		},
+		{
+			args:        []string{"-i", paymentsPolicyId.String(), "-l", "team=pay ments"},
+			wantErr:     true,
+			description: "Test label with invalid value",
+		},
+		{
+			args:        []string{"-i", paymentsPolicyId.String(), "-l", "team=payments", "--remove-label", "team"},
+			wantErr:     true,
+			description: "Test set and remove the same label",
+		},
+		{
+			args:        []string{"-i", paymentsPolicyId.String(), "-d", "line\nbreak"},
+			wantErr:     true,
+			description: "Test description with control characters",
+		},
+		{
+			args:        []string{"-i", "invalid-id", "-l", "team=payments"},
+			wantErr:     true,
+			description: "Test annotate with invalid policy id",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, policyAnnotateCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.PolicyCmd, constants.AnnotateCmd}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, policyAnnotateCmd)
+
+	r, err := registry.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments", "env": "prod"}, r.Get(paymentsPolicyId).Labels)
+	assert.Equal(t, "Payments enclave policy", r.Get(paymentsPolicyId).Description)
+	assert.Equal(t, map[string]string{"team": "identity"}, r.Get(identityPolicyId).Labels)
+
+	// policies are listed with their labels and filtered by the selector
+	listPolicies := func(selector string) []models2.AnnotatedPolicy {
+		resetFlagsForTests(t, getPoliciesCmd)
+		defer resetFlagsForTests(t, getPoliciesCmd)
+		assert.NoError(t, getPoliciesCmd.Flags().Set(constants.SelectorParamName, selector))
+		response, err := getPolicies(getPoliciesCmd)
+		assert.NoError(t, err)
+		var policies []models2.AnnotatedPolicy
+		if response != "No policies match the selector" {
+			assert.NoError(t, json.Unmarshal([]byte(response), &policies))
+		}
+		return policies
+	}
+	assert.Len(t, listPolicies(""), 3)
+	if policies := listPolicies("team=payments"); assert.Len(t, policies, 1) {
+		assert.Equal(t, paymentsPolicyId, policies[0].PolicyId)
+		assert.Equal(t, "prod", policies[0].Labels["env"])
+		assert.Equal(t, "Payments enclave policy", policies[0].Description)
+	}
+	assert.Len(t, listPolicies("team"), 2)
+	assert.Len(t, listPolicies("!team"), 1)
+	assert.Len(t, listPolicies("team!=payments"), 2)
+	assert.Len(t, listPolicies("team=payments,env=dev"), 0)
+
+	resetFlagsForTests(t, getPoliciesCmd)
+	assert.NoError(t, getPoliciesCmd.Flags().Set(constants.SelectorParamName, "team==payments"))
+	_, err = getPolicies(getPoliciesCmd)
+	assert.Error(t, err)
+	assert.NoError(t, getPoliciesCmd.Flags().Set(constants.SelectorParamName, "team,"))
+	_, err = getPolicies(getPoliciesCmd)
+	assert.Error(t, err)
+	assert.NoError(t, getPoliciesCmd.Flags().Set(constants.PolicyIdParamName, paymentsPolicyId.String()))
+	assert.NoError(t, getPoliciesCmd.Flags().Set(constants.SelectorParamName, "team"))
+	_, err = getPolicies(getPoliciesCmd)
+	assert.Error(t, err)
+	resetFlagsForTests(t, getPoliciesCmd)
+
+	// the annotation of a deleted policy is removed
+	forgetPolicyAnnotation(paymentsPolicyId)
+	r, err = registry.Load()
+	assert.NoError(t, err)
+	assert.Nil(t, r.Get(paymentsPolicyId))
+	assert.NotNil(t, r.Get(identityPolicyId))
+}
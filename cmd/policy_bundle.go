@@ -0,0 +1,212 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/bundle"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// policyBundleCmd represents the policy bundle command
+var policyBundleCmd = &cobra.Command{
+	Use:   constants.BundleCmd,
+	Short: "Lint, test and build policy bundles",
+	Long: `A policy bundle is a directory, or a tarball of a directory, with a manifest.yaml naming the entry policy
+and the helper modules it uses:
+
+  name: sgx-workload
+  version: 1.2.0
+  entry: workload.rego
+  modules:
+    - lib/tcb.rego
+  tests:
+    - workload_test.rego
+
+The helper modules are inlined ahead of the entry policy to build a single policy that can be uploaded with
+"create policy".`,
+}
+
+// bundleLintCmd represents the policy bundle lint command
+var bundleLintCmd = &cobra.Command{
+	Use:   constants.LintCmd + " <bundle>",
+	Short: "Check a policy bundle can be built into a single policy",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy bundle lint called")
+		b, err := loadPolicyBundle(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Policy bundle %s %s has no problems\n", b.Manifest.Name, b.Manifest.Version)
+		return nil
+	},
+}
+
+// bundleTestCmd represents the policy bundle test command
+var bundleTestCmd = &cobra.Command{
+	Use:   constants.TestCmd + " <bundle>",
+	Short: "Run the tests of a policy bundle against the built policy with opa test",
+	Long: `Build the policy bundle and run its tests with "opa test". The built policy is declared in the
+test_package of the manifest, "policy" by default, which the tests should use as well. The opa binary is looked up
+in the PATH unless the TRUSTAUTHORITY_OPA environment variable is set.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy bundle test called")
+		response, err := testPolicyBundle(args[0])
+		if response != "" {
+			fmt.Println(response)
+		}
+		return err
+	},
+}
+
+// bundleBuildCmd represents the policy bundle build command
+var bundleBuildCmd = &cobra.Command{
+	Use:   constants.BuildCmd + " <bundle>",
+	Short: "Build a policy bundle into a single policy",
+	Long: `Inline the helper modules of a policy bundle ahead of its entry policy. The built policy starts with
+comments naming the bundle, its version and the SHA-384 hash of its content, so that an uploaded policy can be
+traced back to the bundle it was built from.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy bundle build called")
+		response, err := buildPolicyBundle(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(response)
+		return nil
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(policyBundleCmd)
+	policyBundleCmd.AddCommand(bundleLintCmd)
+	policyBundleCmd.AddCommand(bundleTestCmd)
+	policyBundleCmd.AddCommand(bundleBuildCmd)
+
+	bundleBuildCmd.Flags().StringP(constants.OutFileParamName, "o", "", "Path of the file to which the policy is written. The policy is printed when not provided.")
+	bundleBuildCmd.Flags().Bool(constants.ForceParamName, false, "Replace the output file when it already exists")
+}
+
+// loadPolicyBundle loads the bundle and fails with the problems found by the lint
+func loadPolicyBundle(bundlePath string) (*bundle.Bundle, error) {
+	if bundlePath == "" {
+		return nil, errors.New("Policy bundle path cannot be empty")
+	}
+	path, err := validation.ValidatePath(bundlePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid policy bundle path provided")
+	}
+	b, err := bundle.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if problems := bundle.Lint(b); len(problems) > 0 {
+		messages := make([]string, len(problems))
+		for i, problem := range problems {
+			messages[i] = problem.String()
+		}
+		return nil, errors.Errorf("Policy bundle has %d problem(s):\n%s", len(problems), strings.Join(messages, "\n"))
+	}
+	return b, nil
+}
+
+func buildPolicyBundle(cmd *cobra.Command, bundlePath string) (string, error) {
+	outFile, force, err := outputFilePath(cmd)
+	if err != nil {
+		return "", err
+	}
+	b, err := loadPolicyBundle(bundlePath)
+	if err != nil {
+		return "", err
+	}
+	result, err := bundle.Flatten(b)
+	if err != nil {
+		return "", err
+	}
+
+	if outFile == "" {
+		return result.Policy, nil
+	}
+	if err = utils.WriteNewFile(outFile, []byte(result.Policy), constants.DefaultFilePermission, force); err != nil {
+		return "", errors.Wrap(err, "Error writing policy file")
+	}
+	summary, err := json.MarshalIndent(struct {
+		*bundle.Result
+		Output string `json:"output"`
+	}{result, outFile}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(summary), nil
+}
+
+func testPolicyBundle(bundlePath string) (string, error) {
+	b, err := loadPolicyBundle(bundlePath)
+	if err != nil {
+		return "", err
+	}
+	if len(b.Manifest.Tests) == 0 {
+		return "", errors.New("Policy bundle manifest does not list any tests")
+	}
+	result, err := bundle.Flatten(b)
+	if err != nil {
+		return "", err
+	}
+
+	testDir, err := os.MkdirTemp("", "policy-bundle-")
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating policy bundle test directory")
+	}
+	defer os.RemoveAll(testDir)
+
+	policy := "package " + b.Manifest.TestPackage + "\n\n" + result.Policy
+	if err = os.WriteFile(filepath.Join(testDir, "policy.rego"), []byte(policy), constants.DefaultFilePermission); err != nil {
+		return "", errors.Wrap(err, "Error writing policy bundle test files")
+	}
+	for i, test := range b.Manifest.Tests {
+		// test modules are copied with their index so that tests with the same file name do not clash
+		testFile := filepath.Join(testDir, fmt.Sprintf("test_%d_%s", i, filepath.Base(test)))
+		if err = os.WriteFile(testFile, []byte(b.Files[filepath.ToSlash(filepath.Clean(test))]), constants.DefaultFilePermission); err != nil {
+			return "", errors.Wrap(err, "Error writing policy bundle test files")
+		}
+	}
+
+	opa := os.Getenv(constants.OpaToolEnvVar)
+	if opa == "" {
+		opa = constants.DefaultOpaTool
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultOpaTestTimeout*time.Second)
+	defer cancel()
+
+	var output bytes.Buffer
+	// #nosec G204 -- the opa binary is provided by the user running the CLI
+	command := exec.CommandContext(ctx, opa, "test", "-v", testDir)
+	command.Stdout = &output
+	command.Stderr = &output
+	log.Debugf("Running %s test on %s", opa, testDir)
+	if err = command.Run(); err != nil {
+		return strings.TrimSpace(output.String()), errors.Wrapf(err, "Policy bundle tests failed")
+	}
+	return strings.TrimSpace(output.String()), nil
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/bundle"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var bundleFilesForTests = map[string]string{
+	constants.BundleManifestFileName: "name: sgx-workload\nversion: 1.0.0\nentry: workload.rego\nmodules:\n  - lib/tcb.rego\ntests:\n  - workload_test.rego\n",
+	"lib/tcb.rego": "package lib.tcb\n\nimport future.keywords.in\n\nallowed_tcb_status := {\"UpToDate\"}\n\n" +
+		"tcb_ok {\n\tinput.attester_tcb_status in allowed_tcb_status\n}\n",
+	"workload.rego": "import future.keywords.in\nimport data.lib.tcb\n\ndefault matches_sgx_policy = false\n\n" +
+		"matches_sgx_policy = true {\n\ttcb.tcb_ok\n\tinput.sgx_mrsigner != \"tcb.tcb_ok\" # tcb.tcb_ok\n" +
+		"\tdata.lib.tcb.allowed_tcb_status[\"UpToDate\"]\n}\n",
+	"workload_test.rego": "package policy\n\ntest_matches {\n\tmatches_sgx_policy with input as {\"attester_tcb_status\": \"UpToDate\"}\n}\n",
+}
+
+// writeBundleForTests writes the bundle files to a directory, replacing the content of the files in overrides
+func writeBundleForTests(t *testing.T, overrides map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range bundleFilesForTests {
+		if override, ok := overrides[name]; ok {
+			content = override
+		}
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0750))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	return dir
+}
+
+func writeBundleTarballForTests(t *testing.T, files map[string]string) string {
+	tarball := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	f, err := os.Create(tarball)
+	assert.NoError(t, err)
+	defer f.Close()
+	gzipWriter := gzip.NewWriter(f)
+	tarWriter := tar.NewWriter(gzipWriter)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(files[name])), Typeflag: tar.TypeReg}))
+		_, err = tarWriter.Write([]byte(files[name]))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+	return tarball
+}
+
+func TestPolicyBundleFlatten(t *testing.T) {
+	b, err := bundle.Load(writeBundleForTests(t, nil))
+	assert.NoError(t, err)
+	assert.Empty(t, bundle.Lint(b))
+
+	result, err := bundle.Flatten(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "sgx-workload", result.Name)
+	assert.Equal(t, "1.0.0", result.Version)
+	assert.Len(t, result.ContentHash, 96)
+	assert.Equal(t, len(result.Policy), result.Size)
+	assert.True(t, strings.HasPrefix(result.Policy, "# bundle: sgx-workload\n# bundle-version: 1.0.0\n# content-hash: sha384:"+result.ContentHash+"\n\nimport future.keywords.in\n\n# module: lib/tcb.rego\n"))
+	assert.Equal(t, 1, strings.Count(result.Policy, "import "))
+	assert.NotContains(t, result.Policy, "package ")
+	// references to the inlined module are rewritten outside of strings and comments
+	assert.Contains(t, result.Policy, "\ttcb_ok\n\tinput.sgx_mrsigner != \"tcb.tcb_ok\" # tcb.tcb_ok\n\tallowed_tcb_status[\"UpToDate\"]\n")
+
+	// the bundle read from a tarball builds the same policy
+	tarball, err := bundle.Load(writeBundleTarballForTests(t, bundleFilesForTests))
+	assert.NoError(t, err)
+	tarballResult, err := bundle.Flatten(tarball)
+	assert.NoError(t, err)
+	assert.Equal(t, result.Policy, tarballResult.Policy)
+
+	problems := func(overrides map[string]string) string {
+		b, err := bundle.Load(writeBundleForTests(t, overrides))
+		assert.NoError(t, err)
+		var messages []string
+		for _, problem := range bundle.Lint(b) {
+			messages = append(messages, problem.String())
+		}
+		return strings.Join(messages, "\n")
+	}
+	assert.Contains(t, problems(map[string]string{constants.BundleManifestFileName: "entry: workload.rego\n"}), "name is required")
+	assert.Contains(t, problems(map[string]string{constants.BundleManifestFileName: "name: a\nversion: 1\nentry: missing.rego\n"}),
+		"missing.rego is not found in the bundle")
+	assert.Contains(t, problems(map[string]string{constants.BundleManifestFileName: "name: a\nversion: 1\nentry: workload.rego\nmodules: [lib/tcb.rego, lib/tcb.rego]\n"}),
+		"lib/tcb.rego is listed more than once")
+	assert.Contains(t, problems(map[string]string{"lib/tcb.rego": "allowed_tcb_status := {\"UpToDate\"}\n"}),
+		"lib/tcb.rego: helper modules should declare a package")
+	assert.Contains(t, problems(map[string]string{"lib/tcb.rego": "package lib.tcb\n\nmatches_sgx_policy = true {\n"}),
+		"lib/tcb.rego: unclosed bracket")
+	assert.Contains(t, problems(map[string]string{"lib/tcb.rego": "package lib.tcb\n\nmatches_sgx_policy := true\n"}),
+		"workload.rego:4: rule matches_sgx_policy is also defined in lib/tcb.rego")
+	assert.Contains(t, problems(map[string]string{"workload.rego": "import data.reference_values\n\nallow := true\n"}),
+		"workload.rego:1: import data.reference_values is not provided by the bundle")
+	assert.Contains(t, problems(map[string]string{"lib/tcb.rego": "package lib.tcb\n\nlarge := \"" + strings.Repeat("a", constants.MaxPolicyFileSize) + "\"\n"}),
+		"larger than the 20480 bytes accepted by Trust Authority")
+
+	_, err = bundle.Load(writeBundleTarballForTests(t, map[string]string{"../manifest.yaml": "name: a\n"}))
+	assert.Error(t, err)
+	_, err = bundle.Load(writeBundleTarballForTests(t, map[string]string{"workload.rego": "allow := true\n"}))
+	assert.Error(t, err)
+}
+
+func TestPolicyBundleCmd(t *testing.T) {
+	bundleDir := writeBundleForTests(t, nil)
+	invalidBundleDir := writeBundleForTests(t, map[string]string{"workload.rego": "import data.missing\n"})
+	outFile := filepath.Join(t.TempDir(), "policy.rego")
+
+	// opa is replaced by a script checking the built policy is declared in the test package
+	opa := filepath.Join(t.TempDir(), "opa")
+	assert.NoError(t, os.WriteFile(opa, []byte("#!/bin/sh\ngrep -q '^package policy$' \"$3/policy.rego\" && ls \"$3\" | grep -q workload_test.rego\n"), 0700))
+	t.Setenv(constants.OpaToolEnvVar, opa)
+
+	tenantCmd.AddCommand(policyCmd)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{constants.PolicyCmd, constants.BundleCmd, constants.LintCmd, bundleDir},
+			wantErr:     false,
+			description: "Test lint a valid policy bundle",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.BundleCmd, constants.LintCmd, invalidBundleDir},
+			wantErr:     true,
+			description: "Test lint a policy bundle with problems",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.BundleCmd, constants.LintCmd, filepath.Join(bundleDir, "missing")},
+			wantErr:     true,
+			description: "Test lint a missing policy bundle",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.BundleCmd, constants.BuildCmd, bundleDir, "-o", outFile},
+			wantErr:     false,
+			description: "Test build a policy bundle",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.BundleCmd, constants.BuildCmd, invalidBundleDir},
+			wantErr:     true,
+			description: "Test build a policy bundle with problems",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.BundleCmd, constants.TestCmd, bundleDir},
+			wantErr:     false,
+			description: "Test run the tests of a policy bundle",
+		},
+		{
+			args: []string{constants.PolicyCmd, constants.BundleCmd, constants.TestCmd, writeBundleForTests(t, map[string]string{
+				constants.BundleManifestFileName: "name: sgx-workload\nversion: 1.0.0\nentry: workload.rego\nmodules:\n  - lib/tcb.rego\n"})},
+			wantErr:     true,
+			description: "Test run the tests of a policy bundle without tests",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, bundleBuildCmd)
+		_, err := execute(t, tenantCmd, tc.args)
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, bundleBuildCmd)
+
+	policy, err := os.ReadFile(outFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(policy), "# bundle: sgx-workload\n# bundle-version: 1.0.0\n")
+
+	// failing tests fail the command
+	t.Setenv(constants.OpaToolEnvVar, "false")
+	_, err = execute(t, tenantCmd, []string{constants.PolicyCmd, constants.BundleCmd, constants.TestCmd, bundleDir})
+	assert.Error(t, err)
+
+	summary, err := buildPolicyBundle(bundleBuildCmd, bundleDir)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(summary, "# bundle: sgx-workload"))
+	// the policy file is only replaced with --force
+	assert.NoError(t, bundleBuildCmd.Flags().Set(constants.OutFileParamName, outFile))
+	_, err = buildPolicyBundle(bundleBuildCmd, bundleDir)
+	assert.ErrorContains(t, err, "already exists")
+	assert.NoError(t, bundleBuildCmd.Flags().Set(constants.ForceParamName, "true"))
+	summary, err = buildPolicyBundle(bundleBuildCmd, bundleDir)
+	assert.NoError(t, err)
+	assert.NoError(t, bundleBuildCmd.Flags().Set(constants.OutFileParamName, filepath.Join(t.TempDir(), "policy*.rego")))
+	_, err = buildPolicyBundle(bundleBuildCmd, bundleDir)
+	assert.Error(t, err)
+	resetFlagsForTests(t, bundleBuildCmd)
+	var result map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(summary), &result))
+	assert.Equal(t, "1.0.0", result["version"])
+	assert.Equal(t, outFile, result["output"])
+}
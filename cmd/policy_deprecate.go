@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/registry"
+	"intel/tac/v1/validation"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// policyDeprecateCmd represents the policy deprecate command
+var policyDeprecateCmd = &cobra.Command{
+	Use:   constants.DeprecateCmd,
+	Short: "Mark a policy as deprecated",
+	Long: `Mark a policy as deprecated in the local policy registry. The policy is not changed in Trust Authority, but
+"create apiClient" and "update apiClient" warn when a deprecated policy is linked to an API client.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy deprecate called")
+		response, err := deprecatePolicy(cmd)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Policy annotation: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(policyDeprecateCmd)
+
+	policyDeprecateCmd.Flags().StringP(constants.PolicyIdParamName, "i", "", "Id of the policy")
+	policyDeprecateCmd.Flags().StringP(constants.MessageParamName, "m", "", "Reason of the deprecation or the policy replacing it, shown in the warnings")
+	policyDeprecateCmd.Flags().Bool(constants.UndoParamName, false, "Remove the deprecation of the policy")
+	policyDeprecateCmd.MarkFlagRequired(constants.PolicyIdParamName)
+}
+
+func deprecatePolicy(cmd *cobra.Command) (string, error) {
+	policyIdString, err := cmd.Flags().GetString(constants.PolicyIdParamName)
+	if err != nil {
+		return "", err
+	}
+	policyId, err := uuid.Parse(policyIdString)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid policy Id provided, should be in UUID format")
+	}
+	message, err := cmd.Flags().GetString(constants.MessageParamName)
+	if err != nil {
+		return "", err
+	}
+	if err = validation.ValidateDescription(message); err != nil {
+		return "", errors.Wrap(err, "Invalid deprecation message")
+	}
+	undo, err := cmd.Flags().GetBool(constants.UndoParamName)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := registry.Load()
+	if err != nil {
+		return "", err
+	}
+	annotation := r.Annotate(policyId)
+	if undo {
+		annotation.Deprecated = false
+		annotation.DeprecationMessage = ""
+		annotation.DeprecatedAt = nil
+	} else {
+		deprecatedAt := annotation.UpdatedAt
+		if annotation.Deprecated && annotation.DeprecatedAt != nil {
+			deprecatedAt = *annotation.DeprecatedAt
+		}
+		annotation.Deprecated = true
+		annotation.DeprecationMessage = message
+		annotation.DeprecatedAt = &deprecatedAt
+	}
+	if err = r.Save(); err != nil {
+		return "", err
+	}
+	return formatPolicyAnnotation(annotation)
+}
+
+// deprecatedPolicyWarnings returns the warnings for the deprecated policies among the policy IDs linked to an API
+// client. Failing to read the local policy registry does not fail the command.
+func deprecatedPolicyWarnings(policyIds []uuid.UUID) string {
+	if len(policyIds) == 0 {
+		return ""
+	}
+	r, err := registry.Load()
+	if err != nil {
+		log.WithError(err).Warn("Unable to read the policy registry to check for deprecated policies")
+		return ""
+	}
+	var sb strings.Builder
+	for _, annotation := range r.Deprecated(policyIds) {
+		sb.WriteString(fmt.Sprintf("\nWARNING: Policy %s is deprecated", annotation.PolicyId))
+		if annotation.DeprecatedAt != nil {
+			sb.WriteString(" since " + annotation.DeprecatedAt.Format(time.RFC3339))
+		}
+		if annotation.DeprecationMessage != "" {
+			sb.WriteString(": " + annotation.DeprecationMessage)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/registry"
+	"intel/tac/v1/test"
+	"strings"
+	"testing"
+)
+
+func TestPolicyDeprecateCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	policyId := uuid.New()
+	otherPolicyId := uuid.New()
+
+	policyCmd.AddCommand(policyDeprecateCmd)
+	tenantCmd.AddCommand(policyCmd)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{"-i", policyId.String(), "-m", "Replaced by sgx-workload-v2"},
+			wantErr:     false,
+			description: "Test deprecate a policy",
+		},
+		{
+			args:        []string{"-i", otherPolicyId.String()},
+			wantErr:     false,
+			description: "Test deprecate a policy without message",
+		},
+		{
+			args:        []string{"-i", otherPolicyId.String(), "--undo"},
+			wantErr:     false,
+			description: "Test remove the deprecation of a policy",
+		},
+		{
+			args:        []string{"-i", "invalid-id"},
+			wantErr:     true,
+			description: "Test deprecate with invalid policy id",
+		},
+		{
+			args:        []string{"-i", policyId.String(), "-m", strings.Repeat("a", constants.MaxDescriptionLength+1)},
+			wantErr:     true,
+			description: "Test deprecate with a message too long",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, policyDeprecateCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.PolicyCmd, constants.DeprecateCmd}, tc.args...))
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, policyDeprecateCmd)
+
+	r, err := registry.Load()
+	assert.NoError(t, err)
+	assert.True(t, r.Get(policyId).Deprecated)
+	assert.NotNil(t, r.Get(policyId).DeprecatedAt)
+	assert.False(t, r.Get(otherPolicyId).Deprecated)
+
+	// linking a deprecated policy to an API client warns
+	warnings := deprecatedPolicyWarnings([]uuid.UUID{otherPolicyId, policyId})
+	assert.Contains(t, warnings, "WARNING: Policy "+policyId.String()+" is deprecated since ")
+	assert.Contains(t, warnings, ": Replaced by sgx-workload-v2")
+	assert.NotContains(t, warnings, otherPolicyId.String())
+	assert.Empty(t, deprecatedPolicyWarnings([]uuid.UUID{otherPolicyId}))
+
+	server := test.MockServer(t)
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+	load, err := config.LoadConfiguration()
+	assert.NoError(t, err)
+	viper.Set("trustauthority-url", server.URL)
+	defer viper.Set("trustauthority-url", load.TrustAuthorityBaseUrl)
+	resetFlagsForTests(t, createApiClientCmd)
+	defer resetFlagsForTests(t, createApiClientCmd)
+	for name, value := range map[string]string{
+		constants.ServiceIdParamName:     "5cfb6af4-59ac-4a14-8b83-bd65b1e11777",
+		constants.ProductIdParamName:     "e169f34d-58ce-4717-9b3a-5c0e01d8d5a7",
+		constants.ApiClientNameParamName: "TestApiClient",
+		constants.PolicyIdsParamName:     policyId.String(),
+	} {
+		assert.NoError(t, createApiClientCmd.Flags().Set(name, value))
+	}
+	response, err := createApiClient(createApiClientCmd)
+	assert.NoError(t, err)
+	assert.Contains(t, response, "WARNING: Policy "+policyId.String()+" is deprecated")
+
+	selector, err := registry.ParseSelector("team=payments, env!=dev, tier, !legacy")
+	assert.NoError(t, err)
+	assert.True(t, selector.Matches(map[string]string{"team": "payments", "env": "prod", "tier": "gold"}))
+	assert.False(t, selector.Matches(map[string]string{"team": "payments", "env": "dev", "tier": "gold"}))
+	assert.False(t, selector.Matches(map[string]string{"team": "payments", "tier": "gold", "legacy": "true"}))
+	assert.False(t, selector.Matches(nil))
+}
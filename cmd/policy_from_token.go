@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// policyFromTokenCmd represents the policy from-token command
+var policyFromTokenCmd = &cobra.Command{
+	Use:   constants.FromTokenCmd,
+	Short: "Generate a policy matching the claims of an attestation token",
+	Long: `Decode an Intel Trust Authority attestation token without verifying it and render a Rego policy that only
+matches the values of the selected claims. The policy can then be uploaded with "create policy" or signed with
+"create policy-jwt".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy from-token called")
+		response, err := policyFromToken(cmd)
+		if err != nil {
+			return err
+		}
+		fmt.Println(response)
+		return nil
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(policyFromTokenCmd)
+
+	policyFromTokenCmd.Flags().String(constants.TokenParamName, "", "Path of the file containing the attestation token")
+	policyFromTokenCmd.Flags().StringSlice(constants.ClaimsParamName, nil, "Comma separated claims to be matched, example \"sgx_mrenclave,sgx_mrsigner\". Nested claims are selected with a dot separated path, example \"tdx.tdx_mrtd\", and matched by their name, as Trust Authority appraises the policy against flat claims.")
+	policyFromTokenCmd.Flags().StringP(constants.OutFileParamName, "o", "", "Path of the file to which the policy is written. The policy is printed when not provided.")
+	policyFromTokenCmd.Flags().Bool(constants.ForceParamName, false, "Replace the output file when it already exists")
+	policyFromTokenCmd.MarkFlagRequired(constants.TokenParamName)
+	policyFromTokenCmd.MarkFlagRequired(constants.ClaimsParamName)
+}
+
+func policyFromToken(cmd *cobra.Command) (string, error) {
+	tokenFilePath, err := cmd.Flags().GetString(constants.TokenParamName)
+	if err != nil {
+		return "", err
+	}
+	if tokenFilePath == "" {
+		return "", errors.New("Attestation token file path cannot be empty")
+	}
+	path, err := validation.ValidatePath(tokenFilePath)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid attestation token file path provided")
+	}
+	tokenBytes, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(err, "Error reading attestation token file")
+	}
+
+	outFile, force, err := outputFilePath(cmd)
+	if err != nil {
+		return "", err
+	}
+	if outFile == path {
+		return "", errors.New("Output file cannot be the attestation token file")
+	}
+
+	claims, err := utils.ParseAttestationToken(string(tokenBytes))
+	if err != nil {
+		return "", err
+	}
+
+	selectedClaims, err := cmd.Flags().GetStringSlice(constants.ClaimsParamName)
+	if err != nil {
+		return "", err
+	}
+	policy, err := utils.ReferenceValuePolicy(claims, selectedClaims)
+	if err != nil {
+		return "", err
+	}
+
+	if outFile == "" {
+		return policy, nil
+	}
+	if err = utils.WriteNewFile(outFile, []byte(policy), constants.DefaultFilePermission, force); err != nil {
+		return "", errors.Wrap(err, "Error writing policy file")
+	}
+	return "Policy matching " + strings.Join(selectedClaims, ", ") + " written to: " + outFile, nil
+}
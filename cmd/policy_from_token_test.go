@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicyFromTokenCmd(t *testing.T) {
+	tokenDir := t.TempDir()
+	mrEnclave := strings.Repeat("ab", 32)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sgx_mrenclave":     mrEnclave,
+		"sgx_isvprodid":     0,
+		"sgx_is_debuggable": false,
+		"tdx": map[string]interface{}{
+			"tdx_mrtd": strings.Repeat("cd", 48),
+		},
+		"nvgpu": map[string]interface{}{
+			"x-nvidia-gpu-arch-check": true,
+		},
+		"policy_ids_matched": []string{"e8a72b7e-c4b1-4bdc-bf40-68f23c68a2aa"},
+		"sgx":                map[string]interface{}{"sgx_mrenclave": mrEnclave},
+		"iat":                1700000000,
+		"verifier_nonce":     map[string]interface{}{"val": "bm9uY2U="},
+	}).SignedString([]byte("secret"))
+	assert.NoError(t, err)
+	tokenFile := filepath.Join(tokenDir, "token.jwt")
+	assert.NoError(t, os.WriteFile(tokenFile, []byte(token+"\n"), 0600))
+	invalidTokenFile := filepath.Join(tokenDir, "invalid.jwt")
+	assert.NoError(t, os.WriteFile(invalidTokenFile, []byte("not-a-token"), 0600))
+	policyFile := filepath.Join(tokenDir, "policy.rego")
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", tokenFile, "--claims", "sgx_mrenclave,sgx_isvprodid,sgx_is_debuggable", "-o", policyFile},
+			wantErr:     false,
+			description: "Test generate policy from token claims to a file",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", tokenFile, "--claims", "tdx.tdx_mrtd", "--claims", "nvgpu.x-nvidia-gpu-arch-check"},
+			wantErr:     false,
+			description: "Test generate policy from nested token claims",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", tokenFile, "--claims", "sgx_mrsigner"},
+			wantErr:     true,
+			description: "Test claim missing from the token",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", tokenFile, "--claims", "sgx_mrenclave.value"},
+			wantErr:     true,
+			description: "Test nested path under a claim that is not an object",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", tokenFile, "--claims", "policy_ids_matched"},
+			wantErr:     true,
+			description: "Test claim that is not a scalar value",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", tokenFile, "--claims", "sgx_mrenclave,"},
+			wantErr:     true,
+			description: "Test empty claim name",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", tokenFile, "--claims", "sgx_mrenclave,iat"},
+			wantErr:     true,
+			description: "Test claim differing for every token",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", tokenFile, "--claims", "verifier_nonce.val"},
+			wantErr:     true,
+			description: "Test claim nested in the nonce",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", tokenFile, "--claims", "sgx_mrenclave,sgx.sgx_mrenclave"},
+			wantErr:     true,
+			description: "Test claims matched under the same name",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", invalidTokenFile, "--claims", "sgx_mrenclave"},
+			wantErr:     true,
+			description: "Test invalid attestation token",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", filepath.Join(tokenDir, "missing.jwt"), "--claims", "sgx_mrenclave"},
+			wantErr:     true,
+			description: "Test missing attestation token file",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.FromTokenCmd, "--token", "", "--claims", "sgx_mrenclave"},
+			wantErr:     true,
+			description: "Test empty attestation token file path",
+		},
+	}
+
+	policyCmd.AddCommand(policyFromTokenCmd)
+	tenantCmd.AddCommand(policyCmd)
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, policyFromTokenCmd)
+		_, err := execute(t, tenantCmd, tc.args)
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, policyFromTokenCmd)
+
+	// the policy file is only replaced with --force, and the token file never is
+	fromToken := func(args ...string) error {
+		resetFlagsForTests(t, policyFromTokenCmd)
+		defer resetFlagsForTests(t, policyFromTokenCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.PolicyCmd, constants.FromTokenCmd, "--token", tokenFile},
+			args...))
+		return err
+	}
+	assert.ErrorContains(t, fromToken("--claims", "sgx_mrenclave", "-o", policyFile), "already exists")
+	assert.Error(t, fromToken("--claims", "sgx_mrenclave", "-o", filepath.Join(tokenDir, "policy*.rego")))
+	assert.Error(t, fromToken("--claims", "sgx_mrenclave", "-o", tokenFile, "--force"))
+	assert.NoError(t, fromToken("--claims", "sgx_mrenclave,sgx_isvprodid,sgx_is_debuggable", "-o", policyFile, "--force"))
+	info, err := os.Stat(policyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(constants.DefaultFilePermission), info.Mode().Perm())
+
+	policyBytes, err := os.ReadFile(policyFile)
+	assert.NoError(t, err)
+	policy := string(policyBytes)
+	assert.Contains(t, policy, "default matches_reference_values = false")
+	assert.Contains(t, policy, "\tinput.sgx_mrenclave == \""+mrEnclave+"\"\n")
+	assert.Contains(t, policy, "\tinput.sgx_isvprodid == 0\n")
+	assert.Contains(t, policy, "\tinput.sgx_is_debuggable == false\n")
+
+	claims, err := utils.ParseAttestationToken(token)
+	assert.NoError(t, err)
+	policy, err = utils.ReferenceValuePolicy(claims, []string{"tdx.tdx_mrtd", "nvgpu.x-nvidia-gpu-arch-check"})
+	assert.NoError(t, err)
+	// nested claims are matched by their name only, as Trust Authority appraises the policy against flat claims
+	assert.Contains(t, policy, "\tinput.tdx_mrtd == \""+strings.Repeat("cd", 48)+"\"\n")
+	assert.Contains(t, policy, "\tinput[\"x-nvidia-gpu-arch-check\"] == true\n")
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/history"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// policyHistoryCmd represents the policy history command
+var policyHistoryCmd = &cobra.Command{
+	Use:   constants.HistoryCmd,
+	Short: "List the versions of a policy recorded locally by the CLI",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy history called")
+		response, err := policyHistory(cmd)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Policy history: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(policyHistoryCmd)
+
+	policyHistoryCmd.Flags().StringP(constants.PolicyIdParamName, "i", "", "Id of the policy")
+	policyHistoryCmd.MarkFlagRequired(constants.PolicyIdParamName)
+}
+
+func policyHistory(cmd *cobra.Command) (string, error) {
+	policyIdString, err := cmd.Flags().GetString(constants.PolicyIdParamName)
+	if err != nil {
+		return "", err
+	}
+	policyId, err := uuid.Parse(policyIdString)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid policy Id provided, should be in UUID format")
+	}
+
+	entries, err := history.Load(policyId)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "No versions of the policy have been recorded", nil
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tOPERATION\tMODIFIED\tRECORDED\tSIGNED BY TENANT\tHASH STATUS")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%s\n", entry.Version, entry.PolicyName, entry.Operation,
+			entry.UpdatedAt.Format(time.RFC3339), entry.RecordedAt.Format(time.RFC3339), entry.SignedByTenant,
+			utils.PolicyHashStatus(entry.Policy, entry.PolicyHash))
+	}
+	if err = w.Flush(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// recordPolicyHistory keeps the policy in the local history. Failing to record the history does not fail the command.
+func recordPolicyHistory(policy *models.PolicyResponse, operation string) {
+	if err := history.Record(policy, operation); err != nil {
+		log.WithError(err).Warn("Unable to record the policy in the local history")
+	}
+}
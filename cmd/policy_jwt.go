@@ -0,0 +1,73 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/validation"
+	"os"
+	"strings"
+	"time"
+)
+
+// policyJwtCmd groups the commands used to inspect policy tokens generated by "create policy-jwt"
+var policyJwtCmd = &cobra.Command{
+	Use:   constants.PolicyJwtCmd,
+	Short: "Inspect and verify policy JWTs",
+	Long:  ``,
+}
+
+func init() {
+	tenantCmd.AddCommand(policyJwtCmd)
+}
+
+// readPolicyTokenFile reads a policy token written by "create policy-jwt"
+func readPolicyTokenFile(tokenFilePath string) (string, error) {
+	if tokenFilePath == "" {
+		return "", errors.New("Policy token file path cannot be empty")
+	}
+	path, err := validation.ValidatePath(tokenFilePath)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid policy token file path provided")
+	}
+	tokenBytes, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(err, "Error reading policy token file")
+	}
+	tokenString := strings.TrimSpace(string(tokenBytes))
+	if tokenString == "" {
+		return "", errors.New("Policy token file is empty")
+	}
+	return tokenString, nil
+}
+
+// describeCertificates renders the details of the certificates from the x5c header
+func describeCertificates(certs []*x509.Certificate) string {
+	if len(certs) == 0 {
+		return "No certificates embedded in the token\n"
+	}
+	var sb strings.Builder
+	for i, cert := range certs {
+		fingerprint := sha256.Sum256(cert.Raw)
+		hexBytes := make([]string, len(fingerprint))
+		for j, b := range fingerprint {
+			hexBytes[j] = fmt.Sprintf("%02X", b)
+		}
+		fmt.Fprintf(&sb, "[%d] Subject: %s\n", i, cert.Subject.String())
+		fmt.Fprintf(&sb, "    Issuer: %s\n", cert.Issuer.String())
+		fmt.Fprintf(&sb, "    Serial number: %s\n", cert.SerialNumber.String())
+		fmt.Fprintf(&sb, "    Not before: %s\n", cert.NotBefore.UTC().Format(time.RFC3339))
+		fmt.Fprintf(&sb, "    Not after: %s\n", cert.NotAfter.UTC().Format(time.RFC3339))
+		fmt.Fprintf(&sb, "    Public key algorithm: %s\n", cert.PublicKeyAlgorithm.String())
+		fmt.Fprintf(&sb, "    SHA-256 fingerprint: %s\n", strings.Join(hexBytes, ":"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/utils"
+	"strings"
+)
+
+// decodePolicyJwtCmd represents the policy-jwt decode command
+var decodePolicyJwtCmd = &cobra.Command{
+	Use:   constants.DecodeCmd + " <policy token file>",
+	Short: "Prints the header, embedded certificates and Rego policy of a policy JWT without verifying it",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy-jwt decode called")
+		response, err := decodePolicyJwt(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(response)
+		return nil
+	},
+}
+
+func init() {
+	policyJwtCmd.AddCommand(decodePolicyJwtCmd)
+}
+
+func decodePolicyJwt(tokenFilePath string) (string, error) {
+	tokenString, err := readPolicyTokenFile(tokenFilePath)
+	if err != nil {
+		return "", err
+	}
+
+	token, claims, err := utils.ParsePolicyToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+
+	headerBytes, err := json.MarshalIndent(token.Header, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	certs, err := utils.CertificateChainFromHeader(token)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Header:\n")
+	sb.Write(headerBytes)
+	sb.WriteString("\n\nCertificates:\n")
+	sb.WriteString(describeCertificates(certs))
+	sb.WriteString("\nPolicy:\n")
+	sb.WriteString(claims.AttestationPolicy)
+	return sb.String(), nil
+}
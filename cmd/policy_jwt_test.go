@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"intel/tac/v1/utils"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	verifyKeyFile  = "../test/resources/verify-policy-signing-key.txt"
+	verifyCertFile = "../test/resources/verify-policy-signing-cert.txt"
+	otherKeyFile   = "../test/resources/other-policy-signing-key.txt"
+	otherCertFile  = "../test/resources/other-policy-signing-cert.txt"
+)
+
+func TestPolicyJwtDecodeAndVerifyCmd(t *testing.T) {
+	server := test.MockServer(t)
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+
+	generateKeyPairForTests(t, verifyKeyFile, verifyCertFile)
+	generateKeyPairForTests(t, otherKeyFile, otherCertFile)
+	defer func() {
+		for _, f := range []string{verifyKeyFile, verifyCertFile, otherKeyFile, otherCertFile} {
+			assert.NoError(t, os.Remove(f))
+		}
+	}()
+
+	tokenDir := t.TempDir()
+	signedToken := writeSignedPolicyToken(t, tokenDir, "signed.txt")
+
+	unsignedToken := filepath.Join(tokenDir, "unsigned.txt")
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, models.PolicyClaims{AttestationPolicy: "default allow = true"}).
+		SigningString()
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(unsignedToken, []byte(unsigned+"."), 0600))
+
+	tamperedToken := filepath.Join(tokenDir, "tampered.txt")
+	signedBytes, err := os.ReadFile(signedToken)
+	assert.NoError(t, err)
+	signedBytes[len(signedBytes)-5] ^= 0x01
+	assert.NoError(t, os.WriteFile(tamperedToken, signedBytes, 0600))
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{constants.PolicyJwtCmd, constants.DecodeCmd, signedToken},
+			wantErr:     false,
+			description: "Test decode signed policy token",
+		},
+		{
+			args:        []string{constants.PolicyJwtCmd, constants.DecodeCmd, unsignedToken},
+			wantErr:     false,
+			description: "Test decode unsigned policy token",
+		},
+		{
+			args:        []string{constants.PolicyJwtCmd, constants.DecodeCmd, "../test/resources/@token.txt"},
+			wantErr:     true,
+			description: "Test decode with invalid token file path",
+		},
+		{
+			args:        []string{constants.PolicyJwtCmd, constants.DecodeCmd, "../test/resources/rego-policy.txt"},
+			wantErr:     true,
+			description: "Test decode a file which is not a JWT",
+		},
+		{
+			args:        []string{constants.PolicyJwtCmd, constants.VerifyCmd, signedToken, "-c", verifyCertFile},
+			wantErr:     false,
+			description: "Test verify signed policy token",
+		},
+		{
+			args:        []string{constants.PolicyJwtCmd, constants.VerifyCmd, signedToken, "-c", otherCertFile},
+			wantErr:     true,
+			description: "Test verify policy token signed by an untrusted certificate",
+		},
+		{
+			args:        []string{constants.PolicyJwtCmd, constants.VerifyCmd, tamperedToken, "-c", verifyCertFile},
+			wantErr:     true,
+			description: "Test verify tampered policy token",
+		},
+		{
+			args:        []string{constants.PolicyJwtCmd, constants.VerifyCmd, unsignedToken, "-c", verifyCertFile},
+			wantErr:     true,
+			description: "Test verify unsigned policy token",
+		},
+		{
+			args:        []string{constants.PolicyJwtCmd, constants.VerifyCmd, signedToken, "-c", verifyCertFile, "-a", constants.RS384},
+			wantErr:     true,
+			description: "Test verify policy token signed with an algorithm which is not allowed",
+		},
+		{
+			args:        []string{constants.PolicyJwtCmd, constants.VerifyCmd, signedToken, "-c", verifyCertFile, "-a", constants.NonAlg},
+			wantErr:     true,
+			description: "Test verify policy token allowing unsigned tokens",
+		},
+		{
+			args:        []string{constants.PolicyJwtCmd, constants.VerifyCmd, signedToken, "-c", "../test/resources/rego-policy.txt", "-a", constants.PS384},
+			wantErr:     true,
+			description: "Test verify policy token with a CA file without certificates",
+		},
+	}
+
+	policyJwtCmd.AddCommand(decodePolicyJwtCmd)
+	policyJwtCmd.AddCommand(verifyPolicyJwtCmd)
+	tenantCmd.AddCommand(policyJwtCmd)
+
+	for _, tc := range tt {
+		_, err := execute(t, tenantCmd, tc.args)
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+}
+
+// writeSignedPolicyToken signs the sample rego policy with the verify key pair and writes it to tokenDir
+func writeSignedPolicyToken(t *testing.T, tokenDir, fileName string) string {
+	privKey, certChain, err := utils.CheckKeyFiles(verifyKeyFile, verifyCertFile, nil)
+	assert.NoError(t, err)
+
+	policyBytes, err := os.ReadFile("../test/resources/rego-policy.txt")
+	assert.NoError(t, err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodPS384, models.PolicyClaims{AttestationPolicy: string(policyBytes)})
+	token.Header[constants.KeyHeader] = certChain
+	tokenString, err := token.SignedString(privKey)
+	assert.NoError(t, err)
+
+	tokenFile := filepath.Join(tokenDir, fileName)
+	assert.NoError(t, os.WriteFile(tokenFile, []byte(tokenString), 0600))
+	return tokenFile
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/utils"
+	"strings"
+)
+
+// verifyPolicyJwtCmd represents the policy-jwt verify command
+var verifyPolicyJwtCmd = &cobra.Command{
+	Use:   constants.VerifyCmd + " <policy token file>",
+	Short: "Verifies the signature and signing certificate chain of a policy JWT",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy-jwt verify called")
+		response, err := verifyPolicyJwt(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(response)
+		return nil
+	},
+}
+
+func init() {
+	policyJwtCmd.AddCommand(verifyPolicyJwtCmd)
+
+	verifyPolicyJwtCmd.Flags().StringP(constants.CaFileParamName, "c", "", "Path of the PEM file containing the trusted root certificates")
+	verifyPolicyJwtCmd.Flags().StringSliceP(constants.AllowedAlgorithmsParamName, "a", constants.SigningAlgorithms,
+		"List of comma separated signing algorithms accepted for the policy token")
+	verifyPolicyJwtCmd.MarkFlagRequired(constants.CaFileParamName)
+}
+
+func verifyPolicyJwt(cmd *cobra.Command, tokenFilePath string) (string, error) {
+	caFilePath, err := cmd.Flags().GetString(constants.CaFileParamName)
+	if err != nil {
+		return "", err
+	}
+
+	allowedAlgorithms, err := cmd.Flags().GetStringSlice(constants.AllowedAlgorithmsParamName)
+	if err != nil {
+		return "", err
+	}
+	if len(allowedAlgorithms) == 0 {
+		return "", errors.New("At least one signing algorithm needs to be allowed")
+	}
+	for _, algorithm := range allowedAlgorithms {
+		if strings.EqualFold(algorithm, constants.NonAlg) {
+			return "", errors.New("Unsigned policy tokens cannot be verified")
+		}
+	}
+
+	roots, err := utils.LoadCertPool(caFilePath)
+	if err != nil {
+		return "", err
+	}
+
+	tokenString, err := readPolicyTokenFile(tokenFilePath)
+	if err != nil {
+		return "", err
+	}
+
+	token, chain, err := utils.VerifyPolicyToken(tokenString, roots, allowedAlgorithms)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Policy token signature verified\n")
+	fmt.Fprintf(&sb, "Algorithm: %s\n\n", token.Method.Alg())
+	sb.WriteString("Signing certificate chain:\n")
+	sb.WriteString(describeCertificates(chain))
+	return sb.String(), nil
+}
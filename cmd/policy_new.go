@@ -0,0 +1,260 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/templates"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// sizes in bytes of the hex encoded reference values
+	sgxMeasurementSize = 32
+	tdxMeasurementSize = 48
+	snpMeasurementSize = 48
+	tdxRtmrCount       = 4
+)
+
+// newPolicyCmd represents the policy new command
+var newPolicyCmd = &cobra.Command{
+	Use:   constants.NewCmd,
+	Short: "Render a Rego appraisal policy from a template",
+	Long: `Render a commented Rego appraisal policy from one of the embedded templates (` +
+		strings.Join(templates.Names(), ", ") + `). Reference values that are not provided are rendered as commented out rules. Without any value identifying the
+workload, the identity checks are rendered with placeholders which do not compile until they are edited, and the
+policy cannot be uploaded with --create.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy new called")
+		response, err := newPolicy(cmd)
+		return printUploadedPolicy("", response, err)
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(newPolicyCmd)
+
+	newPolicyCmd.Flags().String(constants.TemplateParamName, "", "Template of the policy, one of "+strings.Join(templates.Names(), ", "))
+	newPolicyCmd.Flags().String(constants.MrEnclaveParamName, "", "Expected SGX enclave measurement (MRENCLAVE) in hex")
+	newPolicyCmd.Flags().String(constants.MrSignerParamName, "", "Expected SGX enclave signer measurement (MRSIGNER) in hex")
+	newPolicyCmd.Flags().Uint16(constants.IsvProdIdParamName, 0, "Expected SGX enclave product ID")
+	newPolicyCmd.Flags().Uint16(constants.IsvSvnParamName, 0, "Minimum SGX enclave security version")
+	newPolicyCmd.Flags().String(constants.MrTdParamName, "", "Expected TDX trust domain measurement (MRTD) in hex")
+	newPolicyCmd.Flags().StringArray(constants.RtmrParamName, nil, "Expected TDX runtime measurement register as < index >=< hex value >, example \"2=ab12...\". Can be repeated.")
+	newPolicyCmd.Flags().String(constants.MeasurementParamName, "", "Expected SEV-SNP launch measurement in hex")
+	newPolicyCmd.Flags().String(constants.MinTcbStatusParamName, "", "Least trusted platform TCB status to accept, one of "+strings.Join(templates.TcbStatuses, ", "))
+	newPolicyCmd.Flags().StringP(constants.OutFileParamName, "o", "", "Path of the file to which the rendered policy is written. The policy is printed when not provided.")
+	newPolicyCmd.Flags().Bool(constants.ForceParamName, false, "Replace the output file when it already exists")
+	newPolicyCmd.Flags().Bool(constants.CreateParamName, false, "Upload the rendered policy to Trust Authority")
+	newPolicyCmd.Flags().StringP(constants.PolicyNameParamName, "n", "", "Name of the policy to be uploaded, required with --create")
+	newPolicyCmd.Flags().StringP(constants.PolicyTypeParamName, "t", constants.DefaultPolicyType, "Type of the policy to be uploaded")
+	newPolicyCmd.Flags().StringP(constants.ServiceOfferIdParamName, "r", "", "Service offer id for which the policy needs to be uploaded, required with --create")
+	newPolicyCmd.Flags().StringP(constants.AttestationTypeParamName, "a", "", "Attestation type of the policy to be uploaded. Defaults to the attestation type of the template.")
+	newPolicyCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	addPolicySigningFlags(newPolicyCmd)
+	newPolicyCmd.MarkFlagRequired(constants.TemplateParamName)
+}
+
+func newPolicy(cmd *cobra.Command) (string, error) {
+	templateName, err := cmd.Flags().GetString(constants.TemplateParamName)
+	if err != nil {
+		return "", err
+	}
+	policyTemplate, err := templates.Get(templateName)
+	if err != nil {
+		return "", err
+	}
+
+	values, err := templateValues(cmd, policyTemplate)
+	if err != nil {
+		return "", err
+	}
+	policy, err := policyTemplate.Render(values)
+	if err != nil {
+		return "", err
+	}
+
+	outFile, force, err := outputFilePath(cmd)
+	if err != nil {
+		return "", err
+	}
+	upload, err := cmd.Flags().GetBool(constants.CreateParamName)
+	if err != nil {
+		return "", err
+	}
+	if !upload {
+		if outFile == "" {
+			return policy, nil
+		}
+		if err = utils.WriteNewFile(outFile, []byte(policy), constants.DefaultFilePermission, force); err != nil {
+			return "", errors.Wrap(err, "Error writing policy file")
+		}
+		return "Policy written to: " + outFile, nil
+	}
+
+	// the policy to be uploaded is checked before the policy file is written, so that a failing --create leaves
+	// nothing behind
+	if !values.HasIdentity() {
+		var identityFlags []string
+		for _, parameter := range []string{constants.MrEnclaveParamName, constants.MrSignerParamName, constants.MrTdParamName,
+			constants.RtmrParamName, constants.MeasurementParamName} {
+			if policyTemplate.Supports(parameter) {
+				identityFlags = append(identityFlags, "--"+parameter)
+			}
+		}
+		return "", errors.Errorf("A policy accepting any workload cannot be uploaded, at least one of %s should be "+
+			"provided with --%s", strings.Join(identityFlags, ", "), constants.CreateParamName)
+	}
+	if _, err = config.LoadConfiguration(); err != nil {
+		return "", err
+	}
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+	if len(policy) > constants.MaxPolicyFileSize {
+		return "", fmt.Errorf("%s: %d", constants.ErrorInvalidSize, len(policy))
+	}
+
+	policyName, err := cmd.Flags().GetString(constants.PolicyNameParamName)
+	if err != nil {
+		return "", err
+	}
+	if err = validation.ValidatePolicyName(policyName); err != nil {
+		return "", err
+	}
+	policyType, err := cmd.Flags().GetString(constants.PolicyTypeParamName)
+	if err != nil {
+		return "", err
+	}
+	soIdString, err := cmd.Flags().GetString(constants.ServiceOfferIdParamName)
+	if err != nil {
+		return "", err
+	}
+	soId, err := uuid.Parse(soIdString)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid service offer Id provided, should be in UUID format")
+	}
+	attestationType, err := cmd.Flags().GetString(constants.AttestationTypeParamName)
+	if err != nil {
+		return "", err
+	}
+	if attestationType == "" {
+		attestationType = policyTemplate.AttestationType
+	}
+
+	var response string
+	if outFile != "" {
+		if err = utils.WriteNewFile(outFile, []byte(policy), constants.DefaultFilePermission, force); err != nil {
+			return "", errors.Wrap(err, "Error writing policy file")
+		}
+		response = "Policy written to: " + outFile
+	}
+
+	uploaded, err := uploadPolicy(cmd, models.CommonPolicy{
+		Policy:          policy,
+		PolicyName:      policyName,
+		PolicyType:      policyType,
+		ServiceOfferId:  soId,
+		AttestationType: attestationType,
+	})
+	if uploaded != "" {
+		if response != "" {
+			response += "\n"
+		}
+		response += "Policy: \n\n " + uploaded
+	}
+	return response, err
+}
+
+// templateValues reads the reference values of the template from the command flags. Reference values that the
+// template does not use are rejected rather than silently dropped.
+func templateValues(cmd *cobra.Command, policyTemplate templates.Template) (templates.Values, error) {
+	var values templates.Values
+	for _, parameter := range []string{constants.MrEnclaveParamName, constants.MrSignerParamName, constants.IsvProdIdParamName,
+		constants.IsvSvnParamName, constants.MrTdParamName, constants.RtmrParamName, constants.MeasurementParamName,
+		constants.MinTcbStatusParamName} {
+		if cmd.Flags().Changed(parameter) && !policyTemplate.Supports(parameter) {
+			return values, errors.Errorf("--%s is not supported by the %s template", parameter, policyTemplate.Name)
+		}
+	}
+
+	measurements := []struct {
+		parameter string
+		size      int
+		value     *string
+	}{
+		{constants.MrEnclaveParamName, sgxMeasurementSize, &values.MrEnclave},
+		{constants.MrSignerParamName, sgxMeasurementSize, &values.MrSigner},
+		{constants.MrTdParamName, tdxMeasurementSize, &values.MrTd},
+		{constants.MeasurementParamName, snpMeasurementSize, &values.Measurement},
+	}
+	for _, m := range measurements {
+		if !cmd.Flags().Changed(m.parameter) {
+			continue
+		}
+		value, err := cmd.Flags().GetString(m.parameter)
+		if err != nil {
+			return values, err
+		}
+		if err = validation.ValidateMeasurement(m.parameter, value, m.size); err != nil {
+			return values, err
+		}
+		*m.value = strings.ToLower(value)
+	}
+
+	for _, parameter := range []struct {
+		name  string
+		value *string
+	}{{constants.IsvProdIdParamName, &values.IsvProdId}, {constants.IsvSvnParamName, &values.IsvSvn}} {
+		if !cmd.Flags().Changed(parameter.name) {
+			continue
+		}
+		value, err := cmd.Flags().GetUint16(parameter.name)
+		if err != nil {
+			return values, err
+		}
+		*parameter.value = strconv.Itoa(int(value))
+	}
+
+	rtmrs, err := cmd.Flags().GetStringArray(constants.RtmrParamName)
+	if err != nil {
+		return values, err
+	}
+	for _, rtmr := range rtmrs {
+		indexString, value, found := strings.Cut(rtmr, "=")
+		index, err := strconv.Atoi(indexString)
+		if !found || err != nil || index < 0 || index >= tdxRtmrCount {
+			return values, errors.Errorf("Invalid --%s %q, should be < index >=< hex value > with an index between 0 and %d",
+				constants.RtmrParamName, rtmr, tdxRtmrCount-1)
+		}
+		if err = validation.ValidateMeasurement(constants.RtmrParamName, value, tdxMeasurementSize); err != nil {
+			return values, err
+		}
+		values.Rtmrs[index] = strings.ToLower(value)
+	}
+
+	if cmd.Flags().Changed(constants.MinTcbStatusParamName) {
+		minTcbStatus, err := cmd.Flags().GetString(constants.MinTcbStatusParamName)
+		if err != nil {
+			return values, err
+		}
+		if values.AllowedTcbStatuses, err = templates.AllowedTcbStatuses(minTcbStatus); err != nil {
+			return values, err
+		}
+	}
+	return values, nil
+}
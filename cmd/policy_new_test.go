@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/templates"
+	"intel/tac/v1/test"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewPolicyCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := test.MockServer(t)
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+
+	outDir := t.TempDir()
+	mrEnclave := strings.Repeat("ab", 32)
+	mrTd := strings.Repeat("cd", 48)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args: []string{constants.PolicyCmd, constants.NewCmd, "--template", "sgx", "--mrenclave", mrEnclave, "--mrsigner", mrEnclave,
+				"--isvprodid", "1", "--isvsvn", "2", "--min-tcb-status", "SWHardeningNeeded", "-o", filepath.Join(outDir, "sgx.rego")},
+			wantErr:     false,
+			description: "Test render SGX policy to a file",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "tdx", "--mrtd", mrTd, "--rtmr", "1=" + mrTd, "--rtmr", "3=" + mrTd},
+			wantErr:     false,
+			description: "Test render TDX policy",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "tdx+nvgpu"},
+			wantErr:     false,
+			description: "Test render TDX and GPU policy without reference values",
+		},
+		{
+			args: []string{constants.PolicyCmd, constants.NewCmd, "--template", "sgx", "--mrenclave", mrEnclave, "--create", "-n", "sgx-policy",
+				"-r", "e8a72b7e-c4b1-4bdc-bf40-68f23c68a2aa", "-q", "valid-id"},
+			wantErr:     false,
+			description: "Test render and upload SGX policy",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "sgx", "--mrsigner", mrEnclave, "--create", "-r", "e8a72b7e-c4b1-4bdc-bf40-68f23c68a2aa"},
+			wantErr:     true,
+			description: "Test upload policy without policy name",
+		},
+		{
+			args: []string{constants.PolicyCmd, constants.NewCmd, "--template", "sgx", "--isvsvn", "2", "--create", "-n", "sgx-policy",
+				"-r", "e8a72b7e-c4b1-4bdc-bf40-68f23c68a2aa"},
+			wantErr:     true,
+			description: "Test upload policy without identity reference value",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "sgx", "--mrenclave", mrEnclave, "--create", "-n", "sgx-policy", "-r", "invalid-id"},
+			wantErr:     true,
+			description: "Test upload policy with invalid service offer id",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "tpm"},
+			wantErr:     true,
+			description: "Test unknown template",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "tdx", "--mrenclave", mrEnclave},
+			wantErr:     true,
+			description: "Test reference value not supported by the template",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "sgx", "--mrenclave", mrTd},
+			wantErr:     true,
+			description: "Test measurement of invalid size",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "sgx", "--mrsigner", strings.Repeat("zz", 32)},
+			wantErr:     true,
+			description: "Test measurement that is not hex encoded",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "tdx", "--rtmr", "4=" + mrTd},
+			wantErr:     true,
+			description: "Test RTMR with invalid index",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "tdx", "--rtmr", mrTd},
+			wantErr:     true,
+			description: "Test RTMR without index",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "tdx", "--min-tcb-status", "Revoked"},
+			wantErr:     true,
+			description: "Test unknown TCB status",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.NewCmd, "--template", "sgx", "-o", filepath.Join(outDir, "missing", "sgx.rego")},
+			wantErr:     true,
+			description: "Test output file in a missing directory",
+		},
+	}
+
+	policyCmd.AddCommand(newPolicyCmd)
+	tenantCmd.AddCommand(policyCmd)
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, newPolicyCmd)
+		_, err := execute(t, tenantCmd, tc.args)
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, newPolicyCmd)
+
+	policyBytes, err := os.ReadFile(filepath.Join(outDir, "sgx.rego"))
+	assert.NoError(t, err)
+	policy := string(policyBytes)
+	assert.Contains(t, policy, `input.sgx_mrenclave == "`+mrEnclave+`"`)
+	assert.Contains(t, policy, "input.sgx_isvprodid == 1")
+	assert.Contains(t, policy, "input.sgx_isvsvn >= 2")
+	assert.Contains(t, policy, `allowed_tcb_status := {"UpToDate", "SWHardeningNeeded"}`)
+	assert.NotContains(t, policy, "# input.")
+
+	newPolicy := func(args ...string) error {
+		resetFlagsForTests(t, newPolicyCmd)
+		defer resetFlagsForTests(t, newPolicyCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.PolicyCmd, constants.NewCmd, "--template", "sgx"}, args...))
+		return err
+	}
+	// the policy file is only replaced with --force
+	sgxPolicyFile := filepath.Join(outDir, "sgx.rego")
+	assert.ErrorContains(t, newPolicy("--mrenclave", mrEnclave, "-o", sgxPolicyFile), "already exists")
+	assert.Error(t, newPolicy("--mrenclave", mrEnclave, "-o", filepath.Join(outDir, "sgx*.rego")))
+	policyBytes, err = os.ReadFile(sgxPolicyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, policy, string(policyBytes))
+	assert.NoError(t, newPolicy("--mrenclave", mrEnclave, "-o", sgxPolicyFile, "--force"))
+	policyBytes, err = os.ReadFile(sgxPolicyFile)
+	assert.NoError(t, err)
+	assert.NotEqual(t, policy, string(policyBytes))
+
+	// nothing is written when the policy cannot be uploaded
+	uploadedPolicyFile := filepath.Join(outDir, "uploaded.rego")
+	assert.Error(t, newPolicy("--mrenclave", mrEnclave, "--create", "-r", "e8a72b7e-c4b1-4bdc-bf40-68f23c68a2aa",
+		"-o", uploadedPolicyFile))
+	assert.Error(t, newPolicy("--mrenclave", mrEnclave, "--create", "-n", "sgx-policy", "-r", "invalid-id",
+		"-o", uploadedPolicyFile))
+	assert.NoFileExists(t, uploadedPolicyFile)
+	assert.NoError(t, newPolicy("--mrenclave", mrEnclave, "--create", "-n", "sgx-policy", "-r",
+		"e8a72b7e-c4b1-4bdc-bf40-68f23c68a2aa", "-o", uploadedPolicyFile))
+	assert.FileExists(t, uploadedPolicyFile)
+}
+
+func TestPolicyTemplates(t *testing.T) {
+	for _, name := range templates.Names() {
+		policyTemplate, err := templates.Get(name)
+		assert.NoError(t, err)
+
+		policy, err := policyTemplate.Render(templates.Values{})
+		assert.NoError(t, err, name)
+		assert.Contains(t, policy, "default matches_", name)
+		// without identity reference value, the identity checks are left as placeholders which do not compile
+		assert.Regexp(t, `\n\tinput\.[a-z_.]+ == <[^>]+>\n`, policy, name)
+		assert.Equal(t, strings.Count(policy, "{"), strings.Count(policy, "}"), name)
+	}
+
+	policyTemplate, err := templates.Get("tdx")
+	assert.NoError(t, err)
+	rtmr := strings.Repeat("ef", 48)
+	policy, err := policyTemplate.Render(templates.Values{Rtmrs: [4]string{2: rtmr}, AllowedTcbStatuses: templates.TcbStatuses[:1]})
+	assert.NoError(t, err)
+	assert.Contains(t, policy, "\tinput.tdx_rtmr2 == \""+rtmr+"\"")
+	assert.Contains(t, policy, "# input.tdx_rtmr0 ==")
+	assert.Contains(t, policy, "# input.tdx_mrtd ==")
+	assert.NotContains(t, policy, "== <")
+	assert.Contains(t, policy, "\tallowed_tcb_status[input.attester_tcb_status]")
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// pullPoliciesCmd represents the policy pull command
+var pullPoliciesCmd = &cobra.Command{
+	Use:   constants.PullCmd,
+	Short: "Export the policies of a tenant to a local directory",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy pull called")
+		response, err := pullPolicies(cmd)
+		utils.PrintRequestAndTraceId()
+		// the policies pulled are also printed when some of them are skipped
+		if response != "" {
+			fmt.Println("Pulled policies: \n\n", response)
+		}
+		return err
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(pullPoliciesCmd)
+
+	pullPoliciesCmd.Flags().StringP(constants.PolicyDirParamName, "d", "./policies", "Directory to which the policies and their metadata are written")
+	pullPoliciesCmd.Flags().StringP(constants.AttestationTypeParamName, "a", "", "Pull only the policies of this attestation type, example \"SGX Attestation\". This is optional.")
+	pullPoliciesCmd.Flags().StringP(constants.PolicyNamePatternParamName, "n", "", "Pull only the policies whose name matches this shell pattern, example \"sgx-*\". This is optional.")
+	pullPoliciesCmd.Flags().Bool(constants.ForceParamName, false, "Replace the policy and metadata files which already exist")
+	pullPoliciesCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+}
+
+func pullPolicies(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	policyDir, err := cmd.Flags().GetString(constants.PolicyDirParamName)
+	if err != nil {
+		return "", err
+	}
+	if policyDir == "" {
+		return "", errors.New("Policy directory path cannot be empty")
+	}
+
+	attestationType, err := cmd.Flags().GetString(constants.AttestationTypeParamName)
+	if err != nil {
+		return "", err
+	}
+
+	namePattern, err := cmd.Flags().GetString(constants.PolicyNamePatternParamName)
+	if err != nil {
+		return "", err
+	}
+	if _, err = path.Match(namePattern, ""); err != nil {
+		return "", errors.Wrap(err, "Invalid policy name pattern provided")
+	}
+
+	if err = os.MkdirAll(filepath.Clean(policyDir), constants.DefaultDirPermission); err != nil {
+		return "", errors.Wrap(err, "Error creating policy directory")
+	}
+	dirPath, err := validation.ValidatePath(policyDir)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid policy directory path provided")
+	}
+
+	pmsClient := pms.NewPmsClient(client, pmsUrl, apiKey)
+	policies, err := pmsClient.SearchPolicy()
+	if err != nil {
+		return "", err
+	}
+
+	force, err := cmd.Flags().GetBool(constants.ForceParamName)
+	if err != nil {
+		return "", err
+	}
+
+	// the policies are selected and their files checked before any file is written
+	var selected []models.PolicyResponse
+	var summary []string
+	for _, policy := range policies {
+		if attestationType != "" && !strings.EqualFold(policy.AttestationType, attestationType) {
+			continue
+		}
+		if namePattern != "" {
+			if matched, _ := path.Match(namePattern, policy.PolicyName); !matched {
+				continue
+			}
+		}
+		// policy names are validated by the service, skip anything that could escape the target directory
+		if err = validation.ValidatePolicyName(policy.PolicyName); err != nil {
+			log.WithError(err).Errorf("Policy %s has a name that cannot be used as a file name", policy.PolicyId)
+			summary = append(summary, fmt.Sprintf("%q (%s) skipped: its name cannot be used as a file name",
+				policy.PolicyName, policy.PolicyId))
+			continue
+		}
+		if !force {
+			for _, extension := range []string{constants.PolicyFileExtension, constants.PolicyMetadataFileExtension} {
+				file := filepath.Join(dirPath, policy.PolicyName+extension)
+				if _, err = os.Lstat(file); err == nil {
+					return "", errors.Errorf("%s already exists, use --%s to replace it", file, constants.ForceParamName)
+				}
+			}
+		}
+		selected = append(selected, policy)
+	}
+	skipped := len(summary)
+
+	for _, policy := range selected {
+		policyFile := filepath.Join(dirPath, policy.PolicyName+constants.PolicyFileExtension)
+		if err = utils.WriteNewFile(policyFile, []byte(policy.Policy), constants.DefaultFilePermission, force); err != nil {
+			return strings.Join(summary, "\n"), errors.Wrap(err, "Error writing policy file")
+		}
+
+		// verify what actually landed on disk rather than the in-memory response
+		writtenPolicy, err := os.ReadFile(policyFile)
+		if err != nil {
+			return strings.Join(summary, "\n"), errors.Wrap(err, "Error reading back policy file")
+		}
+
+		metadata := models2.PolicyMetadata{
+			PolicyId:        policy.PolicyId,
+			PolicyName:      policy.PolicyName,
+			PolicyType:      policy.PolicyType,
+			AttestationType: policy.AttestationType,
+			ServiceOfferId:  policy.ServiceOfferId,
+			Version:         policy.Version,
+			PolicyHash:      policy.PolicyHash,
+			HashStatus:      utils.PolicyHashStatus(string(writtenPolicy), policy.PolicyHash),
+			SignedByTenant:  policy.SignedByTenant,
+			UpdatedAt:       policy.UpdatedAt,
+			PulledAt:        time.Now().UTC(),
+		}
+		metadataBytes, err := json.MarshalIndent(metadata, "", "  ")
+		if err != nil {
+			return strings.Join(summary, "\n"), err
+		}
+		metadataFile := filepath.Join(dirPath, policy.PolicyName+constants.PolicyMetadataFileExtension)
+		if err = utils.WriteNewFile(metadataFile, metadataBytes, constants.DefaultFilePermission, force); err != nil {
+			return strings.Join(summary, "\n"), errors.Wrap(err, "Error writing policy metadata file")
+		}
+
+		recordPolicyHistory(&policy, constants.HistoryOperationPull)
+
+		if metadata.HashStatus == constants.PolicyHashMismatch {
+			log.Warnf("Hash of policy %s does not match the policy hash returned by Trust Authority", policy.PolicyId)
+		}
+		summary = append(summary, fmt.Sprintf("%s (%s) -> %s [hash %s]", policy.PolicyName, policy.PolicyId,
+			policyFile, metadata.HashStatus))
+	}
+
+	if len(summary) == 0 {
+		return "No policies matched the provided filters", nil
+	}
+	if skipped > 0 {
+		return strings.Join(summary, "\n"), errors.Errorf("%d of %d policies were skipped", skipped, len(selected)+skipped)
+	}
+	return strings.Join(summary, "\n"), nil
+}
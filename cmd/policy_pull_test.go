@@ -0,0 +1,143 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPullPoliciesCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := test.MockServer(t)
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+
+	policyDir := t.TempDir()
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{constants.PolicyCmd, constants.PullCmd, "-q", "valid-id", "-d", policyDir, "-a", "SGX Attestation", "-n", "test-*"},
+			wantErr:     false,
+			description: "Test pull policies matching the filters",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.PullCmd, "-d", policyDir, "-a", "TDX Attestation"},
+			wantErr:     false,
+			description: "Test pull policies with no policy matching the attestation type",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.PullCmd, "-d", policyDir, "-n", "["},
+			wantErr:     true,
+			description: "Test invalid policy name pattern",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.PullCmd, "-d", ""},
+			wantErr:     true,
+			description: "Test empty policy directory",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.PullCmd, "-d", policyDir, "-q", "@#$invalid-id"},
+			wantErr:     true,
+			description: "Test invalid request id provided",
+		},
+	}
+
+	policyCmd.AddCommand(pullPoliciesCmd)
+	tenantCmd.AddCommand(policyCmd)
+
+	for _, tc := range tt {
+		_, err := execute(t, tenantCmd, tc.args)
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+
+	policyBytes, err := os.ReadFile(filepath.Join(policyDir, "test-custom4"+constants.PolicyFileExtension))
+	assert.NoError(t, err)
+	assert.Contains(t, string(policyBytes), "matches_sgx_policy")
+
+	metadataBytes, err := os.ReadFile(filepath.Join(policyDir, "test-custom4"+constants.PolicyMetadataFileExtension))
+	assert.NoError(t, err)
+	var metadata models2.PolicyMetadata
+	assert.NoError(t, json.Unmarshal(metadataBytes, &metadata))
+	assert.Equal(t, "52135615-3881-4b94-91ff-49f01e626e7b", metadata.PolicyId.String())
+	assert.Equal(t, "v2", metadata.Version)
+	// the mock server returns a hash that was not computed over the mock policy
+	assert.Equal(t, constants.PolicyHashMismatch, metadata.HashStatus)
+}
+
+func TestPullPoliciesSkipAndForce(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	policies := []models.PolicyResponse{
+		{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "../escape", Policy: "default allow = false",
+			AttestationType: "SGX Attestation"}},
+		{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "edge-policy", Policy: "default allow = true",
+			AttestationType: "SGX Attestation"}},
+	}
+	server := test.PolicyMockServer(t, policies)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	pull := func(args ...string) error {
+		resetFlagsForTests(t, pullPoliciesCmd)
+		defer resetFlagsForTests(t, pullPoliciesCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.PolicyCmd, constants.PullCmd}, args...))
+		return err
+	}
+
+	// a policy whose name cannot be a file name is skipped and reported, the others are still pulled
+	policyDir := t.TempDir()
+	assert.ErrorContains(t, pull("-d", policyDir), "1 of 2 policies were skipped")
+	policyFile := filepath.Join(policyDir, "edge-policy"+constants.PolicyFileExtension)
+	assert.FileExists(t, policyFile)
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(policyDir), "escape"+constants.PolicyFileExtension))
+
+	// existing files are only replaced with --force
+	assert.NoError(t, os.WriteFile(policyFile, []byte("edited"), 0600))
+	assert.ErrorContains(t, pull("-d", policyDir, "-n", "edge-*"), "already exists")
+	policyBytes, err := os.ReadFile(policyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "edited", string(policyBytes))
+	assert.NoError(t, pull("-d", policyDir, "-n", "edge-*", "--force"))
+	policyBytes, err = os.ReadFile(policyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "default allow = true", string(policyBytes))
+}
+
+func TestPullPoliciesCommandWithInvalidUrl(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	test.SetupMockConfiguration("invalid url", tempConfigFile)
+	load, err := config.LoadConfiguration()
+	assert.NoError(t, err)
+
+	policyCmd.AddCommand(pullPoliciesCmd)
+	tenantCmd.AddCommand(policyCmd)
+
+	for _, u := range []string{"bogus\nbase\nURL", "a/b/c"} {
+		viper.Set("trustauthority-url", u)
+		_, err := execute(t, tenantCmd, []string{constants.PolicyCmd, constants.PullCmd, "-d", t.TempDir()})
+		assert.Error(t, err)
+	}
+	viper.Set("trustauthority-url", load.TrustAuthorityBaseUrl)
+}
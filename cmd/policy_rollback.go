@@ -0,0 +1,177 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/history"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// policyRollbackCmd represents the policy rollback command
+var policyRollbackCmd = &cobra.Command{
+	Use:   constants.RollbackCmd,
+	Short: "Reapply a version of a policy recorded in the local history",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy rollback called")
+		response, err := rollbackPolicy(cmd)
+		return printUploadedPolicy("", response, err)
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(policyRollbackCmd)
+
+	policyRollbackCmd.Flags().StringP(constants.PolicyIdParamName, "i", "", "Id of the policy to be rolled back")
+	policyRollbackCmd.Flags().String(constants.RollbackToParamName, "", "Recorded version of the policy, or a timestamp (RFC 3339 or YYYYMMDDhhmmss) to reapply the latest version recorded at that time")
+	policyRollbackCmd.Flags().Bool(constants.DryRunParamName, false, "Only show the differences with the current policy")
+	policyRollbackCmd.Flags().Bool(constants.ForceParamName, false, "Reapply the recorded version without asking for confirmation")
+	policyRollbackCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	addPolicySigningFlags(policyRollbackCmd)
+	policyRollbackCmd.MarkFlagRequired(constants.PolicyIdParamName)
+	policyRollbackCmd.MarkFlagRequired(constants.RollbackToParamName)
+}
+
+func rollbackPolicy(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	policyIdString, err := cmd.Flags().GetString(constants.PolicyIdParamName)
+	if err != nil {
+		return "", err
+	}
+	policyId, err := uuid.Parse(policyIdString)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid policy Id provided, should be in UUID format")
+	}
+	to, err := cmd.Flags().GetString(constants.RollbackToParamName)
+	if err != nil {
+		return "", err
+	}
+	dryRun, err := cmd.Flags().GetBool(constants.DryRunParamName)
+	if err != nil {
+		return "", err
+	}
+	signPolicy, err := cmd.Flags().GetBool(constants.SignObjectParamName)
+	if err != nil {
+		return "", err
+	}
+	force, err := cmd.Flags().GetBool(constants.ForceParamName)
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := history.Load(policyId)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "", errors.Errorf("No versions of policy %s have been recorded", policyId)
+	}
+	target, err := history.Find(entries, to)
+	if err != nil {
+		return "", err
+	}
+
+	pmsClient := pms.NewPmsClient(client, pmsUrl, apiKey)
+	current, err := pmsClient.GetPolicy(policyId)
+	if err != nil {
+		return "", err
+	}
+	diff := utils.UnifiedDiff("current ("+current.Version+")", "recorded ("+target.Version+")", current.Policy, target.Policy)
+	if diff == "" {
+		return fmt.Sprintf("Policy already matches the recorded version %s", target.Version), nil
+	}
+	response := "Diff: \n\n" + diff
+	if dryRun {
+		return response, nil
+	}
+	// reapplying a tenant signed version without signing it again would silently drop the tenant signature
+	if target.SignedByTenant && !signPolicy {
+		return response, errors.Errorf("Version %s of the policy was signed by the tenant, --%s needs to be provided to reapply it",
+			target.Version, constants.SignObjectParamName)
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return response, err
+	}
+	if usage, err := findPolicyUsage(tms.NewTmsClient(client, tmsUrl, apiKey), policyId); err != nil {
+		log.WithError(err).Warn("Unable to check whether the policy is referenced by API clients")
+	} else {
+		affected, err := formatPolicyUsage(usage)
+		if err != nil {
+			return response, err
+		}
+		response += "\nAffected API clients: \n\n" + affected + "\n"
+	}
+	if err = confirmChanges(cmd, constants.ForceParamName, response,
+		fmt.Sprintf("Reapply version %s of the policy?", target.Version)); err != nil {
+		return "", err
+	}
+	// the differences and affected API clients were shown along with the confirmation prompt
+	if force {
+		response += "\n"
+	} else {
+		response = ""
+	}
+
+	policyToken, algorithm, err := signPolicyForUpload(cmd, target.Policy)
+	if err != nil {
+		return response, err
+	}
+	recordPolicyHistory(current, constants.HistoryOperationPrevious)
+	updated, err := pmsClient.UpdatePolicy(&models.PolicyUpdateRequest{PolicyId: policyId, Policy: policyToken})
+	if err != nil {
+		return response, err
+	}
+	recordPolicyHistory(updated, constants.HistoryOperationRollback)
+
+	updatedBytes, err := json.MarshalIndent(updated, "", "  ")
+	if err != nil {
+		return response, err
+	}
+	response += "Policy: \n\n" + string(updatedBytes)
+	if algorithm == "" {
+		return response, nil
+	}
+
+	check, checkErr := checkSignedPolicyResponse(target.Policy, policyToken, algorithm, updated)
+	checkBytes, err := json.MarshalIndent(check, "", "  ")
+	if err != nil {
+		return response, err
+	}
+	return response + "\n\nSignature check: \n\n" + string(checkBytes), checkErr
+}
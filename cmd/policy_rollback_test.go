@@ -0,0 +1,186 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/history"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"intel/tac/v1/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicyRollbackCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	previousPolicy := "default matches_sgx_policy = false\n\nmatches_sgx_policy = true {\n\tinput.sgx_isvsvn == 1\n}\n"
+	updatedPolicy := "default matches_sgx_policy = false\n\nmatches_sgx_policy = true {\n\tinput.sgx_isvsvn == 2\n}\n"
+	policyFile := filepath.Join(t.TempDir(), "policy.rego")
+	assert.NoError(t, os.WriteFile(policyFile, []byte(updatedPolicy), 0600))
+
+	policyId := uuid.New()
+	server := test.PolicyMockServer(t, []models.PolicyResponse{{
+		CommonPolicy: models.CommonPolicy{PolicyId: policyId, Policy: previousPolicy, PolicyName: "rollback-policy"},
+		Version:      "v1",
+	}})
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+	load, err := config.LoadConfiguration()
+	assert.NoError(t, err)
+	viper.Set("trustauthority-url", server.URL)
+	defer viper.Set("trustauthority-url", load.TrustAuthorityBaseUrl)
+
+	updateCmd.AddCommand(updatePolicyCmd)
+	policyCmd.AddCommand(policyRollbackCmd)
+	policyCmd.AddCommand(policyHistoryCmd)
+	tenantCmd.AddCommand(updateCmd)
+	tenantCmd.AddCommand(policyCmd)
+
+	// updating the policy records both the previous and the updated policy
+	resetFlagsForTests(t, updatePolicyCmd)
+	_, err = execute(t, tenantCmd, []string{constants.UpdateCmd, constants.PolicyCmd, "-i", policyId.String(), "-f", policyFile, "--force"})
+	assert.NoError(t, err)
+	resetFlagsForTests(t, updatePolicyCmd)
+
+	entries, err := history.Load(policyId)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, constants.HistoryOperationPrevious, entries[0].Operation)
+		assert.Equal(t, "v1", entries[0].Version)
+		assert.Equal(t, previousPolicy, entries[0].Policy)
+		assert.Equal(t, constants.HistoryOperationUpdate, entries[1].Operation)
+		assert.Equal(t, "v2", entries[1].Version)
+	}
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{constants.PolicyCmd, constants.HistoryCmd, "-i", policyId.String()},
+			wantErr:     false,
+			description: "Test list the recorded versions of the policy",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.RollbackCmd, "-i", policyId.String(), "--to", "v1", "--dry-run"},
+			wantErr:     false,
+			description: "Test show the differences with a recorded version",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.RollbackCmd, "-i", policyId.String(), "--to", "v9"},
+			wantErr:     true,
+			description: "Test rollback to a version that was not recorded",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.RollbackCmd, "-i", policyId.String(), "--to", "20000101000000"},
+			wantErr:     true,
+			description: "Test rollback to a timestamp before the first recorded version",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.RollbackCmd, "-i", uuid.NewString(), "--to", "v1"},
+			wantErr:     true,
+			description: "Test rollback of a policy without recorded versions",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.RollbackCmd, "-i", "invalid-id", "--to", "v1"},
+			wantErr:     true,
+			description: "Test rollback with invalid policy id",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.RollbackCmd, "-i", policyId.String(), "--to", "v1"},
+			wantErr:     true,
+			description: "Test rollback without confirmation",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.RollbackCmd, "-q", "valid-id", "-i", policyId.String(), "--to", "v1", "--force"},
+			wantErr:     false,
+			description: "Test rollback to a recorded version",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.RollbackCmd, "-i", policyId.String(), "--to", "2999-01-01T00:00:00Z"},
+			wantErr:     false,
+			description: "Test rollback to the version that is already applied",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.HistoryCmd, "-i", "invalid-id"},
+			wantErr:     true,
+			description: "Test history with invalid policy id",
+		},
+	}
+
+	tenantCmd.SetIn(strings.NewReader("n\n"))
+	defer tenantCmd.SetIn(nil)
+	for _, tc := range tt {
+		resetFlagsForTests(t, policyRollbackCmd)
+		resetFlagsForTests(t, policyHistoryCmd)
+		_, err := execute(t, tenantCmd, tc.args)
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+
+	entries, err = history.Load(policyId)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, constants.HistoryOperationRollback, entries[2].Operation)
+		assert.Equal(t, "v3", entries[2].Version)
+		assert.Equal(t, previousPolicy, entries[2].Policy)
+	}
+
+	// the recorded version is reapplied once confirmed
+	tenantCmd.SetIn(strings.NewReader("y\n"))
+	_, err = execute(t, tenantCmd, []string{constants.PolicyCmd, constants.RollbackCmd, "-i", policyId.String(), "--to", "v2"})
+	assert.NoError(t, err)
+	resetFlagsForTests(t, policyRollbackCmd)
+	entries, err = history.Load(policyId)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 4) {
+		assert.Equal(t, updatedPolicy, entries[3].Policy)
+	}
+
+	// a tenant signed version is not reapplied without signing it again
+	signedPolicy := &models.PolicyResponse{
+		CommonPolicy:   models.CommonPolicy{PolicyId: policyId, Policy: previousPolicy, PolicyName: "rollback-policy"},
+		Version:        "signed",
+		SignedByTenant: true,
+	}
+	assert.NoError(t, history.Record(signedPolicy, constants.HistoryOperationPull))
+	_, err = execute(t, tenantCmd, []string{constants.PolicyCmd, constants.RollbackCmd, "-i", policyId.String(), "--to", "signed"})
+	assert.Error(t, err)
+	resetFlagsForTests(t, policyRollbackCmd)
+
+	t.Setenv(constants.ProfileEnvVar, "../other")
+	_, err = execute(t, tenantCmd, []string{constants.PolicyCmd, constants.HistoryCmd, "-i", policyId.String()})
+	assert.Error(t, err)
+	resetFlagsForTests(t, policyHistoryCmd)
+
+	// the history is kept per profile
+	t.Setenv(constants.ProfileEnvVar, "staging")
+	entries, err = history.Load(policyId)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// the commands use the configuration of the profile
+	_, err = execute(t, tenantCmd, []string{constants.PolicyCmd, constants.RollbackCmd, "-i", policyId.String(), "--to", "v1"})
+	assert.ErrorContains(t, err, "Failed to load the configuration of profile staging")
+	resetFlagsForTests(t, policyRollbackCmd)
+
+	assert.Empty(t, utils.UnifiedDiff("a", "b", previousPolicy, previousPolicy))
+	assert.Equal(t, "--- a\n+++ b\n@@ -1,5 +1,5 @@\n default matches_sgx_policy = false\n \n matches_sgx_policy = true {\n"+
+		"-\tinput.sgx_isvsvn == 1\n+\tinput.sgx_isvsvn == 2\n }\n", utils.UnifiedDiff("a", "b", previousPolicy, updatedPolicy))
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// policyUsageCmd represents the policy usage command
+var policyUsageCmd = &cobra.Command{
+	Use:   constants.UsageCmd,
+	Short: "List the API clients of every service that reference a policy",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy usage called")
+		response, err := policyUsage(cmd)
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Policy usage: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(policyUsageCmd)
+
+	policyUsageCmd.Flags().StringP(constants.PolicyIdParamName, "i", "", "Id of the policy")
+	policyUsageCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	policyUsageCmd.MarkFlagRequired(constants.PolicyIdParamName)
+}
+
+func policyUsage(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	policyIdString, err := cmd.Flags().GetString(constants.PolicyIdParamName)
+	if err != nil {
+		return "", err
+	}
+	policyId, err := uuid.Parse(policyIdString)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid policy Id provided, should be in UUID format")
+	}
+
+	usage, err := findPolicyUsage(tms.NewTmsClient(client, tmsUrl, apiKey), policyId)
+	if err != nil {
+		return "", err
+	}
+	return formatPolicyUsage(usage)
+}
+
+// findPolicyUsage returns the API clients of every service of the tenant that reference the policy
+func findPolicyUsage(tmsClient tms.TmsClient, policyId uuid.UUID) ([]models2.PolicyUsage, error) {
+	usage, err := findPoliciesUsage(tmsClient)
+	if err != nil {
+		return nil, err
+	}
+	if usage[policyId] == nil {
+		return []models2.PolicyUsage{}, nil
+	}
+	return usage[policyId], nil
+}
+
+// findPoliciesUsage returns the API clients of every service of the tenant referencing each policy
+func findPoliciesUsage(tmsClient tms.TmsClient) (map[uuid.UUID][]models2.PolicyUsage, error) {
+	services, err := tmsClient.GetServices()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the services of the tenant")
+	}
+
+	usage := map[uuid.UUID][]models2.PolicyUsage{}
+	for _, service := range services {
+		apiClients, err := tmsClient.GetApiClient(service.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error fetching the API clients of service %s", service.ID)
+		}
+		for _, apiClient := range apiClients {
+			policies, err := tmsClient.GetApiClientPolicies(service.ID, apiClient.ID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error fetching the policies of API client %s", apiClient.ID)
+			}
+			for _, id := range policies.PolicyIds {
+				usage[id] = append(usage[id], models2.PolicyUsage{
+					ServiceId:     service.ID,
+					ServiceName:   service.Name,
+					ApiClientId:   apiClient.ID,
+					ApiClientName: apiClient.Name,
+					Status:        string(apiClient.Status),
+				})
+			}
+		}
+	}
+	return usage, nil
+}
+
+func formatPolicyUsage(usage []models2.PolicyUsage) (string, error) {
+	if len(usage) == 0 {
+		return "Policy is not referenced by any API client", nil
+	}
+	usageBytes, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(usageBytes), nil
+}
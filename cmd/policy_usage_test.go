@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/test"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestPolicyUsageCmd(t *testing.T) {
+	server := test.MockServer(t)
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{constants.PolicyCmd, constants.UsageCmd, "-q", "valid-id", "-i", "c855d8d6-744f-48c6-a06d-a97ef1811a61"},
+			wantErr:     false,
+			description: "Test usage of a policy referenced by an API client",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.UsageCmd, "-i", "e48dabc5-9608-4ff3-aaed-f25909ab9de1"},
+			wantErr:     false,
+			description: "Test usage of a policy not referenced by any API client",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.UsageCmd, "-i", "invalid id"},
+			wantErr:     true,
+			description: "Test invalid policy id provided",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.UsageCmd, "-q", "@#$invalid-id", "-i", "c855d8d6-744f-48c6-a06d-a97ef1811a61"},
+			wantErr:     true,
+			description: "Test invalid request id provided",
+		},
+	}
+
+	policyCmd.AddCommand(policyUsageCmd)
+	tenantCmd.AddCommand(policyCmd)
+
+	for _, tc := range tt {
+		_, err := execute(t, tenantCmd, tc.args)
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+
+	tmsUrl, err := url.Parse(server.URL + constants.TmsBaseUrl)
+	assert.NoError(t, err)
+	tmsClient := tms.NewTmsClient(&http.Client{}, tmsUrl, "")
+
+	usage, err := findPolicyUsage(tmsClient, uuid.MustParse("c855d8d6-744f-48c6-a06d-a97ef1811a61"))
+	assert.NoError(t, err)
+	if assert.Len(t, usage, 1) {
+		assert.Equal(t, "3780cc39-cce2-4ec2-a47f-03e55b12e259", usage[0].ApiClientId.String())
+		assert.Equal(t, "Test Service", usage[0].ServiceName)
+	}
+
+	usage, err = findPolicyUsage(tmsClient, uuid.MustParse("e48dabc5-9608-4ff3-aaed-f25909ab9de1"))
+	assert.NoError(t, err)
+	assert.Empty(t, usage)
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// verifyPoliciesCmd represents the policy verify command
+var verifyPoliciesCmd = &cobra.Command{
+	Use:   constants.VerifyCmd,
+	Short: "Verify the hash and Trust Authority signature of stored policies",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("policy verify called")
+		response, err := verifyPolicies(cmd)
+		utils.PrintRequestAndTraceId()
+		// the results are also printed when some of the policies fail verification
+		if response != "" {
+			fmt.Println("Policy verification: \n\n", response)
+		}
+		return err
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(verifyPoliciesCmd)
+
+	verifyPoliciesCmd.Flags().StringP(constants.PolicyIdParamName, "i", "", "Id of the policy to be verified")
+	verifyPoliciesCmd.Flags().Bool(constants.AllParamName, false, "Verify all the policies of the tenant")
+	verifyPoliciesCmd.Flags().String(constants.TaCertFileParamName, "", "Path of the PEM file containing the Trust Authority policy signing certificates")
+	verifyPoliciesCmd.Flags().String(constants.JwksUrlParamName, "", "URL of the JWKS containing the Trust Authority policy signing keys")
+	verifyPoliciesCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+}
+
+func verifyPolicies(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	policyIdString, err := cmd.Flags().GetString(constants.PolicyIdParamName)
+	if err != nil {
+		return "", err
+	}
+	allPolicies, err := cmd.Flags().GetBool(constants.AllParamName)
+	if err != nil {
+		return "", err
+	}
+	if (policyIdString == "") == !allPolicies {
+		return "", errors.New("Either a policy Id or --all needs to be provided")
+	}
+
+	signingKeys, err := loadTrustAuthoritySigningKeys(cmd, client)
+	if err != nil {
+		return "", err
+	}
+
+	pmsClient := pms.NewPmsClient(client, pmsUrl, apiKey)
+	var policies []models.PolicyResponse
+	if policyIdString != "" {
+		policyId, err := uuid.Parse(policyIdString)
+		if err != nil {
+			return "", errors.Wrap(err, "Invalid policy Id provided, should be in UUID format")
+		}
+		policy, err := pmsClient.GetPolicy(policyId)
+		if err != nil {
+			return "", err
+		}
+		policies = append(policies, *policy)
+	} else {
+		if policies, err = pmsClient.SearchPolicy(); err != nil {
+			return "", err
+		}
+	}
+
+	failed := 0
+	results := []models2.PolicyVerification{}
+	for _, policy := range policies {
+		result := verifyPolicy(&policy, signingKeys)
+		if result.Status != constants.PolicyStatusVerified {
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	responseBytes, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if failed > 0 {
+		return string(responseBytes), errors.Errorf("%d of %d policies failed verification", failed, len(results))
+	}
+	return string(responseBytes), nil
+}
+
+// loadTrustAuthoritySigningKeys returns the Trust Authority policy signing keys from the certificate file or the JWKS
+func loadTrustAuthoritySigningKeys(cmd *cobra.Command, client *http.Client) ([]crypto.PublicKey, error) {
+	taCertFile, err := cmd.Flags().GetString(constants.TaCertFileParamName)
+	if err != nil {
+		return nil, err
+	}
+	jwksUrl, err := cmd.Flags().GetString(constants.JwksUrlParamName)
+	if err != nil {
+		return nil, err
+	}
+	if (taCertFile == "") == (jwksUrl == "") {
+		return nil, errors.New("Either the Trust Authority signing certificate file or the JWKS URL needs to be provided")
+	}
+	if taCertFile != "" {
+		return utils.LoadSigningCertificateKeys(taCertFile)
+	}
+
+	parsedUrl, err := url.Parse(jwksUrl)
+	if err != nil || (parsedUrl.Scheme != constants.HTTPScheme && parsedUrl.Scheme != "http") || parsedUrl.Host == "" {
+		return nil, errors.New("Invalid JWKS URL provided")
+	}
+	if parsedUrl.Scheme != constants.HTTPScheme {
+		log.Warn("JWKS URL does not use https, the signing keys cannot be trusted")
+	}
+	return utils.LoadJwks(client, jwksUrl)
+}
+
+// verifyPolicy recomputes the hash of the returned policy and checks its Trust Authority signature
+func verifyPolicy(policy *models.PolicyResponse, signingKeys []crypto.PublicKey) models2.PolicyVerification {
+	result := models2.PolicyVerification{
+		PolicyId:   policy.PolicyId,
+		PolicyName: policy.PolicyName,
+		Version:    policy.Version,
+		Status:     constants.PolicyStatusVerified,
+	}
+
+	if policy.PolicySignature == "" {
+		result.Status = constants.PolicyStatusUnsigned
+		result.Reason = "Policy does not carry a Trust Authority signature"
+		return result
+	}
+
+	if hashStatus := utils.PolicyHashStatus(policy.Policy, policy.PolicyHash); hashStatus != constants.PolicyHashVerified {
+		result.Status = constants.PolicyStatusMismatched
+		result.Reason = "Policy hash is " + hashStatus + " for the returned policy"
+		return result
+	}
+
+	// a tenant signed policy has to carry the policy that is enforced
+	if policy.PolicyJWT != "" {
+		_, claims, err := utils.ParsePolicyToken(policy.PolicyJWT)
+		if err != nil || claims.AttestationPolicy != policy.Policy {
+			result.Status = constants.PolicyStatusMismatched
+			result.Reason = "Tenant signed policy JWT does not carry the returned policy"
+			return result
+		}
+	}
+
+	if err := utils.VerifyPolicySignature(policy.Policy, policy.PolicyHash, policy.PolicySignature, signingKeys); err != nil {
+		result.Status = constants.PolicyStatusTampered
+		result.Reason = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+const verifyTestPolicy = "default matches_sgx_policy = false \n\n matches_sgx_policy = true { \n\n quote := input.quote \n\n quote.isvsvn == 0 \n\n }"
+
+// signedPolicyForTests returns a policy carrying the hash and the Trust Authority signature of the provided policy
+func signedPolicyForTests(t *testing.T, key *rsa.PrivateKey, name, policy string) models.PolicyResponse {
+	digest := sha512.Sum384([]byte(policy))
+	signature, err := rsa.SignPSS(rand.Reader, key, crypto.SHA384, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	assert.NoError(t, err)
+
+	return models.PolicyResponse{
+		CommonPolicy: models.CommonPolicy{
+			PolicyId:        uuid.New(),
+			Policy:          policy,
+			PolicyName:      name,
+			PolicyType:      "Appraisal policy",
+			AttestationType: "SGX Attestation",
+		},
+		PolicyHash:      base64.StdEncoding.EncodeToString(digest[:]),
+		PolicySignature: base64.StdEncoding.EncodeToString(signature),
+		Version:         "v1",
+	}
+}
+
+func TestVerifyPolicy(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 3072)
+	assert.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 3072)
+	assert.NoError(t, err)
+	keys := []crypto.PublicKey{&otherKey.PublicKey, &key.PublicKey}
+
+	verified := signedPolicyForTests(t, key, "verified", verifyTestPolicy)
+
+	tampered := signedPolicyForTests(t, otherKey, "tampered", verifyTestPolicy)
+	tampered.PolicySignature = signedPolicyForTests(t, key, "tampered", verifyTestPolicy+" ").PolicySignature
+
+	unsigned := signedPolicyForTests(t, key, "unsigned", verifyTestPolicy)
+	unsigned.PolicySignature = ""
+
+	mismatchedHash := signedPolicyForTests(t, key, "mismatched-hash", verifyTestPolicy)
+	mismatchedHash.Policy = verifyTestPolicy + " "
+
+	tenantToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &models.PolicyClaims{AttestationPolicy: "default allow = true"}).
+		SignedString([]byte("secret"))
+	assert.NoError(t, err)
+	mismatchedToken := signedPolicyForTests(t, key, "mismatched-token", verifyTestPolicy)
+	mismatchedToken.PolicyJWT = tenantToken
+
+	tt := []struct {
+		policy      models.PolicyResponse
+		keys        []crypto.PublicKey
+		status      string
+		description string
+	}{
+		{
+			policy:      verified,
+			keys:        keys,
+			status:      constants.PolicyStatusVerified,
+			description: "Test policy signed by one of the Trust Authority keys",
+		},
+		{
+			policy:      tampered,
+			keys:        keys,
+			status:      constants.PolicyStatusTampered,
+			description: "Test policy signature computed over a different policy",
+		},
+		{
+			policy:      verified,
+			keys:        []crypto.PublicKey{&otherKey.PublicKey},
+			status:      constants.PolicyStatusTampered,
+			description: "Test policy signed by an unknown key",
+		},
+		{
+			policy:      unsigned,
+			keys:        keys,
+			status:      constants.PolicyStatusUnsigned,
+			description: "Test policy without a signature",
+		},
+		{
+			policy:      mismatchedHash,
+			keys:        keys,
+			status:      constants.PolicyStatusMismatched,
+			description: "Test policy hash not computed over the returned policy",
+		},
+		{
+			policy:      mismatchedToken,
+			keys:        keys,
+			status:      constants.PolicyStatusMismatched,
+			description: "Test tenant signed policy JWT carrying a different policy",
+		},
+	}
+
+	for _, tc := range tt {
+		result := verifyPolicy(&tc.policy, tc.keys)
+		assert.Equal(t, tc.status, result.Status, tc.description)
+		assert.Equal(t, tc.policy.PolicyId, result.PolicyId, tc.description)
+		if tc.status == constants.PolicyStatusVerified {
+			assert.Empty(t, result.Reason, tc.description)
+		} else {
+			assert.NotEmpty(t, result.Reason, tc.description)
+		}
+	}
+}
+
+func TestVerifyPoliciesCmd(t *testing.T) {
+	keyDir := t.TempDir()
+	key, err := rsa.GenerateKey(rand.Reader, 3072)
+	assert.NoError(t, err)
+	certPath := filepath.Join(keyDir, "ta-signing.pem")
+	writeKeyAndCertForTests(t, key, constants.RSAPrivateKeyType, filepath.Join(keyDir, "ta-signing.key"), certPath)
+
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{
+				{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+				{
+					"kty": "RSA",
+					"kid": "policy-signing",
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	}))
+	defer jwksServer.Close()
+
+	verified := signedPolicyForTests(t, key, "verified", verifyTestPolicy)
+	tampered := signedPolicyForTests(t, key, "tampered", verifyTestPolicy)
+	tampered.PolicySignature = signedPolicyForTests(t, key, "tampered", verifyTestPolicy+" ").PolicySignature
+
+	server := test.PolicyMockServer(t, []models.PolicyResponse{verified, tampered})
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+	load, err := config.LoadConfiguration()
+	assert.NoError(t, err)
+	viper.Set("trustauthority-url", server.URL)
+	defer viper.Set("trustauthority-url", load.TrustAuthorityBaseUrl)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{constants.PolicyCmd, constants.VerifyCmd, "-i", verified.PolicyId.String(), "--" + constants.TaCertFileParamName, certPath, "-q", "valid-id"},
+			wantErr:     false,
+			description: "Test verify policy with the Trust Authority certificate",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.VerifyCmd, "-i", verified.PolicyId.String(), "--" + constants.JwksUrlParamName, jwksServer.URL},
+			wantErr:     false,
+			description: "Test verify policy with the Trust Authority JWKS",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.VerifyCmd, "-i", tampered.PolicyId.String(), "--" + constants.TaCertFileParamName, certPath},
+			wantErr:     true,
+			description: "Test verify tampered policy",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.VerifyCmd, "--" + constants.AllParamName, "--" + constants.JwksUrlParamName, jwksServer.URL},
+			wantErr:     true,
+			description: "Test verify all policies with one of them tampered",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.VerifyCmd, "-i", uuid.NewString(), "--" + constants.TaCertFileParamName, certPath},
+			wantErr:     true,
+			description: "Test verify policy that does not exist",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.VerifyCmd, "-i", "invalid-id", "--" + constants.TaCertFileParamName, certPath},
+			wantErr:     true,
+			description: "Test verify policy with invalid policy id",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.VerifyCmd, "-i", verified.PolicyId.String(), "--" + constants.AllParamName, "--" + constants.TaCertFileParamName, certPath},
+			wantErr:     true,
+			description: "Test verify with both policy id and all policies",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.VerifyCmd, "-i", verified.PolicyId.String()},
+			wantErr:     true,
+			description: "Test verify without Trust Authority signing keys",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.VerifyCmd, "-i", verified.PolicyId.String(), "--" + constants.TaCertFileParamName, certPath, "--" + constants.JwksUrlParamName, jwksServer.URL},
+			wantErr:     true,
+			description: "Test verify with both the certificate and the JWKS",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.VerifyCmd, "-i", verified.PolicyId.String(), "--" + constants.JwksUrlParamName, "ftp://keys.example.com/jwks"},
+			wantErr:     true,
+			description: "Test verify with invalid JWKS URL",
+		},
+		{
+			args:        []string{constants.PolicyCmd, constants.VerifyCmd, "-i", verified.PolicyId.String(), "--" + constants.JwksUrlParamName, jwksServer.URL + "/missing"},
+			wantErr:     true,
+			description: "Test verify with JWKS URL returning an error",
+		},
+	}
+
+	policyCmd.AddCommand(verifyPoliciesCmd)
+	tenantCmd.AddCommand(policyCmd)
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, verifyPoliciesCmd)
+		_, err := execute(t, tenantCmd, tc.args)
+
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, verifyPoliciesCmd)
+}
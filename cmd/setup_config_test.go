@@ -148,7 +148,7 @@ func TestSetupConfigInvalidFileSize(t *testing.T) {
 
 func TestCheckKeyFileInvalidPrivateKeyPath(t *testing.T) {
 	//Invalid Private Key Path provided
-	_, _, err := utils.CheckKeyFiles("/.privateKey.txt", "./certKey.txt")
+	_, _, err := utils.CheckKeyFiles("/.privateKey.txt", "./certKey.txt", nil)
 	if err != nil {
 		assert.Error(t, err, "")
 	}
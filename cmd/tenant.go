@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// tenantResourceCmd groups the commands handling the configuration of the tenant as a whole
+var tenantResourceCmd = &cobra.Command{
+	Use:   constants.TenantCmd,
+	Short: "Export and import the configuration of the tenant",
+	Long:  ``,
+}
+
+func init() {
+	tenantCmd.AddCommand(tenantResourceCmd)
+}
+
+// profileClients returns the clients of the tenant of the profile, usually another tenant
+func profileClients(profile string) (tms.TmsClient, pms.PmsClient, error) {
+	profileValues, err := config.LoadProfileConfiguration(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	profileClient := &http.Client{
+		Timeout: time.Duration(profileValues.HTTPClientTimeout) * time.Second,
+	}
+	tmsUrl, err := url.Parse(profileValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return nil, nil, err
+	}
+	pmsUrl, err := url.Parse(profileValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tms.NewTmsClient(profileClient, tmsUrl, profileValues.TrustAuthorityApiKey),
+		pms.NewPmsClient(profileClient, pmsUrl, profileValues.TrustAuthorityApiKey), nil
+}
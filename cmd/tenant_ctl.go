@@ -6,17 +6,21 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"intel/tac/v1/config"
 	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
 	"intel/tac/v1/internal/models"
 	"intel/tac/v1/utils"
 	"intel/tac/v1/validation"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 var (
@@ -61,9 +65,18 @@ func Execute() {
 
 		//API key is not needed for generating policy JWT or setting up config, API key check is skipped for these commands
 		cmdListWithNoApiKey := map[string]bool{constants.PolicyJwtCmd: true, constants.SetupConfigCmd: true,
-			constants.UninstallCmd: true, constants.VersionCmd: true}
+			constants.UninstallCmd: true, constants.VersionCmd: true, constants.FromTokenCmd: true,
+			constants.HistoryCmd: true, constants.BundleCmd: true, constants.KeysCmd: true,
+			constants.AnnotateCmd: true, constants.DeprecateCmd: true}
 		//API key is not needed for generating policy JWT or setting up config, API key check is skipped for these 2 commands
-		if ok := cmdListWithNoApiKey[cmd.Name()]; !ok {
+		//Sub commands of an offline command such as "policy-jwt decode" are skipped as well
+		ok := cmdListWithNoApiKey[cmd.Name()] || (cmd.HasParent() && cmdListWithNoApiKey[cmd.Parent().Name()])
+		//Rendering a policy template is offline unless the policy is uploaded as well
+		if cmd.Name() == constants.NewCmd {
+			upload, _ := cmd.Flags().GetBool(constants.CreateParamName)
+			ok = ok || !upload
+		}
+		if !ok {
 			apiKey = configValues.TrustAuthorityApiKey
 			if err = validation.ValidateTrustAuthorityAPIKey(configValues.TrustAuthorityApiKey); err != nil {
 				// check if jwt token is passed instead of api-key (packaged software use-case)
@@ -86,6 +99,12 @@ func Execute() {
 		logrus.SetOutput(logFile)
 		logrus.WithField(constants.HTTPHeaderKeyRequestId, models.RespHeaderFields.RequestId).
 			WithField(constants.HTTPHeaderKeyTraceId, models.RespHeaderFields.TraceId).Error(err)
+		if _, ok := errors.Cause(err).(*lookup.NotFoundError); ok {
+			os.Exit(constants.ExitCodeNotFound)
+		}
+		if _, ok := errors.Cause(err).(*driftDetectedError); ok {
+			os.Exit(constants.ExitCodeDrift)
+		}
 		os.Exit(1)
 	}
 }
@@ -93,3 +112,49 @@ func Execute() {
 func init() {
 	cobra.OnInitialize()
 }
+
+// confirmChanges shows the changes about to be made and asks the user to confirm them, unless the flag skipping the
+// confirmation is set
+func confirmChanges(cmd *cobra.Command, skipParamName, changes, question string) error {
+	skip, err := cmd.Flags().GetBool(skipParamName)
+	if err != nil {
+		return err
+	}
+	if skip {
+		return nil
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s\n%s [y/N]: ", changes, question)
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return errors.Wrap(err, "Error reading the confirmation")
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return errors.Errorf("Changes were not confirmed, nothing was changed. Use --%s to make them without confirmation",
+		skipParamName)
+}
+
+// outputFilePath returns the validated path of the output file flag, empty when the flag is not provided, and whether
+// the file can be replaced. An existing file is refused unless --force is set, the file being written with
+// utils.WriteNewFile once the command succeeded.
+func outputFilePath(cmd *cobra.Command) (string, bool, error) {
+	outFile, err := cmd.Flags().GetString(constants.OutFileParamName)
+	if err != nil || outFile == "" {
+		return "", false, err
+	}
+	force, err := cmd.Flags().GetBool(constants.ForceParamName)
+	if err != nil {
+		return "", false, err
+	}
+	path, err := validation.ValidateOutputPath(outFile)
+	if err != nil {
+		return "", false, errors.Wrap(err, "Invalid output file path provided")
+	}
+	if _, err = os.Lstat(path); err == nil && !force {
+		return "", false, errors.Errorf("%s already exists, use --%s to replace it", outFile, constants.ForceParamName)
+	}
+	return path, force, nil
+}
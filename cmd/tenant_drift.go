@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/backup"
+	"intel/tac/v1/internal/drift"
+	"intel/tac/v1/internal/manifest"
+	"intel/tac/v1/utils"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// tenantDriftCmd represents the tenant drift command
+var tenantDriftCmd = &cobra.Command{
+	Use:   constants.DriftCmd,
+	Short: "Detect changes of the policies, api clients and users of the tenant against a baseline",
+	Long: `Compare the policies, api clients and users of the tenant with a baseline, either YAML manifests as used by
+"apply" or an archive written by "tenant export", and report the resources added, removed or changed since. The
+command exits with status 3 when the tenant drifted from the baseline, so that it can be run by scheduled CI jobs.
+SARIF and JUnit reports are printed alone, without the request and trace ids, unless written to a file with --out.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("tenant drift called")
+		response, err := detectDrift(cmd)
+		// a SARIF or JUnit report printed is a document of its own, which CI jobs can redirect to a file
+		format, _ := cmd.Flags().GetString(constants.FormatParamName)
+		outFile, _ := cmd.Flags().GetString(constants.OutFileParamName)
+		if format != constants.DriftFormatText && outFile == "" {
+			if response != "" {
+				fmt.Println(response)
+			}
+			return err
+		}
+		utils.PrintRequestAndTraceId()
+		if response != "" {
+			fmt.Println("Tenant drift: \n\n", response)
+		}
+		return err
+	},
+}
+
+func init() {
+	tenantResourceCmd.AddCommand(tenantDriftCmd)
+
+	tenantDriftCmd.Flags().String(constants.BaselineParamName, "", "Baseline the tenant is compared with: a manifest file, "+
+		"a directory of .yaml and .yml manifest files, or a .tar.gz archive written by tenant export")
+	tenantDriftCmd.Flags().String(constants.FormatParamName, constants.DriftFormatText, "Format of the report: "+
+		constants.DriftFormatText+", "+constants.DriftFormatSarif+" or "+constants.DriftFormatJunit)
+	tenantDriftCmd.Flags().StringP(constants.OutFileParamName, "o", "", "Path of the file the report is written to, "+
+		"a summary is printed instead of the report")
+	tenantDriftCmd.Flags().Bool(constants.ForceParamName, false, "Replace the report file when it already exists")
+	tenantDriftCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	tenantDriftCmd.MarkFlagRequired(constants.BaselineParamName)
+}
+
+// driftDetectedError reports that the tenant drifted from the baseline, the CLI then exits with ExitCodeDrift
+type driftDetectedError struct {
+	drifted int
+}
+
+func (e *driftDetectedError) Error() string {
+	return fmt.Sprintf("%d resources drifted from the baseline", e.drifted)
+}
+
+func detectDrift(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	baselinePath, err := cmd.Flags().GetString(constants.BaselineParamName)
+	if err != nil {
+		return "", err
+	}
+	format, err := cmd.Flags().GetString(constants.FormatParamName)
+	if err != nil {
+		return "", err
+	}
+	if err = drift.ValidateFormat(format); err != nil {
+		return "", err
+	}
+	outFile, force, err := outputFilePath(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	// the baseline is read before the tenant is fetched so that an invalid baseline fails fast
+	var archive *backup.Tenant
+	var m *manifest.Manifest
+	if strings.HasSuffix(baselinePath, ".tar.gz") || strings.HasSuffix(baselinePath, ".tgz") {
+		if _, archive, err = backup.Read(baselinePath); err != nil {
+			return "", err
+		}
+	} else if m, err = manifest.Load([]string{baselinePath}); err != nil {
+		return "", err
+	}
+
+	tenant, err := fetchTenant(tms.NewTmsClient(client, tmsUrl, apiKey), pms.NewPmsClient(client, pmsUrl, apiKey))
+	if err != nil {
+		return "", err
+	}
+	var baseline *drift.Snapshot
+	if archive != nil {
+		baseline = drift.FromTenant(archive)
+	} else {
+		baseline = drift.FromManifest(m, tenant.Services)
+	}
+	report := drift.Compare(baseline, drift.FromTenant(tenant), filepath.ToSlash(baselinePath))
+
+	response, err := report.Format(format, utils.Version)
+	if err != nil {
+		return "", err
+	}
+	if outFile != "" {
+		if err = utils.WriteNewFile(outFile, []byte(response), constants.DefaultFilePermission, force); err != nil {
+			return "", errors.Wrap(err, "Error writing the drift report")
+		}
+		summary, err := report.Format(constants.DriftFormatText, utils.Version)
+		if err != nil {
+			return "", err
+		}
+		response = summary + "\n\nReport written to: " + outFile
+	}
+
+	if drifted := len(report.Drifted()); drifted > 0 {
+		return response, &driftDetectedError{drifted: drifted}
+	}
+	return response, nil
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/backup"
+	"intel/tac/v1/internal/drift"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// driftBaselineForTests describes the policies, api client and user of the tenant returned by exportTenantForTests
+const driftBaselineForTests = `apiVersion: trustauthority.intel.com/v1
+kind: Policy
+metadata:
+  name: payments-policy
+spec:
+  attestationType: TDX
+  serviceOffer: TDX Attestation
+  policy: default allow = true
+---
+apiVersion: trustauthority.intel.com/v1
+kind: Policy
+metadata:
+  name: shared-policy
+spec:
+  attestationType: TDX
+  serviceOffer: TDX Attestation
+  policy: default allow = false
+---
+apiVersion: trustauthority.intel.com/v1
+kind: ApiClient
+metadata:
+  name: payments-workload
+spec:
+  service: Production
+  product: Enterprise
+  policies:
+    - payments-policy
+    - shared-policy
+  tags:
+    - key: Workload
+      value: Payments
+---
+apiVersion: trustauthority.intel.com/v1
+kind: User
+metadata:
+  name: alice@example.com
+spec:
+  role: Tenant Admin
+`
+
+func TestTenantDriftCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tenant := exportTenantForTests()
+	server := test.TenantMockServer(t, tenant)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "baseline.yaml")
+	assert.NoError(t, os.WriteFile(baselinePath, []byte(driftBaselineForTests), 0600))
+	archivePath := filepath.Join(dir, "tenant.tar.gz")
+	_, _, err := backup.Write(archivePath, server.URL, &backup.Tenant{Services: tenant.Services,
+		ServiceOffers: tenant.ServiceOffers, ApiClients: tenant.ApiClients, Policies: tenant.Policies, Users: tenant.Users}, false)
+	assert.NoError(t, err)
+
+	detect := func(args ...string) error {
+		resetFlagsForTests(t, tenantDriftCmd)
+		defer resetFlagsForTests(t, tenantDriftCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.TenantCmd, constants.DriftCmd}, args...))
+		return err
+	}
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{"--baseline", baselinePath},
+			wantErr:     false,
+			description: "Test detect drift against a manifest",
+		},
+		{
+			args:        []string{"--baseline", dir},
+			wantErr:     false,
+			description: "Test detect drift against a directory of manifests",
+		},
+		{
+			args:        []string{"--baseline", archivePath},
+			wantErr:     false,
+			description: "Test detect drift against an export",
+		},
+		{
+			args:        []string{"--baseline", baselinePath, "--format", constants.DriftFormatSarif},
+			wantErr:     false,
+			description: "Test detect drift with a SARIF report",
+		},
+		{
+			args:        []string{},
+			wantErr:     true,
+			description: "Test detect drift without baseline",
+		},
+		{
+			args:        []string{"--baseline", filepath.Join(dir, "missing.yaml")},
+			wantErr:     true,
+			description: "Test detect drift against a missing baseline",
+		},
+		{
+			args:        []string{"--baseline", baselinePath, "--format", "html"},
+			wantErr:     true,
+			description: "Test detect drift with an unsupported format",
+		},
+	}
+	for _, tc := range tt {
+		err := detect(tc.args...)
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+
+	// the tenant drifts from the baseline
+	tenant.Policies[0].Policy = "default allow = false"
+	tenant.ApiClients[0].Status = constants.ApiClientStatusInactive
+	tenant.Users = append(tenant.Users, models.TenantUser{Email: "mallory@example.com", Role: models.Role{Name: constants.TenantAdminRole}})
+
+	var driftErr *driftDetectedError
+	err = detect("--baseline", baselinePath)
+	assert.True(t, errors.As(err, &driftErr))
+	assert.Equal(t, 3, driftErr.drifted)
+	err = detect("--baseline", archivePath)
+	assert.True(t, errors.As(err, &driftErr))
+	assert.Equal(t, 3, driftErr.drifted)
+
+	sarifPath := filepath.Join(dir, "drift.sarif")
+	err = detect("--baseline", baselinePath, "--format", constants.DriftFormatSarif, "--out", sarifPath)
+	assert.True(t, errors.As(err, &driftErr))
+	sarifBytes, err := os.ReadFile(sarifPath)
+	assert.NoError(t, err)
+	var sarif struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleId string `json:"ruleId"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	assert.NoError(t, json.Unmarshal(sarifBytes, &sarif))
+	assert.Equal(t, constants.SarifVersion, sarif.Version)
+	assert.Len(t, sarif.Runs[0].Results, 3)
+	assert.Equal(t, "drift/"+constants.DriftChangeChanged, sarif.Runs[0].Results[0].RuleId)
+
+	// the report is not replaced unless forced
+	err = detect("--baseline", baselinePath, "--format", constants.DriftFormatSarif, "--out", sarifPath)
+	assert.ErrorContains(t, err, "already exists")
+	err = detect("--baseline", baselinePath, "--format", constants.DriftFormatSarif, "--out", sarifPath, "--force")
+	assert.True(t, errors.As(err, &driftErr))
+	err = detect("--baseline", baselinePath, "--out", filepath.Join(dir, "missing", "drift.txt"))
+	assert.ErrorContains(t, err, "Invalid output file path provided")
+
+	junitPath := filepath.Join(dir, "drift.xml")
+	err = detect("--baseline", baselinePath, "--format", constants.DriftFormatJunit, "--out", junitPath)
+	assert.True(t, errors.As(err, &driftErr))
+	junitBytes, err := os.ReadFile(junitPath)
+	assert.NoError(t, err)
+	var junit struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+	}
+	assert.NoError(t, xml.Unmarshal(junitBytes, &junit))
+	assert.Equal(t, 5, junit.Tests)
+	assert.Equal(t, 3, junit.Failures)
+}
+
+func TestDriftCompare(t *testing.T) {
+	tenant := exportTenantForTests()
+	baseline := drift.FromTenant(&backup.Tenant{Services: tenant.Services, ApiClients: tenant.ApiClients,
+		Policies: tenant.Policies, Users: tenant.Users})
+
+	// a manifest only covers the kinds it describes
+	current := drift.FromTenant(&backup.Tenant{Services: tenant.Services, Policies: tenant.Policies})
+	current.Policies["payments-policy"] = drift.Policy{PolicyType: constants.DefaultPolicyType, AttestationType: "SGX",
+		Hash: current.Policies["payments-policy"].Hash}
+	baseline.Kinds = map[string]bool{constants.ManifestKindPolicy: true}
+	report := drift.Compare(baseline, current, "baseline.yaml")
+	assert.Len(t, report.Results, 2)
+	assert.Len(t, report.Drifted(), 1)
+	assert.Equal(t, constants.DriftChangeChanged, report.Drifted()[0].Change)
+	assert.Equal(t, []string{`attestationType: "TDX" in the baseline, "SGX" in the tenant`}, report.Drifted()[0].Details)
+
+	// resources are reported as removed when missing from the tenant
+	baseline.Kinds = nil
+	report = drift.Compare(baseline, current, "tenant.tar.gz")
+	assert.Len(t, report.Drifted(), 3)
+	assert.Equal(t, constants.DriftChangeRemoved, report.Drifted()[1].Change)
+	assert.Equal(t, "Production/payments-workload", report.Drifted()[1].Name)
+
+	text, err := report.Format(constants.DriftFormatText, "")
+	assert.NoError(t, err)
+	assert.Contains(t, text, "- ApiClient Production/payments-workload")
+	assert.Contains(t, text, "Drift: 0 added, 2 removed, 1 changed out of 4 resources compared with tenant.tar.gz")
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/backup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// tenantExportCmd represents the tenant export command
+var tenantExportCmd = &cobra.Command{
+	Use:   constants.ExportCmd,
+	Short: "Export the configuration of the tenant to an archive",
+	Long: `Export the services, api clients, policies, tags, users and settings of the tenant to a versioned and
+checksummed gzipped tarball, which can be imported in another tenant with "tenant import". The attestation API keys
+of the api clients are not exported.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("tenant export called")
+		response, err := exportTenant(cmd)
+		utils.PrintRequestAndTraceId()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Tenant export: \n\n", response)
+		return nil
+	},
+}
+
+func init() {
+	tenantResourceCmd.AddCommand(tenantExportCmd)
+
+	tenantExportCmd.Flags().StringP(constants.OutFileParamName, "o", "", "Path of the archive the configuration is written to, such as tenant.tar.gz")
+	tenantExportCmd.Flags().Bool(constants.ForceParamName, false, "Replace the archive when it already exists")
+	tenantExportCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	tenantExportCmd.MarkFlagRequired(constants.OutFileParamName)
+}
+
+func exportTenant(cmd *cobra.Command) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	outFile, err := cmd.Flags().GetString(constants.OutFileParamName)
+	if err != nil {
+		return "", err
+	}
+	if outFile == "" {
+		return "", errors.New("Archive path cannot be empty")
+	}
+	force, err := cmd.Flags().GetBool(constants.ForceParamName)
+	if err != nil {
+		return "", err
+	}
+	// the archive is checked before fetching the tenant, it is only written once the tenant has been fetched
+	archivePath, err := validation.ValidateOutputPath(outFile)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid archive path provided")
+	}
+	if _, err = os.Lstat(archivePath); err == nil && !force {
+		return "", errors.Errorf("%s already exists, use --%s to replace it", outFile, constants.ForceParamName)
+	}
+
+	tenant, err := fetchTenant(tms.NewTmsClient(client, tmsUrl, apiKey), pms.NewPmsClient(client, pmsUrl, apiKey))
+	if err != nil {
+		return "", err
+	}
+	metadata, checksum, err := backup.Write(archivePath, configValues.TrustAuthorityBaseUrl, tenant, force)
+	if err != nil {
+		return "", err
+	}
+
+	responseBytes, err := json.MarshalIndent(&models2.TenantExport{
+		File:          outFile,
+		FormatVersion: metadata.FormatVersion,
+		CreatedAt:     metadata.CreatedAt,
+		Checksum:      checksum,
+		Services:      len(tenant.Services),
+		ApiClients:    len(tenant.ApiClients),
+		Policies:      len(tenant.Policies),
+		Tags:          len(tenant.Tags),
+		Users:         len(tenant.Users),
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(responseBytes), nil
+}
+
+// fetchTenant fetches the configuration of the tenant, along with the detail of every service and api client
+func fetchTenant(tmsClient tms.TmsClient, pmsClient pms.PmsClient) (*backup.Tenant, error) {
+	tenant := &backup.Tenant{}
+	services, err := tmsClient.GetServices()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the services")
+	}
+	for _, service := range services {
+		detail, err := tmsClient.RetrieveService(service.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error fetching service %s", service.ID)
+		}
+		tenant.Services = append(tenant.Services, *detail)
+
+		apiClients, err := tmsClient.GetApiClient(service.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error fetching the api clients of service %s", service.ID)
+		}
+		for _, apiClient := range apiClients {
+			apiClientDetail, err := tmsClient.RetrieveApiClient(service.ID, apiClient.ID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error fetching api client %s", apiClient.ID)
+			}
+			apiClientDetail.ServiceId = service.ID
+			tenant.ApiClients = append(tenant.ApiClients, *apiClientDetail)
+		}
+	}
+
+	if tenant.ServiceOffers, err = tmsClient.GetServiceOffers(); err != nil {
+		return nil, errors.Wrap(err, "Error fetching the service offers")
+	}
+	if tenant.Policies, err = pmsClient.SearchPolicy(); err != nil {
+		return nil, errors.Wrap(err, "Error fetching the policies")
+	}
+	tags, err := tmsClient.GetTenantTags()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching the tags")
+	}
+	tenant.Tags = tags.Tags
+	if tenant.Users, err = tmsClient.GetUsers(); err != nil {
+		return nil, errors.Wrap(err, "Error fetching the users")
+	}
+	if tenant.Settings, err = tmsClient.GetTenantSettings(); err != nil {
+		return nil, errors.Wrap(err, "Error fetching the tenant settings")
+	}
+	return tenant, nil
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/backup"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// exportTenantForTests returns a tenant with a service, two policies, tags, a user and an api client
+func exportTenantForTests() *test.Tenant {
+	serviceOfferId := uuid.New()
+	workloadTagId, predefinedTagId := uuid.New(), uuid.New()
+	tenant := &test.Tenant{
+		Services:      []models.ServiceDetail{{ID: uuid.New(), ServiceOfferId: serviceOfferId, Name: "Production", Active: true}},
+		ServiceOffers: []models.ServiceOffer{{ID: serviceOfferId, Name: "TDX Attestation"}},
+		Products:      []models.Product{{ID: uuid.New(), ServiceOfferId: serviceOfferId, Name: "Enterprise"}},
+		Policies: []models.PolicyResponse{
+			{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "payments-policy", Policy: "default allow = true",
+				PolicyType: constants.DefaultPolicyType, ServiceOfferId: serviceOfferId, AttestationType: "TDX"}},
+			{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "shared-policy", Policy: "default allow = false",
+				PolicyType: constants.DefaultPolicyType, ServiceOfferId: serviceOfferId, AttestationType: "TDX"}},
+		},
+		Tags:     []models.Tag{{ID: &workloadTagId, Name: "Workload"}, {ID: &predefinedTagId, Name: "Predefined", Predefined: true}},
+		Users:    []models.TenantUser{{ID: uuid.New(), Email: "alice@example.com", Role: models.Role{Name: constants.TenantAdminRole}}},
+		Settings: models.AttestationFailureEmail{AttestationFailureEmail: "security@example.com"},
+	}
+	tenant.ApiClients = []models.ApiClientDetail{
+		{ID: uuid.New(), ServiceId: tenant.Services[0].ID, ProductId: tenant.Products[0].ID, ProductName: "Enterprise",
+			Status: constants.ApiClientStatusActive, Name: "payments-workload", Keys: []string{"attestation-api-key"},
+			PolicyIds:  []uuid.UUID{tenant.Policies[0].PolicyId, tenant.Policies[1].PolicyId},
+			TagsValues: []models.ApiClientTagValue{{Name: "Workload", Value: "Payments"}}},
+	}
+	return tenant
+}
+
+func TestTenantExportCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tenant := exportTenantForTests()
+	server := test.TenantMockServer(t, tenant)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "tenant.tar.gz")
+	existingPath := filepath.Join(dir, "existing.tar.gz")
+	assert.NoError(t, os.WriteFile(existingPath, []byte("previous archive"), 0644))
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{"--out", archivePath},
+			wantErr:     false,
+			description: "Test export the tenant",
+		},
+		{
+			args:        []string{},
+			wantErr:     true,
+			description: "Test export the tenant without archive path",
+		},
+		{
+			args:        []string{"--out", filepath.Join(t.TempDir(), "missing", "tenant.tar.gz")},
+			wantErr:     true,
+			description: "Test export the tenant to a missing directory",
+		},
+		{
+			args:        []string{"--out", filepath.Join(dir, "tenant*.tar.gz")},
+			wantErr:     true,
+			description: "Test export the tenant to an invalid path",
+		},
+		{
+			args:        []string{"--out", existingPath},
+			wantErr:     true,
+			description: "Test export the tenant to an existing file",
+		},
+		{
+			args:        []string{"--out", archivePath},
+			wantErr:     true,
+			description: "Test export the tenant again to the same archive",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, tenantExportCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.TenantCmd, constants.ExportCmd}, tc.args...))
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+		resetFlagsForTests(t, tenantExportCmd)
+	}
+
+	fileInfo, err := os.Stat(archivePath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(constants.TenantArchiveFilePermission), fileInfo.Mode().Perm())
+
+	// an existing file is only replaced with --force, and is then only readable by the current user
+	content, err := os.ReadFile(existingPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "previous archive", string(content))
+	_, err = execute(t, tenantCmd, []string{constants.TenantCmd, constants.ExportCmd, "--out", existingPath, "--force"})
+	assert.NoError(t, err)
+	resetFlagsForTests(t, tenantExportCmd)
+	fileInfo, err = os.Stat(existingPath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(constants.TenantArchiveFilePermission), fileInfo.Mode().Perm())
+	_, _, err = backup.Read(existingPath)
+	assert.NoError(t, err)
+
+	metadata, exported, err := backup.Read(archivePath)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.TenantArchiveFormatVersion, metadata.FormatVersion)
+	assert.Len(t, metadata.Checksums, 7)
+	assert.Len(t, exported.Services, 1)
+	assert.Len(t, exported.Policies, 2)
+	assert.Equal(t, "default allow = true", exported.Policies[0].Policy)
+	assert.Len(t, exported.Tags, 2)
+	assert.Len(t, exported.Users, 1)
+	assert.Equal(t, "security@example.com", exported.Settings.AttestationFailureEmail)
+	assert.Len(t, exported.ApiClients, 1)
+	assert.Equal(t, tenant.ApiClients[0].PolicyIds, exported.ApiClients[0].PolicyIds)
+	// the attestation API keys are not exported
+	assert.Empty(t, exported.ApiClients[0].Keys)
+}
+
+// alterTenantArchiveForTests rewrites a file of the archive without updating its checksum
+func alterTenantArchiveForTests(t *testing.T, archivePath, name string, content []byte) {
+	f, err := os.Open(archivePath)
+	assert.NoError(t, err)
+	gzipReader, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+
+	var archive bytes.Buffer
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		fileContent, err := io.ReadAll(tarReader)
+		assert.NoError(t, err)
+		if header.Name == name {
+			fileContent = content
+			header.Size = int64(len(content))
+		}
+		assert.NoError(t, tarWriter.WriteHeader(header))
+		_, err = tarWriter.Write(fileContent)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, f.Close())
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+	assert.NoError(t, os.WriteFile(archivePath, archive.Bytes(), 0600))
+}
+
+func TestTenantArchiveChecksums(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "tenant.tar.gz")
+	_, checksum, err := backup.Write(archivePath, "https://staging.example.com", &backup.Tenant{}, false)
+	assert.NoError(t, err)
+	assert.Contains(t, checksum, constants.TenantArchiveChecksumPrefix)
+	_, _, err = backup.Read(archivePath)
+	assert.NoError(t, err)
+
+	alterTenantArchiveForTests(t, archivePath, constants.TenantArchivePoliciesFile, []byte(`[{"policy_name": "injected"}]`))
+	_, _, err = backup.Read(archivePath)
+	assert.ErrorContains(t, err, "Checksum mismatch")
+
+	alterTenantArchiveForTests(t, archivePath, constants.TenantArchiveMetadataFile, []byte(`{"format_version": 99}`))
+	_, _, err = backup.Read(archivePath)
+	assert.ErrorContains(t, err, "Unsupported tenant archive format version")
+
+	assert.NoError(t, os.WriteFile(archivePath, []byte("not an archive"), 0600))
+	_, _, err = backup.Read(archivePath)
+	assert.Error(t, err)
+}
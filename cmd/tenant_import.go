@@ -0,0 +1,429 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/backup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"intel/tac/v1/validation"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// tenantImportCmd represents the tenant import command
+var tenantImportCmd = &cobra.Command{
+	Use:   constants.ImportCmd + " <archive>",
+	Short: "Recreate the configuration of an exported tenant",
+	Long: `Recreate the tags, policies, users, settings and api clients of an archive written by "tenant export" in the
+current tenant, or in the tenant of another profile. Resources are matched by name: the ones which already exist are
+left unchanged and referenced in place of the exported ones. Services cannot be created, so api clients are only
+created in the services of the same name. Every resource of the archive is reported along with what was done with it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("tenant import called")
+		response, err := importTenant(cmd, args[0])
+		utils.PrintRequestAndTraceId()
+		if response != "" {
+			fmt.Println("Tenant import: \n\n", response)
+		}
+		return err
+	},
+}
+
+func init() {
+	tenantResourceCmd.AddCommand(tenantImportCmd)
+
+	tenantImportCmd.Flags().String(constants.ToProfileParamName, "", "Profile of the tenant the configuration is imported in, "+
+		"configured in ~/.config/trustauthorityctl/profiles/<profile>.yaml, the current tenant otherwise")
+	tenantImportCmd.Flags().Bool(constants.DryRunParamName, false, "Report how the configuration would be imported without creating anything")
+	tenantImportCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+}
+
+// tenantImporter recreates the resources of an archive in the destination tenant, remapping the ids of the archive to
+// the ids of the destination tenant
+type tenantImporter struct {
+	tmsClient tms.TmsClient
+	pmsClient pms.PmsClient
+	dryRun    bool
+	report    *models2.TenantImport
+	// tagNames holds the tags available in the destination tenant, policyIds maps the ids of the policies of the
+	// archive to the destination tenant, a nil id standing for a policy only created outside of a dry run
+	tagNames  map[string]bool
+	policyIds map[uuid.UUID]*uuid.UUID
+}
+
+func importTenant(cmd *cobra.Command, archivePath string) (string, error) {
+	configValues, err := config.LoadConfiguration()
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout: time.Duration(configValues.HTTPClientTimeout) * time.Second,
+	}
+
+	tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+	pmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.PmsBaseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if err = setRequestId(cmd); err != nil {
+		return "", err
+	}
+
+	if archivePath, err = validation.ValidatePath(archivePath); err != nil {
+		return "", errors.Wrap(err, "Invalid archive path")
+	}
+	profile, err := cmd.Flags().GetString(constants.ToProfileParamName)
+	if err != nil {
+		return "", err
+	}
+	dryRun, err := cmd.Flags().GetBool(constants.DryRunParamName)
+	if err != nil {
+		return "", err
+	}
+
+	metadata, tenant, err := backup.Read(archivePath)
+	if err != nil {
+		return "", err
+	}
+
+	importer := &tenantImporter{
+		tmsClient: tms.NewTmsClient(client, tmsUrl, apiKey),
+		pmsClient: pms.NewPmsClient(client, pmsUrl, apiKey),
+		dryRun:    dryRun,
+		report: &models2.TenantImport{
+			File:       archivePath,
+			Source:     metadata.Source,
+			ExportedAt: metadata.CreatedAt,
+			Profile:    profile,
+			DryRun:     dryRun,
+			Resources:  []models2.ImportedResource{},
+		},
+		tagNames:  map[string]bool{},
+		policyIds: map[uuid.UUID]*uuid.UUID{},
+	}
+	if profile != "" {
+		if importer.tmsClient, importer.pmsClient, err = profileClients(profile); err != nil {
+			return "", err
+		}
+	}
+
+	// tags and policies are imported first so that the api clients can reference them
+	for _, step := range []func(*backup.Tenant) error{importer.importTags, importer.importPolicies, importer.importUsers,
+		importer.importSettings, importer.importApiClients} {
+		if err = step(tenant); err != nil {
+			// the resources imported so far are reported, the existing ones being left unchanged when the archive is
+			// imported again
+			err = errors.Wrapf(err, "Error importing the tenant, %d resources of the archive were processed",
+				len(importer.report.Resources))
+			break
+		}
+	}
+
+	responseBytes, marshalErr := json.MarshalIndent(importer.report, "", "  ")
+	if marshalErr != nil {
+		return "", marshalErr
+	}
+	return string(responseBytes), err
+}
+
+func (i *tenantImporter) add(kind, name, sourceId string, id *uuid.UUID, action, note string) {
+	i.report.Resources = append(i.report.Resources, models2.ImportedResource{Kind: kind, Name: name, SourceId: sourceId,
+		Id: id, Action: action, Note: note})
+}
+
+func (i *tenantImporter) importTags(tenant *backup.Tenant) error {
+	destTags, err := i.tmsClient.GetTenantTags()
+	if err != nil {
+		return errors.Wrap(err, "Error fetching the tags of the destination tenant")
+	}
+	destIds := map[string]*uuid.UUID{}
+	for _, tag := range destTags.Tags {
+		destIds[tag.Name] = tag.ID
+		i.tagNames[tag.Name] = true
+	}
+
+	for _, tag := range tenant.Tags {
+		sourceId := ""
+		if tag.ID != nil {
+			sourceId = tag.ID.String()
+		}
+		if i.tagNames[tag.Name] {
+			i.add(constants.TagCmd, tag.Name, sourceId, destIds[tag.Name], constants.ImportActionExists, "")
+			continue
+		}
+		if tag.Predefined {
+			i.add(constants.TagCmd, tag.Name, sourceId, nil, constants.ImportActionSkip,
+				"predefined tags are not available in the destination tenant")
+			continue
+		}
+		var id *uuid.UUID
+		if !i.dryRun {
+			created, err := i.tmsClient.CreateTenantTag(&models.TagCreate{Name: tag.Name})
+			if err != nil {
+				return errors.Wrapf(err, "Error importing tag %s", tag.Name)
+			}
+			id = created.ID
+		}
+		i.tagNames[tag.Name] = true
+		i.add(constants.TagCmd, tag.Name, sourceId, id, constants.ImportActionCreate, "")
+	}
+	return nil
+}
+
+func (i *tenantImporter) importPolicies(tenant *backup.Tenant) error {
+	destPolicies, err := i.pmsClient.SearchPolicy()
+	if err != nil {
+		return errors.Wrap(err, "Error fetching the policies of the destination tenant")
+	}
+	destByName := map[string]models.PolicyResponse{}
+	for _, policy := range destPolicies {
+		destByName[policy.PolicyName] = policy
+	}
+	destOffers, err := i.tmsClient.GetServiceOffers()
+	if err != nil {
+		return errors.Wrap(err, "Error fetching the service offers of the destination tenant")
+	}
+	sourceOfferNames := map[uuid.UUID]string{}
+	for _, serviceOffer := range tenant.ServiceOffers {
+		sourceOfferNames[serviceOffer.ID] = serviceOffer.Name
+	}
+
+	for _, policy := range tenant.Policies {
+		if dest, ok := destByName[policy.PolicyName]; ok {
+			id := dest.PolicyId
+			i.policyIds[policy.PolicyId] = &id
+			note := ""
+			if strings.TrimSpace(dest.Policy) != strings.TrimSpace(policy.Policy) {
+				note = "the existing policy differs from the exported one and was left unchanged"
+			}
+			i.add(constants.PolicyCmd, policy.PolicyName, policy.PolicyId.String(), &id, constants.ImportActionExists, note)
+			continue
+		}
+
+		// service offers are mapped by id, and else by name
+		serviceOfferId := uuid.Nil
+		for _, serviceOffer := range destOffers {
+			if serviceOffer.ID == policy.ServiceOfferId {
+				serviceOfferId = serviceOffer.ID
+			}
+		}
+		for _, serviceOffer := range destOffers {
+			if serviceOfferId == uuid.Nil && sourceOfferNames[policy.ServiceOfferId] != "" &&
+				serviceOffer.Name == sourceOfferNames[policy.ServiceOfferId] {
+				serviceOfferId = serviceOffer.ID
+			}
+		}
+		if serviceOfferId == uuid.Nil {
+			i.add(constants.PolicyCmd, policy.PolicyName, policy.PolicyId.String(), nil, constants.ImportActionSkip,
+				fmt.Sprintf("service offer %s is not available in the destination tenant", policy.ServiceOfferId))
+			continue
+		}
+
+		var id *uuid.UUID
+		if !i.dryRun {
+			imported := policy
+			imported.ServiceOfferId = serviceOfferId
+			created, err := copyPolicy(i.pmsClient, &imported)
+			if err != nil {
+				return err
+			}
+			id = &created.PolicyId
+		}
+		i.policyIds[policy.PolicyId] = id
+		i.add(constants.PolicyCmd, policy.PolicyName, policy.PolicyId.String(), id, constants.ImportActionCreate, "")
+	}
+	return nil
+}
+
+func (i *tenantImporter) importUsers(tenant *backup.Tenant) error {
+	destUsers, err := i.tmsClient.GetUsers()
+	if err != nil {
+		return errors.Wrap(err, "Error fetching the users of the destination tenant")
+	}
+	destByEmail := map[string]models.TenantUser{}
+	for _, user := range destUsers {
+		destByEmail[strings.ToLower(user.Email)] = user
+	}
+
+	for _, user := range tenant.Users {
+		if dest, ok := destByEmail[strings.ToLower(user.Email)]; ok {
+			id := dest.ID
+			note := ""
+			if dest.Role.Name != user.Role.Name {
+				note = fmt.Sprintf("the user has the role %s instead of %s and was left unchanged", dest.Role.Name, user.Role.Name)
+			}
+			i.add(constants.UserCmd, user.Email, user.ID.String(), &id, constants.ImportActionExists, note)
+			continue
+		}
+		var id *uuid.UUID
+		if !i.dryRun {
+			created, err := i.tmsClient.CreateUser(&models.CreateTenantUser{Email: user.Email, Role: user.Role.Name})
+			if err != nil {
+				return errors.Wrapf(err, "Error importing user %s", user.Email)
+			}
+			id = &created.ID
+		}
+		i.add(constants.UserCmd, user.Email, user.ID.String(), id, constants.ImportActionCreate, "")
+	}
+	return nil
+}
+
+func (i *tenantImporter) importSettings(tenant *backup.Tenant) error {
+	if tenant.Settings == nil {
+		return nil
+	}
+	destSettings, err := i.tmsClient.GetTenantSettings()
+	if err != nil {
+		return errors.Wrap(err, "Error fetching the settings of the destination tenant")
+	}
+	if destSettings != nil && destSettings.AttestationFailureEmail == tenant.Settings.AttestationFailureEmail {
+		i.add(constants.TenantSettingsCmd, constants.TenantSettingsCmd, "", nil, constants.ImportActionExists, "")
+		return nil
+	}
+	if !i.dryRun {
+		if _, err = i.tmsClient.UpdateTenantSettings(tenant.Settings); err != nil {
+			return errors.Wrap(err, "Error importing the tenant settings")
+		}
+	}
+	i.add(constants.TenantSettingsCmd, constants.TenantSettingsCmd, "", nil, constants.ImportActionUpdate, "")
+	return nil
+}
+
+// importApiClients creates the api clients in the services of the same name of the destination tenant. New
+// attestation API keys are generated as the keys of the exported api clients are not part of the archive.
+func (i *tenantImporter) importApiClients(tenant *backup.Tenant) error {
+	destServices, err := i.tmsClient.GetServices()
+	if err != nil {
+		return errors.Wrap(err, "Error fetching the services of the destination tenant")
+	}
+
+	for s := range tenant.Services {
+		service := &tenant.Services[s]
+		var matches []models.Service
+		for _, destService := range destServices {
+			if destService.Name == service.Name {
+				matches = append(matches, destService)
+			}
+		}
+		if len(matches) != 1 {
+			note := "services cannot be created, the service offer should be subscribed to first"
+			if len(matches) > 1 {
+				note = fmt.Sprintf("%d services of the destination tenant have the same name", len(matches))
+			}
+			i.add(constants.ServiceCmd, service.Name, service.ID.String(), nil, constants.ImportActionSkip, note)
+			for _, apiClient := range tenant.ApiClients {
+				if apiClient.ServiceId == service.ID {
+					i.add(constants.ApiClientCmd, apiClient.Name, apiClient.ID.String(), nil, constants.ImportActionSkip,
+						fmt.Sprintf("service %s is not available in the destination tenant", service.Name))
+				}
+			}
+			continue
+		}
+		destServiceId := matches[0].ID
+		i.add(constants.ServiceCmd, service.Name, service.ID.String(), &destServiceId, constants.ImportActionExists, "")
+
+		destService, err := i.tmsClient.RetrieveService(destServiceId)
+		if err != nil {
+			return errors.Wrapf(err, "Error fetching service %s of the destination tenant", destServiceId)
+		}
+		destApiClients, err := i.tmsClient.GetApiClient(destServiceId)
+		if err != nil {
+			return errors.Wrapf(err, "Error fetching the api clients of service %s of the destination tenant", destServiceId)
+		}
+		for _, apiClient := range tenant.ApiClients {
+			if apiClient.ServiceId != service.ID {
+				continue
+			}
+			if err = i.importApiClient(&apiClient, service, destService, destApiClients); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (i *tenantImporter) importApiClient(apiClient *models.ApiClientDetail, service, destService *models.ServiceDetail,
+	destApiClients []models.ApiClient) error {
+	for _, destApiClient := range destApiClients {
+		if destApiClient.Name == apiClient.Name {
+			id := destApiClient.ID
+			i.add(constants.ApiClientCmd, apiClient.Name, apiClient.ID.String(), &id, constants.ImportActionExists, "")
+			return nil
+		}
+	}
+
+	productId, err := cloneDestinationProduct(i.tmsClient, apiClient, service, destService, false)
+	if err != nil {
+		i.add(constants.ApiClientCmd, apiClient.Name, apiClient.ID.String(), nil, constants.ImportActionSkip, err.Error())
+		return nil
+	}
+
+	var policyIds []uuid.UUID
+	var dropped []string
+	for _, policyId := range apiClient.PolicyIds {
+		destId, ok := i.policyIds[policyId]
+		if !ok {
+			dropped = append(dropped, "policy "+policyId.String())
+		} else if destId != nil {
+			policyIds = append(policyIds, *destId)
+		}
+	}
+	var tags []models.ApiClientTagValue
+	for _, tag := range apiClient.TagsValues {
+		if i.tagNames[tag.Name] {
+			tags = append(tags, tag)
+		} else {
+			dropped = append(dropped, fmt.Sprintf("tag %s:%s", tag.Name, tag.Value))
+		}
+	}
+	// as with clones, only active api clients are imported as active
+	status := models.ApiClientStatus(constants.ApiClientStatusActive)
+	if apiClient.Status != constants.ApiClientStatusActive {
+		status = constants.ApiClientStatusInactive
+	}
+
+	var id *uuid.UUID
+	if !i.dryRun {
+		created, err := i.tmsClient.CreateApiClient(&models.CreateApiClient{
+			ProductId:    productId,
+			ServiceId:    destService.ID,
+			PolicyIds:    policyIds,
+			TagIdsValues: apiClientTagIdValues(tags),
+			Name:         apiClient.Name,
+			Status:       status,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Error importing api client %s", apiClient.Name)
+		}
+		id = &created.ID
+	}
+	note := "new attestation API keys are generated, they can be listed with list apiClient"
+	if len(dropped) > 0 {
+		note += "; left out as not available in the destination tenant: " + strings.Join(dropped, ", ")
+	}
+	i.add(constants.ApiClientCmd, apiClient.Name, apiClient.ID.String(), id, constants.ImportActionCreate, note)
+	return nil
+}
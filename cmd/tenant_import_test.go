@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/backup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/test"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestTenantImportCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	source := exportTenantForTests()
+	source.Services = append(source.Services, models.ServiceDetail{ID: uuid.New(), ServiceOfferId: source.ServiceOffers[0].ID,
+		Name: "Development", Active: true})
+	source.ApiClients = append(source.ApiClients, models.ApiClientDetail{ID: uuid.New(), ServiceId: source.Services[1].ID,
+		ProductId: source.Products[0].ID, ProductName: "Enterprise", Status: constants.ApiClientStatusActive, Name: "dev-workload"})
+	archivePath := filepath.Join(t.TempDir(), "tenant.tar.gz")
+	_, _, err := backup.Write(archivePath, "https://staging.example.com", &backup.Tenant{
+		Services:      source.Services,
+		ServiceOffers: source.ServiceOffers,
+		ApiClients:    source.ApiClients,
+		Policies:      source.Policies,
+		Tags:          source.Tags,
+		Users:         source.Users,
+		Settings:      &source.Settings,
+	}, false)
+	assert.NoError(t, err)
+
+	// the destination tenant subscribed to a service of the same name, with another service offer id, and already
+	// has a policy of the same name
+	serviceOfferId, predefinedTagId := uuid.New(), uuid.New()
+	destination := &test.Tenant{
+		Services:      []models.ServiceDetail{{ID: uuid.New(), ServiceOfferId: serviceOfferId, Name: "Production", Active: true}},
+		ServiceOffers: []models.ServiceOffer{{ID: serviceOfferId, Name: "TDX Attestation"}},
+		Products:      []models.Product{{ID: uuid.New(), ServiceOfferId: serviceOfferId, Name: "Enterprise"}},
+		Policies: []models.PolicyResponse{{CommonPolicy: models.CommonPolicy{PolicyId: uuid.New(), PolicyName: "shared-policy",
+			Policy: "default allow = true", ServiceOfferId: serviceOfferId}}},
+		Tags: []models.Tag{{ID: &predefinedTagId, Name: "Predefined", Predefined: true}},
+	}
+	destinationServer := httptest.NewUnstartedServer(test.TenantMockServer(t, destination).Config.Handler)
+	destinationServer.StartTLS()
+	defer destinationServer.Close()
+	setupProfileForTests(t, "production", destinationServer)
+
+	server := test.TenantMockServer(t, &test.Tenant{})
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args:        []string{archivePath, "--to-profile", "production", "--dry-run"},
+			wantErr:     false,
+			description: "Test import a tenant archive without creating anything",
+		},
+		{
+			args:        []string{filepath.Join(t.TempDir(), "missing.tar.gz"), "--to-profile", "production"},
+			wantErr:     true,
+			description: "Test import a missing tenant archive",
+		},
+		{
+			args:        []string{archivePath, "--to-profile", "unknown"},
+			wantErr:     true,
+			description: "Test import a tenant archive to an unknown profile",
+		},
+	}
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, tenantImportCmd)
+		_, err := execute(t, tenantCmd, append([]string{constants.TenantCmd, constants.ImportCmd}, tc.args...))
+		if tc.wantErr == true {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+		resetFlagsForTests(t, tenantImportCmd)
+	}
+	assert.Len(t, destination.Policies, 1)
+	assert.Empty(t, destination.ApiClients)
+
+	resetFlagsForTests(t, tenantImportCmd)
+	_, err = execute(t, tenantCmd, []string{constants.TenantCmd, constants.ImportCmd, archivePath, "--to-profile", "production"})
+	assert.NoError(t, err)
+	resetFlagsForTests(t, tenantImportCmd)
+
+	assert.Len(t, destination.Tags, 2)
+	assert.Len(t, destination.Policies, 2)
+	assert.Equal(t, serviceOfferId, destination.Policies[1].ServiceOfferId)
+	assert.Len(t, destination.Users, 1)
+	assert.Equal(t, constants.TenantAdminRole, destination.Users[0].Role.Name)
+	assert.Equal(t, "security@example.com", destination.Settings.AttestationFailureEmail)
+
+	// the api client of the missing service is skipped, the other one references the policies of the destination tenant
+	assert.Len(t, destination.ApiClients, 1)
+	imported := destination.ApiClients[0]
+	assert.Equal(t, "payments-workload", imported.Name)
+	assert.Equal(t, destination.Services[0].ID, imported.ServiceId)
+	assert.Equal(t, destination.Products[0].ID, imported.ProductId)
+	assert.ElementsMatch(t, []uuid.UUID{destination.Policies[0].PolicyId, destination.Policies[1].PolicyId}, imported.PolicyIds)
+	assert.Equal(t, "Workload", imported.TagsValues[0].Name)
+
+	// importing the archive again only maps the existing resources
+	resetFlagsForTests(t, tenantImportCmd)
+	_, err = execute(t, tenantCmd, []string{constants.TenantCmd, constants.ImportCmd, archivePath, "--to-profile", "production"})
+	assert.NoError(t, err)
+	resetFlagsForTests(t, tenantImportCmd)
+	assert.Len(t, destination.Tags, 2)
+	assert.Len(t, destination.Policies, 2)
+	assert.Len(t, destination.ApiClients, 1)
+
+	// the resources imported before a failure are reported along with the error
+	source.ApiClients = append(source.ApiClients, models.ApiClientDetail{ID: uuid.New(), ServiceId: source.Services[0].ID,
+		ProductId: source.Products[0].ID, ProductName: "Enterprise", Status: constants.ApiClientStatusActive,
+		Name: test.FailingApiClientName})
+	platformTagId := uuid.New()
+	source.Tags = append(source.Tags, models.Tag{ID: &platformTagId, Name: "Platform"})
+	failingArchivePath := filepath.Join(t.TempDir(), "failing.tar.gz")
+	_, _, err = backup.Write(failingArchivePath, "https://staging.example.com", &backup.Tenant{
+		Services:      source.Services,
+		ServiceOffers: source.ServiceOffers,
+		ApiClients:    source.ApiClients,
+		Policies:      source.Policies,
+		Tags:          source.Tags,
+	}, false)
+	assert.NoError(t, err)
+	resetFlagsForTests(t, tenantImportCmd)
+	defer resetFlagsForTests(t, tenantImportCmd)
+	assert.NoError(t, tenantImportCmd.Flags().Set(constants.ToProfileParamName, "production"))
+	response, err := importTenant(tenantImportCmd, failingArchivePath)
+	assert.ErrorContains(t, err, "Error importing the tenant")
+	var report models2.TenantImport
+	assert.NoError(t, json.Unmarshal([]byte(response), &report))
+	created := map[string]string{}
+	for _, resource := range report.Resources {
+		created[resource.Name] = resource.Action
+	}
+	assert.Equal(t, constants.ImportActionCreate, created["Platform"])
+	assert.Equal(t, constants.ImportActionExists, created["payments-workload"])
+	assert.NotContains(t, created, test.FailingApiClientName)
+	assert.Len(t, destination.Tags, 3)
+	assert.Len(t, destination.ApiClients, 1)
+}
@@ -173,5 +173,5 @@ func updateApiClient(cmd *cobra.Command) (string, error) {
 		return "", err
 	}
 
-	return string(responseBytes), nil
+	return string(responseBytes) + deprecatedPolicyWarnings(policyIds), nil
 }
@@ -12,10 +12,10 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"intel/tac/v1/client/pms"
+	"intel/tac/v1/client/tms"
 	"intel/tac/v1/config"
 	"intel/tac/v1/constants"
 	"intel/tac/v1/models"
-	"intel/tac/v1/utils"
 	"intel/tac/v1/validation"
 	"net/http"
 	"net/url"
@@ -33,12 +33,7 @@ var updatePolicyCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log.Info("update Policy called")
 		response, err := updatePolicy(cmd)
-		utils.PrintRequestAndTraceId()
-		if err != nil {
-			return err
-		}
-		fmt.Println("Updated policy: \n\n", response)
-		return nil
+		return printUploadedPolicy("Updated policy: \n\n ", response, err)
 	},
 }
 
@@ -49,6 +44,8 @@ func init() {
 	updatePolicyCmd.Flags().StringP(constants.PolicyNameParamName, "n", "", "Name of the policy to be updated")
 	updatePolicyCmd.Flags().StringP(constants.PolicyFileParamName, "f", "", "Path of the file containing the rego policy to be uploaded. The file size should be <= 10 KB")
 	updatePolicyCmd.Flags().StringP(constants.RequestIdParamName, "q", "", "Request ID to be associated with the specific request. This is optional.")
+	updatePolicyCmd.Flags().Bool(constants.ForceParamName, false, "Update the policy without confirmation even if it is referenced by API clients")
+	addPolicySigningFlags(updatePolicyCmd)
 	updatePolicyCmd.MarkFlagRequired(constants.PolicyIdParamName)
 }
 
@@ -98,6 +95,15 @@ func updatePolicy(cmd *cobra.Command) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	signPolicy, err := cmd.Flags().GetBool(constants.SignObjectParamName)
+	if err != nil {
+		return "", err
+	}
+	if signPolicy && policyFilePath == "" {
+		return "", errors.New("Policy file needs to be provided to sign the policy")
+	}
+
+	var policy, policyToken, algorithm string
 	// policy file is not mandatory, skipping policy read if file path is empty
 	if policyFilePath != "" {
 		path, err := validation.ValidatePath(policyFilePath)
@@ -114,21 +120,80 @@ func updatePolicy(cmd *cobra.Command) (string, error) {
 			return "", err
 		}
 
+		// an empty policy file leaves the policy unchanged, which cannot be done when the policy is to be signed
+		if len(policyBytes) == 0 && signPolicy {
+			return "", errors.New("Policy file does not contain a rego policy to be signed")
+		}
 		if string(policyBytes) != "" {
-			policyUpdateReq.Policy = string(policyBytes)
+			policy = string(policyBytes)
+			if policyToken, algorithm, err = signPolicyForUpload(cmd, policy); err != nil {
+				return "", err
+			}
+			policyUpdateReq.Policy = policyToken
 		}
 	}
 
 	pmsClient := pms.NewPmsClient(client, pmsUrl, apiKey)
+	// the attestations of the API clients referencing the policy are appraised with the new policy, so the update
+	// needs to be confirmed when there are any
+	var affected string
+	if policyUpdateReq.Policy != "" {
+		force, err := cmd.Flags().GetBool(constants.ForceParamName)
+		if err != nil {
+			return "", err
+		}
+		tmsUrl, err := url.Parse(configValues.TrustAuthorityBaseUrl + constants.TmsBaseUrl)
+		if err != nil {
+			return "", err
+		}
+		usage, err := findPolicyUsage(tms.NewTmsClient(client, tmsUrl, apiKey), policyId)
+		if err != nil {
+			if !force {
+				return "", errors.Wrapf(err, "Unable to check whether the policy is referenced by API clients, use --%s to update it anyway",
+					constants.ForceParamName)
+			}
+			log.WithError(err).Warn("Unable to check whether the policy is referenced by API clients")
+		} else if affected, err = formatPolicyUsage(usage); err != nil {
+			return "", err
+		}
+		if len(usage) > 0 {
+			if err = confirmChanges(cmd, constants.ForceParamName, "Affected API clients: \n\n"+affected,
+				fmt.Sprintf("Update the policy referenced by %d API client(s)?", len(usage))); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	// the previous policy is kept in the local history so that the update can be rolled back
+	if policyUpdateReq.Policy != "" {
+		if current, err := pmsClient.GetPolicy(policyId); err != nil {
+			log.WithError(err).Warn("Unable to fetch the current policy for the local history")
+		} else {
+			recordPolicyHistory(current, constants.HistoryOperationPrevious)
+		}
+	}
 	response, err := pmsClient.UpdatePolicy(&policyUpdateReq)
 	if err != nil {
 		return "", err
 	}
+	recordPolicyHistory(response, constants.HistoryOperationUpdate)
 
 	responseBytes, err := json.MarshalIndent(response, "", "  ")
 	if err != nil {
 		return "", err
 	}
+	output := string(responseBytes)
+	if affected != "" {
+		output += "\n\nAffected API clients: \n\n" + affected
+	}
+	if algorithm == "" {
+		return output, nil
+	}
 
-	return string(responseBytes), nil
+	check, checkErr := checkSignedPolicyResponse(policy, policyToken, algorithm, response)
+	checkBytes, err := json.MarshalIndent(check, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return output + "\n\nSignature check: \n\n" + string(checkBytes), checkErr
 }
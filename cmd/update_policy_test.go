@@ -12,10 +12,13 @@ import (
 	"intel/tac/v1/constants"
 	"intel/tac/v1/test"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
 func TestUpdatePolicyCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	server := test.MockServer(t)
 	defer server.Close()
 	test.SetupMockConfiguration(server.URL, tempConfigFile)
@@ -91,7 +94,63 @@ func TestUpdatePolicyCmd(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUpdateSignedPolicyCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := test.MockServer(t)
+	defer server.Close()
+	test.SetupMockConfiguration(server.URL, tempConfigFile)
+
+	signingArgs := []string{"--" + constants.SignObjectParamName, "--" + constants.PrivateKeyFileParamName, "../test/resources/signing/leaf.p12",
+		"--" + constants.PassphraseFileParamName, "../test/resources/signing/passphrase.txt"}
+	emptyPolicyFile := filepath.Join(t.TempDir(), "empty.rego")
+	assert.NoError(t, os.WriteFile(emptyPolicyFile, nil, 0600))
+	tt := []struct {
+		args        []string
+		wantErr     bool
+		description string
+	}{
+		{
+			args: append([]string{constants.UpdateCmd, constants.PolicyCmd, "-i", "e48dabc5-9608-4ff3-aaed-f25909ab9de1",
+				"-n", "Signed_Policy_SGX", "-f", "../test/resources/rego-policy.txt"}, signingArgs...),
+			description: "Test Update Policy signed by the tenant",
+		},
+		{
+			args: append([]string{constants.UpdateCmd, constants.PolicyCmd, "-i", "e48dabc5-9608-4ff3-aaed-f25909ab9de1",
+				"-n", test.TamperedPolicyName, "-f", "../test/resources/rego-policy.txt"}, signingArgs...),
+			wantErr:     true,
+			description: "Test Update Policy with policy hash mismatch",
+		},
+		{
+			args: append([]string{constants.UpdateCmd, constants.PolicyCmd, "-i", "e48dabc5-9608-4ff3-aaed-f25909ab9de1",
+				"-n", "Signed_Policy_SGX"}, signingArgs...),
+			wantErr:     true,
+			description: "Test Update Policy signing without policy file",
+		},
+		{
+			args: append([]string{constants.UpdateCmd, constants.PolicyCmd, "-i", "e48dabc5-9608-4ff3-aaed-f25909ab9de1",
+				"-n", "Signed_Policy_SGX", "-f", emptyPolicyFile}, signingArgs...),
+			wantErr:     true,
+			description: "Test Update Policy signing an empty policy file",
+		},
+	}
+
+	updateCmd.AddCommand(updatePolicyCmd)
+	tenantCmd.AddCommand(updateCmd)
+
+	for _, tc := range tt {
+		resetFlagsForTests(t, updatePolicyCmd)
+		_, err := execute(t, tenantCmd, tc.args)
+		if tc.wantErr {
+			assert.Error(t, err, tc.description)
+		} else {
+			assert.NoError(t, err, tc.description)
+		}
+	}
+	resetFlagsForTests(t, updatePolicyCmd)
+}
+
 func TestUpdatePolicyCommandWithInvalidUrl(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	test.SetupMockConfiguration("invalid url", tempConfigFile)
 	load, err := config.LoadConfiguration()
 	assert.NoError(t, err)
@@ -132,3 +191,37 @@ func TestUpdatePolicyCommandWithInvalidUrl(t *testing.T) {
 	}
 	viper.Set("trustauthority-url", load.TrustAuthorityBaseUrl)
 }
+
+func TestUpdateReferencedPolicyCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tenant := exportTenantForTests()
+	server := test.TenantMockServer(t, tenant)
+	defer server.Close()
+	useServerForTests(t, server.URL)
+
+	policyFile := filepath.Join(t.TempDir(), "policy.rego")
+	assert.NoError(t, os.WriteFile(policyFile, []byte("default allow = false\n"), 0600))
+	updateCmd.AddCommand(updatePolicyCmd)
+	tenantCmd.AddCommand(updateCmd)
+	update := func(input string, args ...string) error {
+		resetFlagsForTests(t, updatePolicyCmd)
+		defer resetFlagsForTests(t, updatePolicyCmd)
+		tenantCmd.SetIn(strings.NewReader(input))
+		defer tenantCmd.SetIn(nil)
+		_, err := execute(t, tenantCmd, append([]string{constants.UpdateCmd, constants.PolicyCmd, "-i",
+			tenant.Policies[0].PolicyId.String(), "-f", policyFile}, args...))
+		return err
+	}
+
+	// a policy referenced by api clients is only updated once confirmed
+	assert.Error(t, update("n\n"))
+	assert.Error(t, update(""))
+	assert.Equal(t, "default allow = true", tenant.Policies[0].Policy)
+
+	assert.NoError(t, update("y\n"))
+	assert.Equal(t, "default allow = false\n", tenant.Policies[0].Policy)
+
+	assert.NoError(t, os.WriteFile(policyFile, []byte("default allow = true\n"), 0600))
+	assert.NoError(t, update("", "--force"))
+	assert.Equal(t, "default allow = true\n", tenant.Policies[0].Policy)
+}
@@ -36,7 +36,37 @@ func init() {
 	viper.AddConfigPath(userHomeDir + constants.ConfigDir)
 }
 
+// Profile returns the configuration profile selected with the TRUSTAUTHORITY_PROFILE environment variable, the
+// default profile when it is not set
+func Profile() (string, error) {
+	profile := strings.TrimSpace(os.Getenv(constants.ProfileEnvVar))
+	if profile == "" {
+		return constants.DefaultProfile, nil
+	}
+	if err := validation.ValidateProfileName(profile); err != nil {
+		return "", err
+	}
+	return profile, nil
+}
+
+// LoadConfiguration reads the configuration of the profile selected with TRUSTAUTHORITY_PROFILE
 func LoadConfiguration() (*Configuration, error) {
+	profile, err := Profile()
+	if err != nil {
+		return &Configuration{}, err
+	}
+	if profile != constants.DefaultProfile {
+		configValues, err := LoadProfileConfiguration(profile)
+		if err != nil {
+			return &Configuration{}, err
+		}
+		return configValues, nil
+	}
+	return loadDefaultConfiguration()
+}
+
+// loadDefaultConfiguration reads the main configuration file
+func loadDefaultConfiguration() (*Configuration, error) {
 	ret := Configuration{}
 	// Find and read the config file
 	if err := viper.ReadInConfig(); err != nil {
@@ -52,6 +82,41 @@ func LoadConfiguration() (*Configuration, error) {
 	return &ret, nil
 }
 
+// LoadProfileConfiguration reads the configuration of another profile, usually another tenant, from
+// "profiles/<profile>.yaml" in the configuration directory. The default profile is the main configuration.
+func LoadProfileConfiguration(profile string) (*Configuration, error) {
+	if profile == constants.DefaultProfile {
+		return loadDefaultConfiguration()
+	}
+	if err := validation.ValidateProfileName(profile); err != nil {
+		return nil, err
+	}
+	userHomeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching user home directory path")
+	}
+
+	profileViper := viper.New()
+	profileViper.SetConfigFile(filepath.Join(filepath.Clean(userHomeDir+constants.ProfileConfigDir), profile+"."+constants.ConfigFileExtension))
+	profileViper.SetDefault(constants.HttpClientTimeout, constants.DefaultHttpClientTimeout)
+	if err = profileViper.ReadInConfig(); err != nil {
+		return nil, errors.Wrapf(err, "Failed to load the configuration of profile %s", profile)
+	}
+	ret := Configuration{}
+	if err = profileViper.Unmarshal(&ret); err != nil {
+		return nil, errors.Wrapf(err, "Failed to unmarshal the configuration of profile %s", profile)
+	}
+	if err = validation.ValidateURL(ret.TrustAuthorityBaseUrl); err != nil {
+		return nil, errors.Wrapf(err, "Invalid Trust Authority URL in the configuration of profile %s", profile)
+	}
+	if err = validation.ValidateTrustAuthorityAPIKey(ret.TrustAuthorityApiKey); err != nil {
+		if err = validation.ValidateTrustAuthorityJwt(ret.TrustAuthorityApiKey); err != nil {
+			return nil, errors.Errorf("Invalid Trust Authority Api key in the configuration of profile %s", profile)
+		}
+	}
+	return &ret, nil
+}
+
 func SetupConfig(envFilePath string) error {
 	if envFilePath == "" {
 		return errors.New("EnvFilePath needs to be provided in configuration")
@@ -17,10 +17,16 @@ const (
 	ConfigFileName        = "config"
 	ConfigFileExtension   = "yaml"
 	LogFilePath           = LogDir + "trustauthorityctl.log"
+	PolicyHistoryDir      = ConfigDir + "history/"
+	PolicyRegistryDir     = ConfigDir + "registry/"
+	ProfileConfigDir      = ConfigDir + "profiles/"
 	DefaultFilePermission = 0640
+	DefaultDirPermission  = 0750
 	MaxPolicyFileSize     = 20480
-	LinuxFilePathSize     = 4096
-	ExplicitCLIName       = "Intel Trust Authority CLI"
+	// MaxKeyDerivationIterations bounds the iteration count of the key derivations of encrypted private keys
+	MaxKeyDerivationIterations = 2000000
+	LinuxFilePathSize          = 4096
+	ExplicitCLIName            = "Intel Trust Authority CLI"
 )
 
 // Command and parameter names
@@ -51,15 +57,121 @@ const (
 	EnvFileParamName             = "env-file"
 	AlgorithmParamName           = "algorithm"
 	DisableNotificationParamName = "disable-notification"
+	PolicyDirParamName           = "dir"
+	PolicyNamePatternParamName   = "name-pattern"
+	CaFileParamName              = "ca-file"
+	AllowedAlgorithmsParamName   = "algorithms"
+	PassphraseFileParamName      = "passphrase-file"
+	PKCS11URIParamName           = "pkcs11-uri"
+	SignCommandParamName         = "sign-command"
+	KMSUrlParamName              = "kms-url"
+	KMSKeyIdParamName            = "kms-key-id"
+	AllParamName                 = "all"
+	TaCertFileParamName          = "ta-cert-file"
+	JwksUrlParamName             = "jwks-url"
+	TemplateParamName            = "template"
+	OutFileParamName             = "out"
+	CreateParamName              = "create"
+	MrEnclaveParamName           = "mrenclave"
+	MrSignerParamName            = "mrsigner"
+	IsvProdIdParamName           = "isvprodid"
+	IsvSvnParamName              = "isvsvn"
+	MrTdParamName                = "mrtd"
+	RtmrParamName                = "rtmr"
+	MeasurementParamName         = "measurement"
+	MinTcbStatusParamName        = "min-tcb-status"
+	TokenParamName               = "token"
+	ClaimsParamName              = "claims"
+	RollbackToParamName          = "to"
+	DryRunParamName              = "dry-run"
+	ForceParamName               = "force"
+	YesParamName                 = "yes"
+	StdoutOnlyParamName          = "stdout-only"
+	NoEchoParamName              = "no-echo"
+	IssuedAtParamName            = "issued-at"
+	NotBeforeParamName           = "not-before"
+	ExpiresInParamName           = "expires-in"
+	IssuerParamName              = "issuer"
+	KeyIdParamName               = "key-id"
+	JwtIdParamName               = "jwt-id"
+	DeterministicParamName       = "deterministic"
+	KeyTypeParamName             = "type"
+	OutKeyParamName              = "out-key"
+	OutCertParamName             = "out-cert"
+	OutCsrParamName              = "out-csr"
+	SubjectParamName             = "subject"
+	DaysParamName                = "days"
+	LabelParamName               = "label"
+	RemoveLabelParamName         = "remove-label"
+	DescriptionParamName         = "description"
+	SelectorParamName            = "selector"
+	MessageParamName             = "message"
+	UndoParamName                = "undo"
+	KeyOutputParamName           = "key-output"
+	OldStatusParamName           = "old-status"
+	ActivationDelayParamName     = "activation-delay"
+	ActivationTimeoutParamName   = "activation-timeout"
+	PollIntervalParamName        = "poll-interval"
+	AttestationUrlParamName      = "attestation-url"
+	ShowKeysParamName            = "show-keys"
+	ToServiceParamName           = "to-service"
+	ToProfileParamName           = "to-profile"
+	CopyMissingParamName         = "copy-missing"
+	ManifestParamName            = "filename"
+	PruneParamName               = "prune"
+	BaselineParamName            = "baseline"
+	FormatParamName              = "format"
+	InputFileParamName           = "filename"
+	ConcurrencyParamName         = "concurrency"
+	RateLimitParamName           = "rate-limit"
+	ByPolicyParamName            = "by-policy"
+	ByTagParamName               = "by-tag"
+	ByProductParamName           = "by-product"
+	ConfirmParamName             = "confirm"
+	UndoFileParamName            = "undo-file"
 
-	RootCmd        = "trustauthorityctl"
-	CreateCmd      = "create"
-	ListCmd        = "list"
-	DeleteCmd      = "delete"
-	UpdateCmd      = "update"
-	UninstallCmd   = "uninstall"
-	VersionCmd     = "version"
-	SetupConfigCmd = "config"
+	RootCmd         = "trustauthorityctl"
+	CreateCmd       = "create"
+	ListCmd         = "list"
+	DeleteCmd       = "delete"
+	UpdateCmd       = "update"
+	UninstallCmd    = "uninstall"
+	VersionCmd      = "version"
+	SetupConfigCmd  = "config"
+	PullCmd         = "pull"
+	DecodeCmd       = "decode"
+	VerifyCmd       = "verify"
+	NewCmd          = "new"
+	FromTokenCmd    = "from-token"
+	HistoryCmd      = "history"
+	RollbackCmd     = "rollback"
+	UsageCmd        = "usage"
+	BundleCmd       = "bundle"
+	LintCmd         = "lint"
+	TestCmd         = "test"
+	BuildCmd        = "build"
+	KeysCmd         = "keys"
+	GenerateCmd     = "generate"
+	InspectCmd      = "inspect"
+	AnnotateCmd     = "annotate"
+	DeprecateCmd    = "deprecate"
+	RotateCmd       = "rotate"
+	GetCmd          = "get"
+	AttachPolicyCmd = "attach-policy"
+	DetachPolicyCmd = "detach-policy"
+	SetTagCmd       = "set-tag"
+	UnsetTagCmd     = "unset-tag"
+	RenameCmd       = "rename"
+	CloneCmd        = "clone"
+	ApplyCmd        = "apply"
+	DiffCmd         = "diff"
+	ExportCmd       = "export"
+	ImportCmd       = "import"
+	DriftCmd        = "drift"
+	BulkCreateCmd   = "bulk-create"
+	BulkUpdateCmd   = "bulk-update"
+	RevokeCmd       = "revoke"
+	RestoreCmd      = "restore"
 )
 
 // Resource names
@@ -75,6 +187,7 @@ const (
 	TagCmd            = "tag"
 	RoleCmd           = "role"
 	TenantSettingsCmd = "tenant-settings"
+	TenantCmd         = "tenant"
 )
 
 const (
@@ -98,24 +211,172 @@ const (
 	RS256       = "RS256"
 	PS256       = "PS256"
 	RS384       = "RS384"
+	RS512       = "RS512"
+	PS512       = "PS512"
+	ES256       = "ES256"
+	ES384       = "ES384"
+	ES512       = "ES512"
+	EdDSA       = "EdDSA"
 	PublicKey   = "PUBLIC KEY"
 	CertType    = "CERTIFICATE"
 	HashSize256 = "256"
 	HashSize384 = "384"
+	HashSize512 = "512"
 	NonAlg      = "None"
 	KeyHeader   = "x5c"
+	KidHeader   = "kid"
 	TimeLayout  = "20060102150405"
+
+	RSAPrivateKeyType   = "RSA PRIVATE KEY"
+	ECPrivateKeyType    = "EC PRIVATE KEY"
+	PKCS8PrivateKeyType = "PRIVATE KEY"
+
+	EncryptedPrivateKeyType = "ENCRYPTED PRIVATE KEY"
+	CertificateRequestType  = "CERTIFICATE REQUEST"
+	PKCS12FileExtension     = ".p12"
+	PFXFileExtension        = ".pfx"
+
+	KeyTypeRSA2048            = "rsa2048"
+	KeyTypeRSA3072            = "rsa3072"
+	KeyTypeRSA4096            = "rsa4096"
+	KeyTypeECP256             = "ec-p256"
+	KeyTypeECP384             = "ec-p384"
+	KeyTypeECP521             = "ec-p521"
+	KeyTypeEd25519            = "ed25519"
+	DefaultKeyType            = KeyTypeRSA3072
+	DefaultCertificateDays    = 365
+	PrivateKeyFilePermission  = 0600
+	CertificateFilePermission = 0644
+
+	PKCS11URIScheme        = "pkcs11:"
+	DefaultPKCS11Tool      = "pkcs11-tool"
+	DefaultSignerTimeout   = 60
+	PKCS11ToolEnvVar       = "TRUSTAUTHORITY_PKCS11_TOOL"
+	PKCS11PinEnvVar        = "TRUSTAUTHORITY_PKCS11_PIN"
+	KMSTokenEnvVar         = "TRUSTAUTHORITY_KMS_TOKEN"
+	SigningAlgorithmEnvVar = "TRUSTAUTHORITY_SIGNING_ALGORITHM"
+	SourceDateEpochEnvVar  = "SOURCE_DATE_EPOCH"
+	TimestampNow           = "now"
+
+	PolicyFileExtension         = ".rego"
+	PolicyMetadataFileExtension = ".json"
+	PolicyHashVerified          = "verified"
+	PolicyHashMismatch          = "mismatch"
+	PolicyHashUnverifiable      = "unverifiable"
+	PolicyStatusVerified        = "verified"
+	PolicyStatusTampered        = "tampered"
+	PolicyStatusUnsigned        = "unsigned"
+	PolicyStatusMismatched      = "mismatched"
+	DefaultPolicyType           = "Appraisal policy"
+
+	ProfileEnvVar            = "TRUSTAUTHORITY_PROFILE"
+	DefaultProfile           = "default"
+	HistoryOperationCreate   = "create"
+	HistoryOperationUpdate   = "update"
+	HistoryOperationPull     = "pull"
+	HistoryOperationRollback = "rollback"
+	HistoryOperationPrevious = "previous"
+
+	BundleManifestFileName   = "manifest.yaml"
+	DefaultBundleTestPackage = "policy"
+	OpaToolEnvVar            = "TRUSTAUTHORITY_OPA"
+	DefaultOpaTool           = "opa"
+	DefaultOpaTestTimeout    = 120
+	MaxDescriptionLength     = 256
+
+	MaxApiClientNameLength        = 64
+	ApiKeyFilePermission          = 0600
+	DefaultActivationDelay        = 120 // documented delay for attestation API key changes to take effect
+	DefaultActivationTimeout      = 300
+	DefaultActivationPollInterval = 5
+
+	KeySinkFile                     = "file"
+	KeySinkDotenv                   = "dotenv"
+	KeySinkKubernetesSecret         = "k8s-secret"
+	KeySinkExec                     = "exec"
+	DefaultKeySinkTimeout           = 60
+	MaskedKeyPrefixLength           = 4
+	DotenvKeyVariable               = "TRUSTAUTHORITY_API_KEY"
+	ApiClientIdEnvVar               = "TRUSTAUTHORITY_API_CLIENT_ID"
+	ApiClientNameEnvVar             = "TRUSTAUTHORITY_API_CLIENT_NAME"
+	KubernetesSecretNamePrefix      = "trustauthority-"
+	KubernetesSecretKeyName         = "api-key"
+	KubernetesApiClientIdAnnotation = "trustauthority.intel.com/api-client-id"
+
+	ExitCodeDrift    = 3
+	ExitCodeNotFound = 4
+
+	MaxApiClientEditAttempts = 3
+
+	ManifestApiVersion       = "trustauthority.intel.com/v1"
+	ManifestKindTag          = "Tag"
+	ManifestKindPolicy       = "Policy"
+	ManifestKindUser         = "User"
+	ManifestKindSettings     = "TenantSettings"
+	ManifestKindApiClient    = "ApiClient"
+	ManifestFileExtension    = ".yaml"
+	ManifestAltFileExtension = ".yml"
+	PlanOperationCreate      = "create"
+	PlanOperationUpdate      = "update"
+	PlanOperationDelete      = "delete"
+
+	TenantArchiveFormatVersion  = 1
+	TenantArchiveMetadataFile   = "metadata.json"
+	TenantArchiveServicesFile   = "services.json"
+	TenantArchiveOffersFile     = "service-offers.json"
+	TenantArchiveApiClientsFile = "api-clients.json"
+	TenantArchivePoliciesFile   = "policies.json"
+	TenantArchiveTagsFile       = "tags.json"
+	TenantArchiveUsersFile      = "users.json"
+	TenantArchiveSettingsFile   = "settings.json"
+	TenantArchiveChecksumPrefix = "sha384:"
+	TenantArchiveFilePermission = 0600
+	MaxTenantArchiveSize        = 64 << 20
+	ImportActionCreate          = "create"
+	ImportActionUpdate          = "update"
+	ImportActionExists          = "exists"
+	ImportActionSkip            = "skip"
+
+	DriftFormatText    = "text"
+	DriftFormatSarif   = "sarif"
+	DriftFormatJunit   = "junit"
+	DriftChangeAdded   = "added"
+	DriftChangeRemoved = "removed"
+	DriftChangeChanged = "changed"
+	DriftToolName      = "trustauthorityctl"
+	SarifVersion       = "2.1.0"
+	SarifSchema        = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	DefaultBulkConcurrency   = 4
+	MaxBulkConcurrency       = 16
+	DefaultBulkRateLimit     = 5 // requests per second
+	BulkCsvFileExtension     = ".csv"
+	BulkListSeparator        = ";"
+	KeyOutputNamePlaceholder = "{name}"
+	BulkActionCreated        = "created"
+	BulkActionExists         = "exists"
+	BulkActionUpdated        = "updated"
+	BulkActionUnchanged      = "unchanged"
+	BulkActionMatched        = "matched"
+	BulkActionFailed         = "failed"
+	BulkActionRevoked        = "revoked"
+	BulkActionRestored       = "restored"
+	RevokeConfirmation       = "revoke"
+	BulkUpdateConfirmation   = "update"
+	RevokeUndoFileFormat     = "apiclient-revoke-%s.json"
+	RevokeUndoFileTimeFormat = "20060102T150405Z"
 )
 
 // HTTP constants
 const (
-	HTTPMediaTypeJson        = "application/json"
-	HTTPHeaderKeyContentType = "Content-Type"
-	HTTPHeaderKeyAccept      = "Accept"
-	HTTPHeaderKeyApiKey      = "x-api-key"
-	HTTPHeaderKeyRequestId   = "request-id"
-	HTTPHeaderKeyTraceId     = "trace-id"
-	HTTPScheme               = "https"
+	HTTPMediaTypeJson          = "application/json"
+	HTTPHeaderKeyContentType   = "Content-Type"
+	HTTPHeaderKeyAccept        = "Accept"
+	HTTPHeaderKeyApiKey        = "x-api-key"
+	HTTPHeaderKeyAuthorization = "Authorization"
+	HTTPHeaderKeyRequestId     = "request-id"
+	HTTPHeaderKeyTraceId       = "trace-id"
+	HTTPScheme                 = "https"
 )
 
 // API endpoint
@@ -132,6 +393,7 @@ const (
 	PlanApiEndpoint           = "/plans"
 	TenantsApiEndpoint        = "/tenants"
 	SettingsEndpoint          = "/settings"
+	AttestationNonceEndpoint  = "/appraisal/v1/nonce"
 )
 
 type ProductType string
@@ -141,6 +403,13 @@ const (
 	Management  ProductType = "management"
 )
 
+// SigningAlgorithms lists the algorithms supported for signing policy JWTs
+var SigningAlgorithms = []string{RS256, PS256, RS384, PS384, RS512, PS512, ES256, ES384, ES512, EdDSA}
+
+// PerTokenClaims lists the attestation token claims which differ for every token, a policy matching them would only
+// match the token it was generated from
+var PerTokenClaims = []string{"iat", "exp", "nbf", "jti", "ver", "verifier_nonce", "nonce", "attester_nonce"}
+
 var (
 	ErrorInvalidSize        = errors.New("Policy File size is greater than allowed size")
 	ServiceUnavailableError = `service unavailable`
@@ -0,0 +1,203 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Metadata describes a tenant archive. Checksums holds the sha384 checksum of every other file of the archive, which
+// is verified when the archive is read.
+type Metadata struct {
+	FormatVersion int               `json:"format_version"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Source        string            `json:"source"`
+	Checksums     map[string]string `json:"checksums"`
+}
+
+// Tenant is the configuration of a tenant held by an archive. Api clients are stored without their attestation API
+// keys.
+type Tenant struct {
+	Services      []models.ServiceDetail          `json:"services"`
+	ServiceOffers []models.ServiceOffer           `json:"service_offers"`
+	ApiClients    []models.ApiClientDetail        `json:"api_clients"`
+	Policies      []models.PolicyResponse         `json:"policies"`
+	Tags          []models.Tag                    `json:"tags"`
+	Users         []models.TenantUser             `json:"users"`
+	Settings      *models.AttestationFailureEmail `json:"settings"`
+}
+
+// files maps the files of an archive to the parts of the tenant they hold
+func (t *Tenant) files() map[string]interface{} {
+	return map[string]interface{}{
+		constants.TenantArchiveServicesFile:   &t.Services,
+		constants.TenantArchiveOffersFile:     &t.ServiceOffers,
+		constants.TenantArchiveApiClientsFile: &t.ApiClients,
+		constants.TenantArchivePoliciesFile:   &t.Policies,
+		constants.TenantArchiveTagsFile:       &t.Tags,
+		constants.TenantArchiveUsersFile:      &t.Users,
+		constants.TenantArchiveSettingsFile:   &t.Settings,
+	}
+}
+
+// Write writes the tenant to a new gzipped tarball only readable by the current user, and returns the metadata
+// written along with the sha384 checksum of the archive. An existing archive is only replaced when overwrite is set.
+// The attestation API keys of the api clients are left out.
+func Write(archivePath, source string, tenant *Tenant, overwrite bool) (*Metadata, string, error) {
+	exported := *tenant
+	exported.ApiClients = nil
+	for _, apiClient := range tenant.ApiClients {
+		apiClient.Keys = nil
+		exported.ApiClients = append(exported.ApiClients, apiClient)
+	}
+	exported.Users = nil
+	for _, user := range tenant.Users {
+		user.Token = ""
+		exported.Users = append(exported.Users, user)
+	}
+
+	metadata := &Metadata{
+		FormatVersion: constants.TenantArchiveFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		Source:        source,
+		Checksums:     map[string]string{},
+	}
+	contents := map[string][]byte{}
+	var names []string
+	for name, part := range exported.files() {
+		content, err := json.MarshalIndent(part, "", "  ")
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "Error marshalling %s", name)
+		}
+		contents[name] = content
+		metadata.Checksums[name] = checksum(content)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metadataBytes, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error marshalling the archive metadata")
+	}
+
+	var archive bytes.Buffer
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	write := func(name string, content []byte) error {
+		header := &tar.Header{Name: name, Mode: constants.TenantArchiveFilePermission, Size: int64(len(content)),
+			ModTime: metadata.CreatedAt, Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tarWriter.Write(content)
+		return err
+	}
+	// the metadata comes first so that the format version is known before the rest of the archive is read
+	if err = write(constants.TenantArchiveMetadataFile, metadataBytes); err != nil {
+		return nil, "", errors.Wrap(err, "Error writing the archive")
+	}
+	for _, name := range names {
+		if err = write(name, contents[name]); err != nil {
+			return nil, "", errors.Wrap(err, "Error writing the archive")
+		}
+	}
+	if err = tarWriter.Close(); err != nil {
+		return nil, "", errors.Wrap(err, "Error writing the archive")
+	}
+	if err = gzipWriter.Close(); err != nil {
+		return nil, "", errors.Wrap(err, "Error writing the archive")
+	}
+
+	if err = utils.WriteNewFile(archivePath, archive.Bytes(), constants.TenantArchiveFilePermission, overwrite); err != nil {
+		return nil, "", errors.Wrap(err, "Error writing the archive")
+	}
+	return metadata, checksum(archive.Bytes()), nil
+}
+
+// Read reads a tenant archive, checking its format version and the checksums of its files
+func Read(archivePath string) (*Metadata, *Tenant, error) {
+	f, err := os.Open(filepath.Clean(archivePath))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Error opening the tenant archive")
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Error decompressing the tenant archive")
+	}
+	defer gzipReader.Close()
+
+	contents := map[string][]byte{}
+	size := 0
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Error reading the tenant archive")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if size += int(header.Size); header.Size < 0 || size > constants.MaxTenantArchiveSize {
+			return nil, nil, errors.Errorf("Tenant archive is larger than %d bytes", constants.MaxTenantArchiveSize)
+		}
+		content, err := io.ReadAll(io.LimitReader(tarReader, header.Size))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Error reading %s from the tenant archive", header.Name)
+		}
+		contents[header.Name] = content
+	}
+
+	metadataBytes, ok := contents[constants.TenantArchiveMetadataFile]
+	if !ok {
+		return nil, nil, errors.Errorf("Invalid tenant archive, %s is missing", constants.TenantArchiveMetadataFile)
+	}
+	var metadata Metadata
+	if err = json.Unmarshal(metadataBytes, &metadata); err != nil {
+		return nil, nil, errors.Wrap(err, "Error unmarshalling the archive metadata")
+	}
+	if metadata.FormatVersion != constants.TenantArchiveFormatVersion {
+		return nil, nil, errors.Errorf("Unsupported tenant archive format version %d, only version %d is supported",
+			metadata.FormatVersion, constants.TenantArchiveFormatVersion)
+	}
+
+	tenant := &Tenant{}
+	for name, part := range tenant.files() {
+		content, ok := contents[name]
+		if !ok {
+			return nil, nil, errors.Errorf("Invalid tenant archive, %s is missing", name)
+		}
+		if metadata.Checksums[name] != checksum(content) {
+			return nil, nil, errors.Errorf("Checksum mismatch for %s, the tenant archive is corrupted or was altered", name)
+		}
+		if err = json.Unmarshal(content, part); err != nil {
+			return nil, nil, errors.Wrapf(err, "Error unmarshalling %s", name)
+		}
+	}
+	return &metadata, tenant, nil
+}
+
+func checksum(content []byte) string {
+	hash := sha512.Sum384(content)
+	return constants.TenantArchiveChecksumPrefix + hex.EncodeToString(hash[:])
+}
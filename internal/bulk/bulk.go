@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/validation"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CSV columns of the api clients to be created
+const (
+	columnName     = "name"
+	columnService  = "service"
+	columnProduct  = "product"
+	columnStatus   = "status"
+	columnPolicies = "policies"
+	columnTags     = "tags"
+)
+
+// Entry is an api client to be created. The service and product are referenced by id or name, the policies by id or
+// name, and the tags as key:value pairs. Row is the position of the api client in the input, starting at 1.
+type Entry struct {
+	Row      int      `yaml:"-"`
+	Name     string   `yaml:"name"`
+	Service  string   `yaml:"service"`
+	Product  string   `yaml:"product"`
+	Status   string   `yaml:"status,omitempty"`
+	Policies []string `yaml:"policies,omitempty"`
+	Tags     []string `yaml:"tags,omitempty"`
+}
+
+// Load reads the api clients to be created from a CSV file with a header row, or from a YAML file holding a list of
+// api clients. The policies and tags of a CSV row are separated by semicolons.
+func Load(file string) ([]Entry, error) {
+	path, err := validation.ValidatePath(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid input file path %s", file)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error reading input file %s", file)
+	}
+
+	var entries []Entry
+	if strings.EqualFold(filepath.Ext(path), constants.BulkCsvFileExtension) {
+		entries, err = loadCsv(content)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err = decoder.Decode(&entries); err == io.EOF {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error decoding input file %s", file)
+	}
+	if len(entries) == 0 {
+		return nil, errors.Errorf("No api client found in input file %s", file)
+	}
+	for i := range entries {
+		entries[i].Row = i + 1
+	}
+	return entries, nil
+}
+
+func loadCsv(content []byte) ([]Entry, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, column := range records[0] {
+		column = strings.ToLower(strings.TrimSpace(column))
+		switch column {
+		case columnName, columnService, columnProduct, columnStatus, columnPolicies, columnTags:
+		default:
+			return nil, errors.Errorf("Unknown column %q, the columns should be among %s", column,
+				strings.Join([]string{columnName, columnService, columnProduct, columnStatus, columnPolicies, columnTags}, ", "))
+		}
+		if _, ok := columns[column]; ok {
+			return nil, errors.Errorf("Column %q is repeated", column)
+		}
+		columns[column] = i
+	}
+	for _, column := range []string{columnName, columnService, columnProduct} {
+		if _, ok := columns[column]; !ok {
+			return nil, errors.Errorf("Column %q is missing", column)
+		}
+	}
+
+	var entries []Entry
+	for _, record := range records[1:] {
+		value := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		entries = append(entries, Entry{
+			Name:     value(columnName),
+			Service:  value(columnService),
+			Product:  value(columnProduct),
+			Status:   value(columnStatus),
+			Policies: splitList(value(columnPolicies)),
+			Tags:     splitList(value(columnTags)),
+		})
+	}
+	return entries, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, constants.BulkListSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate checks the api client before its references are resolved, defaulting its status to Active
+func (e *Entry) Validate() error {
+	if err := validation.ValidateApiClientName(e.Name); err != nil {
+		return err
+	}
+	if e.Service == "" {
+		return errors.New("Service cannot be empty")
+	}
+	if e.Product == "" {
+		return errors.New("Product cannot be empty")
+	}
+	if e.Status == "" {
+		e.Status = constants.ApiClientStatusActive
+	}
+	if e.Status != constants.ApiClientStatusActive && e.Status != constants.ApiClientStatusInactive {
+		return errors.Errorf("Status should be either %s or %s", constants.ApiClientStatusActive, constants.ApiClientStatusInactive)
+	}
+	for _, tag := range e.Tags {
+		if _, _, err := ParseTag(tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseTag parses and validates a tag key:value pair
+func ParseTag(tag string) (string, string, error) {
+	key, value, found := strings.Cut(tag, ":")
+	if !found || strings.Contains(value, ":") {
+		return "", "", errors.Errorf("Tag %q should be a key:value pair", tag)
+	}
+	if err := validation.ValidateTagName(key); err != nil {
+		return "", "", err
+	}
+	if err := validation.ValidateTagValue(value); err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
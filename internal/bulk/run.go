@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package bulk
+
+import (
+	"intel/tac/v1/constants"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Run runs the task for every item, from 0 to items-1, on at most concurrency goroutines, and returns once every
+// task returned. Tasks report their own outcome, so that a failing item does not stop the others.
+func Run(items, concurrency int, task func(i int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency && worker < items; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				task(i)
+			}
+		}()
+	}
+	for i := 0; i < items; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// rateLimitedTransport spaces out the requests sent, whichever goroutine sends them
+type rateLimitedTransport struct {
+	base     http.RoundTripper
+	interval time.Duration
+
+	mutex sync.Mutex
+	next  time.Time
+}
+
+// RateLimitTransport limits the requests sent through the transport to rate requests per second, the transport
+// being returned as is when rate is not positive
+func RateLimitTransport(base http.RoundTripper, rate float64) http.RoundTripper {
+	if rate <= 0 {
+		return base
+	}
+	return &rateLimitedTransport{base: base, interval: time.Duration(float64(time.Second) / rate)}
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mutex.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	wait := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	t.mutex.Unlock()
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// requestIdTransport sends every request with the same request id
+type requestIdTransport struct {
+	base      http.RoundTripper
+	requestId string
+}
+
+// RequestIdTransport sets the request id of the requests sent through the transport to requestId, the request id
+// being left out when requestId is empty. The requests of a bulk operation are sent concurrently, so that they cannot
+// carry the request id shared by the commands, which every response replaces.
+func RequestIdTransport(base http.RoundTripper, requestId string) http.RoundTripper {
+	return &requestIdTransport{base: base, requestId: requestId}
+}
+
+func (t *requestIdTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a transport should not modify the request it is given
+	req = req.Clone(req.Context())
+	if t.requestId == "" {
+		req.Header.Del(constants.HTTPHeaderKeyRequestId)
+	} else {
+		req.Header.Set(constants.HTTPHeaderKeyRequestId, t.requestId)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// responseIdsTransport passes the request and trace ids of the responses to record
+type responseIdsTransport struct {
+	base   http.RoundTripper
+	record func(requestId, traceId string)
+}
+
+// ResponseIdsTransport passes the request and trace ids of every response received through the transport to record,
+// so that the ids of the requests sent for an item of a bulk operation are kept with its result
+func ResponseIdsTransport(base http.RoundTripper, record func(requestId, traceId string)) http.RoundTripper {
+	return &responseIdsTransport{base: base, record: record}
+}
+
+func (t *responseIdsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		t.record(resp.Header.Get(constants.HTTPHeaderKeyRequestId), resp.Header.Get(constants.HTTPHeaderKeyTraceId))
+	}
+	return resp, err
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package bulk
+
+import (
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/validation"
+	"strings"
+)
+
+// selector keys and operators
+const (
+	keyName     = "name"
+	keyService  = "service"
+	keyProduct  = "product"
+	keyPolicy   = "policy"
+	keyStatus   = "status"
+	keyTag      = "tag"
+	opEquals    = "="
+	opNotEquals = "!="
+)
+
+// requirement is a single condition of an api client selector
+type requirement struct {
+	key      string
+	operator string
+	value    string
+}
+
+// Selector selects api clients. All of its requirements need to match.
+type Selector []requirement
+
+// ParseSelector parses comma separated requirements in the "key=value" and "key!=value" formats, the keys being
+// name, service (id or name), product (id or name), policy (id), status and tag, whose value is either a tag key,
+// matching the api clients with a value of the tag, or a key:value pair
+func ParseSelector(selector string) (Selector, error) {
+	var s Selector
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		var r requirement
+		switch {
+		case strings.Contains(term, opNotEquals):
+			r.key, r.value, _ = strings.Cut(term, opNotEquals)
+			r.operator = opNotEquals
+		case strings.Contains(term, opEquals):
+			r.key, r.value, _ = strings.Cut(term, opEquals)
+			r.operator = opEquals
+		default:
+			return nil, errors.Errorf("Invalid selector requirement %q, it should be in the key=value or key!=value format", term)
+		}
+		r.key = strings.TrimSpace(r.key)
+		r.value = strings.TrimSpace(r.value)
+		if r.value == "" {
+			return nil, errors.Errorf("Invalid selector requirement %q, the value cannot be empty", term)
+		}
+		switch r.key {
+		case keyName, keyService, keyProduct:
+		case keyPolicy:
+			if _, err := uuid.Parse(r.value); err != nil {
+				return nil, errors.Errorf("Invalid selector requirement %q, the policy should be referenced by id", term)
+			}
+		case keyStatus:
+			if r.value != constants.ApiClientStatusActive && r.value != constants.ApiClientStatusInactive &&
+				r.value != constants.ApiClientStatusCancelled {
+				return nil, errors.Errorf("Invalid selector requirement %q, the status should be one of %s, %s or %s", term,
+					constants.ApiClientStatusActive, constants.ApiClientStatusInactive, constants.ApiClientStatusCancelled)
+			}
+		case keyTag:
+			tagKey, tagValue, hasValue := strings.Cut(r.value, ":")
+			if err := validation.ValidateTagName(tagKey); err != nil {
+				return nil, errors.Wrapf(err, "Invalid selector requirement %q", term)
+			}
+			if hasValue {
+				if err := validation.ValidateTagValue(tagValue); err != nil {
+					return nil, errors.Wrapf(err, "Invalid selector requirement %q", term)
+				}
+			}
+		default:
+			return nil, errors.Errorf("Invalid selector requirement %q, the key should be one of %s, %s, %s, %s, %s or %s",
+				term, keyName, keyService, keyProduct, keyPolicy, keyStatus, keyTag)
+		}
+		s = append(s, r)
+	}
+	return s, nil
+}
+
+// Matches reports whether the api client, of the service, meets every requirement of the selector
+func (s Selector) Matches(apiClient *models.ApiClientDetail, service *models.Service) bool {
+	for _, r := range s {
+		var matches bool
+		switch r.key {
+		case keyName:
+			matches = apiClient.Name == r.value
+		case keyService:
+			matches = strings.EqualFold(service.ID.String(), r.value) || service.Name == r.value
+		case keyProduct:
+			matches = strings.EqualFold(apiClient.ProductId.String(), r.value) || apiClient.ProductName == r.value
+		case keyPolicy:
+			for _, policyId := range apiClient.PolicyIds {
+				if strings.EqualFold(policyId.String(), r.value) {
+					matches = true
+				}
+			}
+		case keyStatus:
+			matches = string(apiClient.Status) == r.value
+		case keyTag:
+			tagKey, tagValue, hasValue := strings.Cut(r.value, ":")
+			for _, tag := range apiClient.TagsValues {
+				if tag.Name == tagKey && (!hasValue || tag.Value == tagValue) {
+					matches = true
+				}
+			}
+		}
+		if matches != (r.operator == opEquals) {
+			return false
+		}
+	}
+	return true
+}
+
+// NeedsDetail tells whether the selector matches api clients by their tags or policies, which are only returned with
+// the detail of an api client
+func (s Selector) NeedsDetail() bool {
+	for _, r := range s {
+		if r.key == keyTag || r.key == keyPolicy {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"intel/tac/v1/constants"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxBundleSize bounds the total size of the files read from a bundle
+const maxBundleSize = 4 << 20
+
+// Manifest describes the modules of a policy bundle
+type Manifest struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	// Entry is the module holding the rules evaluated by Trust Authority
+	Entry string `yaml:"entry"`
+	// Modules are the helper modules inlined ahead of the entry module
+	Modules []string `yaml:"modules"`
+	// Tests are OPA test modules run against the flattened policy
+	Tests []string `yaml:"tests"`
+	// TestPackage is the package the flattened policy is declared in when running the tests
+	TestPackage string `yaml:"test_package"`
+}
+
+// Bundle is a manifest along with the content of the files it names
+type Bundle struct {
+	Manifest Manifest
+	// Files holds the content of the bundle files by their slash separated path relative to the bundle root
+	Files map[string]string
+}
+
+// Load reads a bundle from a directory or from a tarball, optionally gzip compressed
+func Load(bundlePath string) (*Bundle, error) {
+	fi, err := os.Stat(bundlePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading policy bundle")
+	}
+	var files map[string]string
+	if fi.IsDir() {
+		files, err = readDirectory(bundlePath)
+	} else {
+		files, err = readTarball(bundlePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, ok := files[constants.BundleManifestFileName]
+	if !ok {
+		return nil, errors.Errorf("Policy bundle does not contain a %s", constants.BundleManifestFileName)
+	}
+	b := &Bundle{Files: files}
+	if err = yaml.Unmarshal([]byte(manifest), &b.Manifest); err != nil {
+		return nil, errors.Wrap(err, "Error parsing the policy bundle manifest")
+	}
+	if b.Manifest.TestPackage == "" {
+		b.Manifest.TestPackage = constants.DefaultBundleTestPackage
+	}
+	return b, nil
+}
+
+// PolicyModules returns the paths of the helper modules followed by the entry module
+func (b *Bundle) PolicyModules() []string {
+	return append(append([]string{}, b.Manifest.Modules...), b.Manifest.Entry)
+}
+
+// readDirectory reads the manifest of the bundle directory and the files it names
+func readDirectory(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	manifestBytes, err := os.ReadFile(filepath.Join(dir, constants.BundleManifestFileName))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading the policy bundle manifest")
+	}
+	files[constants.BundleManifestFileName] = string(manifestBytes)
+
+	var manifest Manifest
+	if err = yaml.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, errors.Wrap(err, "Error parsing the policy bundle manifest")
+	}
+	size := len(manifestBytes)
+	for _, name := range append(append([]string{manifest.Entry}, manifest.Modules...), manifest.Tests...) {
+		cleaned, err := cleanPath(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := files[cleaned]; ok {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(cleaned)))
+		if err != nil {
+			// missing files are reported by the lint
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "Error reading bundle file %s", cleaned)
+		}
+		if size += len(content); size > maxBundleSize {
+			return nil, errors.Errorf("Policy bundle is larger than %d bytes", maxBundleSize)
+		}
+		files[cleaned] = string(content)
+	}
+	return files, nil
+}
+
+// readTarball reads the regular files of the tarball
+func readTarball(tarballPath string) (map[string]string, error) {
+	f, err := os.Open(filepath.Clean(tarballPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error opening policy bundle")
+	}
+	defer f.Close()
+
+	var reader io.Reader = f
+	if strings.HasSuffix(tarballPath, ".gz") || strings.HasSuffix(tarballPath, ".tgz") {
+		gzipReader, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error decompressing policy bundle")
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	files := map[string]string{}
+	size := 0
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Error reading policy bundle tarball")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, err := cleanPath(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if size += int(header.Size); header.Size < 0 || size > maxBundleSize {
+			return nil, errors.Errorf("Policy bundle is larger than %d bytes", maxBundleSize)
+		}
+		content, err := io.ReadAll(io.LimitReader(tarReader, header.Size))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading bundle file %s", name)
+		}
+		files[name] = string(content)
+	}
+	return files, nil
+}
+
+// cleanPath returns the slash separated path of a bundle file, rejecting paths that escape the bundle
+func cleanPath(name string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if name == "" || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", errors.Errorf("Invalid bundle file path %q, paths should be relative to the bundle root", name)
+	}
+	return strings.TrimPrefix(cleaned, "./"), nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package bundle
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+)
+
+const (
+	bundleHeader      = "# bundle: "
+	versionHeader     = "# bundle-version: "
+	contentHashHeader = "# content-hash: sha384:"
+)
+
+// Result is a bundle flattened into a single policy
+type Result struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	ContentHash string `json:"content_hash"`
+	Size        int    `json:"size"`
+	Policy      string `json:"-"`
+}
+
+// Flatten inlines the helper modules ahead of the entry module. Package declarations and the imports of bundle
+// packages are removed and the references to the rules of those packages are rewritten to the rule names, the
+// remaining imports are declared once at the top of the policy. The policy starts with comments naming the bundle,
+// its version and the SHA-384 hash of the flattened modules, so that an uploaded policy can be traced back to
+// the bundle it was built from.
+func Flatten(b *Bundle) (*Result, error) {
+	if b.Manifest.Entry == "" {
+		return nil, errors.New("Policy bundle manifest does not name an entry module")
+	}
+	modules := b.modules()
+	packages := map[string]string{}
+	for _, m := range modules {
+		if m.pkg != "" {
+			packages[m.pkg] = m.path
+		}
+	}
+
+	var imports []string
+	importSeen := map[string]bool{}
+	var body strings.Builder
+	for i, m := range modules {
+		var prefixes []string
+		for pkg := range packages {
+			prefixes = append(prefixes, "data."+pkg+".")
+		}
+		for _, imp := range m.imports {
+			if strings.HasPrefix(imp.path, "data.") {
+				pkg := packageOf(imp.path, packages)
+				if pkg == "" {
+					return nil, errors.Errorf("%s:%d: import %s is not provided by the bundle", m.path, imp.line, imp.path)
+				}
+				if imp.path == "data."+pkg {
+					prefixes = append(prefixes, imp.alias+".")
+				}
+				continue
+			}
+			statement := "import " + imp.path
+			if imp.alias != imp.path[strings.LastIndex(imp.path, ".")+1:] {
+				statement += " as " + imp.alias
+			}
+			if !importSeen[statement] {
+				importSeen[statement] = true
+				imports = append(imports, statement)
+			}
+		}
+		// longest prefixes first so that nested packages are rewritten before their parents
+		sort.Slice(prefixes, func(i, j int) bool {
+			if len(prefixes[i]) != len(prefixes[j]) {
+				return len(prefixes[i]) > len(prefixes[j])
+			}
+			return prefixes[i] < prefixes[j]
+		})
+
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		body.WriteString("# module: " + m.path + "\n")
+		inRawString := false
+		for _, line := range m.body {
+			body.WriteString(rewriteReferences(line, prefixes, &inRawString) + "\n")
+		}
+	}
+
+	var content strings.Builder
+	for _, statement := range imports {
+		content.WriteString(statement + "\n")
+	}
+	if len(imports) > 0 {
+		content.WriteString("\n")
+	}
+	content.WriteString(body.String())
+
+	hash := sha512.Sum384([]byte(content.String()))
+	result := &Result{
+		Name:        b.Manifest.Name,
+		Version:     b.Manifest.Version,
+		ContentHash: hex.EncodeToString(hash[:]),
+	}
+	result.Policy = fmt.Sprintf("%s%s\n%s%s\n%s%s\n\n%s", bundleHeader, result.Name, versionHeader, result.Version,
+		contentHashHeader, result.ContentHash, content.String())
+	result.Size = len(result.Policy)
+	return result, nil
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package bundle
+
+import (
+	"fmt"
+	"intel/tac/v1/constants"
+	"sort"
+	"strings"
+)
+
+// Problem is an issue found by the lint of a bundle
+type Problem struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	switch {
+	case p.File == "":
+		return p.Message
+	case p.Line == 0:
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	default:
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+	}
+}
+
+// Lint checks that the manifest is complete and that the modules can be inlined into a single policy accepted by
+// Trust Authority. An empty list is returned when no problems are found.
+func Lint(b *Bundle) []Problem {
+	var problems []Problem
+	report := func(file string, line int, format string, args ...interface{}) {
+		problems = append(problems, Problem{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	manifest := b.Manifest
+	if strings.TrimSpace(manifest.Name) == "" {
+		report(constants.BundleManifestFileName, 0, "name is required")
+	}
+	if strings.TrimSpace(manifest.Version) == "" || strings.ContainsAny(manifest.Version, " \t\n") {
+		report(constants.BundleManifestFileName, 0, "version is required and cannot contain white space")
+	}
+	if manifest.Entry == "" {
+		report(constants.BundleManifestFileName, 0, "entry is required")
+		return problems
+	}
+
+	seen := map[string]bool{}
+	for _, name := range append(b.PolicyModules(), manifest.Tests...) {
+		cleaned, err := cleanPath(name)
+		if err != nil {
+			report(constants.BundleManifestFileName, 0, "%s", err.Error())
+			continue
+		}
+		if seen[cleaned] {
+			report(constants.BundleManifestFileName, 0, "%s is listed more than once", cleaned)
+			continue
+		}
+		seen[cleaned] = true
+		if !strings.HasSuffix(cleaned, ".rego") {
+			report(constants.BundleManifestFileName, 0, "%s is not a Rego module", cleaned)
+		}
+		if _, ok := b.Files[cleaned]; !ok {
+			report(constants.BundleManifestFileName, 0, "%s is not found in the bundle", cleaned)
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	modules := b.modules()
+	packages := map[string]string{}
+	for _, m := range modules {
+		if m.pkg == "" {
+			// the entry module is uploaded as is, so it does not need a package
+			if m.path != manifest.Entry {
+				report(m.path, 0, "helper modules should declare a package")
+			}
+			continue
+		}
+		if other, ok := packages[m.pkg]; ok {
+			report(m.path, m.pkgLine, "package %s is already declared by %s", m.pkg, other)
+			continue
+		}
+		packages[m.pkg] = m.path
+	}
+
+	ruleModules := map[string]string{}
+	for _, m := range modules {
+		if m.unbalanced != 0 {
+			report(m.path, m.unbalanced, "unbalanced closing bracket")
+		} else if m.openBraces != 0 {
+			report(m.path, 0, "unclosed bracket")
+		}
+		if m.unclosedStr {
+			report(m.path, 0, "unterminated raw string")
+		}
+		for _, imp := range m.imports {
+			if !strings.HasPrefix(imp.path, "data.") {
+				continue
+			}
+			if packageOf(imp.path, packages) == "" {
+				report(m.path, imp.line, "import %s is not provided by the bundle, Trust Authority only evaluates policies against the input", imp.path)
+			}
+		}
+
+		rules := make([]string, 0, len(m.rules))
+		for rule := range m.rules {
+			rules = append(rules, rule)
+		}
+		sort.Strings(rules)
+		for _, rule := range rules {
+			if other, ok := ruleModules[rule]; ok {
+				report(m.path, m.rules[rule], "rule %s is also defined in %s, rules of the inlined modules share a single package", rule, other)
+				continue
+			}
+			ruleModules[rule] = m.path
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	result, err := Flatten(b)
+	if err != nil {
+		report("", 0, "%s", err.Error())
+	} else if result.Size > constants.MaxPolicyFileSize {
+		report("", 0, "flattened policy is %d bytes, larger than the %d bytes accepted by Trust Authority", result.Size,
+			constants.MaxPolicyFileSize)
+	}
+	return problems
+}
+
+// modules parses the helper modules followed by the entry module
+func (b *Bundle) modules() []*module {
+	var modules []*module
+	for _, name := range b.PolicyModules() {
+		cleaned, _ := cleanPath(name)
+		modules = append(modules, parseModule(cleaned, b.Files[cleaned]))
+	}
+	return modules
+}
+
+// packageOf returns the bundle package providing the data reference, the longest package matching first
+func packageOf(ref string, packages map[string]string) string {
+	longest := ""
+	for pkg := range packages {
+		if (ref == "data."+pkg || strings.HasPrefix(ref, "data."+pkg+".")) && len(pkg) > len(longest) {
+			longest = pkg
+		}
+	}
+	return longest
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package bundle
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	packageRegex = regexp.MustCompile(`^package\s+([A-Za-z_][A-Za-z0-9_.]*)\s*(#.*)?$`)
+	importRegex  = regexp.MustCompile(`^import\s+([A-Za-z_][A-Za-z0-9_.]*)(?:\s+as\s+([A-Za-z_][A-Za-z0-9_]*))?\s*(#.*)?$`)
+	ruleRegex    = regexp.MustCompile(`^(?:default\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*(?:\{|=|:=|\[|\(|if\b|contains\b|$)`)
+)
+
+// regoImport is an import statement of a module
+type regoImport struct {
+	path  string
+	alias string
+	line  int
+}
+
+// module holds the statements of a Rego module needed to lint and flatten a bundle
+type module struct {
+	path        string
+	pkg         string
+	pkgLine     int
+	imports     []regoImport
+	rules       map[string]int
+	body        []string
+	openBraces  int
+	unbalanced  int
+	unclosedStr bool
+}
+
+// parseModule splits a module into its package, imports, rule names and remaining lines. The parsing is line
+// based and relies on package, import and rule heads starting at the beginning of a line, as formatted by opa fmt.
+func parseModule(path, content string) *module {
+	m := &module{path: path, rules: map[string]int{}}
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if match := packageRegex.FindStringSubmatch(line); match != nil && m.pkg == "" {
+			m.pkg = match[1]
+			m.pkgLine = i + 1
+			continue
+		}
+		if match := importRegex.FindStringSubmatch(line); match != nil {
+			alias := match[2]
+			if alias == "" {
+				alias = match[1][strings.LastIndex(match[1], ".")+1:]
+			}
+			m.imports = append(m.imports, regoImport{path: match[1], alias: alias, line: i + 1})
+			continue
+		}
+		if m.openBraces == 0 {
+			if match := ruleRegex.FindStringSubmatch(line); match != nil && !isKeyword(match[1]) {
+				if _, ok := m.rules[match[1]]; !ok {
+					m.rules[match[1]] = i + 1
+				}
+			}
+		}
+		m.countBraces(line, i+1)
+		m.body = append(m.body, line)
+	}
+	// trailing blank lines are dropped so that modules are separated by a single blank line when flattened
+	for len(m.body) > 0 && strings.TrimSpace(m.body[len(m.body)-1]) == "" {
+		m.body = m.body[:len(m.body)-1]
+	}
+	for len(m.body) > 0 && strings.TrimSpace(m.body[0]) == "" {
+		m.body = m.body[1:]
+	}
+	return m
+}
+
+// countBraces tracks the nesting of braces outside of strings and comments
+func (m *module) countBraces(line string, lineNumber int) {
+	forEachCode(line, func(i int) {
+		switch line[i] {
+		case '{', '[', '(':
+			m.openBraces++
+		case '}', ']', ')':
+			m.openBraces--
+			if m.openBraces < 0 && m.unbalanced == 0 {
+				m.unbalanced = lineNumber
+			}
+		}
+	}, &m.unclosedStr)
+}
+
+// forEachCode calls fn with the index of every byte of the line that is not part of a string or a comment.
+// inRawString carries the state of raw strings, which may span several lines.
+func forEachCode(line string, fn func(i int), inRawString *bool) {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case *inRawString:
+			if c == '`' {
+				*inRawString = false
+			}
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '`':
+			*inRawString = true
+		case c == '#':
+			return
+		default:
+			fn(i)
+		}
+	}
+}
+
+// rewriteReferences removes the prefixes from the references of the line which are outside of strings and
+// comments, so that references to inlined modules become references to rules of the flattened policy
+func rewriteReferences(line string, prefixes []string, inRawString *bool) string {
+	if len(prefixes) == 0 {
+		// still track raw strings spanning lines
+		forEachCode(line, func(int) {}, inRawString)
+		return line
+	}
+	var sb strings.Builder
+	skipUntil := 0
+	forEachCode(line, func(i int) {
+		if i < skipUntil || (i > 0 && (isIdentifierByte(line[i-1]) || line[i-1] == '.')) {
+			return
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(line[i:], prefix) {
+				sb.WriteString(line[skipUntil:i])
+				skipUntil = i + len(prefix)
+				return
+			}
+		}
+	}, inRawString)
+	sb.WriteString(line[skipUntil:])
+	return sb.String()
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isKeyword(word string) bool {
+	switch word {
+	case "package", "import", "default", "else", "some", "every", "not", "with", "as", "true", "false", "null":
+		return true
+	}
+	return false
+}
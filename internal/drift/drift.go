@@ -0,0 +1,266 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package drift
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/backup"
+	"intel/tac/v1/internal/manifest"
+	"intel/tac/v1/models"
+	"sort"
+	"strings"
+)
+
+// Snapshot is the state of the tenant drift is detected on: policies by name, api clients by service and name, and
+// the roles of the users by email id. A snapshot taken from manifests only covers the kinds the manifests describe,
+// and the api clients of the services they reference.
+type Snapshot struct {
+	Policies   map[string]Policy
+	ApiClients map[string]ApiClient
+	Users      map[string]string
+
+	// Kinds are the kinds of resources covered by the snapshot, Services the services whose api clients are covered.
+	// Every kind and service is covered when nil.
+	Kinds    map[string]bool
+	Services map[string]bool
+}
+
+// Policy is the state of a policy, the rego policy being compared by its hash
+type Policy struct {
+	PolicyType      string
+	AttestationType string
+	Hash            string
+}
+
+// ApiClient is the state of an api client, along with the names of its policies and its tags
+type ApiClient struct {
+	Service  string
+	Status   string
+	Policies []string
+	Tags     []string
+}
+
+// Result is a resource of the baseline, or of the tenant, and how it drifted. Change is empty when the resource
+// matches the baseline.
+type Result struct {
+	Kind    string
+	Name    string
+	Change  string
+	Details []string
+}
+
+// Report is the outcome of the comparison of the tenant with a baseline
+type Report struct {
+	Baseline string
+	Results  []Result
+}
+
+// Drifted returns the results of the resources which do not match the baseline
+func (r *Report) Drifted() []Result {
+	var drifted []Result
+	for _, result := range r.Results {
+		if result.Change != "" {
+			drifted = append(drifted, result)
+		}
+	}
+	return drifted
+}
+
+// FromTenant takes a snapshot of the tenant configuration, as fetched from the tenant or read from an archive
+func FromTenant(tenant *backup.Tenant) *Snapshot {
+	snapshot := &Snapshot{Policies: map[string]Policy{}, ApiClients: map[string]ApiClient{}, Users: map[string]string{}}
+
+	policyNames := map[string]string{}
+	for _, policy := range tenant.Policies {
+		policyNames[policy.PolicyId.String()] = policy.PolicyName
+		snapshot.Policies[policy.PolicyName] = Policy{PolicyType: policy.PolicyType, AttestationType: policy.AttestationType,
+			Hash: policyHash(policy.Policy)}
+	}
+	serviceNames := map[string]string{}
+	for _, service := range tenant.Services {
+		serviceNames[service.ID.String()] = service.Name
+	}
+
+	for _, apiClient := range tenant.ApiClients {
+		service, ok := serviceNames[apiClient.ServiceId.String()]
+		if !ok {
+			service = apiClient.ServiceId.String()
+		}
+		state := ApiClient{Service: service, Status: string(apiClient.Status)}
+		for _, policyId := range apiClient.PolicyIds {
+			if name, ok := policyNames[policyId.String()]; ok {
+				state.Policies = append(state.Policies, name)
+			} else {
+				state.Policies = append(state.Policies, policyId.String())
+			}
+		}
+		state.Tags = apiClientTags(apiClient.TagsValues)
+		snapshot.ApiClients[service+"/"+apiClient.Name] = state.sorted()
+	}
+
+	for _, user := range tenant.Users {
+		snapshot.Users[strings.ToLower(user.Email)] = user.Role.Name
+	}
+	return snapshot
+}
+
+// FromManifest takes a snapshot of the desired state described by manifests. The services of the api clients are
+// referenced by id or name, and resolved to their name with the services of the tenant.
+func FromManifest(m *manifest.Manifest, services []models.ServiceDetail) *Snapshot {
+	snapshot := &Snapshot{Policies: map[string]Policy{}, ApiClients: map[string]ApiClient{}, Users: map[string]string{},
+		Kinds: map[string]bool{}, Services: map[string]bool{}}
+
+	for _, policy := range m.Policies {
+		snapshot.Kinds[constants.ManifestKindPolicy] = true
+		snapshot.Policies[policy.Name] = Policy{PolicyType: policy.PolicyType, AttestationType: policy.AttestationType,
+			Hash: policyHash(policy.Policy)}
+	}
+
+	for _, apiClient := range m.ApiClients {
+		snapshot.Kinds[constants.ManifestKindApiClient] = true
+		service := apiClient.Service
+		for _, s := range services {
+			if strings.EqualFold(s.ID.String(), service) {
+				service = s.Name
+			}
+		}
+		snapshot.Services[service] = true
+		state := ApiClient{Service: service, Status: apiClient.Status, Policies: append([]string(nil), apiClient.Policies...)}
+		for _, tag := range apiClient.Tags {
+			state.Tags = append(state.Tags, tag.Key+":"+tag.Value)
+		}
+		snapshot.ApiClients[service+"/"+apiClient.Name] = state.sorted()
+	}
+
+	for _, user := range m.Users {
+		snapshot.Kinds[constants.ManifestKindUser] = true
+		snapshot.Users[strings.ToLower(user.Email)] = user.Role
+	}
+	return snapshot
+}
+
+// Compare compares the current state of the tenant with the baseline, within what the baseline covers. Resources of
+// the tenant missing from the baseline are reported as added, resources of the baseline missing from the tenant as
+// removed.
+func Compare(baseline, current *Snapshot, baselineName string) *Report {
+	report := &Report{Baseline: baselineName}
+	covers := func(kind string) bool {
+		return baseline.Kinds == nil || baseline.Kinds[kind]
+	}
+
+	if covers(constants.ManifestKindPolicy) {
+		for _, name := range keys(baseline.Policies, current.Policies) {
+			want, inBaseline := baseline.Policies[name]
+			got, inTenant := current.Policies[name]
+			result := Result{Kind: constants.ManifestKindPolicy, Name: name, Change: change(inBaseline, inTenant)}
+			if inBaseline && inTenant {
+				result.Details = append(result.Details, differences("policyType", want.PolicyType, got.PolicyType)...)
+				result.Details = append(result.Details, differences("attestationType", want.AttestationType, got.AttestationType)...)
+				if want.Hash != got.Hash {
+					result.Details = append(result.Details, "policy: the rego policy differs from the baseline")
+				}
+			}
+			report.add(result)
+		}
+	}
+
+	if covers(constants.ManifestKindApiClient) {
+		for _, name := range keys(baseline.ApiClients, current.ApiClients) {
+			want, inBaseline := baseline.ApiClients[name]
+			got, inTenant := current.ApiClients[name]
+			if !inBaseline && baseline.Services != nil && !baseline.Services[got.Service] {
+				continue
+			}
+			result := Result{Kind: constants.ManifestKindApiClient, Name: name, Change: change(inBaseline, inTenant)}
+			if inBaseline && inTenant {
+				result.Details = append(result.Details, differences("status", want.Status, got.Status)...)
+				result.Details = append(result.Details, differences("policies", strings.Join(want.Policies, ", "),
+					strings.Join(got.Policies, ", "))...)
+				result.Details = append(result.Details, differences("tags", strings.Join(want.Tags, ", "),
+					strings.Join(got.Tags, ", "))...)
+			}
+			report.add(result)
+		}
+	}
+
+	if covers(constants.ManifestKindUser) {
+		for _, email := range keys(baseline.Users, current.Users) {
+			want, inBaseline := baseline.Users[email]
+			got, inTenant := current.Users[email]
+			result := Result{Kind: constants.ManifestKindUser, Name: email, Change: change(inBaseline, inTenant)}
+			if inBaseline && inTenant {
+				result.Details = differences("role", want, got)
+			}
+			report.add(result)
+		}
+	}
+	return report
+}
+
+func (r *Report) add(result Result) {
+	if result.Change == constants.DriftChangeChanged && len(result.Details) == 0 {
+		result.Change = ""
+	}
+	r.Results = append(r.Results, result)
+}
+
+func change(inBaseline, inTenant bool) string {
+	switch {
+	case !inBaseline:
+		return constants.DriftChangeAdded
+	case !inTenant:
+		return constants.DriftChangeRemoved
+	}
+	return constants.DriftChangeChanged
+}
+
+func differences(field, want, got string) []string {
+	if want == got {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: %q in the baseline, %q in the tenant", field, want, got)}
+}
+
+// keys returns the sorted keys of both maps
+func keys[T any](a, b map[string]T) []string {
+	var names []string
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (a ApiClient) sorted() ApiClient {
+	sort.Strings(a.Policies)
+	sort.Strings(a.Tags)
+	return a
+}
+
+// apiClientTags returns the tags of an api client as key:value, predefined tags being left out as they are not
+// described by manifests
+func apiClientTags(tags []models.ApiClientTagValue) []string {
+	var values []string
+	for _, tag := range tags {
+		if !tag.Predefined {
+			values = append(values, tag.Name+":"+tag.Value)
+		}
+	}
+	return values
+}
+
+func policyHash(policy string) string {
+	hash := sha512.Sum384([]byte(strings.TrimSpace(policy)))
+	return hex.EncodeToString(hash[:])
+}
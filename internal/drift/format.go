@@ -0,0 +1,223 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package drift
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/pkg/errors"
+	"intel/tac/v1/constants"
+	"strings"
+)
+
+// Format formats the report as text, SARIF or JUnit XML. toolVersion is reported in SARIF when set.
+func (r *Report) Format(format, toolVersion string) (string, error) {
+	switch format {
+	case constants.DriftFormatText:
+		return r.text(), nil
+	case constants.DriftFormatSarif:
+		return r.sarif(toolVersion)
+	case constants.DriftFormatJunit:
+		return r.junit()
+	}
+	return "", ValidateFormat(format)
+}
+
+// ValidateFormat fails unless the format is one of the formats of the report
+func ValidateFormat(format string) error {
+	if format != constants.DriftFormatText && format != constants.DriftFormatSarif && format != constants.DriftFormatJunit {
+		return errors.Errorf("Unsupported format %q, the format should be one of %s, %s or %s", format,
+			constants.DriftFormatText, constants.DriftFormatSarif, constants.DriftFormatJunit)
+	}
+	return nil
+}
+
+// text lists the drifted resources with "+" for the resources added to the tenant, "-" for the ones removed and "~"
+// for the ones changed, followed by a summary
+func (r *Report) text() string {
+	var builder strings.Builder
+	counts := map[string]int{}
+	for _, result := range r.Drifted() {
+		counts[result.Change]++
+		symbol := "~"
+		switch result.Change {
+		case constants.DriftChangeAdded:
+			symbol = "+"
+		case constants.DriftChangeRemoved:
+			symbol = "-"
+		}
+		fmt.Fprintf(&builder, "%s %s %s\n", symbol, result.Kind, result.Name)
+		for _, detail := range result.Details {
+			fmt.Fprintf(&builder, "    %s\n", detail)
+		}
+	}
+	if len(r.Drifted()) == 0 {
+		builder.WriteString("No drift, the tenant matches the baseline\n")
+	}
+	fmt.Fprintf(&builder, "Drift: %d added, %d removed, %d changed out of %d resources compared with %s",
+		counts[constants.DriftChangeAdded], counts[constants.DriftChangeRemoved], counts[constants.DriftChangeChanged],
+		len(r.Results), r.Baseline)
+	return builder.String()
+}
+
+// message describes a drifted resource in a sentence
+func (result *Result) message() string {
+	message := fmt.Sprintf("%s %s was %s", result.Kind, result.Name, result.Change)
+	switch result.Change {
+	case constants.DriftChangeAdded:
+		message = fmt.Sprintf("%s %s is not in the baseline", result.Kind, result.Name)
+	case constants.DriftChangeRemoved:
+		message = fmt.Sprintf("%s %s of the baseline is missing from the tenant", result.Kind, result.Name)
+	}
+	if len(result.Details) > 0 {
+		message += ": " + strings.Join(result.Details, "; ")
+	}
+	return message
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	LogicalLocations []sarifLogical        `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+}
+
+type sarifArtifact struct {
+	Uri string `json:"uri"`
+}
+
+type sarifLogical struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarif reports every drifted resource as a SARIF error located in the baseline, with one rule per change
+func (r *Report) sarif(toolVersion string) (string, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{Name: constants.DriftToolName, Version: toolVersion, Rules: []sarifRule{
+			{Id: "drift/" + constants.DriftChangeAdded, ShortDescription: sarifMessage{Text: "Resource not in the baseline"}},
+			{Id: "drift/" + constants.DriftChangeRemoved, ShortDescription: sarifMessage{Text: "Resource of the baseline missing from the tenant"}},
+			{Id: "drift/" + constants.DriftChangeChanged, ShortDescription: sarifMessage{Text: "Resource differing from the baseline"}},
+		}}},
+		Results: []sarifResult{},
+	}
+	for _, result := range r.Drifted() {
+		run.Results = append(run.Results, sarifResult{
+			RuleId:  "drift/" + result.Change,
+			Level:   "error",
+			Message: sarifMessage{Text: result.message()},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifact{Uri: r.Baseline}},
+				LogicalLocations: []sarifLogical{{Name: result.Name, FullyQualifiedName: result.Kind + "/" + result.Name,
+					Kind: result.Kind}},
+			}},
+		})
+	}
+	sarifBytes, err := json.MarshalIndent(&sarifLog{Version: constants.SarifVersion, Schema: constants.SarifSchema,
+		Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(sarifBytes), nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junit reports every resource compared as a test case, in a test suite per kind, the drifted ones failing
+func (r *Report) junit() (string, error) {
+	suites := junitTestSuites{Name: constants.DriftToolName + " drift"}
+	suiteIndex := map[string]int{}
+	for _, result := range r.Results {
+		index, ok := suiteIndex[result.Kind]
+		if !ok {
+			index = len(suites.Suites)
+			suiteIndex[result.Kind] = index
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: result.Kind})
+		}
+		suite := &suites.Suites[index]
+		testCase := junitTestCase{Name: result.Name, ClassName: result.Kind}
+		if result.Change != "" {
+			testCase.Failure = &junitFailure{Message: result.message(), Type: result.Change,
+				Text: strings.Join(result.Details, "\n")}
+			suite.Failures++
+			suites.Failures++
+		}
+		suite.Tests++
+		suites.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	junitBytes, err := xml.MarshalIndent(&suites, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(junitBytes), nil
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package history
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Dir returns the directory holding the policy history of the current profile
+func Dir() (string, error) {
+	userHomeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching user home directory path")
+	}
+	profile, err := config.Profile()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Clean(userHomeDir+constants.PolicyHistoryDir), profile), nil
+}
+
+// Load returns the recorded versions of the policy, oldest first
+func Load(policyId uuid.UUID) ([]models2.PolicyHistoryEntry, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	historyBytes, err := os.ReadFile(filepath.Join(dir, policyId.String()+constants.PolicyMetadataFileExtension))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "Error reading policy history")
+	}
+	var entries []models2.PolicyHistoryEntry
+	if err = json.Unmarshal(historyBytes, &entries); err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling policy history")
+	}
+	return entries, nil
+}
+
+// Record appends the policy returned by Trust Authority to its history. Nothing is recorded when the policy body
+// and version are the same as the last recorded ones.
+func Record(policy *models.PolicyResponse, operation string) error {
+	if policy == nil || policy.PolicyId == uuid.Nil || policy.Policy == "" {
+		return nil
+	}
+	entries, err := Load(policy.PolicyId)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		if last.Policy == policy.Policy && last.Version == policy.Version {
+			return nil
+		}
+	}
+	entries = append(entries, models2.PolicyHistoryEntry{
+		PolicyId:       policy.PolicyId,
+		PolicyName:     policy.PolicyName,
+		Version:        policy.Version,
+		Policy:         policy.Policy,
+		PolicyHash:     policy.PolicyHash,
+		SignedByTenant: policy.SignedByTenant,
+		Operation:      operation,
+		UpdatedAt:      policy.UpdatedAt,
+		RecordedAt:     time.Now().UTC(),
+	})
+
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, constants.DefaultDirPermission); err != nil {
+		return errors.Wrap(err, "Error creating policy history directory")
+	}
+	historyBytes, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	// the history is replaced atomically so that an interrupted write does not lose the previous versions
+	return utils.WriteFileAtomic(filepath.Join(dir, policy.PolicyId.String()+constants.PolicyMetadataFileExtension), historyBytes,
+		constants.DefaultFilePermission)
+}
+
+// Find returns the latest recorded entry matching the version, or the latest entry recorded at or before the
+// timestamp when no version matches. Timestamps are accepted in the RFC 3339 or "20060102150405" formats.
+func Find(entries []models2.PolicyHistoryEntry, to string) (*models2.PolicyHistoryEntry, error) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Version == to {
+			return &entries[i], nil
+		}
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, to)
+	if err != nil {
+		if timestamp, err = time.Parse(constants.TimeLayout, to); err != nil {
+			return nil, errors.Errorf("No recorded version %q found, provide a recorded version or a timestamp", to)
+		}
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].RecordedAt.After(timestamp) {
+			return &entries[i], nil
+		}
+	}
+	return nil, errors.Errorf("No version of the policy was recorded at or before %s", timestamp.Format(time.RFC3339))
+}
@@ -0,0 +1,273 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package keysink
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/utils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// secretNameRegex matches the characters which are not allowed in the name of a Kubernetes Secret
+var secretNameRegex = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// Sink is a destination the attestation API keys of an API client are written to instead of the terminal
+type Sink interface {
+	// Write delivers the keys of the API client
+	Write(apiClient *models.ApiClientDetail) error
+	// Revert undoes a successful Write, as far as the sink allows it
+	Revert() error
+	// String describes the sink in the command output
+	String() string
+}
+
+// Parse returns the sink of a --key-output value, "file:<path>" or a plain path, "dotenv:<path>",
+// "k8s-secret:<path>" or "exec:<command>". The file and Kubernetes Secret sinks refuse to replace an existing file.
+func Parse(spec string) (Sink, error) {
+	kind, target, found := strings.Cut(spec, ":")
+	if !found {
+		kind, target = constants.KeySinkFile, spec
+	}
+	switch kind {
+	case constants.KeySinkFile, constants.KeySinkKubernetesSecret:
+	case constants.KeySinkDotenv:
+		if strings.TrimSpace(target) == "" {
+			return nil, errors.New("Path of the dotenv file cannot be empty")
+		}
+		return &dotenvSink{path: target}, nil
+	case constants.KeySinkExec:
+		args := strings.Fields(target)
+		if len(args) == 0 {
+			return nil, errors.New("Key output command cannot be empty")
+		}
+		return &execSink{args: args}, nil
+	default:
+		// a path containing a colon
+		kind, target = constants.KeySinkFile, spec
+	}
+
+	if strings.TrimSpace(target) == "" {
+		return nil, errors.New("Path of the key output file cannot be empty")
+	}
+	if _, err := os.Stat(target); err == nil {
+		return nil, errors.Errorf("%s already exists", target)
+	}
+	if kind == constants.KeySinkKubernetesSecret {
+		return &fileSink{path: target, render: renderSecret, description: "Kubernetes Secret manifest " + target}, nil
+	}
+	return &fileSink{path: target, render: renderKeys, description: target}, nil
+}
+
+// ParseFor returns the sink of a --key-output value shared by several API clients, its {name} placeholder being
+// replaced by the name of the API client. The placeholder is required so that the keys of an API client do not
+// replace the keys of another, unless the keys are delivered to a command, which receives the id and name of the API
+// client.
+func ParseFor(spec, name string) (Sink, error) {
+	if err := ValidateShared(spec); err != nil {
+		return nil, err
+	}
+	return Parse(strings.ReplaceAll(spec, constants.KeyOutputNamePlaceholder, name))
+}
+
+// ValidateShared checks that a --key-output value can be shared by several API clients
+func ValidateShared(spec string) error {
+	kind, _, _ := strings.Cut(spec, ":")
+	if kind != constants.KeySinkExec && !strings.Contains(spec, constants.KeyOutputNamePlaceholder) {
+		return errors.Errorf("Key output %q should contain %s, which is replaced by the name of each api client", spec,
+			constants.KeyOutputNamePlaceholder)
+	}
+	return nil
+}
+
+// Mask hides all but the first characters of a key, so that it can be told apart from other keys
+func Mask(key string) string {
+	if len(key) <= constants.MaskedKeyPrefixLength {
+		return strings.Repeat("*", len(key))
+	}
+	return key[:constants.MaskedKeyPrefixLength] + strings.Repeat("*", len(key)-constants.MaskedKeyPrefixLength)
+}
+
+// MaskKeys returns the keys masked
+func MaskKeys(keys []string) []string {
+	masked := make([]string, 0, len(keys))
+	for _, key := range keys {
+		masked = append(masked, Mask(key))
+	}
+	return masked
+}
+
+// fileSink writes the keys to a new file only readable by the current user
+type fileSink struct {
+	path        string
+	render      func(apiClient *models.ApiClientDetail) ([]byte, error)
+	description string
+}
+
+func (s *fileSink) Write(apiClient *models.ApiClientDetail) error {
+	content, err := s.render(apiClient)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Clean(s.path), os.O_WRONLY|os.O_CREATE|os.O_EXCL, constants.ApiKeyFilePermission)
+	if err != nil {
+		if os.IsExist(err) {
+			return errors.Errorf("%s already exists", s.path)
+		}
+		return errors.Wrapf(err, "Error creating %s", s.path)
+	}
+	if _, err = f.Write(content); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "Error writing %s", s.path)
+	}
+	return f.Close()
+}
+
+func (s *fileSink) Revert() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "Error removing %s", s.path)
+	}
+	return nil
+}
+
+func (s *fileSink) String() string {
+	return s.description
+}
+
+// renderKeys lists the keys, one per line
+func renderKeys(apiClient *models.ApiClientDetail) ([]byte, error) {
+	return []byte(strings.Join(apiClient.Keys, "\n") + "\n"), nil
+}
+
+// renderSecret renders a Secret manifest, named after the API client, holding the keys
+func renderSecret(apiClient *models.ApiClientDetail) ([]byte, error) {
+	name := strings.Trim(secretNameRegex.ReplaceAllString(strings.ToLower(apiClient.Name), "-"), "-.")
+	name = strings.TrimRight(constants.KubernetesSecretNamePrefix+name, "-.")
+	data := map[string]string{}
+	for i, key := range apiClient.Keys {
+		data[keyName(constants.KubernetesSecretKeyName, "-", i)] = base64.StdEncoding.EncodeToString([]byte(key))
+	}
+	secret := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "Opaque",
+		"metadata": map[string]interface{}{
+			"name": name,
+			"annotations": map[string]string{
+				constants.KubernetesApiClientIdAnnotation: apiClient.ID.String(),
+			},
+		},
+		"data": data,
+	}
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(secret); err != nil {
+		return nil, errors.Wrap(err, "Error encoding Kubernetes Secret")
+	}
+	return buffer.Bytes(), nil
+}
+
+// dotenvSink sets the keys in a dotenv file, keeping the other variables of an existing file
+type dotenvSink struct {
+	path     string
+	previous []byte
+	existed  bool
+}
+
+func (s *dotenvSink) Write(apiClient *models.ApiClientDetail) error {
+	var err error
+	s.previous, err = os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "Error reading %s", s.path)
+	}
+	s.existed = err == nil
+
+	names := map[string]bool{}
+	var variables []string
+	for i, key := range apiClient.Keys {
+		name := keyName(constants.DotenvKeyVariable, "_", i)
+		names[name] = true
+		variables = append(variables, fmt.Sprintf("%s=%q", name, key))
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(string(s.previous), "\n"), "\n") {
+		name, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), "export "), "=")
+		if line != "" && !names[strings.TrimSpace(name)] {
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, variables...)
+	return utils.WriteFileAtomic(s.path, []byte(strings.Join(lines, "\n")+"\n"), constants.ApiKeyFilePermission)
+}
+
+func (s *dotenvSink) Revert() error {
+	if !s.existed {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "Error removing %s", s.path)
+		}
+		return nil
+	}
+	return utils.WriteFileAtomic(s.path, s.previous, constants.ApiKeyFilePermission)
+}
+
+func (s *dotenvSink) String() string {
+	return "dotenv file " + s.path
+}
+
+// execSink runs a command, without a shell, which receives the keys on stdin, one per line. The id and name of the
+// API client are passed in the TRUSTAUTHORITY_API_CLIENT_ID and TRUSTAUTHORITY_API_CLIENT_NAME environment variables.
+type execSink struct {
+	args []string
+}
+
+func (s *execSink) Write(apiClient *models.ApiClientDetail) error {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultKeySinkTimeout*time.Second)
+	defer cancel()
+
+	keys, _ := renderKeys(apiClient)
+	var stderr bytes.Buffer
+	// #nosec G204 -- the key output command is provided by the user running the CLI
+	command := exec.CommandContext(ctx, s.args[0], s.args[1:]...)
+	command.Env = append(os.Environ(), constants.ApiClientIdEnvVar+"="+apiClient.ID.String(),
+		constants.ApiClientNameEnvVar+"="+apiClient.Name)
+	command.Stdin = bytes.NewReader(keys)
+	command.Stderr = &stderr
+
+	log.Debugf("Running key output command %s", s.args[0])
+	if err := command.Run(); err != nil {
+		return errors.Wrapf(err, "Key output command failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (s *execSink) Revert() error {
+	return errors.Errorf("Keys delivered to command %s cannot be reverted", s.args[0])
+}
+
+func (s *execSink) String() string {
+	return "command " + s.args[0]
+}
+
+// keyName names the first key as is and the following ones with their position
+func keyName(name, separator string, i int) string {
+	if i == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s%s%d", name, separator, i+1)
+}
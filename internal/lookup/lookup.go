@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package lookup
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// NotFoundError reports that a resource referenced by id or name does not exist, the CLI then exits with
+// ExitCodeNotFound
+type NotFoundError struct {
+	Resource  string
+	Reference string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %q not found", e.Resource, e.Reference)
+}
+
+// Matches tells whether the resource is referenced by its id, regardless of its case, or its name
+func Matches(reference string, id uuid.UUID, name string) bool {
+	return strings.EqualFold(reference, id.String()) || reference == name
+}
+
+// CheckMatches fails unless exactly one resource matches the reference
+func CheckMatches(resource, reference string, matches int) error {
+	if matches == 0 {
+		return &NotFoundError{Resource: resource, Reference: reference}
+	}
+	if matches > 1 {
+		return errors.Errorf("%d resources of type %s are named %q, the id should be used instead", matches, resource, reference)
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package manifest
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/validation"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Document is a resource of a manifest file. Several documents can be provided in a file, separated by "---".
+type Document struct {
+	ApiVersion string    `yaml:"apiVersion"`
+	Kind       string    `yaml:"kind"`
+	Metadata   Metadata  `yaml:"metadata"`
+	Spec       yaml.Node `yaml:"spec,omitempty"`
+}
+
+// Metadata identifies the resource of a document: the name of a tag, policy or api client, the email id of a user
+type Metadata struct {
+	Name string `yaml:"name"`
+}
+
+// PolicySpec describes a policy. The rego policy is provided inline or in a file relative to the manifest.
+type PolicySpec struct {
+	PolicyType      string `yaml:"policyType,omitempty"`
+	AttestationType string `yaml:"attestationType"`
+	ServiceOffer    string `yaml:"serviceOffer"`
+	Policy          string `yaml:"policy,omitempty"`
+	PolicyFile      string `yaml:"policyFile,omitempty"`
+}
+
+// ApiClientSpec describes an api client. The service and product are referenced by name or id, the policies by name.
+type ApiClientSpec struct {
+	Service  string     `yaml:"service"`
+	Product  string     `yaml:"product"`
+	Status   string     `yaml:"status,omitempty"`
+	Policies []string   `yaml:"policies,omitempty"`
+	Tags     []TagValue `yaml:"tags,omitempty"`
+}
+
+// TagValue is the value of a tag of an api client
+type TagValue struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value"`
+}
+
+// UserSpec describes the role of a user
+type UserSpec struct {
+	Role string `yaml:"role"`
+}
+
+// TenantSettingsSpec describes the settings of the tenant, an empty email id disables the attestation failure
+// notifications
+type TenantSettingsSpec struct {
+	AttestationFailureEmail string `yaml:"attestationFailureEmail"`
+}
+
+// Policy is a policy of the manifest, with its rego policy read
+type Policy struct {
+	Name string
+	PolicySpec
+}
+
+// ApiClient is an api client of the manifest
+type ApiClient struct {
+	Name string
+	ApiClientSpec
+}
+
+// User is a user of the manifest
+type User struct {
+	Email string
+	UserSpec
+}
+
+// Manifest is the desired state of the tenant, as described by the manifest files
+type Manifest struct {
+	Tags           []string
+	Policies       []Policy
+	Users          []User
+	TenantSettings *TenantSettingsSpec
+	ApiClients     []ApiClient
+}
+
+// Load reads the manifest files, and the manifest files of the directories, with a .yaml or .yml extension
+func Load(paths []string) (*Manifest, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading manifest %s", path)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		var dirFiles []string
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			extension := filepath.Ext(file)
+			if !entry.IsDir() && (extension == constants.ManifestFileExtension || extension == constants.ManifestAltFileExtension) {
+				dirFiles = append(dirFiles, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading manifest directory %s", path)
+		}
+		sort.Strings(dirFiles)
+		files = append(files, dirFiles...)
+	}
+	if len(files) == 0 {
+		return nil, errors.New("No manifest file found")
+	}
+
+	m := &Manifest{}
+	seen := map[string]string{}
+	for _, file := range files {
+		if err := m.loadFile(file, seen); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Manifest) loadFile(file string, seen map[string]string) error {
+	path, err := validation.ValidatePath(file)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid manifest path %s", file)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "Error reading manifest %s", file)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for i := 1; ; i++ {
+		var document Document
+		if err = decoder.Decode(&document); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "Error decoding document %d of manifest %s", i, file)
+		}
+		if document.Kind == "" && document.Metadata.Name == "" {
+			// empty document
+			continue
+		}
+		if err = m.add(&document, filepath.Dir(path), seen); err != nil {
+			return errors.Wrapf(err, "Invalid document %d of manifest %s", i, file)
+		}
+	}
+}
+
+func (m *Manifest) add(document *Document, dir string, seen map[string]string) error {
+	if document.ApiVersion != constants.ManifestApiVersion {
+		return errors.Errorf("apiVersion should be %s", constants.ManifestApiVersion)
+	}
+	name := strings.TrimSpace(document.Metadata.Name)
+	if name == "" && document.Kind != constants.ManifestKindSettings {
+		return errors.New("metadata.name cannot be empty")
+	}
+
+	// resources are identified by their name, along with their service for api clients
+	key := document.Kind + "/" + name
+	switch document.Kind {
+	case constants.ManifestKindTag:
+		if err := validation.ValidateTagName(name); err != nil {
+			return err
+		}
+		m.Tags = append(m.Tags, name)
+
+	case constants.ManifestKindPolicy:
+		policy := Policy{Name: name}
+		if err := decodeSpec(document, &policy.PolicySpec); err != nil {
+			return err
+		}
+		if err := policy.validate(dir); err != nil {
+			return err
+		}
+		m.Policies = append(m.Policies, policy)
+
+	case constants.ManifestKindUser:
+		user := User{Email: name}
+		if err := decodeSpec(document, &user.UserSpec); err != nil {
+			return err
+		}
+		if err := validation.ValidateEmailAddress(name); err != nil {
+			return err
+		}
+		if user.Role != constants.TenantAdminRole && user.Role != constants.UserRole {
+			return errors.Errorf("Role of user %s should be either %s or %s", name, constants.TenantAdminRole, constants.UserRole)
+		}
+		key = document.Kind + "/" + strings.ToLower(name)
+		m.Users = append(m.Users, user)
+
+	case constants.ManifestKindSettings:
+		settings := &TenantSettingsSpec{}
+		if err := decodeSpec(document, settings); err != nil {
+			return err
+		}
+		if settings.AttestationFailureEmail != "" {
+			if err := validation.ValidateEmailAddress(settings.AttestationFailureEmail); err != nil {
+				return err
+			}
+		}
+		key = document.Kind
+		m.TenantSettings = settings
+
+	case constants.ManifestKindApiClient:
+		apiClient := ApiClient{Name: name}
+		if err := decodeSpec(document, &apiClient.ApiClientSpec); err != nil {
+			return err
+		}
+		if err := apiClient.validate(); err != nil {
+			return err
+		}
+		key = document.Kind + "/" + apiClient.Service + "/" + name
+		m.ApiClients = append(m.ApiClients, apiClient)
+
+	default:
+		return errors.Errorf("Unknown kind %q, kind should be one of %s", document.Kind, strings.Join([]string{
+			constants.ManifestKindTag, constants.ManifestKindPolicy, constants.ManifestKindUser, constants.ManifestKindSettings,
+			constants.ManifestKindApiClient}, ", "))
+	}
+
+	if _, ok := seen[key]; ok {
+		return errors.Errorf("%s %s is described more than once", document.Kind, name)
+	}
+	seen[key] = name
+	return nil
+}
+
+// decodeSpec decodes the spec of the document, unknown fields are rejected
+func decodeSpec(document *Document, spec interface{}) error {
+	if document.Spec.Kind == 0 {
+		return errors.New("spec cannot be empty")
+	}
+	specBytes, err := yaml.Marshal(&document.Spec)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(specBytes))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(spec); err != nil {
+		return errors.Wrap(err, "Invalid spec")
+	}
+	return nil
+}
+
+func (p *Policy) validate(dir string) error {
+	if err := validation.ValidatePolicyName(p.Name); err != nil {
+		return err
+	}
+	if p.PolicyType == "" {
+		p.PolicyType = constants.DefaultPolicyType
+	}
+	if p.AttestationType == "" || p.ServiceOffer == "" {
+		return errors.Errorf("Attestation type and service offer of policy %s cannot be empty", p.Name)
+	}
+	if (p.Policy == "") == (p.PolicyFile == "") {
+		return errors.Errorf("Either policy or policyFile should be provided for policy %s", p.Name)
+	}
+	if p.PolicyFile != "" {
+		policyFile := p.PolicyFile
+		if !filepath.IsAbs(policyFile) {
+			policyFile = filepath.Join(dir, policyFile)
+		}
+		path, err := validation.ValidatePath(policyFile)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid policy file of policy %s", p.Name)
+		}
+		if err = validation.ValidateSize(path); err != nil {
+			return err
+		}
+		policyBytes, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "Error reading the policy file of policy %s", p.Name)
+		}
+		p.Policy = string(policyBytes)
+	}
+	if len(p.Policy) > constants.MaxPolicyFileSize {
+		return errors.Errorf("Policy %s is larger than %d bytes", p.Name, constants.MaxPolicyFileSize)
+	}
+	return nil
+}
+
+func (a *ApiClient) validate() error {
+	if err := validation.ValidateApiClientName(a.Name); err != nil {
+		return err
+	}
+	if a.Service == "" || a.Product == "" {
+		return errors.Errorf("Service and product of api client %s cannot be empty", a.Name)
+	}
+	if a.Status == "" {
+		a.Status = constants.ApiClientStatusActive
+	}
+	if a.Status != constants.ApiClientStatusActive && a.Status != constants.ApiClientStatusInactive &&
+		a.Status != constants.ApiClientStatusCancelled {
+		return errors.Errorf("Status of api client %s should be one of %s, %s or %s", a.Name, constants.ApiClientStatusActive,
+			constants.ApiClientStatusInactive, constants.ApiClientStatusCancelled)
+	}
+	for _, tag := range a.Tags {
+		if err := validation.ValidateTagName(tag.Key); err != nil {
+			return err
+		}
+		if err := validation.ValidateTagValue(tag.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
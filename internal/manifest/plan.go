@@ -0,0 +1,473 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package manifest
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/internal/lookup"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/models"
+	"sort"
+	"strings"
+)
+
+// State is the live state of the tenant the manifests are compared against. ApiClients only needs to hold the api
+// clients of the services referenced by the manifests, and Products the products of their service offers.
+type State struct {
+	Tags          []models.Tag
+	Policies      []models.PolicyResponse
+	ServiceOffers []models.ServiceOffer
+	Services      []models.Service
+	Products      map[uuid.UUID][]models.Product
+	ApiClients    []models.ApiClientDetail
+	Users         []models.TenantUser
+	Settings      *models.AttestationFailureEmail
+	// PolicyUsage holds the api clients of every service referencing each policy, it is only needed to prune policies
+	PolicyUsage map[uuid.UUID][]models2.PolicyUsage
+	// TagUsage holds the api clients of every service carrying each tag, by tag name, it is only needed to prune tags
+	TagUsage map[string][]models2.PolicyUsage
+}
+
+// Action is a change to be made to the tenant for it to match the manifests. Only the field of the kind of the
+// action is set, and Id is the id of the live resource for updates and deletes, and of the api client created once
+// the action is applied.
+type Action struct {
+	Operation string
+	Kind      string
+	Name      string
+	Changes   []string
+	Id        uuid.UUID
+
+	Tag       string
+	Policy    *PlannedPolicy
+	User      *User
+	Settings  *TenantSettingsSpec
+	ApiClient *PlannedApiClient
+}
+
+// PlannedPolicy is a policy of the manifests with its service offer resolved
+type PlannedPolicy struct {
+	Policy
+	ServiceOfferId uuid.UUID
+	// Live is the policy being updated, if any
+	Live *models.PolicyResponse
+}
+
+// PlannedApiClient is an api client of the manifests with its service and product resolved. Its policies are
+// referenced by name as they may only be created while the plan is applied.
+type PlannedApiClient struct {
+	ApiClient
+	ServiceId uuid.UUID
+	ProductId uuid.UUID
+}
+
+// Plan is the ordered list of actions making the tenant match the manifests
+type Plan struct {
+	Actions []Action
+}
+
+// kindOrder is the order in which resources are created and updated, so that api clients are created after the tags
+// and policies they reference. Resources are deleted in the reverse order.
+var kindOrder = []string{constants.ManifestKindTag, constants.ManifestKindPolicy, constants.ManifestKindUser,
+	constants.ManifestKindSettings, constants.ManifestKindApiClient}
+
+// NewPlan compares the manifests with the live state of the tenant. When prune is set, the live resources of the kinds
+// described by the manifests that are not in the manifests are deleted. Api clients are only pruned from the services
+// referenced by the manifests, and neither predefined tags nor the policies and tags referenced by the api clients of
+// the manifests are pruned. Policies and tags still referenced by the api clients of other services fail the plan.
+func NewPlan(m *Manifest, state *State, prune bool) (*Plan, error) {
+	var actions []Action
+
+	liveTags := map[string]models.Tag{}
+	for _, tag := range state.Tags {
+		liveTags[tag.Name] = tag
+	}
+	tags := map[string]bool{}
+	for _, name := range m.Tags {
+		tags[name] = true
+		if _, ok := liveTags[name]; !ok {
+			actions = append(actions, Action{Operation: constants.PlanOperationCreate, Kind: constants.ManifestKindTag,
+				Name: name, Tag: name})
+		}
+	}
+
+	livePolicies := map[string]*models.PolicyResponse{}
+	for i := range state.Policies {
+		livePolicies[state.Policies[i].PolicyName] = &state.Policies[i]
+	}
+	policies := map[string]bool{}
+	for _, policy := range m.Policies {
+		policies[policy.Name] = true
+		action, err := planPolicy(policy, livePolicies[policy.Name], state)
+		if err != nil {
+			return nil, err
+		}
+		if action != nil {
+			actions = append(actions, *action)
+		}
+	}
+
+	liveUsers := map[string]models.TenantUser{}
+	for _, user := range state.Users {
+		liveUsers[strings.ToLower(user.Email)] = user
+	}
+	users := map[string]bool{}
+	for i, user := range m.Users {
+		users[strings.ToLower(user.Email)] = true
+		live, ok := liveUsers[strings.ToLower(user.Email)]
+		if !ok {
+			actions = append(actions, Action{Operation: constants.PlanOperationCreate, Kind: constants.ManifestKindUser,
+				Name: user.Email, User: &m.Users[i]})
+		} else if live.Role.Name != user.Role {
+			actions = append(actions, Action{Operation: constants.PlanOperationUpdate, Kind: constants.ManifestKindUser,
+				Name: user.Email, Id: live.ID, User: &m.Users[i],
+				Changes: []string{fmt.Sprintf("role: %s -> %s", live.Role.Name, user.Role)}})
+		}
+	}
+
+	if m.TenantSettings != nil {
+		current := ""
+		if state.Settings != nil {
+			current = state.Settings.AttestationFailureEmail
+		}
+		if current != m.TenantSettings.AttestationFailureEmail {
+			actions = append(actions, Action{Operation: constants.PlanOperationUpdate, Kind: constants.ManifestKindSettings,
+				Name: constants.ManifestKindSettings, Settings: m.TenantSettings,
+				Changes: []string{fmt.Sprintf("attestationFailureEmail: %q -> %q", current,
+					m.TenantSettings.AttestationFailureEmail)}})
+		}
+	}
+
+	policyNames := map[uuid.UUID]string{}
+	for _, policy := range state.Policies {
+		policyNames[policy.PolicyId] = policy.PolicyName
+	}
+	apiClients := map[uuid.UUID]map[string]bool{}
+	for _, apiClient := range m.ApiClients {
+		action, err := planApiClient(apiClient, state, tags, liveTags, policies, livePolicies, policyNames)
+		if err != nil {
+			return nil, err
+		}
+		serviceId := action.ApiClient.ServiceId
+		if apiClients[serviceId] == nil {
+			apiClients[serviceId] = map[string]bool{}
+		}
+		apiClients[serviceId][apiClient.Name] = true
+		if action.Operation != "" {
+			actions = append(actions, *action)
+		}
+	}
+
+	if prune {
+		// the policies and tags referenced by the api clients of the manifests are kept
+		for _, apiClient := range m.ApiClients {
+			for _, policy := range apiClient.Policies {
+				policies[policy] = true
+			}
+			for _, tag := range apiClient.Tags {
+				tags[tag.Key] = true
+			}
+		}
+
+		// deletes are listed in the reverse order of the creations, api clients first
+		if len(m.ApiClients) > 0 {
+			serviceNames := map[uuid.UUID]string{}
+			for _, service := range state.Services {
+				serviceNames[service.ID] = service.Name
+			}
+			for _, apiClient := range state.ApiClients {
+				if names, ok := apiClients[apiClient.ServiceId]; ok && !names[apiClient.Name] {
+					actions = append(actions, Action{Operation: constants.PlanOperationDelete,
+						Kind: constants.ManifestKindApiClient, Name: apiClient.Name, Id: apiClient.ID,
+						ApiClient: &PlannedApiClient{ApiClient: ApiClient{Name: apiClient.Name, ApiClientSpec: ApiClientSpec{
+							Service: serviceNames[apiClient.ServiceId]}}, ServiceId: apiClient.ServiceId}})
+				}
+			}
+		}
+		if len(m.Users) > 0 {
+			for _, user := range state.Users {
+				if !users[strings.ToLower(user.Email)] {
+					actions = append(actions, Action{Operation: constants.PlanOperationDelete, Kind: constants.ManifestKindUser,
+						Name: user.Email, Id: user.ID})
+				}
+			}
+		}
+		if len(m.Policies) > 0 {
+			for _, policy := range state.Policies {
+				if policies[policy.PolicyName] {
+					continue
+				}
+				// the api clients of the services of the manifests are either deleted or updated to only reference
+				// policies of the manifests, the api clients of the other services keep referencing the policy
+				var references []string
+				for _, usage := range state.PolicyUsage[policy.PolicyId] {
+					if _, ok := apiClients[usage.ServiceId]; !ok {
+						references = append(references, fmt.Sprintf("%s (service %s)", usage.ApiClientName, usage.ServiceName))
+					}
+				}
+				if len(references) > 0 {
+					return nil, errors.Errorf("Policy %s is not in the manifests but cannot be pruned, it is referenced by api clients %s",
+						policy.PolicyName, strings.Join(references, ", "))
+				}
+				actions = append(actions, Action{Operation: constants.PlanOperationDelete,
+					Kind: constants.ManifestKindPolicy, Name: policy.PolicyName, Id: policy.PolicyId})
+			}
+		}
+		if len(m.Tags) > 0 {
+			for _, tag := range state.Tags {
+				if tags[tag.Name] || tag.Predefined || tag.ID == nil {
+					continue
+				}
+				// as for policies, the api clients of the other services keep carrying the tag
+				var references []string
+				for _, usage := range state.TagUsage[tag.Name] {
+					if _, ok := apiClients[usage.ServiceId]; !ok {
+						references = append(references, fmt.Sprintf("%s (service %s)", usage.ApiClientName, usage.ServiceName))
+					}
+				}
+				if len(references) > 0 {
+					return nil, errors.Errorf("Tag %s is not in the manifests but cannot be pruned, it is carried by api clients %s",
+						tag.Name, strings.Join(references, ", "))
+				}
+				actions = append(actions, Action{Operation: constants.PlanOperationDelete, Kind: constants.ManifestKindTag,
+					Name: tag.Name, Id: *tag.ID})
+			}
+		}
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actionRank(actions[i]) < actionRank(actions[j])
+	})
+	return &Plan{Actions: actions}, nil
+}
+
+// actionRank orders the creations and updates by kind, followed by the deletes in the reverse order of the kinds
+func actionRank(action Action) int {
+	for i, kind := range kindOrder {
+		if kind == action.Kind {
+			if action.Operation == constants.PlanOperationDelete {
+				return 2*len(kindOrder) - i
+			}
+			return i
+		}
+	}
+	return len(kindOrder)
+}
+
+func planPolicy(policy Policy, live *models.PolicyResponse, state *State) (*Action, error) {
+	serviceOfferId, err := resolveServiceOffer(policy.ServiceOffer, state.ServiceOffers)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid service offer of policy %s", policy.Name)
+	}
+	planned := &PlannedPolicy{Policy: policy, ServiceOfferId: serviceOfferId, Live: live}
+	if live == nil {
+		return &Action{Operation: constants.PlanOperationCreate, Kind: constants.ManifestKindPolicy, Name: policy.Name,
+			Policy: planned}, nil
+	}
+
+	// the type, attestation type and service offer of a policy cannot be updated
+	if !strings.EqualFold(live.PolicyType, policy.PolicyType) || !strings.EqualFold(live.AttestationType,
+		policy.AttestationType) || live.ServiceOfferId != serviceOfferId {
+		return nil, errors.Errorf("Policy %s cannot be updated as its policy type, attestation type or service offer "+
+			"changed, the policy should be deleted or renamed", policy.Name)
+	}
+	if strings.TrimSpace(live.Policy) == strings.TrimSpace(policy.Policy) {
+		return nil, nil
+	}
+	return &Action{Operation: constants.PlanOperationUpdate, Kind: constants.ManifestKindPolicy, Name: policy.Name,
+		Id: live.PolicyId, Policy: planned, Changes: []string{"policy"}}, nil
+}
+
+func planApiClient(apiClient ApiClient, state *State, tags map[string]bool, liveTags map[string]models.Tag,
+	policies map[string]bool, livePolicies map[string]*models.PolicyResponse, policyNames map[uuid.UUID]string) (*Action, error) {
+	service, err := resolveService(apiClient.Service, state.Services)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid service of api client %s", apiClient.Name)
+	}
+	productId, err := resolveProduct(apiClient.Product, state.Products[service.ServiceOfferId])
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid product of api client %s", apiClient.Name)
+	}
+	for _, policy := range apiClient.Policies {
+		if _, ok := livePolicies[policy]; !ok && !policies[policy] {
+			return nil, errors.Errorf("Policy %s of api client %s is neither in the manifests nor in the tenant", policy,
+				apiClient.Name)
+		}
+	}
+	for _, tag := range apiClient.Tags {
+		if _, ok := liveTags[tag.Key]; !ok && !tags[tag.Key] {
+			return nil, errors.Errorf("Tag %s of api client %s is neither in the manifests nor in the tenant", tag.Key,
+				apiClient.Name)
+		}
+	}
+
+	action := &Action{Kind: constants.ManifestKindApiClient, Name: apiClient.Name,
+		ApiClient: &PlannedApiClient{ApiClient: apiClient, ServiceId: service.ID, ProductId: productId}}
+	var live *models.ApiClientDetail
+	for i := range state.ApiClients {
+		if state.ApiClients[i].ServiceId == service.ID && state.ApiClients[i].Name == apiClient.Name {
+			live = &state.ApiClients[i]
+			break
+		}
+	}
+	if live == nil {
+		action.Operation = constants.PlanOperationCreate
+		return action, nil
+	}
+
+	action.Id = live.ID
+	if live.ProductId != productId {
+		action.Changes = append(action.Changes, fmt.Sprintf("product: %s -> %s", live.ProductName, apiClient.Product))
+	}
+	if string(live.Status) != apiClient.Status {
+		action.Changes = append(action.Changes, fmt.Sprintf("status: %s -> %s", live.Status, apiClient.Status))
+	}
+	var livePolicyNames []string
+	for _, policyId := range live.PolicyIds {
+		if name, ok := policyNames[policyId]; ok {
+			livePolicyNames = append(livePolicyNames, name)
+		} else {
+			livePolicyNames = append(livePolicyNames, policyId.String())
+		}
+	}
+	if !sameSet(livePolicyNames, apiClient.Policies) {
+		action.Changes = append(action.Changes, fmt.Sprintf("policies: [%s] -> [%s]",
+			strings.Join(sorted(livePolicyNames), ", "), strings.Join(sorted(apiClient.Policies), ", ")))
+	}
+	var liveTagValues, tagValues []string
+	for _, tag := range live.TagsValues {
+		if !tag.Predefined {
+			liveTagValues = append(liveTagValues, tag.Name+":"+tag.Value)
+		}
+	}
+	for _, tag := range apiClient.Tags {
+		tagValues = append(tagValues, tag.Key+":"+tag.Value)
+	}
+	if !sameSet(liveTagValues, tagValues) {
+		action.Changes = append(action.Changes, fmt.Sprintf("tags: [%s] -> [%s]",
+			strings.Join(sorted(liveTagValues), ", "), strings.Join(sorted(tagValues), ", ")))
+	}
+	if len(action.Changes) > 0 {
+		action.Operation = constants.PlanOperationUpdate
+	}
+	return action, nil
+}
+
+func resolveServiceOffer(reference string, serviceOffers []models.ServiceOffer) (uuid.UUID, error) {
+	var matches []uuid.UUID
+	for _, serviceOffer := range serviceOffers {
+		if lookup.Matches(reference, serviceOffer.ID, serviceOffer.Name) {
+			matches = append(matches, serviceOffer.ID)
+		}
+	}
+	if len(matches) != 1 {
+		return uuid.Nil, lookup.CheckMatches("service offer", reference, len(matches))
+	}
+	return matches[0], nil
+}
+
+func resolveService(reference string, services []models.Service) (*models.Service, error) {
+	var matches []*models.Service
+	for i := range services {
+		if lookup.Matches(reference, services[i].ID, services[i].Name) {
+			matches = append(matches, &services[i])
+		}
+	}
+	if len(matches) != 1 {
+		return nil, lookup.CheckMatches("service", reference, len(matches))
+	}
+	return matches[0], nil
+}
+
+func resolveProduct(reference string, products []models.Product) (uuid.UUID, error) {
+	var matches []uuid.UUID
+	for _, product := range products {
+		if lookup.Matches(reference, product.ID, product.Name) {
+			matches = append(matches, product.ID)
+		}
+	}
+	if len(matches) != 1 {
+		return uuid.Nil, lookup.CheckMatches("product", reference, len(matches))
+	}
+	return matches[0], nil
+}
+
+// ServiceReferences returns the services referenced by the api clients of the manifests, so that only their api
+// clients are fetched
+func (m *Manifest) ServiceReferences(services []models.Service) []models.Service {
+	var referenced []models.Service
+	for _, service := range services {
+		for _, apiClient := range m.ApiClients {
+			if lookup.Matches(apiClient.Service, service.ID, service.Name) {
+				referenced = append(referenced, service)
+				break
+			}
+		}
+	}
+	return referenced
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := map[string]int{}
+	for _, value := range a {
+		counts[value]++
+	}
+	for _, value := range b {
+		if counts[value] == 0 {
+			return false
+		}
+		counts[value]--
+	}
+	return true
+}
+
+func sorted(values []string) []string {
+	values = append([]string(nil), values...)
+	sort.Strings(values)
+	return values
+}
+
+// String formats the plan with one line per action, "+" for creates, "~" for updates and "-" for deletes, followed by
+// a summary
+func (p *Plan) String() string {
+	var builder strings.Builder
+	counts := map[string]int{}
+	for _, action := range p.Actions {
+		counts[action.Operation]++
+		symbol := "~"
+		switch action.Operation {
+		case constants.PlanOperationCreate:
+			symbol = "+"
+		case constants.PlanOperationDelete:
+			symbol = "-"
+		}
+		name := action.Name
+		// api clients are only unique within their service
+		if action.Kind == constants.ManifestKindApiClient && action.ApiClient != nil {
+			name = action.ApiClient.Service + "/" + name
+		}
+		if action.Kind == constants.ManifestKindSettings {
+			fmt.Fprintf(&builder, "%s %s\n", symbol, action.Kind)
+		} else {
+			fmt.Fprintf(&builder, "%s %s %s\n", symbol, action.Kind, name)
+		}
+		for _, change := range action.Changes {
+			fmt.Fprintf(&builder, "    %s\n", change)
+		}
+	}
+	if len(p.Actions) == 0 {
+		builder.WriteString("No changes, the tenant matches the manifests\n")
+	}
+	fmt.Fprintf(&builder, "Plan: %d to create, %d to update, %d to delete", counts[constants.PlanOperationCreate],
+		counts[constants.PlanOperationUpdate], counts[constants.PlanOperationDelete])
+	return builder.String()
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package models
+
+import (
+	"github.com/google/uuid"
+	"intel/tac/v1/models"
+	"time"
+)
+
+// ApiClientRotation reports the replacement of an API client, and so of its attestation API keys, by a new API client
+// with the same product, policies and tags
+type ApiClientRotation struct {
+	ServiceId        uuid.UUID `json:"service_id"`
+	OldApiClientId   uuid.UUID `json:"old_api_client_id"`
+	OldApiClientName string    `json:"old_api_client_name"`
+	OldStatus        string    `json:"old_status"`
+	NewApiClientId   uuid.UUID `json:"new_api_client_id"`
+	NewApiClientName string    `json:"new_api_client_name"`
+	Keys             []string  `json:"keys,omitempty"`
+	KeyOutput        string    `json:"key_output,omitempty"`
+	ActivatedAt      time.Time `json:"activated_at"`
+}
+
+// ApiClientView is an API client along with the name of its service and the names of its policies
+type ApiClientView struct {
+	models.ApiClientDetail
+	ServiceName string            `json:"service_name,omitempty"`
+	Policies    []ApiClientPolicy `json:"policies"`
+}
+
+// ApiClientPolicy is a policy linked to an API client. Missing is set when the policy no longer exists.
+type ApiClientPolicy struct {
+	PolicyId   uuid.UUID `json:"policy_id"`
+	PolicyName string    `json:"policy_name,omitempty"`
+	Deprecated bool      `json:"deprecated,omitempty"`
+	Missing    bool      `json:"missing,omitempty"`
+}
+
+// ApiClientEdit is an API client after an incremental edit. Changed is not set when the API client already matched
+// the edit and was left untouched.
+type ApiClientEdit struct {
+	models.ApiClient
+	PolicyIds  []uuid.UUID                `json:"policy_ids"`
+	TagsValues []models.ApiClientTagValue `json:"tags"`
+	Changed    bool                       `json:"changed"`
+}
+
+// ApiClientClone reports the copy of an API client to another service or tenant, along with how its policies and
+// tags were mapped. Unmapped lists what could not be found, nor copied, in the destination.
+type ApiClientClone struct {
+	SourceServiceId   uuid.UUID                  `json:"source_service_id"`
+	SourceApiClientId uuid.UUID                  `json:"source_api_client_id"`
+	Profile           string                     `json:"profile,omitempty"`
+	ServiceId         uuid.UUID                  `json:"service_id"`
+	ApiClientId       *uuid.UUID                 `json:"api_client_id,omitempty"`
+	Name              string                     `json:"name"`
+	ProductId         uuid.UUID                  `json:"product_id"`
+	Status            string                     `json:"status"`
+	Policies          []ClonedPolicy             `json:"policies"`
+	Tags              []models.ApiClientTagValue `json:"tags"`
+	Unmapped          []string                   `json:"unmapped,omitempty"`
+	Keys              []string                   `json:"keys,omitempty"`
+	KeyOutput         string                     `json:"key_output,omitempty"`
+	DryRun            bool                       `json:"dry_run,omitempty"`
+}
+
+// ClonedPolicy is a policy of a cloned API client and the policy of the same name it was mapped to
+type ClonedPolicy struct {
+	SourcePolicyId uuid.UUID  `json:"source_policy_id"`
+	PolicyName     string     `json:"policy_name,omitempty"`
+	PolicyId       *uuid.UUID `json:"policy_id,omitempty"`
+	Copied         bool       `json:"copied,omitempty"`
+}
+
+// ApiClientBulkResult is the outcome of a bulk operation for an API client: its id, or the error it failed with. Row
+// is the position of the API client in the input of a bulk creation. RequestId and TraceId are those of the last
+// response received while changing the API client.
+type ApiClientBulkResult struct {
+	Row         int        `json:"row,omitempty"`
+	Name        string     `json:"name"`
+	ServiceId   *uuid.UUID `json:"service_id,omitempty"`
+	ApiClientId *uuid.UUID `json:"api_client_id,omitempty"`
+	Action      string     `json:"action"`
+	KeyOutput   string     `json:"key_output,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	RequestId   string     `json:"request_id,omitempty"`
+	TraceId     string     `json:"trace_id,omitempty"`
+}
+
+// ApiClientBulk reports a bulk operation on API clients
+type ApiClientBulk struct {
+	ResultFile string                `json:"result_file,omitempty"`
+	Succeeded  int                   `json:"succeeded"`
+	Failed     int                   `json:"failed"`
+	DryRun     bool                  `json:"dry_run,omitempty"`
+	UndoFile   string                `json:"undo_file,omitempty"`
+	Results    []ApiClientBulkResult `json:"results"`
+}
+
+// ApiClientRevocation is the undo file of a revocation, listing the API clients set to Inactive with the status they
+// had before. Until the revocation is completed, it lists every API client about to be revoked.
+type ApiClientRevocation struct {
+	RevokedAt  time.Time          `json:"revoked_at"`
+	Selector   string             `json:"selector"`
+	Completed  bool               `json:"completed"`
+	ApiClients []RevokedApiClient `json:"api_clients"`
+}
+
+// RevokedApiClient is an API client set to Inactive by a revocation
+type RevokedApiClient struct {
+	ServiceId   uuid.UUID `json:"service_id"`
+	ApiClientId uuid.UUID `json:"api_client_id"`
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+}
@@ -7,9 +7,39 @@
 
 package models
 
+import "sync"
+
 type ResponderHeaderFields struct {
 	RequestId string
 	TraceId   string
 }
 
+// RespHeaderFields holds the request id sent with the requests of a command, which is replaced by the request and
+// trace ids of every response. The bulk commands send concurrent requests, which do not use it: they carry the request
+// id provided and the ids of their responses are kept in the result of every api client.
 var RespHeaderFields ResponderHeaderFields
+
+// respHeaderFieldsMutex guards RespHeaderFields, which the responses to concurrent requests still replace
+var respHeaderFieldsMutex sync.Mutex
+
+// GetRequestId returns the request id, it is safe for concurrent use
+func (f *ResponderHeaderFields) GetRequestId() string {
+	respHeaderFieldsMutex.Lock()
+	defer respHeaderFieldsMutex.Unlock()
+	return f.RequestId
+}
+
+// SetRequestId sets the request id, it is safe for concurrent use
+func (f *ResponderHeaderFields) SetRequestId(requestId string) {
+	respHeaderFieldsMutex.Lock()
+	defer respHeaderFieldsMutex.Unlock()
+	f.RequestId = requestId
+}
+
+// SetResponseIds sets the request and trace ids of a response, it is safe for concurrent use
+func (f *ResponderHeaderFields) SetResponseIds(requestId, traceId string) {
+	respHeaderFieldsMutex.Lock()
+	defer respHeaderFieldsMutex.Unlock()
+	f.RequestId = requestId
+	f.TraceId = traceId
+}
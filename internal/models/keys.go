@@ -0,0 +1,31 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package models
+
+import "time"
+
+// SigningKeyInfo describes a policy signing key and the signing algorithms it can be used with
+type SigningKeyInfo struct {
+	KeyFile            string           `json:"key_file,omitempty"`
+	KeyType            string           `json:"key_type"`
+	KeySize            int              `json:"key_size"`
+	Algorithms         []string         `json:"algorithms"`
+	DefaultAlgorithm   string           `json:"default_algorithm,omitempty"`
+	Certificate        *CertificateInfo `json:"certificate,omitempty"`
+	CertificateRequest string           `json:"certificate_request_file,omitempty"`
+}
+
+// CertificateInfo describes the certificate added to the x5c header of policy tokens signed with a key
+type CertificateInfo struct {
+	CertificateFile string    `json:"certificate_file,omitempty"`
+	Subject         string    `json:"subject"`
+	Issuer          string    `json:"issuer"`
+	NotBefore       time.Time `json:"not_before"`
+	NotAfter        time.Time `json:"not_after"`
+	MatchesKey      bool      `json:"matches_key"`
+	Valid           bool      `json:"valid"`
+	Reason          string    `json:"reason,omitempty"`
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package models
+
+import (
+	"github.com/google/uuid"
+	"intel/tac/v1/models"
+	"time"
+)
+
+// PolicyMetadata is the sidecar written next to every policy pulled from the tenant
+type PolicyMetadata struct {
+	PolicyId        uuid.UUID `json:"policy_id"`
+	PolicyName      string    `json:"policy_name"`
+	PolicyType      string    `json:"policy_type"`
+	AttestationType string    `json:"attestation_type"`
+	ServiceOfferId  uuid.UUID `json:"service_offer_id"`
+	Version         string    `json:"version"`
+	PolicyHash      string    `json:"policy_hash"`
+	HashStatus      string    `json:"hash_status"`
+	SignedByTenant  bool      `json:"signed_by_tenant"`
+	UpdatedAt       time.Time `json:"modified_time"`
+	PulledAt        time.Time `json:"pulled_time"`
+}
+
+// PolicySignatureCheck reports the local verification of a tenant signed policy returned by Trust Authority
+type PolicySignatureCheck struct {
+	Algorithm       string `json:"algorithm"`
+	SignedByTenant  bool   `json:"signed_by_tenant"`
+	HashStatus      string `json:"hash_status"`
+	PolicyJwtStatus string `json:"policy_jwt_status"`
+	SignatureStatus string `json:"signature_status"`
+}
+
+// PolicyVerification is the result of the local verification of a policy returned by Trust Authority
+type PolicyVerification struct {
+	PolicyId   uuid.UUID `json:"policy_id"`
+	PolicyName string    `json:"policy_name"`
+	Version    string    `json:"version"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+// PolicyHistoryEntry is a policy body recorded in the local history when the CLI uploads or pulls a policy
+type PolicyHistoryEntry struct {
+	PolicyId       uuid.UUID `json:"policy_id"`
+	PolicyName     string    `json:"policy_name"`
+	Version        string    `json:"version"`
+	Policy         string    `json:"policy"`
+	PolicyHash     string    `json:"policy_hash"`
+	SignedByTenant bool      `json:"signed_by_tenant"`
+	Operation      string    `json:"operation"`
+	UpdatedAt      time.Time `json:"modified_time"`
+	RecordedAt     time.Time `json:"recorded_time"`
+}
+
+// PolicyUsage is an API client, and so an attestation key, that references a policy
+type PolicyUsage struct {
+	ServiceId     uuid.UUID `json:"service_id"`
+	ServiceName   string    `json:"service_name"`
+	ApiClientId   uuid.UUID `json:"api_client_id"`
+	ApiClientName string    `json:"api_client_name"`
+	Status        string    `json:"status"`
+}
+
+// PolicyAnnotation is the client side metadata of a policy kept in the local policy registry
+type PolicyAnnotation struct {
+	PolicyId           uuid.UUID         `json:"policy_id"`
+	Labels             map[string]string `json:"labels,omitempty"`
+	Description        string            `json:"description,omitempty"`
+	Deprecated         bool              `json:"deprecated,omitempty"`
+	DeprecationMessage string            `json:"deprecation_message,omitempty"`
+	DeprecatedAt       *time.Time        `json:"deprecated_time,omitempty"`
+	UpdatedAt          time.Time         `json:"modified_time"`
+}
+
+// AnnotatedPolicy is a policy returned by Trust Authority along with its client side metadata
+type AnnotatedPolicy struct {
+	models.PolicyResponse
+	Labels             map[string]string `json:"labels,omitempty"`
+	Description        string            `json:"description,omitempty"`
+	Deprecated         bool              `json:"deprecated,omitempty"`
+	DeprecationMessage string            `json:"deprecation_message,omitempty"`
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package models
+
+import (
+	"github.com/google/uuid"
+	"intel/tac/v1/models"
+	"time"
+)
+
+// ServiceView is a service of the tenant along with its API clients
+type ServiceView struct {
+	models.ServiceDetail
+	ApiClients []models.ApiClient `json:"api_clients"`
+}
+
+// PlanView is a plan along with the name of its service offer
+type PlanView struct {
+	models.PlanProducts
+	ServiceOfferName string `json:"service_offer_name"`
+}
+
+// ProductView is a product along with the name of its service offer
+type ProductView struct {
+	models.Product
+	ServiceOfferName string `json:"service_offer_name"`
+}
+
+// TenantExport reports the configuration of the tenant written to an archive
+type TenantExport struct {
+	File          string    `json:"file"`
+	FormatVersion int       `json:"format_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Checksum      string    `json:"checksum"`
+	Services      int       `json:"services"`
+	ApiClients    int       `json:"api_clients"`
+	Policies      int       `json:"policies"`
+	Tags          int       `json:"tags"`
+	Users         int       `json:"users"`
+}
+
+// TenantImport reports how the configuration of a tenant archive was recreated in the destination tenant, one entry
+// per resource of the archive
+type TenantImport struct {
+	File       string             `json:"file"`
+	Source     string             `json:"source"`
+	ExportedAt time.Time          `json:"exported_at"`
+	Profile    string             `json:"profile,omitempty"`
+	DryRun     bool               `json:"dry_run,omitempty"`
+	Resources  []ImportedResource `json:"resources"`
+}
+
+// ImportedResource is a resource of a tenant archive and what was done with it: created, updated, mapped to the
+// existing resource of the same name or skipped. Id is the id of the resource in the destination tenant.
+type ImportedResource struct {
+	Kind     string     `json:"kind"`
+	Name     string     `json:"name"`
+	SourceId string     `json:"source_id,omitempty"`
+	Id       *uuid.UUID `json:"id,omitempty"`
+	Action   string     `json:"action"`
+	Note     string     `json:"note,omitempty"`
+}
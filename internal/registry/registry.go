@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package registry
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
+	models2 "intel/tac/v1/internal/models"
+	"intel/tac/v1/utils"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Registry holds the labels, descriptions and deprecation of the policies of the current profile. Trust Authority
+// does not store this metadata, it is only kept by the CLI.
+type Registry struct {
+	Policies map[uuid.UUID]*models2.PolicyAnnotation `json:"policies"`
+}
+
+// Path returns the registry file of the current profile
+func Path() (string, error) {
+	userHomeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "Error fetching user home directory path")
+	}
+	profile, err := config.Profile()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Clean(userHomeDir+constants.PolicyRegistryDir), profile+constants.PolicyMetadataFileExtension), nil
+}
+
+// Load reads the registry of the current profile, an empty registry is returned when none was saved yet
+func Load() (*Registry, error) {
+	r := &Registry{Policies: map[uuid.UUID]*models2.PolicyAnnotation{}}
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	registryBytes, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, errors.Wrap(err, "Error reading policy registry")
+	}
+	if err = json.Unmarshal(registryBytes, r); err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling policy registry")
+	}
+	if r.Policies == nil {
+		r.Policies = map[uuid.UUID]*models2.PolicyAnnotation{}
+	}
+	return r, nil
+}
+
+// Save writes the registry of the current profile
+func (r *Registry) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), constants.DefaultDirPermission); err != nil {
+		return errors.Wrap(err, "Error creating policy registry directory")
+	}
+	registryBytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, registryBytes, constants.DefaultFilePermission)
+}
+
+// Get returns the annotation of the policy, or nil when the policy is not annotated
+func (r *Registry) Get(policyId uuid.UUID) *models2.PolicyAnnotation {
+	return r.Policies[policyId]
+}
+
+// Annotate returns the annotation of the policy to be modified, creating it when the policy is not annotated yet
+func (r *Registry) Annotate(policyId uuid.UUID) *models2.PolicyAnnotation {
+	annotation, ok := r.Policies[policyId]
+	if !ok {
+		annotation = &models2.PolicyAnnotation{PolicyId: policyId}
+		r.Policies[policyId] = annotation
+	}
+	annotation.UpdatedAt = time.Now().UTC()
+	return annotation
+}
+
+// Remove drops the annotation of the policy and reports whether there was one
+func (r *Registry) Remove(policyId uuid.UUID) bool {
+	_, ok := r.Policies[policyId]
+	delete(r.Policies, policyId)
+	return ok
+}
+
+// Deprecated returns the annotations of the deprecated policies among the policy IDs
+func (r *Registry) Deprecated(policyIds []uuid.UUID) []*models2.PolicyAnnotation {
+	var deprecated []*models2.PolicyAnnotation
+	for _, policyId := range policyIds {
+		if annotation := r.Get(policyId); annotation != nil && annotation.Deprecated {
+			deprecated = append(deprecated, annotation)
+		}
+	}
+	return deprecated
+}
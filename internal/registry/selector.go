@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package registry
+
+import (
+	"github.com/pkg/errors"
+	"intel/tac/v1/validation"
+	"strings"
+)
+
+// selector operators
+const (
+	opEquals    = "="
+	opNotEquals = "!="
+	opExists    = "exists"
+	opNotExists = "!exists"
+)
+
+// requirement is a single condition of a label selector
+type requirement struct {
+	key      string
+	operator string
+	value    string
+}
+
+// Selector selects policies by their labels. All of its requirements need to match.
+type Selector []requirement
+
+// ParseSelector parses comma separated requirements in the "key=value", "key!=value", "key" (the label is set)
+// and "!key" (the label is not set) formats
+func ParseSelector(selector string) (Selector, error) {
+	var s Selector
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		var r requirement
+		switch {
+		case term == "":
+			return nil, errors.Errorf("Invalid selector %q, requirements cannot be empty", selector)
+		case strings.Contains(term, opNotEquals):
+			r.key, r.value, _ = strings.Cut(term, opNotEquals)
+			r.operator = opNotEquals
+		case strings.Contains(term, opEquals):
+			r.key, r.value, _ = strings.Cut(term, opEquals)
+			r.operator = opEquals
+		case strings.HasPrefix(term, "!"):
+			r.key = strings.TrimPrefix(term, "!")
+			r.operator = opNotExists
+		default:
+			r.key = term
+			r.operator = opExists
+		}
+		r.key = strings.TrimSpace(r.key)
+		r.value = strings.TrimSpace(r.value)
+		if err := validation.ValidateLabelKey(r.key); err != nil {
+			return nil, errors.Wrapf(err, "Invalid selector requirement %q", term)
+		}
+		if err := validation.ValidateLabelValue(r.value); err != nil {
+			return nil, errors.Wrapf(err, "Invalid selector requirement %q", term)
+		}
+		s = append(s, r)
+	}
+	return s, nil
+}
+
+// Matches reports whether the labels meet every requirement of the selector
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		value, ok := labels[r.key]
+		var matches bool
+		switch r.operator {
+		case opEquals:
+			matches = ok && value == r.value
+		case opNotEquals:
+			matches = !ok || value != r.value
+		case opExists:
+			matches = ok
+		case opNotExists:
+			matches = !ok
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"github.com/pkg/errors"
+	"intel/tac/v1/constants"
+	"sort"
+	"text/template"
+)
+
+//go:embed rego/*.rego.tmpl
+var regoTemplates embed.FS
+
+// Template describes an embedded Rego policy template
+type Template struct {
+	Name            string
+	AttestationType string
+	// Parameters lists the flags of the reference values the template can be rendered with
+	Parameters []string
+	file       string
+}
+
+var policyTemplates = map[string]Template{
+	"sgx": {
+		Name:            "sgx",
+		AttestationType: "SGX Attestation",
+		Parameters:      []string{constants.MrEnclaveParamName, constants.MrSignerParamName, constants.IsvProdIdParamName, constants.IsvSvnParamName, constants.MinTcbStatusParamName},
+		file:            "rego/sgx.rego.tmpl",
+	},
+	"tdx": {
+		Name:            "tdx",
+		AttestationType: "TDX Attestation",
+		Parameters:      []string{constants.MrTdParamName, constants.RtmrParamName, constants.MinTcbStatusParamName},
+		file:            "rego/tdx.rego.tmpl",
+	},
+	"tdx+nvgpu": {
+		Name:            "tdx+nvgpu",
+		AttestationType: "TDX and NVGPU Attestation",
+		Parameters:      []string{constants.MrTdParamName, constants.RtmrParamName, constants.MinTcbStatusParamName},
+		file:            "rego/tdx-nvgpu.rego.tmpl",
+	},
+	"sevsnp": {
+		Name:            "sevsnp",
+		AttestationType: "SEV-SNP Attestation",
+		Parameters:      []string{constants.MeasurementParamName},
+		file:            "rego/sevsnp.rego.tmpl",
+	},
+}
+
+// TcbStatuses lists the platform TCB statuses from the most to the least trusted
+var TcbStatuses = []string{"UpToDate", "SWHardeningNeeded", "ConfigurationNeeded", "ConfigurationAndSWHardeningNeeded",
+	"OutOfDate", "OutOfDateConfigurationNeeded"}
+
+// Values holds the reference values rendered into a template. Values left empty are rendered as commented out
+// rules that can be filled in later, unless no identity value is provided at all: the identity checks are then
+// rendered with placeholders which do not compile, so that the policy cannot accept any workload until edited.
+type Values struct {
+	MrEnclave   string
+	MrSigner    string
+	IsvProdId   string
+	IsvSvn      string
+	MrTd        string
+	Rtmrs       [4]string
+	Measurement string
+	// AllowedTcbStatuses is derived from the minimum TCB status
+	AllowedTcbStatuses []string
+}
+
+// HasIdentity reports whether a reference value identifying the workload is provided: an enclave, trust domain or
+// guest measurement, an enclave signer or a runtime measurement register
+func (v Values) HasIdentity() bool {
+	if v.MrEnclave != "" || v.MrSigner != "" || v.MrTd != "" || v.Measurement != "" {
+		return true
+	}
+	for _, rtmr := range v.Rtmrs {
+		if rtmr != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the names of the embedded templates
+func Names() []string {
+	var names []string
+	for name := range policyTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the template with the provided name
+func Get(name string) (Template, error) {
+	t, ok := policyTemplates[name]
+	if !ok {
+		return Template{}, errors.Errorf("Unknown policy template %q, supported templates are %v", name, Names())
+	}
+	return t, nil
+}
+
+// Supports reports whether the template can be rendered with the provided reference value
+func (t Template) Supports(parameter string) bool {
+	for _, p := range t.Parameters {
+		if p == parameter {
+			return true
+		}
+	}
+	return false
+}
+
+// Render returns the Rego policy of the template filled in with the provided reference values
+func (t Template) Render(values Values) (string, error) {
+	tmpl, err := template.ParseFS(regoTemplates, t.file)
+	if err != nil {
+		return "", errors.Wrap(err, "Error parsing policy template")
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, values); err != nil {
+		return "", errors.Wrap(err, "Error rendering policy template")
+	}
+	return buf.String(), nil
+}
+
+// AllowedTcbStatuses returns the TCB statuses that are at least as trusted as the provided minimum status
+func AllowedTcbStatuses(minTcbStatus string) ([]string, error) {
+	for i, status := range TcbStatuses {
+		if status == minTcbStatus {
+			return TcbStatuses[:i+1], nil
+		}
+	}
+	return nil, errors.Errorf("Unknown TCB status %q, supported statuses are %v", minTcbStatus, TcbStatuses)
+}
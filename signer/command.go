@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// CommandSigner signs by running an external command which receives the signing input on stdin and writes
+// the raw binary signature to stdout. The JWS algorithm is passed in the TRUSTAUTHORITY_SIGNING_ALGORITHM
+// environment variable.
+type CommandSigner struct {
+	args      []string
+	publicKey crypto.PublicKey
+}
+
+// NewCommandSigner creates a signer for the command line, which is split on white space and run without a shell
+func NewCommandSigner(command string, publicKey crypto.PublicKey) (*CommandSigner, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("Signing command cannot be empty")
+	}
+	return &CommandSigner{args: args, publicKey: publicKey}, nil
+}
+
+// Public returns the public key of the signing certificate
+func (s *CommandSigner) Public() crypto.PublicKey {
+	return s.publicKey
+}
+
+// Sign is not supported as the signing command needs the complete signing input
+func (s *CommandSigner) Sign(_ io.Reader, _ []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return nil, errors.New("Signing command requires the signing input instead of its digest")
+}
+
+// SignMessage runs the signing command with the signing input on stdin
+func (s *CommandSigner) SignMessage(message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	algorithm, err := Algorithm(s.publicKey, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultSignerTimeout*time.Second)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	// #nosec G204 -- the signing command is provided by the user running the CLI
+	command := exec.CommandContext(ctx, s.args[0], s.args[1:]...)
+	command.Env = append(os.Environ(), constants.SigningAlgorithmEnvVar+"="+algorithm)
+	command.Stdin = bytes.NewReader(message)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+
+	log.Debugf("Running signing command %s for algorithm %s", s.args[0], algorithm)
+	if err = command.Run(); err != nil {
+		return nil, errors.Wrapf(err, "Signing command failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, errors.New("Signing command did not return a signature")
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package signer
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// KMSSignRequest is the body posted to the remote key management service
+type KMSSignRequest struct {
+	KeyId     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	Digest    string `json:"digest,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// KMSSignResponse is the body returned by the remote key management service
+type KMSSignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// KMSSigner signs digests with a key held by a remote key management service. The service receives the
+// base64 encoded digest (or message for EdDSA) and returns the base64 encoded signature. A bearer token is
+// sent when the TRUSTAUTHORITY_KMS_TOKEN environment variable is set.
+type KMSSigner struct {
+	client    *http.Client
+	url       string
+	keyId     string
+	publicKey crypto.PublicKey
+}
+
+// NewKMSSigner creates a signer for the key identified by keyId at the KMS signing endpoint
+func NewKMSSigner(client *http.Client, url, keyId string, publicKey crypto.PublicKey) (*KMSSigner, error) {
+	if keyId == "" {
+		return nil, errors.New("KMS key ID cannot be empty")
+	}
+	return &KMSSigner{client: client, url: url, keyId: keyId, publicKey: publicKey}, nil
+}
+
+// Public returns the public key of the signing certificate
+func (s *KMSSigner) Public() crypto.PublicKey {
+	return s.publicKey
+}
+
+// Sign requests the signature of the digest from the key management service
+func (s *KMSSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	algorithm, err := Algorithm(s.publicKey, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	signRequest := KMSSignRequest{KeyId: s.keyId, Algorithm: algorithm}
+	if opts.HashFunc() == 0 {
+		signRequest.Message = base64.StdEncoding.EncodeToString(digest)
+	} else {
+		signRequest.Digest = base64.StdEncoding.EncodeToString(digest)
+	}
+	reqBytes, err := json.Marshal(signRequest)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling KMS sign request")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(constants.HTTPHeaderKeyContentType, constants.HTTPMediaTypeJson)
+	req.Header.Set(constants.HTTPHeaderKeyAccept, constants.HTTPMediaTypeJson)
+	if token := strings.TrimSpace(os.Getenv(constants.KMSTokenEnvVar)); token != "" {
+		req.Header.Set(constants.HTTPHeaderKeyAuthorization, "Bearer "+token)
+	}
+
+	log.Debugf("Requesting %s signature from KMS key %s", algorithm, s.keyId)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error sending KMS sign request")
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.WithError(err).Error("Failed to close KMS response body")
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading KMS response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("KMS sign request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var signResponse KMSSignResponse
+	if err = json.Unmarshal(body, &signResponse); err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling KMS response")
+	}
+	signature, err := base64.StdEncoding.DecodeString(signResponse.Signature)
+	if err != nil || len(signature) == 0 {
+		return nil, errors.New("KMS response does not contain a valid base64 encoded signature")
+	}
+	return signature, nil
+}
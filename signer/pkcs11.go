@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"intel/tac/v1/constants"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// pkcs11Mechanisms maps the JWS algorithms to the pkcs11-tool mechanisms which hash the input on the token
+var pkcs11Mechanisms = map[string]string{
+	constants.RS256: "SHA256-RSA-PKCS",
+	constants.RS384: "SHA384-RSA-PKCS",
+	constants.RS512: "SHA512-RSA-PKCS",
+	constants.PS256: "SHA256-RSA-PKCS-PSS",
+	constants.PS384: "SHA384-RSA-PKCS-PSS",
+	constants.PS512: "SHA512-RSA-PKCS-PSS",
+	constants.ES256: "ECDSA-SHA256",
+	constants.ES384: "ECDSA-SHA384",
+	constants.ES512: "ECDSA-SHA512",
+	constants.EdDSA: "EDDSA",
+}
+
+// PKCS11URI holds the attributes of an RFC 7512 PKCS#11 URI used to locate the signing key
+type PKCS11URI struct {
+	Token      string
+	Object     string
+	Id         []byte
+	SlotId     string
+	ModulePath string
+	Pin        string
+}
+
+// ParsePKCS11URI parses a URI such as
+// pkcs11:token=signing;object=policy-key?module-path=/usr/lib/softhsm/libsofthsm2.so&pin-source=file:/run/pin
+func ParsePKCS11URI(uri string) (*PKCS11URI, error) {
+	if !strings.HasPrefix(uri, constants.PKCS11URIScheme) {
+		return nil, errors.New("PKCS#11 URI should start with " + constants.PKCS11URIScheme)
+	}
+	path, query, _ := strings.Cut(strings.TrimPrefix(uri, constants.PKCS11URIScheme), "?")
+
+	parsed := &PKCS11URI{}
+	for _, attribute := range strings.Split(path, ";") {
+		if attribute == "" {
+			continue
+		}
+		name, value, err := uriAttribute(attribute)
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "token":
+			parsed.Token = value
+		case "object":
+			parsed.Object = value
+		case "id":
+			parsed.Id = []byte(value)
+		case "slot-id":
+			parsed.SlotId = value
+		}
+	}
+
+	for _, attribute := range strings.Split(query, "&") {
+		if attribute == "" {
+			continue
+		}
+		name, value, err := uriAttribute(attribute)
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "module-path":
+			parsed.ModulePath = value
+		case "pin-value":
+			parsed.Pin = value
+		case "pin-source":
+			pin, err := os.ReadFile(filepath.Clean(strings.TrimPrefix(value, "file:")))
+			if err != nil {
+				return nil, errors.Wrap(err, "Error reading PKCS#11 PIN source")
+			}
+			parsed.Pin = strings.TrimRight(string(pin), "\r\n")
+		}
+	}
+
+	if parsed.ModulePath == "" {
+		return nil, errors.New("PKCS#11 URI should contain the module-path query attribute")
+	}
+	if parsed.Object == "" && len(parsed.Id) == 0 {
+		return nil, errors.New("PKCS#11 URI should identify the key with the object or id attribute")
+	}
+	return parsed, nil
+}
+
+func uriAttribute(attribute string) (string, string, error) {
+	name, value, found := strings.Cut(attribute, "=")
+	if !found {
+		return "", "", errors.Errorf("Invalid PKCS#11 URI attribute %q", attribute)
+	}
+	value, err := url.PathUnescape(value)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Invalid PKCS#11 URI attribute %q", attribute)
+	}
+	return name, value, nil
+}
+
+// PKCS11Signer signs with a key stored on a PKCS#11 token through pkcs11-tool (OpenSC). The tool location can
+// be overridden with the TRUSTAUTHORITY_PKCS11_TOOL environment variable.
+type PKCS11Signer struct {
+	uri       *PKCS11URI
+	publicKey crypto.PublicKey
+}
+
+// NewPKCS11Signer creates a signer for the key identified by the PKCS#11 URI
+func NewPKCS11Signer(uri string, publicKey crypto.PublicKey) (*PKCS11Signer, error) {
+	parsed, err := ParsePKCS11URI(uri)
+	if err != nil {
+		return nil, err
+	}
+	return &PKCS11Signer{uri: parsed, publicKey: publicKey}, nil
+}
+
+// Public returns the public key of the signing certificate
+func (s *PKCS11Signer) Public() crypto.PublicKey {
+	return s.publicKey
+}
+
+// Sign is not supported as the token hashes the signing input itself
+func (s *PKCS11Signer) Sign(_ io.Reader, _ []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return nil, errors.New("PKCS#11 signer requires the signing input instead of its digest")
+}
+
+// SignMessage signs the signing input on the token
+func (s *PKCS11Signer) SignMessage(message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	algorithm, err := Algorithm(s.publicKey, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	workDir, err := os.MkdirTemp("", "trustauthorityctl-pkcs11")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workDir)
+	inputFile := filepath.Join(workDir, "input")
+	outputFile := filepath.Join(workDir, "signature")
+	if err = os.WriteFile(inputFile, message, 0600); err != nil {
+		return nil, err
+	}
+
+	args := []string{"--module", s.uri.ModulePath, "--sign", "--mechanism", pkcs11Mechanisms[algorithm],
+		"--input-file", inputFile, "--output-file", outputFile}
+	if s.uri.Token != "" {
+		args = append(args, "--token-label", s.uri.Token)
+	}
+	if s.uri.SlotId != "" {
+		args = append(args, "--slot", s.uri.SlotId)
+	}
+	if s.uri.Object != "" {
+		args = append(args, "--label", s.uri.Object)
+	}
+	if len(s.uri.Id) > 0 {
+		args = append(args, "--id", hex.EncodeToString(s.uri.Id))
+	}
+	if strings.HasPrefix(algorithm, "PS") {
+		hashName := strings.ReplaceAll(opts.HashFunc().String(), "-", "")
+		args = append(args, "--hash-algorithm", hashName, "--mgf", "MGF1-"+hashName, "--salt-len", "-1")
+	}
+
+	tool := constants.DefaultPKCS11Tool
+	if override := os.Getenv(constants.PKCS11ToolEnvVar); override != "" {
+		tool = override
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultSignerTimeout*time.Second)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	// #nosec G204 -- the PKCS#11 tool and its arguments are provided by the user running the CLI
+	command := exec.CommandContext(ctx, tool, args...)
+	command.Stderr = &stderr
+	if s.uri.Pin != "" {
+		// the PIN is passed through the environment to keep it out of the process list
+		command.Args = append(command.Args, "--login", "--pin", "env:"+constants.PKCS11PinEnvVar)
+		command.Env = append(os.Environ(), constants.PKCS11PinEnvVar+"="+s.uri.Pin)
+	}
+
+	log.Debugf("Signing with PKCS#11 module %s using mechanism %s", s.uri.ModulePath, pkcs11Mechanisms[algorithm])
+	if err = command.Run(); err != nil {
+		return nil, errors.Wrapf(err, "PKCS#11 signing failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	signature, err := os.ReadFile(outputFile)
+	if err != nil || len(signature) == 0 {
+		return nil, errors.New("PKCS#11 signing did not return a signature")
+	}
+	return signature, nil
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/asn1"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"intel/tac/v1/constants"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MessageSigner is implemented by signers that need the complete signing input instead of its digest,
+// for example when the hashing is done by the key management system itself
+type MessageSigner interface {
+	crypto.Signer
+	SignMessage(message []byte, opts crypto.SignerOpts) ([]byte, error)
+}
+
+// SigningMethod is a jwt.SigningMethod that delegates the raw signature to a crypto.Signer, so that the
+// private key never needs to be loaded by the CLI
+type SigningMethod struct {
+	algorithm string
+}
+
+// NewSigningMethod returns the signing method for one of the supported JWS algorithms
+func NewSigningMethod(algorithm string) (*SigningMethod, error) {
+	if _, err := signerOpts(algorithm); err != nil {
+		return nil, err
+	}
+	return &SigningMethod{algorithm: algorithm}, nil
+}
+
+// Alg returns the JWS algorithm name
+func (m *SigningMethod) Alg() string {
+	return m.algorithm
+}
+
+// Verify checks the signature with the standard signing method of the algorithm
+func (m *SigningMethod) Verify(signingString string, sig []byte, key interface{}) error {
+	if signer, ok := key.(crypto.Signer); ok {
+		key = signer.Public()
+	}
+	return jwt.GetSigningMethod(m.algorithm).Verify(signingString, sig, key)
+}
+
+// Sign requests the signature of the signing input from the crypto.Signer and converts it to the JWS format.
+// The signature is verified with the public key of the signer before it is returned.
+func (m *SigningMethod) Sign(signingString string, key interface{}) ([]byte, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("Signing key should implement crypto.Signer")
+	}
+	opts, err := signerOpts(m.algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var signature []byte
+	if messageSigner, ok := signer.(MessageSigner); ok {
+		signature, err = messageSigner.SignMessage([]byte(signingString), opts)
+	} else {
+		digest := []byte(signingString)
+		if opts.HashFunc() != 0 {
+			h := opts.HashFunc().New()
+			h.Write(digest)
+			digest = h.Sum(nil)
+		}
+		signature, err = signer.Sign(rand.Reader, digest, opts)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Error signing policy token")
+	}
+
+	if ecdsaKey, ok := signer.Public().(*ecdsa.PublicKey); ok {
+		if signature, err = rawECDSASignature(signature, ecdsaKey); err != nil {
+			return nil, err
+		}
+	}
+	if err = m.Verify(signingString, signature, signer.Public()); err != nil {
+		return nil, errors.Wrap(err, "Signature returned by the signer does not match the certificate public key")
+	}
+	return signature, nil
+}
+
+// signerOpts returns the crypto.SignerOpts matching the JWS algorithm
+func signerOpts(algorithm string) (crypto.SignerOpts, error) {
+	var hash crypto.Hash
+	switch {
+	case algorithm == constants.EdDSA:
+		return crypto.Hash(0), nil
+	case strings.HasSuffix(algorithm, constants.HashSize256):
+		hash = crypto.SHA256
+	case strings.HasSuffix(algorithm, constants.HashSize384):
+		hash = crypto.SHA384
+	case strings.HasSuffix(algorithm, constants.HashSize512):
+		hash = crypto.SHA512
+	default:
+		return nil, errors.Errorf("Unsupported signing algorithm %q", algorithm)
+	}
+
+	switch algorithm[:2] {
+	case "PS":
+		return &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}, nil
+	case "RS", "ES":
+		return hash, nil
+	}
+	return nil, errors.Errorf("Unsupported signing algorithm %q", algorithm)
+}
+
+// Algorithm returns the JWS algorithm name for a signature with the given public key and options
+func Algorithm(pub crypto.PublicKey, opts crypto.SignerOpts) (string, error) {
+	var hashSize string
+	switch opts.HashFunc() {
+	case crypto.SHA256:
+		hashSize = constants.HashSize256
+	case crypto.SHA384:
+		hashSize = constants.HashSize384
+	case crypto.SHA512:
+		hashSize = constants.HashSize512
+	}
+
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		if hashSize == "" {
+			break
+		}
+		if _, ok := opts.(*rsa.PSSOptions); ok {
+			return "PS" + hashSize, nil
+		}
+		return "RS" + hashSize, nil
+	case *ecdsa.PublicKey:
+		if hashSize != "" {
+			return "ES" + hashSize, nil
+		}
+	case ed25519.PublicKey:
+		if opts.HashFunc() == 0 {
+			return constants.EdDSA, nil
+		}
+	}
+	return "", errors.New("Unsupported combination of public key type and signature options")
+}
+
+// rawECDSASignature converts an ASN.1 DER encoded ECDSA signature to the fixed size r||s format required by JWS.
+// Signatures already in the r||s format are returned unchanged.
+func rawECDSASignature(signature []byte, pub *ecdsa.PublicKey) ([]byte, error) {
+	keySize := (pub.Curve.Params().BitSize + 7) / 8
+	if len(signature) == 2*keySize {
+		return signature, nil
+	}
+
+	var sig struct {
+		R, S *big.Int
+	}
+	if rest, err := asn1.Unmarshal(signature, &sig); err != nil || len(rest) != 0 {
+		return nil, errors.New("ECDSA signature is neither ASN.1 DER nor " + strconv.Itoa(2*keySize) + " bytes r||s encoded")
+	}
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.BitLen() > 8*keySize || sig.S.BitLen() > 8*keySize {
+		return nil, errors.New("Invalid ECDSA signature values")
+	}
+
+	raw := make([]byte, 2*keySize)
+	sig.R.FillBytes(raw[:keySize])
+	sig.S.FillBytes(raw[keySize:])
+	return raw, nil
+}
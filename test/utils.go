@@ -7,19 +7,33 @@ package test
 
 import (
 	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"gopkg.in/yaml.v3"
 	"intel/tac/v1/config"
+	"intel/tac/v1/constants"
 	"intel/tac/v1/models"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
+// TamperedPolicyName is the name of signed policies for which the mock server returns a mismatching policy hash
+const TamperedPolicyName = "tampered-policy"
+
+// FailingApiClientName is the name of API clients for which the mock server rejects updates
+const FailingApiClientName = "failing-api-client"
+
 var (
 	policy = `{
         "policy_id": "52135615-3881-4b94-91ff-49f01e626e7b",
@@ -294,7 +308,7 @@ func MockServer(t *testing.T) *httptest.Server {
 	r.HandleFunc("/management/v1/policies", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Add("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
-		_, err := w.Write([]byte(policy))
+		_, err := w.Write(policyResponse(r))
 		if err != nil {
 			t.Log("test/test_utility:mockServer(): Unable to write data")
 		}
@@ -330,7 +344,7 @@ func MockServer(t *testing.T) *httptest.Server {
 	r.HandleFunc(policyIdExpr, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Add("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
-		_, err := w.Write([]byte(policy))
+		_, err := w.Write(policyResponse(r))
 		if err != nil {
 			t.Log("test/test_utility:mockServer(): Unable to write data")
 		}
@@ -545,6 +559,558 @@ func MockServer(t *testing.T) *httptest.Server {
 	return httptest.NewServer(r)
 }
 
+// PolicyMockServer serves the provided policies from the policy list, get and update policy endpoints. Updates
+// replace the stored policy and bump its version.
+func PolicyMockServer(t *testing.T, policies []models.PolicyResponse) *httptest.Server {
+	r := mux.NewRouter()
+
+	r.HandleFunc("/management/v1/policies", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(policies); err != nil {
+			t.Log("test/test_utility:PolicyMockServer(): Unable to write data")
+		}
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc(fmt.Sprintf("%s%s", "/management/v1/policies/", idReg), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		for _, policy := range policies {
+			if policy.PolicyId.String() == mux.Vars(r)["id"] {
+				if err := json.NewEncoder(w).Encode(policy); err != nil {
+					t.Log("test/test_utility:PolicyMockServer(): Unable to write data")
+				}
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc(fmt.Sprintf("%s%s", "/management/v1/policies/", idReg), func(w http.ResponseWriter, r *http.Request) {
+		var request models.PolicyUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for i := range policies {
+			if policies[i].PolicyId.String() != mux.Vars(r)["id"] {
+				continue
+			}
+			if request.Policy != "" {
+				policies[i].Policy = request.Policy
+				policyHash := sha512.Sum384([]byte(request.Policy))
+				policies[i].PolicyHash = base64.StdEncoding.EncodeToString(policyHash[:])
+			}
+			if request.PolicyName != "" {
+				policies[i].PolicyName = request.PolicyName
+			}
+			var version int
+			_, _ = fmt.Sscanf(policies[i].Version, "v%d", &version)
+			policies[i].Version = fmt.Sprintf("v%d", version+1)
+			policies[i].UpdatedAt = time.Now().UTC()
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(policies[i]); err != nil {
+				t.Log("test/test_utility:PolicyMockServer(): Unable to write data")
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodPut)
+
+	return httptest.NewServer(r)
+}
+
+// ApiClientMockServer serves the provided API clients from the service and API client endpoints. Created API clients
+// are added with two attestation API keys, which are accepted by the attestation nonce endpoint while the API client is
+// active. The creation and updates of API clients named FailingApiClientName are rejected.
+func ApiClientMockServer(t *testing.T, apiClients *[]models.ApiClientDetail) *httptest.Server {
+	var mutex sync.Mutex
+	r := mux.NewRouter()
+	write := mockWriter(t)
+
+	r.HandleFunc("/management/v1/services", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		services := []models.Service{}
+		for _, apiClient := range *apiClients {
+			known := false
+			for _, service := range services {
+				known = known || service.ID == apiClient.ServiceId
+			}
+			if !known {
+				services = append(services, models.Service{ID: apiClient.ServiceId, Name: fmt.Sprintf("Service %d", len(services)+1), Active: true})
+			}
+		}
+		write(w, services)
+	}).Methods(http.MethodGet)
+
+	handleApiClients(t, r, &mutex, apiClients)
+	return httptest.NewServer(r)
+}
+
+// Tenant is the state of the tenant served by TenantMockServer
+type Tenant struct {
+	Services      []models.ServiceDetail
+	ServiceOffers []models.ServiceOffer
+	Products      []models.Product
+	Policies      []models.PolicyResponse
+	Tags          []models.Tag
+	ApiClients    []models.ApiClientDetail
+	Users         []models.TenantUser
+	Settings      models.AttestationFailureEmail
+}
+
+// TenantMockServer serves the services, service offers, products, policies, tags, API clients, users and settings of
+// the tenant, which are updated by the requests. API clients are served as by ApiClientMockServer.
+func TenantMockServer(t *testing.T, tenant *Tenant) *httptest.Server {
+	var mutex sync.Mutex
+	r := mux.NewRouter()
+	write := mockWriter(t)
+	serviceOfferIdReg := strings.Replace(idReg, "{id:", "{service_offer_id:", 1)
+
+	// as Trust Authority does, the request id is returned along with a trace id of its own to every request
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(constants.HTTPHeaderKeyRequestId, r.Header.Get(constants.HTTPHeaderKeyRequestId))
+			w.Header().Set(constants.HTTPHeaderKeyTraceId, uuid.NewString())
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	r.HandleFunc("/management/v1/services", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		services := []models.Service{}
+		for _, service := range tenant.Services {
+			services = append(services, models.Service{ID: service.ID, ServiceOfferId: service.ServiceOfferId, Name: service.Name,
+				PlanId: service.PlanId, PlanName: service.PlanName, Active: service.Active, CreatedAt: service.CreatedAt})
+		}
+		write(w, services)
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/services/"+idReg, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, service := range tenant.Services {
+			if service.ID.String() == mux.Vars(r)["id"] {
+				write(w, service)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/service-offers", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		write(w, append([]models.ServiceOffer{}, tenant.ServiceOffers...))
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/service-offers/"+serviceOfferIdReg+"/products", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		products := []models.Product{}
+		for _, product := range tenant.Products {
+			if product.ServiceOfferId.String() == mux.Vars(r)["service_offer_id"] {
+				products = append(products, product)
+			}
+		}
+		write(w, products)
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/policies", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		write(w, append([]models.PolicyResponse{}, tenant.Policies...))
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/policies", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		var request models.PolicyRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, policy := range tenant.Policies {
+			if policy.PolicyName == request.PolicyName {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		policy := models.PolicyResponse{CommonPolicy: request.CommonPolicy, Version: "v1", CreatedAt: time.Now().UTC()}
+		policy.PolicyId = uuid.New()
+		policyHash := sha512.Sum384([]byte(request.Policy))
+		policy.PolicyHash = base64.StdEncoding.EncodeToString(policyHash[:])
+		tenant.Policies = append(tenant.Policies, policy)
+		write(w, policy)
+	}).Methods(http.MethodPost)
+
+	r.HandleFunc("/management/v1/policies/"+idReg, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, policy := range tenant.Policies {
+			if policy.PolicyId.String() == mux.Vars(r)["id"] {
+				write(w, policy)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/policies/"+idReg, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		var request models.PolicyUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for i := range tenant.Policies {
+			policy := &tenant.Policies[i]
+			if policy.PolicyId.String() != mux.Vars(r)["id"] {
+				continue
+			}
+			if request.Policy != "" {
+				policy.Policy = request.Policy
+				policyHash := sha512.Sum384([]byte(request.Policy))
+				policy.PolicyHash = base64.StdEncoding.EncodeToString(policyHash[:])
+			}
+			if request.PolicyName != "" {
+				policy.PolicyName = request.PolicyName
+			}
+			var version int
+			_, _ = fmt.Sscanf(policy.Version, "v%d", &version)
+			policy.Version = fmt.Sprintf("v%d", version+1)
+			policy.UpdatedAt = time.Now().UTC()
+			write(w, policy)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodPut)
+
+	r.HandleFunc("/management/v1/policies/"+idReg, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for i, policy := range tenant.Policies {
+			if policy.PolicyId.String() == mux.Vars(r)["id"] {
+				tenant.Policies = append(tenant.Policies[:i], tenant.Policies[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodDelete)
+
+	r.HandleFunc("/management/v1/tags", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		write(w, models.Tags{Tags: append([]models.Tag{}, tenant.Tags...)})
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/tags", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		var request models.TagCreate
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, tag := range tenant.Tags {
+			if tag.Name == request.Name {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		id := uuid.New()
+		tag := models.Tag{ID: &id, Name: request.Name}
+		tenant.Tags = append(tenant.Tags, tag)
+		write(w, tag)
+	}).Methods(http.MethodPost)
+
+	r.HandleFunc("/management/v1/tags/"+idReg, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for i, tag := range tenant.Tags {
+			if tag.ID != nil && tag.ID.String() == mux.Vars(r)["id"] {
+				tenant.Tags = append(tenant.Tags[:i], tenant.Tags[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodDelete)
+
+	r.HandleFunc("/management/v1/users", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		write(w, append([]models.TenantUser{}, tenant.Users...))
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/users", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		var request models.CreateTenantUser
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, user := range tenant.Users {
+			if strings.EqualFold(user.Email, request.Email) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		user := models.TenantUser{ID: uuid.New(), Email: request.Email, Role: models.Role{ID: uuid.New(), Name: request.Role},
+			Active: true, CreatedAt: time.Now().UTC()}
+		tenant.Users = append(tenant.Users, user)
+		write(w, user)
+	}).Methods(http.MethodPost)
+
+	r.HandleFunc("/management/v1/users/"+idReg, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		var request models.UpdateTenantUserRoles
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for i := range tenant.Users {
+			if tenant.Users[i].ID.String() == mux.Vars(r)["id"] {
+				tenant.Users[i].Role.Name = request.Role
+				write(w, tenant.Users[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodPut)
+
+	r.HandleFunc("/management/v1/users/"+idReg, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for i, user := range tenant.Users {
+			if user.ID.String() == mux.Vars(r)["id"] {
+				tenant.Users = append(tenant.Users[:i], tenant.Users[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodDelete)
+
+	r.HandleFunc("/management/v1/tenants/settings", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		write(w, tenant.Settings)
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/tenants/settings", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if err := json.NewDecoder(r.Body).Decode(&tenant.Settings); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		write(w, tenant.Settings)
+	}).Methods(http.MethodPut)
+
+	handleApiClients(t, r, &mutex, &tenant.ApiClients)
+	return httptest.NewServer(r)
+}
+
+// mockWriter returns a function writing JSON responses of the stateful mock servers
+func mockWriter(t *testing.T) func(w http.ResponseWriter, response interface{}) {
+	return func(w http.ResponseWriter, response interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Log("test/test_utility: Unable to write data")
+		}
+	}
+}
+
+// handleApiClients serves the API client and attestation nonce endpoints of ApiClientMockServer and TenantMockServer
+func handleApiClients(t *testing.T, r *mux.Router, mutex *sync.Mutex, apiClients *[]models.ApiClientDetail) {
+	find := func(r *http.Request) int {
+		for i, apiClient := range *apiClients {
+			if apiClient.ServiceId.String() == mux.Vars(r)["service_id"] && apiClient.ID.String() == mux.Vars(r)["id"] {
+				return i
+			}
+		}
+		return -1
+	}
+	write := mockWriter(t)
+	serviceIdReg := strings.Replace(idReg, "{id:", "{service_id:", 1)
+
+	r.HandleFunc("/management/v1/services/"+serviceIdReg+"/api-clients", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		list := []models.ApiClient{}
+		for _, apiClient := range *apiClients {
+			if apiClient.ServiceId.String() == mux.Vars(r)["service_id"] {
+				list = append(list, models.ApiClient{ID: apiClient.ID, ServiceId: apiClient.ServiceId, ProductId: apiClient.ProductId,
+					ProductName: apiClient.ProductName, Status: apiClient.Status, Name: apiClient.Name, CreatedAt: apiClient.CreatedAt})
+			}
+		}
+		write(w, list)
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/services/"+serviceIdReg+"/api-clients", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		var request models.CreateApiClient
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if request.Name == FailingApiClientName {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		serviceId := uuid.MustParse(mux.Vars(r)["service_id"])
+		for _, apiClient := range *apiClients {
+			if apiClient.ServiceId == serviceId && apiClient.Name == request.Name {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		apiClient := models.ApiClientDetail{
+			ID:        uuid.New(),
+			ServiceId: serviceId,
+			ProductId: request.ProductId,
+			Status:    request.Status,
+			Name:      request.Name,
+			Keys:      []string{strings.ReplaceAll(uuid.NewString(), "-", ""), strings.ReplaceAll(uuid.NewString(), "-", "")},
+			PolicyIds: request.PolicyIds,
+			CreatedAt: time.Now().UTC(),
+		}
+		for _, tag := range request.TagIdsValues {
+			apiClient.TagsValues = append(apiClient.TagsValues, models.ApiClientTagValue{Name: tag.Key, Value: tag.Value})
+		}
+		*apiClients = append(*apiClients, apiClient)
+		write(w, apiClient)
+	}).Methods(http.MethodPost)
+
+	r.HandleFunc("/management/v1/services/"+serviceIdReg+"/api-clients/"+idReg, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if i := find(r); i >= 0 {
+			write(w, (*apiClients)[i])
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/services/"+serviceIdReg+"/api-clients/"+idReg, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		var request models.UpdateApiClient
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		i := find(r)
+		if i < 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		apiClient := &(*apiClients)[i]
+		if apiClient.Name == FailingApiClientName {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		apiClient.ProductId = request.ProductId
+		apiClient.PolicyIds = request.PolicyIds
+		apiClient.TagsValues = nil
+		for _, tag := range request.TagIdsValues {
+			apiClient.TagsValues = append(apiClient.TagsValues, models.ApiClientTagValue{Name: tag.Key, Value: tag.Value})
+		}
+		if request.Name != nil {
+			apiClient.Name = *request.Name
+		}
+		if request.Status != nil {
+			apiClient.Status = *request.Status
+		}
+		write(w, models.ApiClient{ID: apiClient.ID, ServiceId: apiClient.ServiceId, ProductId: apiClient.ProductId,
+			Status: apiClient.Status, Name: apiClient.Name, CreatedAt: apiClient.CreatedAt})
+	}).Methods(http.MethodPut)
+
+	r.HandleFunc("/management/v1/services/"+serviceIdReg+"/api-clients/"+idReg, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		i := find(r)
+		if i < 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		*apiClients = append((*apiClients)[:i], (*apiClients)[i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodDelete)
+
+	r.HandleFunc("/management/v1/services/"+serviceIdReg+"/api-clients/"+idReg+"/policies", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if i := find(r); i >= 0 {
+			write(w, models.ApiClientPolicies{PolicyIds: (*apiClients)[i].PolicyIds})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/management/v1/services/"+serviceIdReg+"/api-clients/"+idReg+"/tags", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if i := find(r); i >= 0 {
+			write(w, models.ApiClientTags{TagsValues: (*apiClients)[i].TagsValues})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/appraisal/v1/nonce", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, apiClient := range *apiClients {
+			for _, key := range apiClient.Keys {
+				if key == r.Header.Get("x-api-key") && apiClient.Status == "Active" {
+					write(w, map[string]string{"val": "bm9uY2U=", "iat": "aWF0", "signature": "c2lnbmF0dXJl"})
+					return
+				}
+			}
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}).Methods(http.MethodGet)
+
+}
+
+// policyResponse returns the mock policy, or for tenant signed policies a response recording the submitted policy JWT,
+// its signature and the hash of its rego policy. The hash does not match for policies named TamperedPolicyName.
+func policyResponse(r *http.Request) []byte {
+	var request models.PolicyUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return []byte(policy)
+	}
+	claims := &models.PolicyClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(request.Policy, claims); err != nil {
+		return []byte(policy)
+	}
+
+	var response models.PolicyResponse
+	if err := json.Unmarshal([]byte(policy), &response); err != nil {
+		return []byte(policy)
+	}
+	response.Policy = claims.AttestationPolicy
+	response.PolicyJWT = request.Policy
+	response.PolicySignature = request.Policy[strings.LastIndex(request.Policy, ".")+1:]
+	response.SignedByTenant = true
+	if request.PolicyName != TamperedPolicyName {
+		policyHash := sha512.Sum384([]byte(claims.AttestationPolicy))
+		response.PolicyHash = base64.StdEncoding.EncodeToString(policyHash[:])
+	}
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		return []byte(policy)
+	}
+	return responseBytes
+}
+
 // SetupMockConfiguration setting up mock CLI configurations
 func SetupMockConfiguration(serverUrl string, configFile *os.File) *config.Configuration {
 
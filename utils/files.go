@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package utils
+
+import (
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces the file with the data through a temporary file in the same directory, so that an
+// interrupted write does not lose the previous content
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "Error creating %s", path)
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err = tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return errors.Wrapf(err, "Error writing %s", path)
+	}
+	if err = tempFile.Close(); err != nil {
+		return errors.Wrapf(err, "Error writing %s", path)
+	}
+	if err = os.Chmod(tempFile.Name(), perm); err != nil {
+		return errors.Wrapf(err, "Error writing %s", path)
+	}
+	return os.Rename(tempFile.Name(), path)
+}
+
+// WriteNewFile writes the data to a new file with the permissions. An existing file is only replaced when overwrite
+// is set, it is then removed first so that the file does not keep its previous permissions.
+func WriteNewFile(path string, data []byte, perm os.FileMode, overwrite bool) error {
+	if overwrite {
+		if err := os.Remove(filepath.Clean(path)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "Error replacing %s", path)
+		}
+	}
+	f, err := os.OpenFile(filepath.Clean(path), os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		if os.IsExist(err) {
+			return errors.Errorf("%s already exists", path)
+		}
+		return errors.Wrapf(err, "Error creating %s", path)
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "Error writing %s", path)
+	}
+	return f.Close()
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/pkg/errors"
+	"intel/tac/v1/constants"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// signingKeyGenerators generates the private key of each supported key type
+var signingKeyGenerators = map[string]func() (crypto.Signer, error){
+	constants.KeyTypeRSA2048: func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 2048) },
+	constants.KeyTypeRSA3072: func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 3072) },
+	constants.KeyTypeRSA4096: func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 4096) },
+	constants.KeyTypeECP256:  func() (crypto.Signer, error) { return ecdsa.GenerateKey(elliptic.P256(), rand.Reader) },
+	constants.KeyTypeECP384:  func() (crypto.Signer, error) { return ecdsa.GenerateKey(elliptic.P384(), rand.Reader) },
+	constants.KeyTypeECP521:  func() (crypto.Signer, error) { return ecdsa.GenerateKey(elliptic.P521(), rand.Reader) },
+	constants.KeyTypeEd25519: func() (crypto.Signer, error) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	},
+}
+
+// SigningKeyTypes lists the key types that can be generated
+func SigningKeyTypes() []string {
+	keyTypes := make([]string, 0, len(signingKeyGenerators))
+	for keyType := range signingKeyGenerators {
+		keyTypes = append(keyTypes, keyType)
+	}
+	sort.Strings(keyTypes)
+	return keyTypes
+}
+
+// GenerateSigningKey generates a private key of the key type, which is sized to be accepted by the signing algorithms
+func GenerateSigningKey(keyType string) (crypto.Signer, error) {
+	generate, ok := signingKeyGenerators[strings.ToLower(keyType)]
+	if !ok {
+		return nil, errors.Errorf("Unsupported key type %q, should be one of %s", keyType, strings.Join(SigningKeyTypes(), ", "))
+	}
+	key, err := generate()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error generating private key")
+	}
+	return key, nil
+}
+
+// DescribeKey returns the algorithm and size in bits of the public key
+func DescribeKey(publicKey crypto.PublicKey) (string, int) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return "RSA", key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return "EC " + key.Curve.Params().Name, key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return "Ed25519", 256
+	}
+	return "unknown", 0
+}
+
+// ParseSubject parses a distinguished name either in the OpenSSL "/C=US/O=Example/CN=Policy signer" format or
+// as comma separated attributes "CN=Policy signer,O=Example,C=US". Commas cannot be escaped in the latter format.
+func ParseSubject(subject string) (pkix.Name, error) {
+	var name pkix.Name
+	var attributes []string
+	if strings.HasPrefix(subject, "/") {
+		attributes = strings.Split(strings.TrimPrefix(subject, "/"), "/")
+	} else {
+		attributes = strings.Split(subject, ",")
+	}
+	for _, attribute := range attributes {
+		key, value, found := strings.Cut(strings.TrimSpace(attribute), "=")
+		value = strings.TrimSpace(value)
+		if !found || value == "" {
+			return name, errors.Errorf("Invalid subject attribute %q, should be in the <attribute>=<value> format", attribute)
+		}
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "CN":
+			name.CommonName = value
+		case "O":
+			name.Organization = append(name.Organization, value)
+		case "OU":
+			name.OrganizationalUnit = append(name.OrganizationalUnit, value)
+		case "C":
+			name.Country = append(name.Country, value)
+		case "ST":
+			name.Province = append(name.Province, value)
+		case "L":
+			name.Locality = append(name.Locality, value)
+		case "SERIALNUMBER":
+			name.SerialNumber = value
+		default:
+			return name, errors.Errorf("Unsupported subject attribute %q, should be one of CN, O, OU, C, ST, L or serialNumber", key)
+		}
+	}
+	if name.CommonName == "" {
+		return name, errors.New("Subject should contain a common name (CN)")
+	}
+	return name, nil
+}
+
+// CreateSelfSignedCertificate creates a certificate for the key, valid from now for the number of days, which can
+// be used to sign policies
+func CreateSelfSignedCertificate(key crypto.Signer, subject pkix.Name, days int) (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error generating certificate serial number")
+	}
+	// backdated to accept clocks slightly behind
+	notBefore := time.Now().Add(-5 * time.Minute).UTC()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               subject,
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.AddDate(0, 0, days),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating certificate")
+	}
+	return x509.ParseCertificate(certBytes)
+}
+
+// CreateCertificateRequest creates a PKCS#10 certificate signing request for the key
+func CreateCertificateRequest(key crypto.Signer, subject pkix.Name) ([]byte, error) {
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: subject}, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating certificate signing request")
+	}
+	return csrBytes, nil
+}
+
+// WritePrivateKey writes the key as an unencrypted PKCS#8 PEM file only readable by the current user. An
+// existing file is only replaced when overwrite is set.
+func WritePrivateKey(path string, key crypto.Signer, overwrite bool) error {
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding private key")
+	}
+	return WritePEMFile(path, constants.PKCS8PrivateKeyType, keyBytes, constants.PrivateKeyFilePermission, overwrite)
+}
+
+// WritePEMFile writes the PEM block to the file with the permissions. An existing file is only replaced when
+// overwrite is set.
+func WritePEMFile(path, blockType string, der []byte, perm os.FileMode, overwrite bool) error {
+	return WriteNewFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), perm, overwrite)
+}
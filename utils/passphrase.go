@@ -0,0 +1,29 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package utils
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+	"os"
+)
+
+// ReadPassphrase prompts for a passphrase on the terminal without echoing the input
+func ReadPassphrase(prompt string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, errors.New("Passphrase cannot be prompted for as standard input is not a terminal, use a passphrase file instead")
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading passphrase")
+	}
+	return passphrase, nil
+}
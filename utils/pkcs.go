@@ -0,0 +1,271 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/asn1"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+	"hash"
+	"intel/tac/v1/constants"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// Encrypted PKCS#8 keys are only supported with the PBES2 scheme produced by current OpenSSL releases (PBKDF2 and
+// AES/3DES in CBC mode). PKCS#12 bundles are decoded by go-pkcs12.
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidPKCS12PBE      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC     = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+// maxDERDepth bounds the nesting of the DER structures inspected for iteration counts
+const maxDERDepth = 32
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	EncryptionAlgorithm algorithmIdentifier
+	EncryptedData       []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc algorithmIdentifier
+	EncryptionScheme  algorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                 `asn1:"optional"`
+	Prf            algorithmIdentifier `asn1:"optional"`
+}
+
+// saltAndIterations is the beginning of the PBKDF2 and PKCS#12 PBE parameters
+type saltAndIterations struct {
+	Salt       asn1.RawValue
+	Iterations int
+}
+
+// pfxMacData is the beginning of a PKCS#12 PFX, up to the iteration count of its MAC
+type pfxMacData struct {
+	Version  int
+	AuthSafe asn1.RawValue
+	MacData  struct {
+		Mac        asn1.RawValue
+		MacSalt    []byte
+		Iterations int `asn1:"optional,default:1"`
+	} `asn1:"optional"`
+}
+
+// checkIterationCount rejects the iteration counts of key derivations which would keep the CLI busy for too long
+func checkIterationCount(iterations int) error {
+	if iterations < 1 || iterations > constants.MaxKeyDerivationIterations {
+		return errors.Errorf("Unsupported key derivation iteration count %d, should be between 1 and %d", iterations,
+			constants.MaxKeyDerivationIterations)
+	}
+	return nil
+}
+
+// checkIterationCounts walks the DER encoded structure and checks the iteration count of every PBKDF2 and PKCS#12
+// key derivation found in it, including within octet strings holding DER, as the PKCS#12 safe contents do. Safe
+// contents which are encrypted cannot be inspected, but they are only decrypted once the MAC of the bundle, whose
+// iteration count is checked, or the padding of the outer encryption has confirmed the passphrase.
+func checkIterationCounts(der []byte, depth int) error {
+	if depth > maxDERDepth {
+		return nil
+	}
+	for len(der) > 0 {
+		var value asn1.RawValue
+		rest, err := asn1.Unmarshal(der, &value)
+		if err != nil {
+			// not DER, e.g. an octet string holding encrypted data
+			return nil
+		}
+		der = rest
+
+		switch {
+		case value.Class == asn1.ClassUniversal && value.Tag == asn1.TagSequence:
+			var algorithm algorithmIdentifier
+			if _, err = asn1.Unmarshal(value.FullBytes, &algorithm); err == nil &&
+				(algorithm.Algorithm.Equal(oidPBKDF2) || isPKCS12PBE(algorithm.Algorithm)) {
+				var params saltAndIterations
+				if _, err = asn1.Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+					return errors.Wrap(err, "Error parsing key derivation parameters")
+				}
+				if err = checkIterationCount(params.Iterations); err != nil {
+					return err
+				}
+			}
+			if err = checkIterationCounts(value.Bytes, depth+1); err != nil {
+				return err
+			}
+		case value.IsCompound:
+			if err = checkIterationCounts(value.Bytes, depth+1); err != nil {
+				return err
+			}
+		case value.Class == asn1.ClassUniversal && value.Tag == asn1.TagOctetString:
+			if err = checkIterationCounts(value.Bytes, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isPKCS12PBE tells whether the algorithm is one of the password based encryption schemes of PKCS#12
+func isPKCS12PBE(algorithm asn1.ObjectIdentifier) bool {
+	return len(algorithm) == len(oidPKCS12PBE)+1 && algorithm[:len(oidPKCS12PBE)].Equal(oidPKCS12PBE)
+}
+
+// prfForOID returns the hash of the HMAC pseudorandom function of PBKDF2
+func prfForOID(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case oid.Equal(oidHMACWithSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidHMACWithSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidHMACWithSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidHMACWithSHA512):
+		return sha512.New, nil
+	}
+	return nil, errors.Errorf("Unsupported pseudorandom function %s", oid.String())
+}
+
+// pbes2Decrypt decrypts data protected with the PBES2 scheme described by the algorithm identifier
+func pbes2Decrypt(algorithm algorithmIdentifier, encrypted, password []byte) ([]byte, error) {
+	if !algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, errors.Errorf("Unsupported encryption scheme %s, only PBES2 is supported", algorithm.Algorithm.String())
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, errors.Wrap(err, "Error parsing PBES2 parameters")
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, errors.Errorf("Unsupported key derivation function %s", params.KeyDerivationFunc.Algorithm.String())
+	}
+
+	var kdfParams pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		return nil, errors.Wrap(err, "Error parsing PBKDF2 parameters")
+	}
+	if err := checkIterationCount(kdfParams.IterationCount); err != nil {
+		return nil, err
+	}
+	prf := oidHMACWithSHA1
+	if len(kdfParams.Prf.Algorithm) > 0 {
+		prf = kdfParams.Prf.Algorithm
+	}
+	prfHash, err := prfForOID(prf)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyLength int
+	var newCipher func([]byte) (cipher.Block, error)
+	switch {
+	case params.EncryptionScheme.Algorithm.Equal(oidAES128CBC):
+		keyLength, newCipher = 16, aes.NewCipher
+	case params.EncryptionScheme.Algorithm.Equal(oidAES192CBC):
+		keyLength, newCipher = 24, aes.NewCipher
+	case params.EncryptionScheme.Algorithm.Equal(oidAES256CBC):
+		keyLength, newCipher = 32, aes.NewCipher
+	case params.EncryptionScheme.Algorithm.Equal(oidDESEDE3CBC):
+		keyLength, newCipher = 24, des.NewTripleDESCipher
+	default:
+		return nil, errors.Errorf("Unsupported encryption algorithm %s", params.EncryptionScheme.Algorithm.String())
+	}
+
+	var iv []byte
+	if _, err = asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, errors.Wrap(err, "Error parsing encryption IV")
+	}
+
+	block, err := newCipher(pbkdf2.Key(password, kdfParams.Salt, kdfParams.IterationCount, keyLength, prfHash))
+	if err != nil {
+		return nil, err
+	}
+	blockSize := block.BlockSize()
+	if len(iv) != blockSize || len(encrypted) == 0 || len(encrypted)%blockSize != 0 {
+		return nil, errors.New("Invalid encrypted data length")
+	}
+
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, encrypted)
+
+	// the padding is checked in constant time, a wrong passphrase almost always shows up as invalid padding
+	padding := int(decrypted[len(decrypted)-1])
+	valid := subtle.ConstantTimeLessOrEq(1, padding) & subtle.ConstantTimeLessOrEq(padding, blockSize)
+	lastBlock := decrypted[len(decrypted)-blockSize:]
+	for i := range lastBlock {
+		inPadding := subtle.ConstantTimeLessOrEq(blockSize-padding, i)
+		matches := subtle.ConstantTimeByteEq(lastBlock[i], byte(padding))
+		valid &= subtle.ConstantTimeSelect(inPadding, matches, 1)
+	}
+	if valid != 1 {
+		return nil, errors.New("Decryption failed, the passphrase is probably incorrect")
+	}
+	return decrypted[:len(decrypted)-padding], nil
+}
+
+// DecryptPKCS8PrivateKey decrypts a DER encoded PKCS#8 EncryptedPrivateKeyInfo and parses the private key
+func DecryptPKCS8PrivateKey(der, passphrase []byte) (interface{}, error) {
+	var keyInfo encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &keyInfo); err != nil {
+		return nil, errors.Wrap(err, "Error parsing encrypted private key")
+	}
+	decrypted, err := pbes2Decrypt(keyInfo.EncryptionAlgorithm, keyInfo.EncryptedData, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKCS8PrivateKey(decrypted)
+}
+
+// ParsePKCS12 extracts the private key and the certificates from a DER encoded PKCS#12 bundle
+func ParsePKCS12(der, passphrase []byte) (interface{}, []*x509.Certificate, error) {
+	var pfx pfxMacData
+	if _, err := asn1.Unmarshal(der, &pfx); err != nil {
+		return nil, nil, errors.Wrap(err, "Error parsing PKCS#12 bundle")
+	}
+	if len(pfx.MacData.MacSalt) > 0 {
+		if err := checkIterationCount(pfx.MacData.Iterations); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := checkIterationCounts(der, 0); err != nil {
+		return nil, nil, err
+	}
+
+	key, certificate, caCerts, err := pkcs12.DecodeChain(der, string(passphrase))
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		return nil, nil, errors.New("PKCS#12 integrity check failed, the passphrase is probably incorrect")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, append([]*x509.Certificate{certificate}, caCerts...), nil
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package utils
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"intel/tac/v1/constants"
+	"intel/tac/v1/models"
+	"intel/tac/v1/validation"
+	"os"
+	"strings"
+	"time"
+)
+
+// ParsePolicyToken decodes a policy JWT without verifying its signature
+func ParsePolicyToken(tokenString string) (*jwt.Token, *models.PolicyClaims, error) {
+	claims := &models.PolicyClaims{}
+	token, _, err := jwt.NewParser().ParseUnverified(strings.TrimSpace(tokenString), claims)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Error decoding policy token")
+	}
+	return token, claims, nil
+}
+
+// CertificateChainFromHeader parses the certificates carried in the x5c header of the token, leaf first
+func CertificateChainFromHeader(token *jwt.Token) ([]*x509.Certificate, error) {
+	x5c, ok := token.Header[constants.KeyHeader]
+	if !ok {
+		return nil, nil
+	}
+
+	entries, ok := x5c.([]interface{})
+	if !ok {
+		return nil, errors.Errorf("%s header should be an array of certificates", constants.KeyHeader)
+	}
+
+	var certs []*x509.Certificate
+	for _, entry := range entries {
+		encodedCert, ok := entry.(string)
+		if !ok {
+			return nil, errors.Errorf("%s header contains an entry which is not a string", constants.KeyHeader)
+		}
+		der, err := base64.StdEncoding.DecodeString(encodedCert)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error decoding certificate in %s header", constants.KeyHeader)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error parsing certificate in %s header", constants.KeyHeader)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// LoadCertPool reads all PEM encoded certificates from the file into a certificate pool
+func LoadCertPool(caFilePath string) (*x509.CertPool, error) {
+	if caFilePath == "" {
+		return nil, errors.New("CA certificate file path cannot be empty")
+	}
+	path, err := validation.ValidatePath(caFilePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid CA certificate file path")
+	}
+	caBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading CA certificate file")
+	}
+
+	pool := x509.NewCertPool()
+	found := false
+	for block, rest := pem.Decode(caBytes); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != constants.CertType {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error parsing CA certificate")
+		}
+		pool.AddCert(cert)
+		found = true
+	}
+	if !found {
+		return nil, errors.New("CA certificate file does not contain any PEM encoded certificate")
+	}
+	return pool, nil
+}
+
+// VerifyPolicyToken validates the signature of the policy token with the leaf certificate from the x5c header,
+// checks that the certificate chains up to one of the provided roots and that the algorithm is allowed
+func VerifyPolicyToken(tokenString string, roots *x509.CertPool, allowedAlgorithms []string) (*jwt.Token, []*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		certs, err := CertificateChainFromHeader(token)
+		if err != nil {
+			return nil, err
+		}
+		if len(certs) == 0 {
+			return nil, errors.Errorf("Policy token does not carry a %s header", constants.KeyHeader)
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err = certs[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   time.Now(),
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "Signing certificate is not trusted")
+		}
+		chain = certs
+		return certs[0].PublicKey, nil
+	}
+
+	claims := &models.PolicyClaims{}
+	token, err := jwt.ParseWithClaims(strings.TrimSpace(tokenString), claims, keyFunc,
+		jwt.WithValidMethods(allowedAlgorithms))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Policy token verification failed")
+	}
+	return token, chain, nil
+}
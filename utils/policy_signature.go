@@ -0,0 +1,198 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"math/big"
+	"net/http"
+)
+
+// jsonWebKey holds the members of a JWK used to build RSA, EC and OKP public keys
+type jsonWebKey struct {
+	Kty string   `json:"kty"`
+	Kid string   `json:"kid"`
+	Use string   `json:"use"`
+	N   string   `json:"n"`
+	E   string   `json:"e"`
+	Crv string   `json:"crv"`
+	X   string   `json:"x"`
+	Y   string   `json:"y"`
+	X5c []string `json:"x5c"`
+}
+
+// jwkCurves maps the JWK curve names to the supported elliptic curves
+var jwkCurves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// LoadSigningCertificateKeys returns the public keys of the PEM encoded Trust Authority signing certificates
+func LoadSigningCertificateKeys(certificateFilePath string) ([]crypto.PublicKey, error) {
+	chain, err := ReadCertificateChain(certificateFilePath)
+	if err != nil {
+		return nil, err
+	}
+	var keys []crypto.PublicKey
+	for _, cert := range chain {
+		keys = append(keys, cert.PublicKey)
+	}
+	return keys, nil
+}
+
+// LoadJwks downloads the JSON Web Key Set and returns the public keys it contains. Keys that are not used for
+// signatures or have an unsupported type are skipped.
+func LoadJwks(client *http.Client, jwksUrl string) ([]crypto.PublicKey, error) {
+	resp, err := client.Get(jwksUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error fetching JWKS")
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.WithError(err).Error("Failed to close JWKS response body")
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("Fetching JWKS failed with status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading JWKS")
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err = json.Unmarshal(body, &jwks); err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling JWKS")
+	}
+
+	var keys []crypto.PublicKey
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.WithError(err).Warnf("Skipping JWK %q", jwk.Kid)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS does not contain any supported signing key")
+	}
+	return keys, nil
+}
+
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	if len(jwk.X5c) > 0 {
+		der, err := base64.StdEncoding.DecodeString(jwk.X5c[0])
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid x5c certificate encoding")
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid x5c certificate")
+		}
+		return cert.PublicKey, nil
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid RSA modulus")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("Invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		curve, ok := jwkCurves[jwk.Crv]
+		if !ok {
+			return nil, errors.Errorf("Unsupported curve %q", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid EC x coordinate")
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid EC y coordinate")
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return key, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || jwk.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("Invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, errors.Errorf("Unsupported key type %q", jwk.Kty)
+}
+
+// VerifyPolicySignature checks the base64 encoded signature of the policy returned by Trust Authority with any of
+// the provided keys. The signature is computed over the policy with the digest algorithm of the policy hash.
+func VerifyPolicySignature(policy, policyHash, policySignature string, keys []crypto.PublicKey) error {
+	signature, err := base64.StdEncoding.DecodeString(policySignature)
+	if err != nil {
+		return errors.Wrap(err, "Policy signature is not base64 encoded")
+	}
+
+	hash := crypto.SHA384
+	if decodedHash, err := base64.StdEncoding.DecodeString(policyHash); err == nil {
+		switch len(decodedHash) {
+		case crypto.SHA256.Size():
+			hash = crypto.SHA256
+		case crypto.SHA512.Size():
+			hash = crypto.SHA512
+		}
+	}
+	h := hash.New()
+	h.Write([]byte(policy))
+	digest := h.Sum(nil)
+
+	for _, key := range keys {
+		switch publicKey := key.(type) {
+		case *rsa.PublicKey:
+			if rsa.VerifyPSS(publicKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}) == nil ||
+				rsa.VerifyPKCS1v15(publicKey, hash, digest, signature) == nil {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(publicKey, digest, signature) {
+				return nil
+			}
+			keySize := (publicKey.Curve.Params().BitSize + 7) / 8
+			if len(signature) == 2*keySize && ecdsa.Verify(publicKey, digest,
+				new(big.Int).SetBytes(signature[:keySize]), new(big.Int).SetBytes(signature[keySize:])) {
+				return nil
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(publicKey, []byte(policy), signature) {
+				return nil
+			}
+		}
+	}
+	return errors.New("Policy signature does not match any of the Trust Authority signing keys")
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2024 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package utils
+
+import (
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"intel/tac/v1/constants"
+	"regexp"
+	"strings"
+)
+
+// regoIdentifierRegex matches the claim names that can be referenced with the dot notation in Rego
+var regoIdentifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ParseAttestationToken decodes the claims of an Intel Trust Authority attestation token without verifying it.
+// Numeric claims are kept as json.Number so that they are rendered exactly as they appear in the token.
+func ParseAttestationToken(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, _, err := jwt.NewParser(jwt.WithJSONNumber()).ParseUnverified(strings.TrimSpace(tokenString), claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error decoding attestation token")
+	}
+	return claims, nil
+}
+
+// ReferenceValuePolicy renders a Rego policy that only matches the values of the selected claims. Nested claims
+// are selected with a dot separated path, example "tdx.tdx_mrtd". Trust Authority appraises the policy against the
+// flat claims of the evidence, so the rules only reference the name of the claim, example "input.tdx_mrtd".
+func ReferenceValuePolicy(claims jwt.MapClaims, selectedClaims []string) (string, error) {
+	if len(selectedClaims) == 0 {
+		return "", errors.New("At least one claim needs to be selected")
+	}
+
+	var rules []string
+	ruleClaims := map[string]string{}
+	for _, claim := range selectedClaims {
+		claim = strings.TrimSpace(claim)
+		if claim == "" {
+			return "", errors.New("Claim names cannot be empty")
+		}
+
+		var value interface{} = map[string]interface{}(claims)
+		names := strings.Split(claim, ".")
+		for _, name := range names {
+			for _, perTokenClaim := range constants.PerTokenClaims {
+				if name == perTokenClaim {
+					return "", errors.Errorf("Claim %q differs for every attestation token and cannot be matched", claim)
+				}
+			}
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return "", errors.Errorf("Claim %q is not present in the attestation token", claim)
+			}
+			if value, ok = object[name]; !ok {
+				return "", errors.Errorf("Claim %q is not present in the attestation token", claim)
+			}
+		}
+
+		name := names[len(names)-1]
+		if previous, ok := ruleClaims[name]; ok {
+			return "", errors.Errorf("Claims %q and %q are both matched as %q", previous, claim, name)
+		}
+		ruleClaims[name] = claim
+		path := "input." + name
+		if !regoIdentifierRegex.MatchString(name) {
+			nameBytes, err := json.Marshal(name)
+			if err != nil {
+				return "", err
+			}
+			path = "input[" + string(nameBytes) + "]"
+		}
+
+		var regoValue string
+		switch v := value.(type) {
+		case string, bool, json.Number:
+			valueBytes, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			regoValue = string(valueBytes)
+		default:
+			return "", errors.Errorf("Claim %q is not a string, number or boolean and cannot be matched", claim)
+		}
+		rules = append(rules, path+" == "+regoValue)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Reference value policy generated from the claims of an Intel Trust Authority attestation token.\n")
+	sb.WriteString("# The policy only matches the values of the following claims: " + strings.Join(selectedClaims, ", ") + "\n\n")
+	sb.WriteString("default matches_reference_values = false\n\n")
+	sb.WriteString("matches_reference_values = true {\n")
+	for _, rule := range rules {
+		sb.WriteString("\t" + rule + "\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
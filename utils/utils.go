@@ -8,7 +8,13 @@ package utils
 import (
 	"bufio"
 	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
@@ -85,13 +91,54 @@ func SetUpLogs(logFile io.Writer, logLevel string) error {
 	return nil
 }
 
+// rsaKeyHashSizes maps the supported RSA modulus sizes to the hash size of the matching signing algorithms
+var rsaKeyHashSizes = map[int]string{
+	2048: constants.HashSize256,
+	3072: constants.HashSize384,
+	4096: constants.HashSize512,
+}
+
+// ecdsaCurveAlgorithms maps the supported elliptic curves to their signing algorithm
+var ecdsaCurveAlgorithms = map[string]string{
+	elliptic.P256().Params().Name: constants.ES256,
+	elliptic.P384().Params().Name: constants.ES384,
+	elliptic.P521().Params().Name: constants.ES512,
+}
+
+// SigningAlgorithmsForKey lists the signing algorithms that can be used with the provided private key
+func SigningAlgorithmsForKey(privKey crypto.Signer) []string {
+	switch key := privKey.Public().(type) {
+	case *rsa.PublicKey:
+		hashSize, ok := rsaKeyHashSizes[key.N.BitLen()]
+		if !ok {
+			return nil
+		}
+		return []string{"PS" + hashSize, "RS" + hashSize}
+	case *ecdsa.PublicKey:
+		if algorithm, ok := ecdsaCurveAlgorithms[key.Curve.Params().Name]; ok {
+			return []string{algorithm}
+		}
+	case ed25519.PublicKey:
+		return []string{constants.EdDSA}
+	}
+	return nil
+}
+
 // CheckSigningAlgorithm check if provided algorithm makes sense
-func CheckSigningAlgorithm(privKeyFinal *rsa.PrivateKey, algorithm string) jwt.SigningMethod {
-	if privKeyFinal.N.BitLen() == 2048 && !strings.Contains(algorithm, constants.HashSize256) {
-		fmt.Println("Input private key file and algorithm do not match")
+func CheckSigningAlgorithm(privKeyFinal crypto.Signer, algorithm string) jwt.SigningMethod {
+	validAlgorithms := SigningAlgorithmsForKey(privKeyFinal)
+	if len(validAlgorithms) == 0 {
+		fmt.Println("Input private key type or size is not supported")
 		return nil
 	}
-	if privKeyFinal.N.BitLen() == 3072 && !strings.Contains(algorithm, constants.HashSize384) {
+	matched := false
+	for _, validAlgorithm := range validAlgorithms {
+		if validAlgorithm == algorithm {
+			matched = true
+			break
+		}
+	}
+	if !matched {
 		fmt.Println("Input private key file and algorithm do not match")
 		return nil
 	}
@@ -102,52 +149,241 @@ func CheckSigningAlgorithm(privKeyFinal *rsa.PrivateKey, algorithm string) jwt.S
 	return signMethod
 }
 
-// CheckKeyFiles check input private key and certificate files are valid
-func CheckKeyFiles(privKeyFilePath, certificateFilePath string) (*rsa.PrivateKey, string, error) {
-	if privKeyFilePath == "" {
-		return nil, "", errors.New("Private key file path cannot be empty")
+// PassphraseSource provides the passphrase of an encrypted private key. It is only invoked for encrypted keys.
+type PassphraseSource func() ([]byte, error)
+
+// NewPassphraseSource reads the passphrase from the given file, or prompts for it on the terminal when no file is provided
+func NewPassphraseSource(passphraseFilePath string) PassphraseSource {
+	return func() ([]byte, error) {
+		if passphraseFilePath == "" {
+			return ReadPassphrase("Enter private key passphrase: ")
+		}
+		path, err := validation.ValidatePath(passphraseFilePath)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid passphrase file path")
+		}
+		passphrase, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error reading passphrase file")
+		}
+		return bytes.TrimRight(passphrase, "\r\n"), nil
 	}
+}
 
-	if certificateFilePath == "" {
-		return nil, "", errors.New("Certificate file path cannot be empty")
+func (source PassphraseSource) passphrase() ([]byte, error) {
+	if source == nil {
+		return nil, errors.New("Private key is encrypted but no passphrase was provided")
+	}
+	return source()
+}
+
+// ParsePrivateKey parses a PEM encoded RSA (PKCS#1), EC (SEC1), PKCS#8 or passphrase protected PKCS#8 private key
+func ParsePrivateKey(privKeyBytes []byte, passphrase PassphraseSource) (crypto.Signer, error) {
+	block, _ := pem.Decode(privKeyBytes)
+	if block == nil {
+		return nil, errors.New("Private key file is not PEM encoded")
+	}
+	if _, ok := block.Headers["Proc-Type"]; ok {
+		return nil, errors.New("Legacy encrypted PEM private keys are not supported, convert the key to an encrypted PKCS#8 key")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case constants.RSAPrivateKeyType:
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case constants.ECPrivateKeyType:
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case constants.PKCS8PrivateKeyType:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case constants.EncryptedPrivateKeyType:
+		var secret []byte
+		if secret, err = passphrase.passphrase(); err != nil {
+			return nil, err
+		}
+		key, err = DecryptPKCS8PrivateKey(block.Bytes, secret)
+	default:
+		return nil, errors.Errorf("Unsupported private key PEM type %q", block.Type)
 	}
-	filepath, err := validation.ValidatePath(privKeyFilePath)
 	if err != nil {
-		return nil, "", errors.Wrap(err, "Invalid privKeyFilePath")
+		return nil, err
 	}
-	certfile, err := validation.ValidatePath(certificateFilePath)
+	return toSigner(key)
+}
+
+// toSigner restricts the parsed private key to the key types supported for signing
+func toSigner(key interface{}) (crypto.Signer, error) {
+	switch privKey := key.(type) {
+	case *rsa.PrivateKey:
+		return privKey, nil
+	case *ecdsa.PrivateKey:
+		return privKey, nil
+	case ed25519.PrivateKey:
+		return privKey, nil
+	}
+	return nil, errors.New("Private key should be an RSA, ECDSA or Ed25519 key")
+}
+
+// CheckKeyFiles check input private key and certificate files are valid. The private key file can be a PEM
+// encoded key or a PKCS#12 (.p12/.pfx) bundle, in which case the certificate file is optional. The returned
+// x5c chain contains the base64 encoded DER certificates, leaf first.
+func CheckKeyFiles(privKeyFilePath, certificateFilePath string, passphrase PassphraseSource) (crypto.Signer, []string, error) {
+	if privKeyFilePath == "" {
+		return nil, nil, errors.New("Private key file path cannot be empty")
+	}
+
+	isPKCS12 := IsPKCS12File(privKeyFilePath)
+	if certificateFilePath == "" && !isPKCS12 {
+		return nil, nil, errors.New("Certificate file path cannot be empty")
+	}
+	filepath, err := validation.ValidatePath(privKeyFilePath)
 	if err != nil {
-		return nil, "", errors.Wrap(err, "Invalid certificateFilePath")
+		return nil, nil, errors.Wrap(err, "Invalid privKeyFilePath")
 	}
 	privKeyBytes, err := os.ReadFile(filepath)
 	if err != nil {
-		return nil, "", errors.Wrap(err, "Error reading private key file")
+		return nil, nil, errors.Wrap(err, "Error reading private key file")
 	}
 
-	privKeyFinal, err := jwt.ParseRSAPrivateKeyFromPEM(privKeyBytes)
-	if err != nil {
-		return nil, "", errors.Wrap(err, "Error parsing private key PEM file")
+	var privKeyFinal crypto.Signer
+	var chain []*x509.Certificate
+	if isPKCS12 {
+		secret, err := passphrase.passphrase()
+		if err != nil {
+			return nil, nil, err
+		}
+		key, bundleCerts, err := ParsePKCS12(privKeyBytes, secret)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Error parsing PKCS#12 file")
+		}
+		if privKeyFinal, err = toSigner(key); err != nil {
+			return nil, nil, err
+		}
+		if chain, err = orderCertificateChain(privKeyFinal, bundleCerts); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		privKeyFinal, err = ParsePrivateKey(privKeyBytes, passphrase)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Error parsing private key PEM file")
+		}
+	}
+
+	// an explicitly provided certificate file takes precedence over the certificates of a PKCS#12 bundle
+	if certificateFilePath != "" {
+		if chain, err = ReadCertificateChain(certificateFilePath); err != nil {
+			return nil, nil, err
+		}
 	}
 
+	if err = CheckKeyMatchesCertificate(privKeyFinal, chain[0]); err != nil {
+		return nil, nil, err
+	}
+	if err = ValidateCertificateChain(chain, time.Now()); err != nil {
+		return nil, nil, err
+	}
+	return privKeyFinal, EncodeCertificateChain(chain), nil
+}
+
+// ReadCertificateChain reads the PEM encoded certificates of the file, keeping the order of the file
+func ReadCertificateChain(certificateFilePath string) ([]*x509.Certificate, error) {
+	certfile, err := validation.ValidatePath(certificateFilePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid certificateFilePath")
+	}
 	certBytes, err := os.ReadFile(certfile)
 	if err != nil {
-		return nil, "", errors.Wrap(err, "Error reading certificate file")
+		return nil, errors.Wrap(err, "Error reading certificate file")
 	}
-
-	cert, err := parseCertificate(certBytes)
+	chain, err := parseCertificateChain(certBytes)
 	if err != nil {
-		return nil, "", errors.Wrap(err, "Error parsing certificate")
+		return nil, errors.Wrap(err, "Error parsing certificate")
+	}
+	return chain, nil
+}
+
+// EncodeCertificateChain returns the x5c header value of the certificate chain
+func EncodeCertificateChain(chain []*x509.Certificate) []string {
+	var x5c []string
+	for _, cert := range chain {
+		x5c = append(x5c, base64.StdEncoding.EncodeToString(cert.Raw))
 	}
+	return x5c
+}
 
-	pubKeyBytesFromCert := publicKeyToBytes(cert.PublicKey.(*rsa.PublicKey))
-	pubKeyBytesFromPriv := publicKeyToBytes(&privKeyFinal.PublicKey)
+// IsPKCS12File reports whether the file name has a PKCS#12 extension
+func IsPKCS12File(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == constants.PKCS12FileExtension || ext == constants.PFXFileExtension
+}
+
+// ValidateCertificateChain checks that every certificate is within its validity period and is issued by the
+// certificate that follows it
+func ValidateCertificateChain(chain []*x509.Certificate, now time.Time) error {
+	for i, cert := range chain {
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return errors.Errorf("Certificate %q is not valid at the current time (valid from %s to %s)", cert.Subject.String(),
+				cert.NotBefore.UTC().Format(time.RFC3339), cert.NotAfter.UTC().Format(time.RFC3339))
+		}
+		if i == len(chain)-1 {
+			break
+		}
+		if err := cert.CheckSignatureFrom(chain[i+1]); err != nil {
+			return errors.Wrapf(err, "Certificate chain is not ordered leaf first, %q is not issued by %q",
+				cert.Subject.String(), chain[i+1].Subject.String())
+		}
+	}
+	return nil
+}
 
-	if bytes.Compare(pubKeyBytesFromCert, pubKeyBytesFromPriv) != 0 {
-		return nil, "", errors.New("Provided private key and certificate do not match")
+// orderCertificateChain builds the chain of the key from an unordered set of certificates, leaf first
+func orderCertificateChain(privKey crypto.Signer, certs []*x509.Certificate) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	for _, cert := range certs {
+		if CheckKeyMatchesCertificate(privKey, cert) == nil {
+			chain = append(chain, cert)
+			break
+		}
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("PKCS#12 bundle does not contain a certificate for the private key")
 	}
 
-	certContents := base64.StdEncoding.EncodeToString(cert.Raw)
-	return privKeyFinal, certContents, nil
+	for len(chain) < len(certs) {
+		current := chain[len(chain)-1]
+		if bytes.Equal(current.RawIssuer, current.RawSubject) {
+			break
+		}
+		var issuer *x509.Certificate
+		for _, cert := range certs {
+			if cert != current && bytes.Equal(current.RawIssuer, cert.RawSubject) && current.CheckSignatureFrom(cert) == nil {
+				issuer = cert
+				break
+			}
+		}
+		if issuer == nil {
+			break
+		}
+		chain = append(chain, issuer)
+	}
+	return chain, nil
+}
+
+// CheckKeyMatchesCertificate checks that the public key of the certificate belongs to the private key
+func CheckKeyMatchesCertificate(privKey crypto.Signer, cert *x509.Certificate) error {
+	pubKeyBytesFromCert, err := publicKeyToBytes(cert.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "Error reading certificate public key")
+	}
+	pubKeyBytesFromPriv, err := publicKeyToBytes(privKey.Public())
+	if err != nil {
+		return errors.Wrap(err, "Error reading private key public part")
+	}
+
+	if !bytes.Equal(pubKeyBytesFromCert, pubKeyBytesFromPriv) {
+		return errors.New("Provided private key and certificate do not match")
+	}
+	return nil
 }
 
 func GenerateOutputFileName(inputFile string) (string, error) {
@@ -161,6 +397,35 @@ func GenerateOutputFileName(inputFile string) (string, error) {
 	return filename + ".signed." + date + ".txt", nil
 }
 
+// PolicyHashStatus compares the base64 encoded policy hash returned by Trust Authority against the hash of the
+// provided policy. The digest algorithm is derived from the length of the decoded hash.
+func PolicyHashStatus(policy, policyHash string) string {
+	expected, err := base64.StdEncoding.DecodeString(policyHash)
+	if err != nil {
+		return constants.PolicyHashUnverifiable
+	}
+
+	var actual []byte
+	switch len(expected) {
+	case sha256.Size:
+		digest := sha256.Sum256([]byte(policy))
+		actual = digest[:]
+	case sha512.Size384:
+		digest := sha512.Sum384([]byte(policy))
+		actual = digest[:]
+	case sha512.Size:
+		digest := sha512.Sum512([]byte(policy))
+		actual = digest[:]
+	default:
+		return constants.PolicyHashUnverifiable
+	}
+
+	if !bytes.Equal(expected, actual) {
+		return constants.PolicyHashMismatch
+	}
+	return constants.PolicyHashVerified
+}
+
 func PrintRequestAndTraceId() {
 	if models2.RespHeaderFields.RequestId != "" {
 		fmt.Println(constants.HTTPHeaderKeyRequestId+": ", models2.RespHeaderFields.RequestId)
@@ -171,10 +436,10 @@ func PrintRequestAndTraceId() {
 }
 
 // publicKeyToBytes public key to bytes
-func publicKeyToBytes(pub *rsa.PublicKey) []byte {
+func publicKeyToBytes(pub crypto.PublicKey) ([]byte, error) {
 	pubASN1, err := x509.MarshalPKIXPublicKey(pub)
 	if err != nil {
-		fmt.Println(err.Error())
+		return nil, err
 	}
 
 	pubBytes := pem.EncodeToMemory(&pem.Block{
@@ -182,14 +447,24 @@ func publicKeyToBytes(pub *rsa.PublicKey) []byte {
 		Bytes: pubASN1,
 	})
 
-	return pubBytes
+	return pubBytes, nil
 }
 
-// parseCertificate parse certificate from unencrypted string format
-func parseCertificate(certBytes []byte) (*x509.Certificate, error) {
-	certBlock, _ := pem.Decode(certBytes)
-	if certBlock == nil || certBlock.Type != constants.CertType {
+// parseCertificateChain parses all PEM encoded certificates, keeping the order of the file
+func parseCertificateChain(certBytes []byte) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	for certBlock, rest := pem.Decode(certBytes); certBlock != nil; certBlock, rest = pem.Decode(rest) {
+		if certBlock.Type != constants.CertType {
+			return nil, errors.New("not a valid certificate pem file")
+		}
+		cert, err := x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
 		return nil, errors.New("not a valid certificate pem file")
 	}
-	return x509.ParseCertificate(certBlock.Bytes)
+	return chain, nil
 }
@@ -16,6 +16,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
 var (
@@ -33,6 +34,11 @@ var (
 	fileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_. -]{1,255}$`)
 	//in file path, characters allowed are a-z, A-Z, 0-9, _, ., -, \, /, :
 	filePathRegex = regexp.MustCompile(`^[a-zA-Z0-9_. :/\\-]*$`)
+	hexRegex      = regexp.MustCompile(`^[a-fA-F0-9]*$`)
+	profileRegex  = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+	// label keys may use / and . to namespace them, for example "example.com/team"
+	labelKeyRegex   = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9_./-]{0,61}[a-zA-Z0-9])?$`)
+	labelValueRegex = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9_.-]{0,61}[a-zA-Z0-9])?)?$`)
 )
 
 func ValidateEmailAddress(email string) error {
@@ -59,6 +65,20 @@ func ValidatePath(path string) (string, error) {
 	return r, nil
 }
 
+// ValidateOutputPath validates the path of a file to be written. The file may not exist yet, so only its directory
+// is resolved.
+func ValidateOutputPath(path string) (string, error) {
+	cleanedPath := filepath.Clean(path)
+	if err := checkFilePathForInvalidChars(cleanedPath); err != nil {
+		return "", err
+	}
+	dir, err := ValidatePath(filepath.Dir(cleanedPath))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.Base(cleanedPath)), nil
+}
+
 func ValidateSize(path string) error {
 	fi, err := os.Stat(path)
 	if err != nil {
@@ -144,6 +164,47 @@ func ValidateRequestId(requestId string) error {
 	return nil
 }
 
+// ValidateMeasurement checks that the measurement is a hex encoded value of the expected size in bytes
+func ValidateMeasurement(name, measurement string, size int) error {
+	if len(measurement) != 2*size || !hexRegex.MatchString(measurement) {
+		return errors.Errorf("%s should be a hex encoded value of %d characters", name, 2*size)
+	}
+	return nil
+}
+
+func ValidateProfileName(profile string) error {
+	if !profileRegex.MatchString(profile) {
+		return errors.New("Profile name should be alphanumeric with _ or - as separators and should be at most 64 characters long")
+	}
+	return nil
+}
+
+func ValidateLabelKey(key string) error {
+	if !labelKeyRegex.MatchString(key) {
+		return errors.New("Label key should be alphanumeric with _, -, . or / as separators and should be at most 63 characters long")
+	}
+	return nil
+}
+
+func ValidateLabelValue(value string) error {
+	if !labelValueRegex.MatchString(value) {
+		return errors.New("Label value should be alphanumeric with _, - or . as separators and should be at most 63 characters long")
+	}
+	return nil
+}
+
+func ValidateDescription(description string) error {
+	if len(description) > constants.MaxDescriptionLength {
+		return errors.Errorf("Description should be at most %d characters long", constants.MaxDescriptionLength)
+	}
+	for _, r := range description {
+		if unicode.IsControl(r) {
+			return errors.New("Description cannot contain control characters")
+		}
+	}
+	return nil
+}
+
 func ValidateURL(baseURL string) error {
 	baseUrl, err := url.Parse(baseURL)
 	if err != nil {